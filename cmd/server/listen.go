@@ -0,0 +1,82 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// listenOn opens the listener of the server. A Unix socket left behind by
+// a previous run is removed first, the new one is only accessible to its
+// owner and server.socketGroup.
+func listenOn(network, address string, s config.ServerSettings) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	if fi, err := os.Lstat(address); err == nil {
+		if fi.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", address)
+		}
+		if err := os.Remove(address); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	gid, err := s.SocketGroupID()
+	if err == nil && gid >= 0 {
+		err = os.Chown(address, -1, gid)
+	}
+	if err == nil {
+		err = os.Chmod(address, 0660)
+	}
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// unixPeer treats the requests on the Unix socket as made from 127.0.0.1:
+// only local processes can connect, as to a port on the loopback
+// interface, so the access list, trusted proxies and ban callbacks
+// work the same.
+func unixPeer(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.RemoteAddr = "127.0.0.1:0"
+		h.ServeHTTP(w, req)
+	})
+}
+
+// listenURL describes where the server listens for the banner.
+func listenURL(scheme, network, address string) string {
+	if network == "unix" {
+		return scheme + "+unix://" + address
+	}
+	if strings.HasPrefix(address, ":") {
+		address = "0.0.0.0" + address
+	}
+	return scheme + "://" + address
+}
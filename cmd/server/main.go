@@ -17,59 +17,177 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
 	"github.com/swissmakers/fail2ban-ui/pkg/web"
 )
 
 func main() {
+	// Core options from the environment, overridden by the flags.
+	opts, err := config.OptionsFromEnv()
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	flag.IntVar(&opts.Port, "port", opts.Port, "port to listen on instead of server.port ("+config.PortEnv+")")
+	flag.StringVar(&opts.Listen, "listen", opts.Listen, "Unix socket (unix:/path) or host:port to listen on instead of server.listen and the port ("+config.ListenEnv+")")
+	flag.StringVar(&opts.SettingsPath, "settings", opts.SettingsPath, "settings file, the other data files are stored next to it ("+config.SettingsPathEnv+")")
+	flag.StringVar(&opts.LogPath, "log-path", opts.LogPath, "fail2ban log file, default "+fail2ban.DefaultLogPath+" ("+config.LogPathEnv+")")
+	flag.StringVar(&opts.GeoIPPath, "geoip-path", opts.GeoIPPath, "directory of the GeoIP databases, default /usr/share/GeoIP ("+config.GeoIPPathEnv+")")
+	flag.StringVar(&opts.TemplatesDir, "templates-dir", opts.TemplatesDir, "directory of the HTML templates ("+config.TemplatesDirEnv+")")
+	flag.StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "YAML, TOML or JSON file with settings applied on every start ("+config.ConfigEnv+")")
+	flag.Parse()
+	if err := config.Load(opts); err != nil {
+		log.Fatalf("Failed to load the settings: %v\n", err)
+	}
+
 	// Get application settings from the config package.
 	settings := config.GetSettings()
 
 	// Set Gin mode based on the debug flag in settings.
-	if settings.Debug {
+	if settings.Server.Debug {
 		gin.SetMode(gin.DebugMode)
 	} else {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Create a new Gin router.
-	router := gin.Default()
-	serverPort := strconv.Itoa(int(settings.Port))
-
 	// Load HTML templates depending on whether the application is running inside a container.
+	cfg := web.Config{LogPath: cmp.Or(opts.LogPath, fail2ban.DefaultLogPath)}
 	_, container := os.LookupEnv("CONTAINER")
 	if container {
 		// In container, templates are assumed to be in /app/templates
-		router.LoadHTMLGlob("/app/templates/*")
-		router.Static("/locales", "/app/locales")
+		cfg.TemplateGlob = "/app/templates/*"
+		cfg.LocalesDir = "/app/locales"
 	} else {
 		// When running locally, load templates from pkg/web/templates
-		router.LoadHTMLGlob("pkg/web/templates/*")
-		router.Static("/locales", "./internal/locales")
+		cfg.TemplateGlob = "pkg/web/templates/*"
+		cfg.LocalesDir = "./internal/locales"
+	}
+	if opts.TemplatesDir != "" {
+		cfg.TemplateGlob = filepath.Join(opts.TemplatesDir, "*")
+	}
+
+	// Report configuration problems now rather than on the next failed reload.
+	for _, f := range fail2ban.LintConfig().Findings {
+		log.Printf("⚠️ Config lint (%s) %s:%d: %s", f.Severity, f.File, f.Line, f.Message)
 	}
 
-	// Register all application routes, including the static files and templates.
-	web.RegisterRoutes(router)
+	// Create the handler serving all application routes, including the static files and templates.
+	handler := web.NewHandler(cfg)
 
-	printWelcomeBanner(serverPort)
+	// Serve HTTPS directly when a certificate or ACME is configured.
+	network, address := config.ListenAddress()
+	srv := &http.Server{Addr: address, Handler: handler}
+	if network == "unix" {
+		srv.Handler = unixPeer(handler)
+	}
+	srv.RegisterOnShutdown(web.CloseStreams)
+	scheme := "http"
+	var redirectSrv *http.Server
+	if tlsSettings := settings.Server.TLS; tlsSettings.Enabled() {
+		port := config.ListenPort()
+		redirect := redirectToHTTPS(port)
+		if acmeSettings := tlsSettings.ACME; acmeSettings.Enabled {
+			// The manager also answers the TLS-ALPN challenges on the
+			// server port and the HTTP challenges on the redirect port.
+			m := newACMEManager(acmeSettings)
+			srv.TLSConfig = m.TLSConfig()
+			srv.TLSConfig.MinVersion = tls.VersionTLS12
+			redirect = m.HTTPHandler(redirect)
+			log.Println("Obtaining certificates via ACME for", strings.Join(acmeSettings.Hostnames, ", "), "in", acmeSettings.Cache())
+			if port != 443 && tlsSettings.RedirectPort != 80 {
+				log.Printf("⚠️ ACME validates on port 443 or 80, but the UI listens on %d and redirects on %d", port, tlsSettings.RedirectPort)
+			}
+		} else {
+			certs, err := newCertReloader(tlsSettings.Files())
+			if err != nil {
+				log.Fatalf("Could not load TLS certificate: %v\n", err)
+			}
+			srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certs.GetCertificate}
+		}
+		if clientCerts := tlsSettings.ClientCerts; clientCerts.Enabled() {
+			// Only clients having a certificate send one, so browsers
+			// are not bothered; the API checks who presented one.
+			pool, err := clientCerts.Pool()
+			if err != nil {
+				log.Fatalf("Could not load client CA file: %v\n", err)
+			}
+			srv.TLSConfig.ClientCAs = pool
+			srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			log.Println("Accepting API client certificates issued by", clientCerts.CAFile)
+		}
+		scheme = "https"
+		if tlsSettings.RedirectPort > 0 {
+			redirectSrv = &http.Server{
+				Addr:              ":" + strconv.Itoa(tlsSettings.RedirectPort),
+				Handler:           redirect,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+		}
+	}
+
+	ln, err := listenOn(network, address, settings.Server)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v\n", address, err)
+	}
+	printWelcomeBanner(listenURL(scheme, network, address))
 	log.Println("--- Fail2Ban-UI started in", gin.Mode(), "mode ---")
-	log.Println("Server listening on port", serverPort, ".")
+	log.Println("Server listening on", address, "("+scheme+").")
 
-	// Start the server on port 8080.
-	if err := router.Run(":" + serverPort); err != nil {
-		log.Fatalf("Could not start server: %v\n", err)
+	// Start the server on port 8080 or the configured address.
+	go func() {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ServeTLS(ln, "", "")
+		} else {
+			err = srv.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Could not start server: %v\n", err)
+		}
+	}()
+	if redirectSrv != nil {
+		log.Println("Redirecting HTTP on port", settings.Server.TLS.RedirectPort, "to HTTPS.")
+		go func() {
+			if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Could not start HTTP redirect server: %v\n", err)
+			}
+		}()
+	}
+
+	// On SIGINT/SIGTERM let running requests, e.g. settings saves, finish before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(shutdownCtx)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ Shutdown: %v", err)
 	}
 }
 
 // printWelcomeBanner prints a cool Tux banner with startup info.
-func printWelcomeBanner(appPort string) {
+func printWelcomeBanner(url string) {
 	greeting := getGreeting()
 	const tuxBanner = `
       .--.
@@ -84,11 +202,11 @@ Fail2Ban UI - A Swissmade Management Interface
 ----------------------------------------------
 Developers:   https://swissmakers.ch
 Mode:         %s
-Listening on: http://0.0.0.0:%s
+Listening on: %s
 ----------------------------------------------
 
 `
-	fmt.Printf(tuxBanner, greeting, gin.Mode(), appPort)
+	fmt.Printf(tuxBanner, greeting, gin.Mode(), url)
 }
 
 // getGreeting returns a friendly greeting based on the time of day.
@@ -0,0 +1,116 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AccessSettings restrict which clients may reach the UI and the API.
+type AccessSettings struct {
+	// AllowedClients are the IPs/CIDRs allowed to connect; empty allows everyone.
+	AllowedClients []string `json:"allowedClients"`
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header is honored.
+	TrustedProxies []string `json:"trustedProxies"`
+}
+
+// AccessList is the parsed form of AccessSettings.
+type AccessList struct {
+	Allowed []*net.IPNet
+	Proxies []*net.IPNet
+}
+
+// Compile parses the settings.
+func (a AccessSettings) Compile() (AccessList, error) {
+	var acl AccessList
+	var err error
+	if acl.Allowed, err = parseNetworks(a.AllowedClients); err != nil {
+		return AccessList{}, fmt.Errorf("allowed clients: %w", err)
+	}
+	if acl.Proxies, err = parseNetworks(a.TrustedProxies); err != nil {
+		return AccessList{}, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return acl, nil
+}
+
+// Enabled reports whether access is restricted at all.
+func (acl AccessList) Enabled() bool {
+	return len(acl.Allowed) > 0
+}
+
+// Allows reports whether ip may connect.
+func (acl AccessList) Allows(ip net.IP) bool {
+	return !acl.Enabled() || containsIP(acl.Allowed, ip)
+}
+
+// ClientIP returns the address of the client behind remoteAddr. The
+// X-Forwarded-For entries are only followed while the hop that added
+// them is a trusted proxy, so clients cannot spoof their address.
+func (acl AccessList) ClientIP(remoteAddr string, forwardedFor []string) net.IP {
+	ip := peerIP(remoteAddr)
+	var hops []string
+	for _, header := range forwardedFor {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && ip != nil && containsIP(acl.Proxies, ip); i-- {
+		ip = net.ParseIP(strings.Trim(strings.TrimSpace(hops[i]), "[]"))
+	}
+	return ip
+}
+
+// FromProxy reports whether the connection from remoteAddr is made by a
+// trusted proxy.
+func (acl AccessList) FromProxy(remoteAddr string) bool {
+	return containsIP(acl.Proxies, peerIP(remoteAddr))
+}
+
+func peerIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+func parseNetworks(values []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		n, err := parseNetwork(v)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
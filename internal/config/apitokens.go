@@ -0,0 +1,181 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Scopes of API tokens. Each grants the routes of a role, see RoleAdmin.
+const (
+	ScopeRead   = "read"   // the summary and other read APIs, like a viewer
+	ScopeBan    = "ban"    // banning, unbanning and annotating IPs, like an operator
+	ScopeConfig = "config" // filters, jails, settings and restarts, like an admin
+)
+
+// APITokenPrefix starts the secret of every API token, so it can be told
+// apart from ingest tokens and the UI login.
+const APITokenPrefix = "f2bapi_"
+
+// scopeRoles maps the scopes to the roles whose routes they open.
+var scopeRoles = map[string]string{ScopeRead: RoleViewer, ScopeBan: RoleOperator, ScopeConfig: RoleAdmin}
+
+// APIToken lets automation call the REST API without the UI login.
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"` // SHA-256 of the token, which is only shown once
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+}
+
+const apiTokensFile = "fail2ban-ui-api-tokens.json" // stored next to the settings file
+
+// ErrAPITokenNotFound is returned when an API token does not exist.
+var ErrAPITokenNotFound = errors.New("API token not found")
+
+var (
+	apiTokens       []APIToken
+	apiTokensLoaded bool
+	apiTokensLock   sync.Mutex
+)
+
+// Validate checks the fields set by the user.
+func (t APIToken) Validate() error {
+	if t.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(t.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, s := range t.Scopes {
+		if _, ok := scopeRoles[s]; !ok {
+			return fmt.Errorf("unknown scope %q, use read, ban or config", s)
+		}
+	}
+	return nil
+}
+
+// Role returns the highest role opened by the scopes of the token.
+func (t APIToken) Role() string {
+	role := ""
+	for _, s := range t.Scopes {
+		if r := scopeRoles[s]; role == "" || HasRole(r, role) {
+			role = r
+		}
+	}
+	return role
+}
+
+// Allows reports whether the token has the scope for the routes of role.
+func (t APIToken) Allows(role string) bool {
+	for _, s := range t.Scopes {
+		if scopeRoles[s] == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeFor returns the scope opening the routes of role.
+func ScopeFor(role string) string {
+	for s, r := range scopeRoles {
+		if r == role {
+			return s
+		}
+	}
+	return ""
+}
+
+// GetAPITokens returns a copy of all API tokens.
+func GetAPITokens() []APIToken {
+	apiTokensLock.Lock()
+	defer apiTokensLock.Unlock()
+	loadAPITokens()
+	return append([]APIToken(nil), apiTokens...)
+}
+
+// AddAPIToken stores a new API token with a generated secret. The secret
+// is returned once, only its hash is kept.
+func AddAPIToken(t APIToken) (APIToken, string, error) {
+	apiTokensLock.Lock()
+	defer apiTokensLock.Unlock()
+	loadAPITokens()
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	secret := APITokenPrefix + hex.EncodeToString(b)
+	t.ID = newID()
+	t.Hash = hashToken(secret)
+	t.Scopes = slices.Compact(slices.Sorted(slices.Values(t.Scopes)))
+	t.CreatedAt = time.Now()
+	apiTokens = append(apiTokens, t)
+	return t, secret, writeJSONFile(apiTokensFile, apiTokens)
+}
+
+// DeleteAPIToken revokes the API token with the given ID.
+func DeleteAPIToken(id string) error {
+	apiTokensLock.Lock()
+	defer apiTokensLock.Unlock()
+	loadAPITokens()
+
+	for i := range apiTokens {
+		if apiTokens[i].ID == id {
+			apiTokens = append(apiTokens[:i], apiTokens[i+1:]...)
+			return writeJSONFile(apiTokensFile, apiTokens)
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// LookupAPIToken returns the API token with the given secret.
+func LookupAPIToken(secret string) (APIToken, bool) {
+	apiTokensLock.Lock()
+	defer apiTokensLock.Unlock()
+	loadAPITokens()
+
+	hash := hashToken(secret)
+	for _, t := range apiTokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+			return t, true
+		}
+	}
+	return APIToken{}, false
+}
+
+// loadAPITokens reads the API tokens file once. The caller must hold
+// apiTokensLock.
+func loadAPITokens() {
+	if apiTokensLoaded {
+		return
+	}
+	apiTokensLoaded = true
+	if err := readJSONFile(apiTokensFile, &apiTokens); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", apiTokensFile, err)
+	}
+}
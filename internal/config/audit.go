@@ -0,0 +1,100 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditEntry records an administrative action, e.g. a console command.
+type AuditEntry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	ClientIP string    `json:"clientIP,omitempty"`
+	Action   string    `json:"action"`           // e.g. "console"
+	Detail   string    `json:"detail,omitempty"` // e.g. the command line
+	Output   string    `json:"output,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+const (
+	// maxAuditEntries is the number of entries kept, oldest are dropped first.
+	maxAuditEntries = 2000
+	// maxAuditOutput is the number of output bytes kept per entry.
+	maxAuditOutput = 4096
+)
+
+const auditFile = "fail2ban-ui-audit.json" // stored next to the settings file
+
+var (
+	auditLog       []AuditEntry
+	auditLogLoaded bool
+	auditLogLock   sync.Mutex
+)
+
+// RecordAudit appends e to the audit log. Long outputs are truncated.
+func RecordAudit(e AuditEntry) error {
+	auditLogLock.Lock()
+	defer auditLogLock.Unlock()
+	loadAuditLog()
+
+	e.ID = newID()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if len(e.Output) > maxAuditOutput {
+		e.Output = e.Output[:maxAuditOutput] + "\n[...]"
+	}
+	auditLog = append(auditLog, e)
+	if len(auditLog) > maxAuditEntries {
+		auditLog = append([]AuditEntry(nil), auditLog[len(auditLog)-maxAuditEntries:]...)
+	}
+	return writeJSONFile(auditFile, auditLog)
+}
+
+// GetAuditLog returns up to limit entries of the given action ("" for all), newest first.
+func GetAuditLog(action string, limit int) []AuditEntry {
+	auditLogLock.Lock()
+	defer auditLogLock.Unlock()
+	loadAuditLog()
+
+	var out []AuditEntry
+	for i := len(auditLog) - 1; i >= 0; i-- {
+		if action != "" && auditLog[i].Action != action {
+			continue
+		}
+		out = append(out, auditLog[i])
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+// loadAuditLog reads the audit log once. The caller must hold auditLogLock.
+func loadAuditLog() {
+	if auditLogLoaded {
+		return
+	}
+	auditLogLoaded = true
+	if err := readJSONFile(auditFile, &auditLog); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", auditFile, err)
+	}
+}
@@ -0,0 +1,248 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Authentication modes, see AuthSettings.
+const (
+	AuthNone     = ""         // no authentication, or by the host application (web.Config.UserFunc)
+	AuthHeader   = "header"   // trust identity headers of an authenticating reverse proxy
+	AuthPassword = "password" // built-in login with the admin password
+)
+
+// AdminPasswordHashEnv names the environment variable holding the bcrypt
+// hash of the admin password. It takes precedence over the settings file
+// and enables password mode when no other mode is set.
+const AdminPasswordHashEnv = "FAIL2BAN_UI_ADMIN_PASSWORD_HASH"
+
+// MinPasswordLength is the shortest admin password accepted by SetAdminPassword.
+const MinPasswordLength = 10
+
+// Roles of authenticated users, each including the rights of the ones
+// below it.
+const (
+	RoleAdmin    = "admin"    // full access, including filters, settings and restarts
+	RoleOperator = "operator" // may also ban and unban IPs and annotate them
+	RoleViewer   = "viewer"   // read-only access
+)
+
+// roleRanks orders the roles by their rights.
+var roleRanks = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// HasRole reports whether role grants at least the rights of min.
+func HasRole(role, min string) bool {
+	return roleRanks[role] >= roleRanks[min]
+}
+
+// AuthSettings configure how users are authenticated and who may reach the
+// UI. In header mode the UI trusts the identity headers set by a reverse
+// proxy such as Authelia or oauth2-proxy; they are only accepted from the
+// TrustedProxies of the access settings.
+type AuthSettings struct {
+	Mode         string `json:"mode"`
+	UserHeader   string `json:"userHeader"`   // default Remote-User
+	GroupsHeader string `json:"groupsHeader"` // default Remote-Groups, comma separated
+	// AdminGroups, OperatorGroups and ViewerGroups map the proxy's groups
+	// to roles, the highest role wins. Users in none get no access; when
+	// no groups and no Roles are set every user is an admin.
+	AdminGroups    []string `json:"adminGroups"`
+	OperatorGroups []string `json:"operatorGroups"`
+	ViewerGroups   []string `json:"viewerGroups"`
+	// Roles assigns roles to single users by name, overriding their groups.
+	Roles map[string]string `json:"roles"`
+
+	// Login in password mode, see AdminPasswordHashEnv
+	AdminUser    string `json:"adminUser"`                      // default admin
+	PasswordHash string `json:"passwordHash" settings:"secret"` // bcrypt
+	// Second factor of the login in password mode, see totp.go
+	TOTP TOTPSettings `json:"totp"`
+
+	Access         AccessSettings         `json:"access"`
+	SelfProtection SelfProtectionSettings `json:"selfProtection"`
+	// Customer scoping for service providers, see tenants.go
+	Tenants []Tenant `json:"tenants"`
+	// UI sessions in header mode, see sessions.go
+	Sessions SessionSettings `json:"sessions"`
+}
+
+// User returns the name of the user header.
+func (a AuthSettings) User() string {
+	if a.UserHeader == "" {
+		return "Remote-User"
+	}
+	return a.UserHeader
+}
+
+// Groups returns the name of the groups header.
+func (a AuthSettings) Groups() string {
+	if a.GroupsHeader == "" {
+		return "Remote-Groups"
+	}
+	return a.GroupsHeader
+}
+
+// Admin returns the name of the admin user in password mode.
+func (a AuthSettings) Admin() string {
+	if a.AdminUser == "" {
+		return "admin"
+	}
+	return a.AdminUser
+}
+
+// AdminPasswordHash returns the bcrypt hash of the admin password, from
+// the environment or the settings.
+func (a AuthSettings) AdminPasswordHash() string {
+	if hash := os.Getenv(AdminPasswordHashEnv); hash != "" {
+		return hash
+	}
+	return a.PasswordHash
+}
+
+// PasswordLogin reports whether users log in with the admin password.
+func (a AuthSettings) PasswordLogin() bool {
+	return a.Mode == AuthPassword || (a.Mode == AuthNone && os.Getenv(AdminPasswordHashEnv) != "")
+}
+
+// CheckPassword reports whether user and password are the admin's
+// credentials. The hash is compared even for unknown users, so the
+// response time does not reveal the user name.
+func (a AuthSettings) CheckPassword(user, password string) bool {
+	hash := a.AdminPasswordHash()
+	if hash == "" {
+		return false
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil && subtle.ConstantTimeCompare([]byte(user), []byte(a.Admin())) == 1
+}
+
+// HashPassword returns the bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("the password must have at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// SetAdminPassword stores the bcrypt hash of a new admin password and,
+// with enable, switches from no authentication to password mode. A hash
+// set in the environment still takes precedence.
+func SetAdminPassword(password string, enable bool) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	currentSettings.Auth.PasswordHash = hash
+	if enable && currentSettings.Auth.Mode == AuthNone {
+		currentSettings.Auth.Mode = AuthPassword
+	}
+	return saveSettings()
+}
+
+// Validate rejects unknown modes, password mode without a password and
+// header mode without trusted proxies, which would let every client
+// choose its identity.
+func (a AuthSettings) Validate() error {
+	if a.PasswordHash != "" {
+		if _, err := bcrypt.Cost([]byte(a.PasswordHash)); err != nil {
+			return fmt.Errorf("the password hash is not a bcrypt hash")
+		}
+	}
+	for user, role := range a.Roles {
+		if roleRanks[role] == 0 {
+			return fmt.Errorf("unknown role %q of user %s, use admin, operator or viewer", role, user)
+		}
+	}
+	switch a.Mode {
+	case AuthNone:
+		return nil
+	case AuthPassword:
+		if a.AdminPasswordHash() == "" {
+			return fmt.Errorf("password authentication requires an admin password, set it first")
+		}
+		return nil
+	case AuthHeader:
+	default:
+		return fmt.Errorf("unknown authentication mode %q", a.Mode)
+	}
+	for _, h := range []string{a.User(), a.Groups()} {
+		if strings.ContainsAny(h, " :\t") {
+			return fmt.Errorf("invalid header name %q", h)
+		}
+	}
+	if strings.EqualFold(a.User(), a.Groups()) {
+		return fmt.Errorf("user and groups headers must differ")
+	}
+	if len(a.Access.TrustedProxies) == 0 {
+		return fmt.Errorf("header authentication requires at least one trusted proxy")
+	}
+	return nil
+}
+
+// RoleFromHeaders returns the user and role identified by the headers. The
+// role is empty when the user is missing or in none of the mapped groups.
+func (a AuthSettings) RoleFromHeaders(h http.Header) (user, role string) {
+	user = strings.TrimSpace(h.Get(a.User()))
+	if user == "" {
+		return "", ""
+	}
+	if role, ok := a.Roles[user]; ok {
+		return user, role
+	}
+	if len(a.AdminGroups) == 0 && len(a.OperatorGroups) == 0 && len(a.ViewerGroups) == 0 && len(a.Roles) == 0 {
+		return user, RoleAdmin
+	}
+	var groups []string
+	for _, v := range h.Values(a.Groups()) {
+		for _, g := range strings.Split(v, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				groups = append(groups, g)
+			}
+		}
+	}
+	switch {
+	case containsAny(a.AdminGroups, groups):
+		return user, RoleAdmin
+	case containsAny(a.OperatorGroups, groups):
+		return user, RoleOperator
+	case containsAny(a.ViewerGroups, groups):
+		return user, RoleViewer
+	}
+	return user, ""
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		for _, l := range list {
+			if l == v {
+				return true
+			}
+		}
+	}
+	return false
+}
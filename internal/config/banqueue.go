@@ -0,0 +1,86 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueuedBan is a ban notification accepted from the fail2ban action but
+// not processed (GeoIP, alerts, ...) yet.
+type QueuedBan struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	Jail       string    `json:"jail"`
+	Hostname   string    `json:"hostname,omitempty"`
+	Failures   string    `json:"failures,omitempty"`
+	Whois      string    `json:"whois,omitempty"`
+	Logs       string    `json:"logs,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// banQueueDir holds one file per queued ban, so accepting a ban never
+// rewrites the whole queue. It is created next to the settings file.
+const banQueueDir = "fail2ban-ui-queue"
+
+// SaveQueuedBan persists b until RemoveQueuedBan is called, so queued bans
+// survive a restart. It assigns b.ID.
+func SaveQueuedBan(b *QueuedBan) error {
+	if err := os.MkdirAll(DataPath(banQueueDir), 0700); err != nil {
+		return err
+	}
+	b.ID = newID()
+	return writeJSONFile(filepath.Join(banQueueDir, b.ID+".json"), b)
+}
+
+// RemoveQueuedBan deletes a processed ban from the queue.
+func RemoveQueuedBan(id string) error {
+	err := os.Remove(DataPath(filepath.Join(banQueueDir, id+".json")))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadQueuedBans returns the bans left in the queue, oldest first.
+func LoadQueuedBans() ([]QueuedBan, error) {
+	entries, err := os.ReadDir(DataPath(banQueueDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var bans []QueuedBan
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		var b QueuedBan
+		if err := readJSONFile(filepath.Join(banQueueDir, e.Name()), &b); err != nil {
+			DebugLog("Skipping queued ban %s: %v", e.Name(), err)
+			continue
+		}
+		bans = append(bans, b)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].ReceivedAt.Before(bans[j].ReceivedAt) })
+	return bans, nil
+}
@@ -0,0 +1,122 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"unicode/utf8"
+)
+
+// BrandingSettings customise the look of the dashboard (white-labeling).
+// Empty values keep the Fail2ban UI defaults.
+type BrandingSettings struct {
+	Title       string `json:"title"`
+	AccentColor string `json:"accentColor"` // "#rrggbb"
+	Logo        string `json:"logo"`        // file name of the uploaded logo, see SetBrandingLogo
+}
+
+const (
+	// MaxLogoSize is the largest accepted logo upload.
+	MaxLogoSize     = 512 * 1024
+	maxBrandingText = 80
+	logoFilePrefix  = "fail2ban-ui-logo" // stored next to the settings file
+)
+
+// logoExtensions lists the accepted logo formats. SVG is not accepted since
+// it may carry scripts.
+var logoExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var accentColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ErrInvalidLogo is returned for uploads that are too large or not a supported image.
+var ErrInvalidLogo = errors.New("logo must be a PNG, JPEG, GIF or WebP image of at most 512 KiB")
+
+// UpdateBranding stores title and accent color, keeping the current logo.
+func UpdateBranding(b BrandingSettings) (BrandingSettings, error) {
+	if utf8.RuneCountInString(b.Title) > maxBrandingText {
+		return BrandingSettings{}, fmt.Errorf("title must be at most %d characters", maxBrandingText)
+	}
+	if b.AccentColor != "" && !accentColorRegex.MatchString(b.AccentColor) {
+		return BrandingSettings{}, fmt.Errorf("accent color must look like #1a2b3c")
+	}
+
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	b.Logo = currentSettings.Server.Branding.Logo
+	currentSettings.Server.Branding = b
+	return b, saveSettings()
+}
+
+// SetBrandingLogo validates and stores an uploaded logo.
+func SetBrandingLogo(data []byte) (BrandingSettings, error) {
+	if len(data) == 0 || len(data) > MaxLogoSize {
+		return BrandingSettings{}, ErrInvalidLogo
+	}
+	ext, ok := logoExtensions[http.DetectContentType(data)]
+	if !ok {
+		return BrandingSettings{}, ErrInvalidLogo
+	}
+
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	name := logoFilePrefix + ext
+	if err := WriteFileAtomic(DataPath(name), data, 0644); err != nil {
+		return BrandingSettings{}, err
+	}
+	if old := currentSettings.Server.Branding.Logo; old != "" && old != name {
+		os.Remove(DataPath(old))
+	}
+	currentSettings.Server.Branding.Logo = name
+	return currentSettings.Server.Branding, saveSettings()
+}
+
+// DeleteBrandingLogo removes the uploaded logo.
+func DeleteBrandingLogo() error {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	if currentSettings.Server.Branding.Logo == "" {
+		return nil
+	}
+	if err := os.Remove(DataPath(currentSettings.Server.Branding.Logo)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	currentSettings.Server.Branding.Logo = ""
+	return saveSettings()
+}
+
+// BrandingLogoPath returns the path of the uploaded logo, if any.
+func BrandingLogoPath() (string, bool) {
+	settingsLock.RLock()
+	name := currentSettings.Server.Branding.Logo
+	settingsLock.RUnlock()
+	// Only files written by SetBrandingLogo are served, whatever the settings file says.
+	for _, ext := range logoExtensions {
+		if name == logoFilePrefix+ext {
+			return DataPath(name), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,84 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// applyConfigFile applies the settings of a YAML, TOML or JSON config file,
+// chosen by its extension, onto the current settings and saves them. The
+// file has the layout of the settings file and may only contain the
+// settings it manages, e.g. with Ansible or Puppet; the others keep their
+// value. It is read on every start, so its values win over changes made
+// in the UI in the meantime.
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err = configFileJSON(path, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Decode onto a copy, the maps of the current settings are merged into.
+	s := CopySettings()
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.Notifications.MailReply.Secret = GetSettings().Notifications.MailReply.Secret
+	if err := s.Server.ValidateListen(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	currentSettings = s
+	migrateAlertCountries(&currentSettings)
+	ensureMailReplySecret(&currentSettings)
+	return saveSettings()
+}
+
+// configFileJSON converts the content of a config file to JSON, so it is
+// decoded like the settings file, including the flat keys of older
+// versions.
+func configFileJSON(path string, data []byte) ([]byte, error) {
+	var values map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return data, nil
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config file type %q, use .yaml, .yml, .toml or .json", ext)
+	}
+	return json.Marshal(values)
+}
@@ -0,0 +1,62 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrorAlertSettings control the email to the admin address (destemail)
+// when a request handler panics or the UI answers many requests with
+// server errors, see pkg/web/recovery.go.
+type ErrorAlertSettings struct {
+	Enabled bool `json:"enabled"`
+	// Threshold is the number of 5xx responses within WindowMinutes that
+	// counts as an error spike.
+	Threshold     int `json:"threshold"`     // default 20
+	WindowMinutes int `json:"windowMinutes"` // default 5
+}
+
+const (
+	defaultErrorThreshold     = 20
+	defaultErrorWindowMinutes = 5
+)
+
+// Limit returns the number of server errors that triggers an alert.
+func (e ErrorAlertSettings) Limit() int {
+	if e.Threshold <= 0 {
+		return defaultErrorThreshold
+	}
+	return e.Threshold
+}
+
+// Window returns the period the server errors are counted in.
+func (e ErrorAlertSettings) Window() time.Duration {
+	if e.WindowMinutes <= 0 {
+		return defaultErrorWindowMinutes * time.Minute
+	}
+	return time.Duration(e.WindowMinutes) * time.Minute
+}
+
+// Validate rejects negative values.
+func (e ErrorAlertSettings) Validate() error {
+	if e.Threshold < 0 || e.WindowMinutes < 0 {
+		return fmt.Errorf("error alert threshold and window must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Optional features that can be switched off in the "features" section.
+const (
+	FeatureConsole   = "console"   // fail2ban-client console in the UI
+	FeatureWebhooks  = "webhooks"  // delivery of bans and warnings to webhooks
+	FeatureSlack     = "slack"     // Slack buttons and slash command
+	FeatureWhois     = "whois"     // background whois/RDAP lookups
+	FeatureLogHealth = "logHealth" // monitoring of the jails' log files
+	FeatureMetrics   = "metrics"   // Prometheus endpoint and textfile export
+	FeatureHistory   = "history"   // SQLite ban history, see internal/store
+	FeatureLockout   = "lockout"   // public lockout self-service page for banned visitors
+)
+
+// featureDefaults lists the known features and whether they are enabled
+// when the settings do not mention them.
+var featureDefaults = map[string]bool{
+	FeatureConsole:   true,
+	FeatureWebhooks:  true,
+	FeatureSlack:     true,
+	FeatureWhois:     true,
+	FeatureLogHealth: true,
+	FeatureMetrics:   true,
+	FeatureHistory:   true,
+	FeatureLockout:   false,
+}
+
+// FeatureFlags switch optional features on and off by name. Features not
+// listed use their default.
+type FeatureFlags map[string]bool
+
+// Enabled reports whether the feature is switched on.
+func (f FeatureFlags) Enabled(name string) bool {
+	if on, ok := f[name]; ok {
+		return on
+	}
+	return featureDefaults[name]
+}
+
+// Validate rejects unknown feature names.
+func (f FeatureFlags) Validate() error {
+	for name := range f {
+		if _, ok := featureDefaults[name]; !ok {
+			return fmt.Errorf("unknown feature %q, known are %v", name, FeatureNames())
+		}
+	}
+	return nil
+}
+
+// FeatureNames returns the names of all known features, sorted.
+func FeatureNames() []string {
+	names := make([]string, 0, len(featureDefaults))
+	for name := range featureDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FeatureDefault reports whether the feature is enabled by default.
+func FeatureDefault(name string) bool {
+	return featureDefaults[name]
+}
@@ -0,0 +1,182 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// GeoOverride replaces the GeoIP database result for an IP or network,
+// e.g. for corporate ranges the database places in the wrong country.
+// Overrides take precedence over the database in alerts, statistics and
+// country filters, see geoip.Lookup.
+type GeoOverride struct {
+	ID      string `json:"id"`
+	Network string `json:"network"` // IP or CIDR
+	Country string `json:"country"` // ISO code
+	ASN     uint   `json:"asn,omitempty"`
+	ASOrg   string `json:"asOrg,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// maxGeoOverrides bounds the overrides, which are checked on every lookup.
+const maxGeoOverrides = 1000
+
+// ErrGeoOverrideNotFound is returned when an override does not exist.
+var ErrGeoOverrideNotFound = errors.New("GeoIP override not found")
+
+// Validate normalizes the network and country and checks the lengths.
+func (o *GeoOverride) Validate() error {
+	n, err := parseNetwork(o.Network)
+	if err != nil {
+		return err
+	}
+	o.Network = n.String()
+	o.Country = strings.ToUpper(strings.TrimSpace(o.Country))
+	if len(o.Country) != 2 || strings.Trim(o.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("country must be a two-letter ISO code, got %q", o.Country)
+	}
+	if len(o.ASOrg) > 200 || len(o.Comment) > 500 {
+		return fmt.Errorf("AS organization or comment too long")
+	}
+	return nil
+}
+
+// ValidateGeoOverrides validates all overrides, assigns missing IDs and
+// rejects duplicate networks.
+func ValidateGeoOverrides(overrides []GeoOverride) error {
+	if len(overrides) > maxGeoOverrides {
+		return fmt.Errorf("at most %d GeoIP overrides are supported", maxGeoOverrides)
+	}
+	seen := make(map[string]bool, len(overrides))
+	for i := range overrides {
+		if err := overrides[i].Validate(); err != nil {
+			return err
+		}
+		if overrides[i].ID == "" {
+			overrides[i].ID = newID()
+		}
+		if seen[overrides[i].Network] {
+			return fmt.Errorf("duplicate GeoIP override for %s", overrides[i].Network)
+		}
+		seen[overrides[i].Network] = true
+	}
+	return nil
+}
+
+// GetGeoOverrides returns a copy of the GeoIP overrides.
+func GetGeoOverrides() []GeoOverride {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	return append([]GeoOverride(nil), currentSettings.GeoIP.Overrides...)
+}
+
+// AddGeoOverride validates and stores a new override.
+func AddGeoOverride(o GeoOverride) (GeoOverride, error) {
+	o.ID = newID()
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	overrides := append(append([]GeoOverride(nil), currentSettings.GeoIP.Overrides...), o)
+	if err := ValidateGeoOverrides(overrides); err != nil {
+		return GeoOverride{}, err
+	}
+	currentSettings.GeoIP.Overrides = overrides
+	return overrides[len(overrides)-1], saveSettings()
+}
+
+// UpdateGeoOverride replaces an override, keeping its ID.
+func UpdateGeoOverride(id string, o GeoOverride) (GeoOverride, error) {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	overrides := append([]GeoOverride(nil), currentSettings.GeoIP.Overrides...)
+	for i := range overrides {
+		if overrides[i].ID == id {
+			o.ID = id
+			overrides[i] = o
+			if err := ValidateGeoOverrides(overrides); err != nil {
+				return GeoOverride{}, err
+			}
+			currentSettings.GeoIP.Overrides = overrides
+			return overrides[i], saveSettings()
+		}
+	}
+	return GeoOverride{}, ErrGeoOverrideNotFound
+}
+
+// DeleteGeoOverride removes an override.
+func DeleteGeoOverride(id string) error {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	for i, o := range currentSettings.GeoIP.Overrides {
+		if o.ID == id {
+			overrides := append([]GeoOverride(nil), currentSettings.GeoIP.Overrides[:i]...)
+			currentSettings.GeoIP.Overrides = append(overrides, currentSettings.GeoIP.Overrides[i+1:]...)
+			return saveSettings()
+		}
+	}
+	return ErrGeoOverrideNotFound
+}
+
+// geoOverrideNets caches the parsed networks of the overrides, rebuilt
+// when the networks change.
+var geoOverrideNets struct {
+	sync.Mutex
+	key  string
+	nets []*net.IPNet
+}
+
+// MatchGeoOverride returns the most specific override containing ip.
+func MatchGeoOverride(ip net.IP) (GeoOverride, bool) {
+	overrides := GetSettings().GeoIP.Overrides
+	if len(overrides) == 0 || ip == nil {
+		return GeoOverride{}, false
+	}
+	networks := make([]string, len(overrides))
+	for i, o := range overrides {
+		networks[i] = o.Network
+	}
+	key := strings.Join(networks, ",")
+
+	geoOverrideNets.Lock()
+	if geoOverrideNets.key != key {
+		nets := make([]*net.IPNet, len(networks))
+		for i, n := range networks {
+			nets[i], _ = parseNetwork(n) // validated on save
+		}
+		geoOverrideNets.key, geoOverrideNets.nets = key, nets
+	}
+	nets := geoOverrideNets.nets
+	geoOverrideNets.Unlock()
+
+	best, bestBits := -1, -1
+	for i, n := range nets {
+		if n == nil || !n.Contains(ip) {
+			continue
+		}
+		if bits, _ := n.Mask.Size(); bits > bestBits {
+			best, bestBits = i, bits
+		}
+	}
+	if best < 0 {
+		return GeoOverride{}, false
+	}
+	return overrides[best], true
+}
@@ -0,0 +1,91 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GitOpsSettings keep the history of /etc/fail2ban in a Git repository,
+// committing every change made through the UI and optionally pushing it.
+// Zero values use the defaults below.
+type GitOpsSettings struct {
+	Enabled bool `json:"enabled"`
+	// RepoPath is the Git directory; the work tree is /etc/fail2ban itself,
+	// so nothing is added to the configuration directory.
+	RepoPath string `json:"repoPath"`
+	// Remote is pushed to after every commit, e.g. "git@git.example.com:ops/fail2ban.git".
+	Remote string `json:"remote"`
+	Branch string `json:"branch"`
+	// DeployKey is the path of the SSH private key used for pushing.
+	DeployKey string `json:"deployKey"`
+}
+
+const (
+	defaultGitOpsRepo   = "/var/lib/fail2ban-ui/config.git"
+	defaultGitOpsBranch = "main"
+)
+
+var gitBranchRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// Repo returns the path of the Git directory.
+func (g GitOpsSettings) Repo() string {
+	if g.RepoPath == "" {
+		return defaultGitOpsRepo
+	}
+	return g.RepoPath
+}
+
+// BranchName returns the branch the changes are committed to.
+func (g GitOpsSettings) BranchName() string {
+	if g.Branch == "" {
+		return defaultGitOpsBranch
+	}
+	return g.Branch
+}
+
+// Validate checks the paths, the branch and the remote. Remotes must use
+// ssh or https, so git never runs local or command transports.
+func (g GitOpsSettings) Validate() error {
+	if !filepath.IsAbs(g.Repo()) {
+		return fmt.Errorf("the repository path must be absolute")
+	}
+	if !gitBranchRegex.MatchString(g.BranchName()) || strings.Contains(g.BranchName(), "..") {
+		return fmt.Errorf("invalid branch name %q", g.BranchName())
+	}
+	if g.DeployKey != "" && (!filepath.IsAbs(g.DeployKey) || strings.ContainsAny(g.DeployKey, "'\n")) {
+		return fmt.Errorf("the deploy key path must be absolute and must not contain quotes")
+	}
+	if g.Remote == "" {
+		return nil
+	}
+	if strings.HasPrefix(g.Remote, "-") {
+		return fmt.Errorf("invalid remote %q", g.Remote)
+	}
+	if u, err := url.Parse(g.Remote); err == nil && (u.Scheme == "ssh" || u.Scheme == "https") && u.Host != "" {
+		return nil
+	}
+	// scp-like syntax: user@host:path
+	if user, rest, ok := strings.Cut(g.Remote, "@"); ok && user != "" && strings.Contains(rest, ":") && !strings.Contains(user, "/") {
+		return nil
+	}
+	return fmt.Errorf("remote %q must be an ssh or https URL", g.Remote)
+}
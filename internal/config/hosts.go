@@ -0,0 +1,256 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RemoteHost is an additional fail2ban host managed over SSH. The UI logs
+// in with PrivateKey, or the key in KeyFile, runs fail2ban-client there
+// and reads its fail2ban log.
+type RemoteHost struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"` // host name or address, optionally with ":port"
+	User    string `json:"user"`    // default root
+	// PrivateKey is an unencrypted private key in OpenSSH or PEM format.
+	// It is never returned by the API, see Redacted.
+	PrivateKey string `json:"privateKey,omitempty"`
+	KeyFile    string `json:"keyFile"` // used if there is no PrivateKey
+	// HostKeys are the public keys of the host, one per line as printed by
+	// ssh-keyscan. Other host keys are rejected.
+	HostKeys  string    `json:"hostKeys"`
+	Sudo      bool      `json:"sudo"`    // run the commands with "sudo -n"
+	LogPath   string    `json:"logPath"` // default /var/log/fail2ban.log
+	Labels    []string  `json:"labels"`  // groups in the fleet view, e.g. "web" or "dmz"
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const hostsFile = "fail2ban-ui-hosts.json" // stored next to the settings file
+
+// ErrHostNotFound is returned when a remote host does not exist.
+var ErrHostNotFound = errors.New("host not found")
+
+var (
+	sshUserRegex   = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*$`)
+	hostLabelRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,31}$`)
+)
+
+// LocalHostLabel is the implicit label of the host the UI runs on.
+const LocalHostLabel = "local"
+
+// NormalizeHostLabels lowercases and deduplicates labels and rejects
+// invalid ones. Labels group hosts for the fleet summary and actions.
+func NormalizeHostLabels(labels []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || seen[l] {
+			continue
+		}
+		if !hostLabelRegex.MatchString(l) {
+			return nil, fmt.Errorf("invalid label %q", l)
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// HasLabel reports whether h carries label. Every host matches "".
+func (h RemoteHost) HasLabel(label string) bool {
+	return label == "" || slices.Contains(h.Labels, label)
+}
+
+// Login returns the user of the SSH login.
+func (h RemoteHost) Login() string {
+	if h.User == "" {
+		return "root"
+	}
+	return h.User
+}
+
+// HostPort splits Address into the host and the SSH port.
+func (h RemoteHost) HostPort() (host, port string) {
+	if host, port, err := net.SplitHostPort(h.Address); err == nil {
+		return host, port
+	}
+	return strings.Trim(h.Address, "[]"), "22"
+}
+
+// Log returns the fail2ban log file on the host.
+func (h RemoteHost) Log() string {
+	if h.LogPath == "" {
+		return "/var/log/fail2ban.log"
+	}
+	return h.LogPath
+}
+
+// Validate rejects incomplete hosts and values that ssh would read as options.
+func (h RemoteHost) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	host, port := h.HostPort()
+	if host == "" || strings.HasPrefix(host, "-") || strings.ContainsAny(host, " \t@/") {
+		return fmt.Errorf("invalid address %q", h.Address)
+	}
+	if p, err := net.LookupPort("tcp", port); err != nil || p == 0 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	if !sshUserRegex.MatchString(h.Login()) {
+		return fmt.Errorf("invalid user %q", h.User)
+	}
+	if h.PrivateKey != "" {
+		if _, err := ssh.ParseRawPrivateKey([]byte(h.PrivateKey)); err != nil {
+			return fmt.Errorf("invalid private key: %w", err)
+		}
+	} else if !filepath.IsAbs(h.KeyFile) {
+		return fmt.Errorf("a private key or the absolute path of a key file is required")
+	}
+	if _, err := ParseHostKeys(h.HostKeys); err != nil {
+		return err
+	}
+	if h.LogPath != "" && !filepath.IsAbs(h.LogPath) {
+		return fmt.Errorf("the log path must be absolute")
+	}
+	if _, err := NormalizeHostLabels(h.Labels); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseHostKeys returns the public keys in hostKeys in authorized_keys
+// format. Lines of ssh-keyscan and known_hosts, which start with the host
+// names, are accepted as well. At least one key is required.
+func ParseHostKeys(hostKeys string) ([]string, error) {
+	var keys []string
+	for _, line := range strings.Split(hostKeys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// The host names are read as key options.
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key %q: %w", line, err)
+		}
+		keys = append(keys, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("the host keys are required, e.g. from ssh-keyscan")
+	}
+	return keys, nil
+}
+
+// Redacted returns h without its private key, for API responses.
+func (h RemoteHost) Redacted() RemoteHost {
+	h.PrivateKey = ""
+	return h
+}
+
+var (
+	remoteHosts       []RemoteHost
+	remoteHostsLoaded bool
+	remoteHostsLock   sync.Mutex
+)
+
+// GetRemoteHosts returns a copy of all remote hosts.
+func GetRemoteHosts() []RemoteHost {
+	remoteHostsLock.Lock()
+	defer remoteHostsLock.Unlock()
+	loadRemoteHosts()
+	return append([]RemoteHost(nil), remoteHosts...)
+}
+
+// GetRemoteHost returns the remote host with the given ID.
+func GetRemoteHost(id string) (RemoteHost, error) {
+	for _, h := range GetRemoteHosts() {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return RemoteHost{}, ErrHostNotFound
+}
+
+// AddRemoteHost stores a new remote host and returns it with its generated ID.
+func AddRemoteHost(h RemoteHost) (RemoteHost, error) {
+	remoteHostsLock.Lock()
+	defer remoteHostsLock.Unlock()
+	loadRemoteHosts()
+
+	h.ID = newID()
+	h.CreatedAt = time.Now()
+	remoteHosts = append(remoteHosts, h)
+	return h, writePrivateJSONFile(hostsFile, remoteHosts)
+}
+
+// UpdateRemoteHost replaces the remote host with the given ID, keeping ID and creation time.
+func UpdateRemoteHost(id string, h RemoteHost) (RemoteHost, error) {
+	remoteHostsLock.Lock()
+	defer remoteHostsLock.Unlock()
+	loadRemoteHosts()
+
+	for i := range remoteHosts {
+		if remoteHosts[i].ID == id {
+			h.ID = id
+			h.CreatedAt = remoteHosts[i].CreatedAt
+			remoteHosts[i] = h
+			return h, writePrivateJSONFile(hostsFile, remoteHosts)
+		}
+	}
+	return RemoteHost{}, ErrHostNotFound
+}
+
+// DeleteRemoteHost removes the remote host with the given ID.
+func DeleteRemoteHost(id string) error {
+	remoteHostsLock.Lock()
+	defer remoteHostsLock.Unlock()
+	loadRemoteHosts()
+
+	for i := range remoteHosts {
+		if remoteHosts[i].ID == id {
+			remoteHosts = append(remoteHosts[:i], remoteHosts[i+1:]...)
+			return writePrivateJSONFile(hostsFile, remoteHosts)
+		}
+	}
+	return ErrHostNotFound
+}
+
+// loadRemoteHosts reads the hosts file once. The caller must hold remoteHostsLock.
+func loadRemoteHosts() {
+	if remoteHostsLoaded {
+		return
+	}
+	remoteHostsLoaded = true
+	if err := readJSONFile(hostsFile, &remoteHosts); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", hostsFile, err)
+	}
+}
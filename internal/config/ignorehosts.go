@@ -0,0 +1,88 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hostnameRegex matches DNS names with at least one dot, e.g. office.example.com.
+var hostnameRegex = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?\.?$`)
+
+// ValidateIgnoreHosts rejects entries that are not hostnames.
+func ValidateIgnoreHosts(hosts []string) error {
+	for _, h := range hosts {
+		if len(h) > 253 || !hostnameRegex.MatchString(h) {
+			return fmt.Errorf("invalid hostname: %q", h)
+		}
+	}
+	return nil
+}
+
+// ignoreIPValue returns the ignoreip option for jail.local: the static
+// IgnoreIP entries followed by the addresses of the ignore hosts.
+func ignoreIPValue(s AppSettings) string {
+	entries := strings.Fields(s.Fail2ban.IgnoreIP)
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e] = true
+	}
+	hosts := make([]string, 0, len(s.Fail2ban.ResolvedIgnoreHosts))
+	for host := range s.Fail2ban.ResolvedIgnoreHosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		for _, ip := range s.Fail2ban.ResolvedIgnoreHosts[host] {
+			if !seen[ip] {
+				seen[ip] = true
+				entries = append(entries, ip)
+			}
+		}
+	}
+	return strings.Join(entries, " ")
+}
+
+// SetResolvedIgnoreHosts stores the addresses the ignore hosts resolved to
+// and writes the combined ignoreip option to jail.local. It reports whether
+// jail.local changed, in which case fail2ban has to be reloaded.
+func SetResolvedIgnoreHosts(resolved map[string][]string) (bool, error) {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	if len(resolved) == 0 && len(currentSettings.Fail2ban.ResolvedIgnoreHosts) == 0 {
+		// Never used: leave the ignoreip option of jail.local alone.
+		return false, nil
+	}
+	currentSettings.Fail2ban.ResolvedIgnoreHosts = resolved
+	if err := saveSettings(); err != nil {
+		return false, err
+	}
+
+	value := ignoreIPValue(currentSettings)
+	if current, ok := jailLocalDefault("ignoreip"); ok && current == value {
+		return false, nil
+	}
+	DebugLog("Updating ignoreip in jail.local: %s", value)
+	if err := updateJailLocalDefaults(map[string]string{"ignoreip": value}); err != nil {
+		return false, fmt.Errorf("failed to update ignoreip in jail.local: %w", err)
+	}
+	return true, nil
+}
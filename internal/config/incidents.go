@@ -0,0 +1,205 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Incident is a named period of attack activity, e.g. "credential stuffing
+// wave 2025-03", annotating the bans of its IPs and jails. An empty IP or
+// jail list matches all IPs or jails, a zero To marks an ongoing incident.
+type Incident struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to,omitempty"`
+	IPs         []string  `json:"ips"`   // IPs or CIDRs
+	Jails       []string  `json:"jails"` // jail names
+	CreatedBy   string    `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	nets []*net.IPNet
+}
+
+const incidentsFile = "fail2ban-ui-incidents.json" // stored next to the settings file
+
+// ErrIncidentNotFound is returned when an incident does not exist.
+var ErrIncidentNotFound = errors.New("incident not found")
+
+var (
+	incidents       []Incident
+	incidentsLoaded bool
+	incidentsLock   sync.Mutex
+)
+
+// Validate checks the name, period and IPs of the incident and normalizes the IPs.
+func (inc *Incident) Validate() error {
+	inc.Name = strings.TrimSpace(inc.Name)
+	if inc.Name == "" || len(inc.Name) > 200 {
+		return fmt.Errorf("incident name must have 1 to 200 characters")
+	}
+	if len(inc.Description) > 4000 {
+		return fmt.Errorf("incident description must not exceed 4000 characters")
+	}
+	if inc.From.IsZero() {
+		return fmt.Errorf("incident start is required")
+	}
+	if !inc.To.IsZero() && !inc.To.After(inc.From) {
+		return fmt.Errorf("incident end must be after its start")
+	}
+	inc.nets = inc.nets[:0]
+	for i, v := range inc.IPs {
+		n, err := parseNetwork(v)
+		if err != nil {
+			return err
+		}
+		inc.IPs[i] = n.String()
+		inc.nets = append(inc.nets, n)
+	}
+	for _, j := range inc.Jails {
+		if j == "" || strings.ContainsAny(j, " \t[]/") {
+			return fmt.Errorf("invalid jail name %q", j)
+		}
+	}
+	return nil
+}
+
+// Overlaps reports whether the incident overlaps the period [from, to).
+func (inc Incident) Overlaps(from, to time.Time) bool {
+	return inc.From.Before(to) && (inc.To.IsZero() || inc.To.After(from))
+}
+
+// Matches reports whether a ban of ip in jail at t belongs to the incident.
+func (inc Incident) Matches(ip, jail string, t time.Time) bool {
+	if t.Before(inc.From) || (!inc.To.IsZero() && !t.Before(inc.To)) {
+		return false
+	}
+	if len(inc.Jails) > 0 && !slices.Contains(inc.Jails, jail) {
+		return false
+	}
+	if len(inc.nets) == 0 {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	for _, n := range inc.nets {
+		if parsed != nil && n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIncidents returns all incidents, newest first.
+func GetIncidents() []Incident {
+	incidentsLock.Lock()
+	defer incidentsLock.Unlock()
+	loadIncidents()
+	out := append([]Incident(nil), incidents...)
+	sort.Slice(out, func(i, j int) bool { return out[i].From.After(out[j].From) })
+	return out
+}
+
+// IncidentsBetween returns the incidents overlapping [from, to), newest first.
+func IncidentsBetween(from, to time.Time) []Incident {
+	var out []Incident
+	for _, inc := range GetIncidents() {
+		if inc.Overlaps(from, to) {
+			out = append(out, inc)
+		}
+	}
+	return out
+}
+
+// AddIncident validates and stores a new incident.
+func AddIncident(inc Incident) (Incident, error) {
+	if err := inc.Validate(); err != nil {
+		return Incident{}, err
+	}
+	incidentsLock.Lock()
+	defer incidentsLock.Unlock()
+	loadIncidents()
+
+	inc.ID = newID()
+	inc.CreatedAt = time.Now()
+	inc.UpdatedAt = inc.CreatedAt
+	incidents = append(incidents, inc)
+	return inc, writeJSONFile(incidentsFile, incidents)
+}
+
+// UpdateIncident replaces the name, description, period, IPs and jails of an incident.
+func UpdateIncident(id string, inc Incident) (Incident, error) {
+	if err := inc.Validate(); err != nil {
+		return Incident{}, err
+	}
+	incidentsLock.Lock()
+	defer incidentsLock.Unlock()
+	loadIncidents()
+
+	for i := range incidents {
+		if incidents[i].ID == id {
+			inc.ID = id
+			inc.CreatedBy = incidents[i].CreatedBy
+			inc.CreatedAt = incidents[i].CreatedAt
+			inc.UpdatedAt = time.Now()
+			incidents[i] = inc
+			return inc, writeJSONFile(incidentsFile, incidents)
+		}
+	}
+	return Incident{}, ErrIncidentNotFound
+}
+
+// DeleteIncident removes an incident.
+func DeleteIncident(id string) error {
+	incidentsLock.Lock()
+	defer incidentsLock.Unlock()
+	loadIncidents()
+
+	for i := range incidents {
+		if incidents[i].ID == id {
+			incidents = append(incidents[:i], incidents[i+1:]...)
+			return writeJSONFile(incidentsFile, incidents)
+		}
+	}
+	return ErrIncidentNotFound
+}
+
+// loadIncidents reads the incidents file once. The caller must hold incidentsLock.
+func loadIncidents() {
+	if incidentsLoaded {
+		return
+	}
+	incidentsLoaded = true
+	if err := readJSONFile(incidentsFile, &incidents); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", incidentsFile, err)
+	}
+	for i := range incidents {
+		if err := incidents[i].Validate(); err != nil {
+			DebugLog("Invalid incident %s in %s: %v", incidents[i].ID, incidentsFile, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// IngestToken authorizes an external tool, e.g. a WordPress plugin or a
+// custom application, to post security events to /api/events. With a
+// Jail, an IP reaching Threshold events within WindowMinutes is banned
+// there, so the UI can act as a small central banning hub.
+type IngestToken struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Hash          string    `json:"hash"` // SHA-256 of the token, which is only shown once
+	Jail          string    `json:"jail,omitempty"`
+	Threshold     int       `json:"threshold,omitempty"`     // default 1
+	WindowMinutes int       `json:"windowMinutes,omitempty"` // default 10
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+const (
+	ingestTokensFile           = "fail2ban-ui-ingest-tokens.json" // stored next to the settings file
+	defaultIngestWindowMinutes = 10
+)
+
+// ErrIngestTokenNotFound is returned when an ingest token does not exist.
+var ErrIngestTokenNotFound = errors.New("ingest token not found")
+
+var (
+	ingestTokens       []IngestToken
+	ingestTokensLoaded bool
+	ingestTokensLock   sync.Mutex
+)
+
+// BanThreshold returns the number of events banning an IP, at least 1.
+func (t IngestToken) BanThreshold() int {
+	return max(t.Threshold, 1)
+}
+
+// Window returns the period in which BanThreshold events lead to a ban.
+func (t IngestToken) Window() time.Duration {
+	if t.WindowMinutes <= 0 {
+		return defaultIngestWindowMinutes * time.Minute
+	}
+	return time.Duration(t.WindowMinutes) * time.Minute
+}
+
+// Validate checks the fields set by the user.
+func (t IngestToken) Validate() error {
+	if t.Name == "" {
+		return errors.New("name is required")
+	}
+	if t.Threshold < 0 || t.WindowMinutes < 0 {
+		return fmt.Errorf("threshold and window must not be negative")
+	}
+	return nil
+}
+
+// GetIngestTokens returns a copy of all ingest tokens.
+func GetIngestTokens() []IngestToken {
+	ingestTokensLock.Lock()
+	defer ingestTokensLock.Unlock()
+	loadIngestTokens()
+	return append([]IngestToken(nil), ingestTokens...)
+}
+
+// AddIngestToken stores a new ingest token with a generated secret. The
+// secret is returned once, only its hash is kept.
+func AddIngestToken(t IngestToken) (IngestToken, string, error) {
+	ingestTokensLock.Lock()
+	defer ingestTokensLock.Unlock()
+	loadIngestTokens()
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	secret := "f2bui_" + hex.EncodeToString(b)
+	t.ID = newID()
+	t.Hash = hashToken(secret)
+	t.CreatedAt = time.Now()
+	ingestTokens = append(ingestTokens, t)
+	return t, secret, writeJSONFile(ingestTokensFile, ingestTokens)
+}
+
+// UpdateIngestToken replaces the ingest token with the given ID, keeping
+// its ID, secret and creation time.
+func UpdateIngestToken(id string, t IngestToken) (IngestToken, error) {
+	ingestTokensLock.Lock()
+	defer ingestTokensLock.Unlock()
+	loadIngestTokens()
+
+	for i := range ingestTokens {
+		if ingestTokens[i].ID == id {
+			t.ID = id
+			t.Hash = ingestTokens[i].Hash
+			t.CreatedAt = ingestTokens[i].CreatedAt
+			ingestTokens[i] = t
+			return t, writeJSONFile(ingestTokensFile, ingestTokens)
+		}
+	}
+	return IngestToken{}, ErrIngestTokenNotFound
+}
+
+// DeleteIngestToken removes the ingest token with the given ID.
+func DeleteIngestToken(id string) error {
+	ingestTokensLock.Lock()
+	defer ingestTokensLock.Unlock()
+	loadIngestTokens()
+
+	for i := range ingestTokens {
+		if ingestTokens[i].ID == id {
+			ingestTokens = append(ingestTokens[:i], ingestTokens[i+1:]...)
+			return writeJSONFile(ingestTokensFile, ingestTokens)
+		}
+	}
+	return ErrIngestTokenNotFound
+}
+
+// LookupIngestToken returns the enabled ingest token with the given secret.
+func LookupIngestToken(secret string) (IngestToken, bool) {
+	ingestTokensLock.Lock()
+	defer ingestTokensLock.Unlock()
+	loadIngestTokens()
+
+	hash := hashToken(secret)
+	for _, t := range ingestTokens {
+		if t.Enabled && subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+			return t, true
+		}
+	}
+	return IngestToken{}, false
+}
+
+// hashToken returns the stored form of an ingest or API token secret.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadIngestTokens reads the ingest tokens file once. The caller must hold
+// ingestTokensLock.
+func loadIngestTokens() {
+	if ingestTokensLoaded {
+		return
+	}
+	ingestTokensLoaded = true
+	if err := readJSONFile(ingestTokensFile, &ingestTokens); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", ingestTokensFile, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// IPNote is what operators know about an address, e.g. "customer VPN
+// egress, do not permanent-ban". It is shown with every ban of the IP.
+type IPNote struct {
+	IP        string    `json:"ip"`
+	Note      string    `json:"note"`
+	Tags      []string  `json:"tags"`
+	Author    string    `json:"author"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+const (
+	ipNotesFile = "fail2ban-ui-ipnotes.json" // stored next to the settings file
+	maxNoteLen  = 2000
+	maxNoteTags = 20
+)
+
+// ErrIPNoteNotFound is returned when an IP has no note.
+var ErrIPNoteNotFound = errors.New("no note for this IP")
+
+// Operator tags share the namespace of the integration tags ("cloud:aws"),
+// so they are restricted to the same simple form.
+var noteTagRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,31}$`)
+
+var (
+	ipNotes       map[string]IPNote
+	ipNotesLoaded bool
+	ipNotesLock   sync.Mutex
+)
+
+// GetIPNotes returns all notes, most recently updated first.
+func GetIPNotes() []IPNote {
+	ipNotesLock.Lock()
+	defer ipNotesLock.Unlock()
+	loadIPNotes()
+	notes := make([]IPNote, 0, len(ipNotes))
+	for _, n := range ipNotes {
+		notes = append(notes, n)
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].UpdatedAt.After(notes[j].UpdatedAt) })
+	return notes
+}
+
+// GetIPNote returns the note of a (normalized) IP.
+func GetIPNote(ip string) (IPNote, bool) {
+	ipNotesLock.Lock()
+	defer ipNotesLock.Unlock()
+	loadIPNotes()
+	n, ok := ipNotes[ip]
+	return n, ok
+}
+
+// SetIPNote stores the note and tags of a (normalized) IP, replacing any
+// previous note. Tags are lower-cased and de-duplicated.
+func SetIPNote(ip, note string, tags []string, author string) (IPNote, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxNoteLen {
+		return IPNote{}, fmt.Errorf("note is longer than %d characters", maxNoteLen)
+	}
+	var clean []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if !noteTagRegex.MatchString(t) {
+			return IPNote{}, fmt.Errorf("invalid tag %q: use up to 32 letters, digits, '_', '.', ':' or '-'", t)
+		}
+		seen[t] = true
+		clean = append(clean, t)
+	}
+	if len(clean) > maxNoteTags {
+		return IPNote{}, fmt.Errorf("more than %d tags", maxNoteTags)
+	}
+	if note == "" && len(clean) == 0 {
+		return IPNote{}, errors.New("note and tags are empty")
+	}
+
+	ipNotesLock.Lock()
+	defer ipNotesLock.Unlock()
+	loadIPNotes()
+	n := IPNote{IP: ip, Note: note, Tags: clean, Author: author, UpdatedAt: time.Now()}
+	ipNotes[ip] = n
+	return n, writeJSONFile(ipNotesFile, ipNotes)
+}
+
+// AddIPNotes stores notes for the IPs in notes (by IP) that have none yet,
+// with a single write, and returns how many were added. Longer notes are
+// truncated.
+func AddIPNotes(notes map[string]string, tags []string, author string) (int, error) {
+	ipNotesLock.Lock()
+	defer ipNotesLock.Unlock()
+	loadIPNotes()
+	added := 0
+	now := time.Now()
+	for ip, note := range notes {
+		if _, ok := ipNotes[ip]; ok {
+			continue
+		}
+		if r := []rune(strings.TrimSpace(note)); len(r) > maxNoteLen {
+			note = string(r[:maxNoteLen])
+		}
+		ipNotes[ip] = IPNote{IP: ip, Note: strings.TrimSpace(note), Tags: tags, Author: author, UpdatedAt: now}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, writeJSONFile(ipNotesFile, ipNotes)
+}
+
+// DeleteIPNote removes the note of an IP.
+func DeleteIPNote(ip string) error {
+	ipNotesLock.Lock()
+	defer ipNotesLock.Unlock()
+	loadIPNotes()
+	if _, ok := ipNotes[ip]; !ok {
+		return ErrIPNoteNotFound
+	}
+	delete(ipNotes, ip)
+	return writeJSONFile(ipNotesFile, ipNotes)
+}
+
+// loadIPNotes reads the notes file once. The caller must hold ipNotesLock.
+func loadIPNotes() {
+	if ipNotesLoaded {
+		return
+	}
+	ipNotesLoaded = true
+	ipNotes = make(map[string]IPNote)
+	if err := readJSONFile(ipNotesFile, &ipNotes); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", ipNotesFile, err)
+	}
+}
@@ -0,0 +1,169 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// updateJailLocalDefaults sets the given options in the [DEFAULT] section of
+// jail.local. Existing keys are replaced in place, missing keys are appended
+// to the section and all other lines are kept untouched.
+func updateJailLocalDefaults(values map[string]string) error {
+	return updateDefaultOptions(jailFile, values)
+}
+
+// WriteJailLocalDefaults writes the [DEFAULT] options managed by the
+// settings to the jail.local at path. Only these keys are replaced; the
+// jails, other options and comments are kept as they are.
+func WriteJailLocalDefaults(path string, s AppSettings) error {
+	return updateDefaultOptions(path, map[string]string{
+		"bantime.increment": fmt.Sprintf("%t", s.Fail2ban.BantimeIncrement),
+		"ignoreip":          ignoreIPValue(s),
+		"bantime":           s.Fail2ban.Bantime,
+		"findtime":          s.Fail2ban.Findtime,
+		"maxretry":          fmt.Sprintf("%d", s.Fail2ban.Maxretry),
+		"destemail":         s.Fail2ban.Destemail,
+	})
+}
+
+func updateDefaultOptions(path string, values map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := setSectionOptions(string(content), "DEFAULT", values)
+	return WriteFileAtomic(path, []byte(updated), 0644)
+}
+
+// UpdateFail2banLocal sets the given options of the fail2ban server in
+// the [Definition] section of fail2ban.local, keeping all other lines.
+func UpdateFail2banLocal(values map[string]string) error {
+	content, err := os.ReadFile(fail2banLocal)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := setSectionOptions(string(content), "Definition", values)
+	return WriteFileAtomic(fail2banLocal, []byte(updated), 0644)
+}
+
+// jailLocalDefault returns the value of an option in the [DEFAULT] section of jail.local.
+func jailLocalDefault(key string) (string, bool) {
+	content, err := os.ReadFile(jailFile)
+	if err != nil {
+		return "", false
+	}
+	inSection := false
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			inSection = strings.Trim(trimmed, "[]") == "DEFAULT"
+			continue
+		}
+		if k, v, ok := strings.Cut(trimmed, "="); ok && inSection && strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
+
+// setSectionOptions returns content with the options of section replaced or added.
+func setSectionOptions(content, section string, values map[string]string) string {
+	lines := strings.Split(content, "\n")
+	pending := make(map[string]string, len(values))
+	for k, v := range values {
+		pending[strings.ToLower(k)] = v
+	}
+
+	var out []string
+	inSection := false
+	sectionFound := false
+	replacing := false // drop the continuation lines of a replaced value
+	flush := func() {
+		keys := make([]string, 0, len(pending))
+		for k := range pending {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = append(out, fmt.Sprintf("%s = %s", k, pending[k]))
+		}
+		pending = map[string]string{}
+	}
+	// endSection inserts the remaining keys before the blank lines that
+	// separate the section from the next one or end the file.
+	endSection := func() {
+		var trailing []string
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			trailing = append(trailing, out[len(out)-1])
+			out = out[:len(out)-1]
+		}
+		flush()
+		out = append(out, trailing...)
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if replacing && trimmed != "" && (line[0] == ' ' || line[0] == '\t') {
+			continue
+		}
+		replacing = false
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			if inSection {
+				endSection()
+			}
+			inSection = strings.Trim(trimmed, "[]") == section
+			sectionFound = sectionFound || inSection
+			out = append(out, line)
+			continue
+		}
+		if inSection && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, ";") {
+			if key, _, ok := strings.Cut(trimmed, "="); ok {
+				key = strings.ToLower(strings.TrimSpace(key))
+				if v, ok := pending[key]; ok {
+					out = append(out, fmt.Sprintf("%s = %s", key, v))
+					delete(pending, key)
+					replacing = true
+					continue
+				}
+			}
+		}
+		out = append(out, line)
+	}
+
+	if inSection {
+		endSection()
+	}
+	if !sectionFound && len(pending) > 0 {
+		// Put a new section in front, so it applies before any jail.
+		rest := out
+		out = []string{"[" + section + "]"}
+		flush()
+		if strings.TrimSpace(content) != "" {
+			out = append(out, "")
+			out = append(out, rest...)
+		}
+	}
+	result := strings.Join(out, "\n")
+	if result != "" && !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return result
+}
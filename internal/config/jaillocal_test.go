@@ -0,0 +1,112 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetSectionOptions(t *testing.T) {
+	values := map[string]string{"bantime": "2h", "maxretry": "3"}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "default first",
+			content: "[DEFAULT]\nbantime = 1h\n\n[sshd]\nenabled = true\n",
+			want:    "[DEFAULT]\nbantime = 2h\nmaxretry = 3\n\n[sshd]\nenabled = true\n",
+		},
+		{
+			name:    "default last",
+			content: "[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 1h\n",
+			want:    "[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 2h\nmaxretry = 3\n",
+		},
+		{
+			name:    "default last with trailing blank lines",
+			content: "[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 1h\n\n\n",
+			want:    "[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 2h\nmaxretry = 3\n\n\n",
+		},
+		{
+			name:    "default last without final newline",
+			content: "[DEFAULT]\nbantime = 1h",
+			want:    "[DEFAULT]\nbantime = 2h\nmaxretry = 3\n",
+		},
+		{
+			name:    "default missing",
+			content: "[sshd]\nenabled = true\n",
+			want:    "[DEFAULT]\nbantime = 2h\nmaxretry = 3\n\n[sshd]\nenabled = true\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    "[DEFAULT]\nbantime = 2h\nmaxretry = 3\n",
+		},
+		{
+			name:    "commented keys are kept",
+			content: "[DEFAULT]\n# bantime = 10m\n; maxretry = 5\nbantime = 1h\n",
+			want:    "[DEFAULT]\n# bantime = 10m\n; maxretry = 5\nbantime = 2h\nmaxretry = 3\n",
+		},
+		{
+			name:    "continuation lines of a replaced value are dropped",
+			content: "[DEFAULT]\nbantime = 1h\n  ; old\n\tmore\nfindtime = 10m\n",
+			want:    "[DEFAULT]\nbantime = 2h\nfindtime = 10m\nmaxretry = 3\n",
+		},
+		{
+			name:    "continuation lines of other values are kept",
+			content: "[DEFAULT]\nignoreip = 127.0.0.1\n  10.0.0.0/8\nbantime = 1h\nmaxretry = 5\n",
+			want:    "[DEFAULT]\nignoreip = 127.0.0.1\n  10.0.0.0/8\nbantime = 2h\nmaxretry = 3\n",
+		},
+		{
+			name:    "keys of other sections are untouched",
+			content: "[DEFAULT]\n\n[sshd]\nbantime = 1d\n",
+			want:    "[DEFAULT]\nbantime = 2h\nmaxretry = 3\n\n[sshd]\nbantime = 1d\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setSectionOptions(tt.content, "DEFAULT", values); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDefaultOptionsIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jail.local")
+	if err := os.WriteFile(path, []byte("[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 1h\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{"bantime": "2h", "findtime": "10m"}
+	if err := updateDefaultOptions(path, values); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := os.ReadFile(path)
+	if err := updateDefaultOptions(path, values); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := os.ReadFile(path)
+	if string(first) != string(second) {
+		t.Errorf("second update changed the file:\n%q\n%q", first, second)
+	}
+	if want := "[sshd]\nenabled = true\n\n[DEFAULT]\nbantime = 2h\nfindtime = 10m\n"; string(second) != want {
+		t.Errorf("got %q, want %q", second, want)
+	}
+}
@@ -0,0 +1,107 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// legacyKeys maps the top-level keys of the flat settings layout used by
+// older versions to the field now holding the value. The keys "auth",
+// "geoip" and "integrations" kept their name and layout.
+var legacyKeys = map[string]func(s *AppSettings) any{
+	"language":      func(s *AppSettings) any { return &s.Server.Language },
+	"port":          func(s *AppSettings) any { return &s.Server.Port },
+	"debug":         func(s *AppSettings) any { return &s.Server.Debug },
+	"sampleData":    func(s *AppSettings) any { return &s.Server.SampleData },
+	"restartNeeded": func(s *AppSettings) any { return &s.Server.RestartNeeded },
+	"branding":      func(s *AppSettings) any { return &s.Server.Branding },
+	"refresh":       func(s *AppSettings) any { return &s.Server.Refresh },
+	"limits":        func(s *AppSettings) any { return &s.Server.Limits },
+
+	"access":         func(s *AppSettings) any { return &s.Auth.Access },
+	"selfProtection": func(s *AppSettings) any { return &s.Auth.SelfProtection },
+	"tenants":        func(s *AppSettings) any { return &s.Auth.Tenants },
+
+	"smtp":                 func(s *AppSettings) any { return &s.Notifications.SMTP },
+	"recipientLanguages":   func(s *AppSettings) any { return &s.Notifications.RecipientLanguages },
+	"notificationPolicies": func(s *AppSettings) any { return &s.Notifications.Policies },
+	"alertCountries":       func(s *AppSettings) any { return &s.Notifications.AlertCountries },
+	"alertExpression":      func(s *AppSettings) any { return &s.Notifications.AlertExpression },
+	"slack":                func(s *AppSettings) any { return &s.Notifications.Slack },
+	"mailReply":            func(s *AppSettings) any { return &s.Notifications.MailReply },
+
+	"whois":   func(s *AppSettings) any { return &s.Integrations.Whois },
+	"metrics": func(s *AppSettings) any { return &s.Integrations.Metrics },
+	"gitops":  func(s *AppSettings) any { return &s.Integrations.GitOps },
+
+	"bantimeIncrement":    func(s *AppSettings) any { return &s.Fail2ban.BantimeIncrement },
+	"ignoreip":            func(s *AppSettings) any { return &s.Fail2ban.IgnoreIP },
+	"bantime":             func(s *AppSettings) any { return &s.Fail2ban.Bantime },
+	"findtime":            func(s *AppSettings) any { return &s.Fail2ban.Findtime },
+	"maxretry":            func(s *AppSettings) any { return &s.Fail2ban.Maxretry },
+	"destemail":           func(s *AppSettings) any { return &s.Fail2ban.Destemail },
+	"ignoreHosts":         func(s *AppSettings) any { return &s.Fail2ban.IgnoreHosts },
+	"resolvedIgnoreHosts": func(s *AppSettings) any { return &s.Fail2ban.ResolvedIgnoreHosts },
+	"escalationRules":     func(s *AppSettings) any { return &s.Fail2ban.EscalationRules },
+	"logSources":          func(s *AppSettings) any { return &s.Fail2ban.LogSources },
+	"logHealth":           func(s *AppSettings) any { return &s.Fail2ban.LogHealth },
+	"profiles":            func(s *AppSettings) any { return &s.Fail2ban.Profiles },
+	"activeProfile":       func(s *AppSettings) any { return &s.Fail2ban.ActiveProfile },
+}
+
+// appSettingsJSON has the fields of AppSettings without its UnmarshalJSON.
+type appSettingsJSON AppSettings
+
+// UnmarshalJSON decodes the sectioned layout and moves values given with
+// the flat keys of older versions into their section, so settings files
+// and API clients written for them keep working. Like the default
+// decoding, fields missing in data are left as they are.
+func (s *AppSettings) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*appSettingsJSON)(s)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		field, ok := legacyKeys[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, field(s)); err != nil {
+			return fmt.Errorf("legacy setting %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// isLegacySettings reports whether data uses the flat layout of older versions.
+func isLegacySettings(data []byte) bool {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) != nil {
+		return false
+	}
+	for key := range raw {
+		if _, ok := legacyKeys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
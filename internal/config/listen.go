@@ -0,0 +1,112 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseListen returns the network and address of a listen value: a Unix
+// socket as "unix:/run/fail2ban-ui.sock" or an absolute path, or a TCP
+// address such as "127.0.0.1:8080" or ":8080".
+func ParseListen(value string) (network, address string, err error) {
+	if path, ok := strings.CutPrefix(value, "unix:"); ok || filepath.IsAbs(value) {
+		if !ok {
+			path = value
+		}
+		if !filepath.IsAbs(path) || filepath.Clean(path) != path || strings.ContainsAny(path, " \t\r\n") {
+			return "", "", fmt.Errorf("invalid socket path %q, use an absolute path", path)
+		}
+		return "unix", path, nil
+	}
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid listen address %q, use host:port or a socket path", value)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", fmt.Errorf("invalid port in listen address %q", value)
+	}
+	if strings.ContainsAny(host, " /") {
+		return "", "", fmt.Errorf("invalid host in listen address %q", value)
+	}
+	return "tcp", value, nil
+}
+
+// ListenAddress returns where the server listens: the listen address or
+// the port of the startup options, server.listen or all interfaces on
+// server.port.
+func ListenAddress() (network, address string) {
+	settings := GetSettings().Server
+	opts := GetOptions()
+	if network, address, err := ParseListen(opts.Listen); err == nil {
+		return network, address
+	}
+	if port := opts.Port; port > 0 {
+		return "tcp", ":" + strconv.Itoa(port)
+	}
+	if network, address, err := ParseListen(settings.Listen); err == nil {
+		return network, address
+	}
+	return "tcp", ":" + strconv.Itoa(settings.Port)
+}
+
+// ListenPort returns the TCP port the server listens on, or server.port
+// on a Unix socket.
+func ListenPort() int {
+	if network, address := ListenAddress(); network == "tcp" {
+		if _, port, err := net.SplitHostPort(address); err == nil {
+			if n, err := strconv.Atoi(port); err == nil {
+				return n
+			}
+		}
+	}
+	return GetSettings().Server.Port
+}
+
+// SocketGroupID returns the group ID of server.socketGroup, a group name
+// or number, or -1 if not set.
+func (s ServerSettings) SocketGroupID() (int, error) {
+	if s.SocketGroup == "" {
+		return -1, nil
+	}
+	if gid, err := strconv.Atoi(s.SocketGroup); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(s.SocketGroup)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// ValidateListen checks server.listen and server.socketGroup.
+func (s ServerSettings) ValidateListen() error {
+	if s.Listen != "" {
+		if _, _, err := ParseListen(s.Listen); err != nil {
+			return err
+		}
+	}
+	if _, err := s.SocketGroupID(); err != nil {
+		return fmt.Errorf("invalid socket group %q: %w", s.SocketGroup, err)
+	}
+	return nil
+}
@@ -24,7 +24,7 @@ import (
 func DebugLog(format string, v ...interface{}) {
 	// Avoid deadlocks by not calling GetSettings() inside DebugLog.
 	debugEnabled := false
-	debugEnabled = currentSettings.Debug
+	debugEnabled = currentSettings.Server.Debug
 	if !debugEnabled {
 		return
 	}
@@ -0,0 +1,49 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogHealthSettings control the monitoring of the log files read by the
+// jails. A missing or unreadable file, or one without new lines for
+// StaleHours, silently stops a jail from banning.
+type LogHealthSettings struct {
+	StaleHours int `json:"staleHours"` // default 24
+	// Silent only reports problems in the API, without email and webhook warnings.
+	Silent bool `json:"silent"`
+}
+
+const defaultLogStaleHours = 24
+
+// StaleAfter returns how long a log file may go without new lines.
+func (l LogHealthSettings) StaleAfter() time.Duration {
+	if l.StaleHours <= 0 {
+		return defaultLogStaleHours * time.Hour
+	}
+	return time.Duration(l.StaleHours) * time.Hour
+}
+
+// Validate rejects negative values.
+func (l LogHealthSettings) Validate() error {
+	if l.StaleHours < 0 {
+		return fmt.Errorf("staleHours must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+// Log source types, see LogSource.
+const (
+	LogSourceFile    = "file"    // a log file, Path may be a glob matching rotated (.gz) archives
+	LogSourceJournal = "journal" // the systemd journal of the unit in Path, "fail2ban" if empty
+)
+
+// LogSource is a place fail2ban writes its ban messages to. Several sources
+// are merged into one event stream, bans logged by more than one source
+// are counted once.
+type LogSource struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+var unitNameRegex = regexp.MustCompile(`^[A-Za-z0-9@_.:-]+$`)
+
+// Validate checks the type and path of the source.
+func (s LogSource) Validate() error {
+	switch s.Type {
+	case LogSourceFile:
+		if !filepath.IsAbs(s.Path) {
+			return fmt.Errorf("log file %q must be an absolute path", s.Path)
+		}
+		if _, err := filepath.Match(s.Path, ""); err != nil {
+			return fmt.Errorf("log file %q: %v", s.Path, err)
+		}
+	case LogSourceJournal:
+		if s.Path != "" && !unitNameRegex.MatchString(s.Path) {
+			return fmt.Errorf("invalid systemd unit %q", s.Path)
+		}
+	default:
+		return fmt.Errorf("unknown log source type %q, use %q or %q", s.Type, LogSourceFile, LogSourceJournal)
+	}
+	return nil
+}
+
+// Unit returns the systemd unit of a journal source.
+func (s LogSource) Unit() string {
+	if s.Path == "" {
+		return "fail2ban"
+	}
+	return s.Path
+}
+
+// String returns the source in the "type:path" form used by the settings page.
+func (s LogSource) String() string {
+	if s.Type == LogSourceJournal {
+		return s.Type + ":" + s.Unit()
+	}
+	return s.Type + ":" + s.Path
+}
@@ -0,0 +1,156 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MailReplySettings let recipients unban an IP by replying "UNBAN" to its
+// alert email. The replies are fetched from an IMAP mailbox; every alert
+// carries a signed token that can be used once. Zero values use the
+// defaults below.
+type MailReplySettings struct {
+	Enabled bool `json:"enabled"`
+	// Address is set as Reply-To of the alert emails and must be delivered
+	// to the IMAP mailbox below.
+	Address string `json:"address"`
+	// IMAPServer is "host:port" of an IMAP server with implicit TLS.
+	IMAPServer string `json:"imapServer"`
+	Username   string `json:"username"`
+	Password   string `json:"password" settings:"secret"`
+	Mailbox    string `json:"mailbox"`
+	// PollSeconds is the time between two checks of the mailbox.
+	PollSeconds int `json:"pollSeconds"`
+	// TokenHours is how long a reply to an alert is accepted.
+	TokenHours int `json:"tokenHours"`
+	// AllowedSenders may reply; empty allows the alert recipient (destemail).
+	AllowedSenders []string `json:"allowedSenders"`
+	// Secret signs the tokens. It is generated on first start and cannot
+	// be set through the settings.
+	Secret string `json:"secret" settings:"secret,readonly"`
+}
+
+const (
+	defaultMailReplyMailbox = "INBOX"
+	defaultMailReplyPoll    = 60
+	defaultMailReplyToken   = 24
+	mailTokensFile          = "fail2ban-ui-mailtokens.json" // stored next to the settings file
+)
+
+// MailboxName returns the IMAP mailbox replies are read from.
+func (m MailReplySettings) MailboxName() string {
+	if m.Mailbox == "" {
+		return defaultMailReplyMailbox
+	}
+	return m.Mailbox
+}
+
+// PollInterval returns the time between two checks of the mailbox.
+func (m MailReplySettings) PollInterval() time.Duration {
+	if m.PollSeconds <= 0 {
+		return defaultMailReplyPoll * time.Second
+	}
+	return time.Duration(m.PollSeconds) * time.Second
+}
+
+// TokenTTL returns how long the token of an alert is valid.
+func (m MailReplySettings) TokenTTL() time.Duration {
+	if m.TokenHours <= 0 {
+		return defaultMailReplyToken * time.Hour
+	}
+	return time.Duration(m.TokenHours) * time.Hour
+}
+
+// Validate checks the addresses and the server of enabled mail replies.
+func (m MailReplySettings) Validate() error {
+	for _, a := range append([]string{m.Address}, m.AllowedSenders...) {
+		if a == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(a); err != nil || strings.ContainsAny(a, "\r\n") {
+			return fmt.Errorf("invalid email address %q", a)
+		}
+	}
+	if !m.Enabled {
+		return nil
+	}
+	if m.Address == "" {
+		return fmt.Errorf("the reply address is required")
+	}
+	if _, _, err := net.SplitHostPort(m.IMAPServer); err != nil {
+		return fmt.Errorf("the IMAP server must be host:port, e.g. imap.example.com:993")
+	}
+	if m.Username == "" || m.Password == "" {
+		return fmt.Errorf("the IMAP username and password are required")
+	}
+	if strings.ContainsAny(m.MailboxName(), "\"\\\r\n") {
+		return fmt.Errorf("invalid mailbox name %q", m.MailboxName())
+	}
+	return nil
+}
+
+// ensureMailReplySecret generates the token signing secret if there is none.
+func ensureMailReplySecret(s *AppSettings) {
+	if s.Notifications.MailReply.Secret != "" {
+		return
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	s.Notifications.MailReply.Secret = hex.EncodeToString(b)
+}
+
+var (
+	mailTokens       map[string]time.Time
+	mailTokensLoaded bool
+	mailTokensLock   sync.Mutex
+)
+
+// UseMailToken marks the token with the given nonce as used and reports
+// whether it was unused. Used tokens are remembered until they expire.
+func UseMailToken(nonce string, expires time.Time) (bool, error) {
+	mailTokensLock.Lock()
+	defer mailTokensLock.Unlock()
+	if !mailTokensLoaded {
+		mailTokensLoaded = true
+		mailTokens = make(map[string]time.Time)
+		if err := readJSONFile(mailTokensFile, &mailTokens); err != nil && !os.IsNotExist(err) {
+			DebugLog("Error reading %s: %v", mailTokensFile, err)
+		}
+	}
+	if _, used := mailTokens[nonce]; used {
+		return false, nil
+	}
+	now := time.Now()
+	for n, exp := range mailTokens {
+		if exp.Before(now) {
+			delete(mailTokens, n)
+		}
+	}
+	mailTokens[nonce] = expires
+	return true, writeJSONFile(mailTokensFile, mailTokens)
+}
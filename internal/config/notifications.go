@@ -0,0 +1,300 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification channels and outcomes.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+	NotificationSkipped = "skipped" // an alert was not sent on purpose, see Error for the reason
+)
+
+// NotificationPolicy decides which bans a notification channel alerts on.
+type NotificationPolicy struct {
+	// Countries are the country codes to alert on; empty or "ALL" alert on every country.
+	Countries []string `json:"countries"`
+}
+
+// AllowsCountry reports whether bans of IPs from country are alerted.
+func (p NotificationPolicy) AllowsCountry(country string) bool {
+	if len(p.Countries) == 0 {
+		return true
+	}
+	for _, c := range p.Countries {
+		if c == "ALL" || strings.EqualFold(country, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyFor returns the notification policy of channel. Channels without
+// a policy alert on every ban.
+func (s AppSettings) PolicyFor(channel string) NotificationPolicy {
+	return s.Notifications.Policies[channel]
+}
+
+// AlertRoute sends the alert emails of matching bans to its own recipients,
+// e.g. bans of domestic IPs to the local team. Routes are evaluated in
+// order; the first match wins unless it sets Continue. Bans matching no
+// route are alerted to Destemail under the email policy and AlertExpression.
+type AlertRoute struct {
+	Name string `json:"name"`
+	// Countries the route applies to; empty matches every country.
+	Countries []string `json:"countries"`
+	// Expression (see internal/expr) further restricting the route.
+	Expression string   `json:"expression"`
+	Recipients []string `json:"recipients"`
+	// Language of the emails; empty uses RecipientLanguages or Server.Language.
+	Language string `json:"language"`
+	// Continue evaluates the following routes too, to alert several teams.
+	Continue bool `json:"continue"`
+}
+
+// Validate checks the recipients and normalizes the country codes.
+func (r *AlertRoute) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("alert routes need a name")
+	}
+	if len(r.Recipients) == 0 {
+		return fmt.Errorf("alert route %s has no recipients", r.Name)
+	}
+	for i, to := range r.Recipients {
+		addr, err := mail.ParseAddress(strings.TrimSpace(to))
+		if err != nil {
+			return fmt.Errorf("alert route %s: invalid recipient %q", r.Name, to)
+		}
+		r.Recipients[i] = addr.Address
+	}
+	for i, c := range r.Countries {
+		r.Countries[i] = strings.ToUpper(strings.TrimSpace(c))
+	}
+	return nil
+}
+
+// migrateAlertCountries moves the former global AlertCountries into the
+// email policy, which they used to restrict. Webhooks always got every ban.
+func migrateAlertCountries(s *AppSettings) {
+	if s.Notifications.Policies == nil {
+		s.Notifications.Policies = map[string]NotificationPolicy{
+			ChannelEmail:   {Countries: []string{"ALL"}},
+			ChannelWebhook: {Countries: []string{"ALL"}},
+		}
+	}
+	if s.Notifications.AlertCountries != nil {
+		// Copy the map, it may be shared with the current settings.
+		s.Notifications.Policies = maps.Clone(s.Notifications.Policies)
+		s.Notifications.Policies[ChannelEmail] = NotificationPolicy{Countries: s.Notifications.AlertCountries}
+		s.Notifications.AlertCountries = nil
+	}
+}
+
+// NotificationRecord is a single alert delivery attempt.
+type NotificationRecord struct {
+	ID         string    `json:"id"`
+	Channel    string    `json:"channel"`
+	Recipient  string    `json:"recipient"`
+	Subject    string    `json:"subject,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Jail       string    `json:"jail,omitempty"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// NotificationFilter selects records from the notification log. Zero values match all.
+type NotificationFilter struct {
+	Channel string
+	Status  string
+	IP      string
+	Jail    string
+	Since   time.Time
+	Limit   int
+	// JailAllowed, if set, restricts the result to records of jails it accepts.
+	JailAllowed func(jail string) bool
+}
+
+// maxNotificationRecords is the number of records kept, oldest are dropped first.
+const maxNotificationRecords = 1000
+
+// The notification log is stored as JSON lines, so a delivery only appends
+// a line. The file is rewritten with the kept records once it has twice as
+// many lines, see compactNotifications.
+const (
+	notificationsFile       = "fail2ban-ui-notifications.jsonl" // stored next to the settings file
+	legacyNotificationsFile = "fail2ban-ui-notifications.json"  // JSON array of older versions
+)
+
+var (
+	notifications       []NotificationRecord
+	notificationLines   int // lines in the file, including dropped records
+	notificationsLoaded bool
+	notificationsLock   sync.Mutex
+)
+
+// RecordNotification appends rec to the notification log.
+func RecordNotification(rec NotificationRecord) error {
+	notificationsLock.Lock()
+	defer notificationsLock.Unlock()
+	loadNotifications()
+
+	rec.ID = newID()
+	if rec.FinishedAt.IsZero() {
+		rec.FinishedAt = time.Now()
+	}
+	if rec.StartedAt.IsZero() {
+		rec.StartedAt = rec.FinishedAt
+	}
+	notifications = append(notifications, rec)
+	if len(notifications) > maxNotificationRecords {
+		notifications = append([]NotificationRecord(nil), notifications[len(notifications)-maxNotificationRecords:]...)
+	}
+	if notificationLines >= 2*maxNotificationRecords {
+		return compactNotifications()
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(DataPath(notificationsFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	notificationLines++
+	return nil
+}
+
+// compactNotifications rewrites the log file with the kept records. The
+// caller must hold notificationsLock.
+func compactNotifications() error {
+	var buf bytes.Buffer
+	for _, rec := range notifications {
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	if err := WriteFileAtomic(DataPath(notificationsFile), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	notificationLines = len(notifications)
+	return nil
+}
+
+// GetNotificationLog returns the records matching f, newest first.
+func GetNotificationLog(f NotificationFilter) []NotificationRecord {
+	notificationsLock.Lock()
+	defer notificationsLock.Unlock()
+	loadNotifications()
+
+	var out []NotificationRecord
+	for i := len(notifications) - 1; i >= 0; i-- {
+		rec := notifications[i]
+		if (f.Channel != "" && rec.Channel != f.Channel) ||
+			(f.Status != "" && rec.Status != f.Status) ||
+			(f.IP != "" && rec.IP != f.IP) ||
+			(f.Jail != "" && rec.Jail != f.Jail) ||
+			(!f.Since.IsZero() && rec.StartedAt.Before(f.Since)) ||
+			(f.JailAllowed != nil && (rec.Jail == "" || !f.JailAllowed(rec.Jail))) {
+			continue
+		}
+		out = append(out, rec)
+		if f.Limit > 0 && len(out) >= f.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// loadNotifications reads the notification log once. The caller must hold notificationsLock.
+func loadNotifications() {
+	if notificationsLoaded {
+		return
+	}
+	notificationsLoaded = true
+	f, err := os.Open(DataPath(notificationsFile))
+	if os.IsNotExist(err) {
+		// Convert the log of older versions.
+		if err := readJSONFile(legacyNotificationsFile, &notifications); err != nil {
+			if !os.IsNotExist(err) {
+				DebugLog("Error reading %s: %v", legacyNotificationsFile, err)
+			}
+			return
+		}
+		if err := compactNotifications(); err != nil {
+			DebugLog("Error writing %s: %v", notificationsFile, err)
+			return
+		}
+		os.Remove(DataPath(legacyNotificationsFile))
+		return
+	}
+	if err != nil {
+		DebugLog("Error reading %s: %v", notificationsFile, err)
+		return
+	}
+	defer f.Close()
+
+	damaged := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec NotificationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// E.g. a line cut off by a crash.
+			damaged = true
+			continue
+		}
+		notifications = append(notifications, rec)
+		notificationLines++
+	}
+	if err := scanner.Err(); err != nil {
+		DebugLog("Error reading %s: %v", notificationsFile, err)
+	}
+	if len(notifications) > maxNotificationRecords {
+		notifications = notifications[len(notifications)-maxNotificationRecords:]
+	}
+	if damaged {
+		// Rewrite the file, so new lines are not appended to a broken one.
+		if err := compactNotifications(); err != nil {
+			DebugLog("Error writing %s: %v", notificationsFile, err)
+		}
+	}
+}
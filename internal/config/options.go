@@ -0,0 +1,139 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Environment variables relocating fail2ban-ui, e.g. in containers or
+// distribution packages. The flags of the server take precedence.
+const (
+	PortEnv         = "F2BUI_PORT"
+	ListenEnv       = "F2BUI_LISTEN"
+	SettingsPathEnv = "F2BUI_SETTINGS_PATH"
+	LogPathEnv      = "F2BUI_LOG_PATH"
+	GeoIPPathEnv    = "F2BUI_GEOIP_PATH"
+	TemplatesDirEnv = "F2BUI_TEMPLATES_DIR"
+	ConfigEnv       = "F2BUI_CONFIG"
+)
+
+// Options are the core options given at startup rather than in the
+// settings, by flags or the environment. Empty values keep the defaults.
+type Options struct {
+	Port   int    // overrides server.port
+	Listen string // Unix socket or host:port, overrides server.listen and Port
+	// SettingsPath is the settings file, default fail2ban-ui-settings.json
+	// in the working directory. The other data files are stored next to it.
+	SettingsPath string
+	LogPath      string // fail2ban log, default /var/log/fail2ban.log
+	GeoIPPath    string // directory of the GeoIP databases, default /usr/share/GeoIP
+	TemplatesDir string // HTML templates, default the embedded copies
+	// ConfigPath is a YAML, TOML or JSON file applied onto the settings on
+	// start, see applyConfigFile.
+	ConfigPath string
+}
+
+var (
+	startupOptions Options
+	loadOnce       sync.Once
+	loadErr        error
+)
+
+// OptionsFromEnv returns the options set in the environment. An invalid
+// port is reported and left out.
+func OptionsFromEnv() (Options, error) {
+	opts := Options{
+		Listen:       os.Getenv(ListenEnv),
+		SettingsPath: os.Getenv(SettingsPathEnv),
+		LogPath:      os.Getenv(LogPathEnv),
+		GeoIPPath:    os.Getenv(GeoIPPathEnv),
+		TemplatesDir: os.Getenv(TemplatesDirEnv),
+		ConfigPath:   os.Getenv(ConfigEnv),
+	}
+	if v := os.Getenv(PortEnv); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 1 || port > 65535 {
+			return opts, fmt.Errorf("invalid %s %q", PortEnv, v)
+		}
+		opts.Port = port
+	}
+	return opts, nil
+}
+
+// Validate checks the port and the listen address.
+func (o Options) Validate() error {
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("invalid port %d", o.Port)
+	}
+	if o.Listen != "" {
+		if _, _, err := ParseListen(o.Listen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the settings and the other data files with opts, creating
+// the settings with defaults and their directory if there are none,
+// applies the config file and writes the fail2ban action. Only the first
+// call has an effect, later ones return its error.
+func Load(opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	loadOnce.Do(func() {
+		startupOptions = opts
+		if opts.SettingsPath != "" {
+			settingsPath = opts.SettingsPath
+			if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
+				fmt.Println("Failed to create the settings directory:", err)
+			}
+		}
+		loadErr = loadAll(opts.ConfigPath)
+	})
+	return loadErr
+}
+
+// EnsureLoaded loads the settings with the options of the environment
+// unless Load was called before, e.g. when the UI is embedded into
+// another application.
+func EnsureLoaded() error {
+	opts, err := OptionsFromEnv()
+	if loadErr := Load(opts); loadErr != nil {
+		return loadErr
+	}
+	return err
+}
+
+// GetOptions returns the options the settings were loaded with.
+func GetOptions() Options {
+	return startupOptions
+}
+
+// DataPath returns the path of a data file stored next to the settings
+// file; absolute paths are returned as they are.
+func DataPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(settingsPath), name)
+}
@@ -0,0 +1,183 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// SettingsProfile is a named preset of ban and alerting settings.
+type SettingsProfile struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	BantimeIncrement bool     `json:"bantimeIncrement"`
+	Bantime          string   `json:"bantime"`
+	Findtime         string   `json:"findtime"`
+	Maxretry         int      `json:"maxretry"`
+	AlertCountries   []string `json:"alertCountries"` // countries of the email policy
+	AlertExpression  string   `json:"alertExpression"`
+}
+
+// DefaultProfiles are offered as long as no own profiles are defined.
+var DefaultProfiles = []SettingsProfile{
+	{
+		Name:             "strict",
+		Description:      "Long, increasing bans after few failures",
+		BantimeIncrement: true,
+		Bantime:          "7d",
+		Findtime:         "1h",
+		Maxretry:         3,
+		AlertCountries:   []string{"ALL"},
+	},
+	{
+		Name:           "lenient",
+		Description:    "Short bans, tolerant to typos",
+		Bantime:        "1h",
+		Findtime:       "10m",
+		Maxretry:       10,
+		AlertCountries: []string{"ALL"},
+	},
+	{
+		Name:            "maintenance",
+		Description:     "Minimal bans and no email alerts, e.g. during migrations",
+		Bantime:         "10m",
+		Findtime:        "5m",
+		Maxretry:        20,
+		AlertCountries:  []string{"ALL"},
+		AlertExpression: "false",
+	},
+}
+
+// ErrProfileNotFound is returned when a settings profile does not exist.
+var ErrProfileNotFound = errors.New("settings profile not found")
+
+// GetProfiles returns the configured profiles, or the defaults if none are configured.
+func GetProfiles() []SettingsProfile {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	return profilesLocked()
+}
+
+func profilesLocked() []SettingsProfile {
+	if len(currentSettings.Fail2ban.Profiles) == 0 {
+		return append([]SettingsProfile(nil), DefaultProfiles...)
+	}
+	return append([]SettingsProfile(nil), currentSettings.Fail2ban.Profiles...)
+}
+
+// SaveProfile creates or replaces the profile with the same name.
+func SaveProfile(p SettingsProfile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("profile name is required")
+	}
+	if p.Bantime == "" || p.Findtime == "" || p.Maxretry <= 0 {
+		return errors.New("bantime, findtime and maxretry are required")
+	}
+
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	profiles := profilesLocked()
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == p.Name {
+			profiles[i] = p
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, p)
+	}
+	currentSettings.Fail2ban.Profiles = profiles
+	return saveSettings()
+}
+
+// DeleteProfile removes a profile. The active profile cannot be deleted.
+func DeleteProfile(name string) error {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	if currentSettings.Fail2ban.ActiveProfile == name {
+		return fmt.Errorf("profile %s is active and cannot be deleted", name)
+	}
+	profiles := profilesLocked()
+	for i := range profiles {
+		if profiles[i].Name == name {
+			currentSettings.Fail2ban.Profiles = append(profiles[:i], profiles[i+1:]...)
+			return saveSettings()
+		}
+	}
+	return ErrProfileNotFound
+}
+
+// ActivateProfile copies the values of the named profile into the settings,
+// stages the [DEFAULT] options in jail.local and marks a reload as needed.
+func ActivateProfile(name string) (AppSettings, error) {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	var profile *SettingsProfile
+	profiles := profilesLocked()
+	for i := range profiles {
+		if profiles[i].Name == name {
+			profile = &profiles[i]
+		}
+	}
+	if profile == nil {
+		return currentSettings, ErrProfileNotFound
+	}
+
+	// Build the new settings on a copy, they replace the current ones only
+	// after jail.local and the settings file were written.
+	next := currentSettings
+	next.Fail2ban.BantimeIncrement = profile.BantimeIncrement
+	next.Fail2ban.Bantime = profile.Bantime
+	next.Fail2ban.Findtime = profile.Findtime
+	next.Fail2ban.Maxretry = profile.Maxretry
+	next.Notifications.AlertCountries = append([]string{}, profile.AlertCountries...)
+	migrateAlertCountries(&next)
+	next.Notifications.AlertExpression = profile.AlertExpression
+	next.Fail2ban.ActiveProfile = profile.Name
+	next.Server.RestartNeeded = true
+
+	if err := updateJailLocalDefaults(profileDefaults(next)); err != nil {
+		return currentSettings, fmt.Errorf("failed to stage jail.local changes: %w", err)
+	}
+	if err := writeSettings(next); err != nil {
+		// Put the previous values back, jail.local must match the settings.
+		if err := updateJailLocalDefaults(profileDefaults(currentSettings)); err != nil {
+			log.Printf("⚠️ Failed to restore jail.local: %v", err)
+		}
+		return currentSettings, err
+	}
+	currentSettings = next
+	return currentSettings, nil
+}
+
+// profileDefaults returns the jail.local [DEFAULT] options set by profiles.
+func profileDefaults(s AppSettings) map[string]string {
+	return map[string]string{
+		"bantime.increment": fmt.Sprintf("%t", s.Fail2ban.BantimeIncrement),
+		"bantime":           s.Fail2ban.Bantime,
+		"findtime":          s.Fail2ban.Findtime,
+		"maxretry":          fmt.Sprintf("%d", s.Fail2ban.Maxretry),
+	}
+}
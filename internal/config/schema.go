@@ -0,0 +1,181 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaField describes a settings field for clients rendering the
+// settings form dynamically, see SettingsSchema.
+type SchemaField struct {
+	Key  string `json:"key"`  // JSON name
+	Path string `json:"path"` // dotted path from the root, e.g. "notifications.smtp.port"
+	// Type is "string", "integer", "boolean", "array", "object" or "map".
+	Type       string        `json:"type"`
+	Items      *SchemaField  `json:"items,omitempty"`  // element type of arrays and values of maps
+	Fields     []SchemaField `json:"fields,omitempty"` // fields of objects
+	Secret     bool          `json:"secret,omitempty"`
+	ReadOnly   bool          `json:"readOnly,omitempty"`
+	Deprecated bool          `json:"deprecated,omitempty"`
+}
+
+// Flags of the "settings" struct tag, e.g. `settings:"secret"`.
+const (
+	SettingSecret     = "secret"     // credentials, hidden in change plans
+	SettingReadOnly   = "readonly"   // runtime state or managed by other endpoints
+	SettingDeprecated = "deprecated" // migrated on load, do not set
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SettingsSchema describes the sections of AppSettings and their fields.
+func SettingsSchema() []SchemaField {
+	return schemaFields(reflect.TypeOf(AppSettings{}), "")
+}
+
+func schemaFields(t reflect.Type, prefix string) []SchemaField {
+	var fields []SchemaField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := schemaType(sf.Type, prefix+name)
+		f.Key = name
+		for _, flag := range strings.Split(sf.Tag.Get("settings"), ",") {
+			switch flag {
+			case SettingSecret:
+				f.Secret = true
+			case SettingReadOnly:
+				f.ReadOnly = true
+			case SettingDeprecated:
+				f.Deprecated = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func schemaType(t reflect.Type, path string) SchemaField {
+	f := SchemaField{Path: path}
+	switch {
+	case t == timeType:
+		f.Type = "string"
+	case t.Kind() == reflect.String:
+		f.Type = "string"
+	case t.Kind() == reflect.Bool:
+		f.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		f.Type = "integer"
+	case t.Kind() == reflect.Slice:
+		f.Type = "array"
+		items := schemaType(t.Elem(), path+"[]")
+		f.Items = &items
+	case t.Kind() == reflect.Map:
+		f.Type = "map"
+		items := schemaType(t.Elem(), path+".*")
+		f.Items = &items
+	case t.Kind() == reflect.Struct:
+		f.Type = "object"
+		f.Fields = schemaFields(t, path+".")
+	default:
+		f.Type = t.Kind().String()
+	}
+	return f
+}
+
+// hasFlag reports whether the field carries a flag of the "settings" tag.
+func (f SchemaField) hasFlag(flag string) bool {
+	switch flag {
+	case SettingSecret:
+		return f.Secret
+	case SettingReadOnly:
+		return f.ReadOnly
+	case SettingDeprecated:
+		return f.Deprecated
+	}
+	return false
+}
+
+// SettingsPaths returns the dotted paths of all fields whose "settings"
+// tag has the given flag.
+func SettingsPaths(flag string) []string {
+	var paths []string
+	var walk func(fields []SchemaField)
+	walk = func(fields []SchemaField) {
+		for _, f := range fields {
+			if f.hasFlag(flag) {
+				paths = append(paths, f.Path)
+			}
+			walk(f.Fields)
+			if f.Items != nil {
+				walk(f.Items.Fields)
+			}
+		}
+	}
+	walk(SettingsSchema())
+	return paths
+}
+
+// RedactSecrets blanks the fields of s whose "settings" tag has the secret
+// flag, e.g. before the settings are sent to a client.
+func RedactSecrets(s *AppSettings) {
+	walkSecrets(reflect.ValueOf(s).Elem(), reflect.Value{}, func(v, _ reflect.Value) {
+		v.SetZero()
+	})
+}
+
+// KeepSecrets restores the secrets of old that s leaves empty, so clients
+// can send redacted settings back without wiping the stored credentials.
+func KeepSecrets(s *AppSettings, old AppSettings) {
+	walkSecrets(reflect.ValueOf(s).Elem(), reflect.ValueOf(old), func(v, o reflect.Value) {
+		if v.IsZero() {
+			v.Set(o)
+		}
+	})
+}
+
+// walkSecrets calls fn for every secret field of the struct v along with
+// the same field of old, which is the zero Value when old is not valid.
+// Secrets are only looked up in nested structs, not in slices or maps.
+func walkSecrets(v, old reflect.Value, fn func(v, old reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		var o reflect.Value
+		if old.IsValid() {
+			o = old.Field(i)
+		}
+		if slices.Contains(strings.Split(sf.Tag.Get("settings"), ","), SettingSecret) {
+			fn(v.Field(i), o)
+		} else if sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			walkSecrets(v.Field(i), o, fn)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Policies for users logging in while they already have active sessions,
+// see SessionSettings.
+const (
+	ConcurrentAllow      = "allow"      // any number of sessions per user (default)
+	ConcurrentKickOldest = "kickOldest" // end the oldest session to make room
+	ConcurrentDeny       = "deny"       // refuse the new login
+)
+
+// SameSite attributes of the session cookie, see SessionSettings.
+const (
+	SameSiteStrict = "strict" // default
+	SameSiteLax    = "lax"    // also sent when following links from other sites
+	SameSiteNone   = "none"   // also sent cross-site, requires a secure cookie
+)
+
+// Secure attribute of the session cookie, see SessionSettings.
+const (
+	SecureAuto   = "auto"   // secure when the browser connected over HTTPS (default)
+	SecureAlways = "always" // always secure, e.g. behind a proxy not sending X-Forwarded-Proto
+	SecureNever  = "never"  // never secure, for plain HTTP test setups
+)
+
+// SessionSettings control the UI sessions started when a user loads the
+// dashboard. Sessions end after LifetimeHours, or after IdleMinutes
+// without user activity; the dashboard sends keep-alives while in use.
+type SessionSettings struct {
+	LifetimeHours int `json:"lifetimeHours"` // default 12
+	IdleMinutes   int `json:"idleMinutes"`   // default 30
+	// ConcurrentLogins is the policy once a user has MaxPerUser sessions.
+	ConcurrentLogins string `json:"concurrentLogins"`
+	MaxPerUser       int    `json:"maxPerUser"` // default 1
+	// Attributes of the session cookie.
+	SameSite     string `json:"sameSite"`
+	SecureCookie string `json:"secureCookie"`
+}
+
+const (
+	defaultSessionLifetimeHours = 12
+	defaultSessionIdleMinutes   = 30
+)
+
+// Lifetime returns how long a session lasts at most.
+func (s SessionSettings) Lifetime() time.Duration {
+	if s.LifetimeHours <= 0 {
+		return defaultSessionLifetimeHours * time.Hour
+	}
+	return time.Duration(s.LifetimeHours) * time.Hour
+}
+
+// Idle returns how long a session lasts without user activity.
+func (s SessionSettings) Idle() time.Duration {
+	if s.IdleMinutes <= 0 {
+		return defaultSessionIdleMinutes * time.Minute
+	}
+	return time.Duration(s.IdleMinutes) * time.Minute
+}
+
+// Max returns the number of sessions a user may have before the
+// concurrent login policy applies.
+func (s SessionSettings) Max() int {
+	if s.MaxPerUser <= 0 {
+		return 1
+	}
+	return s.MaxPerUser
+}
+
+// Validate rejects unknown policies and negative values.
+func (s SessionSettings) Validate() error {
+	switch s.ConcurrentLogins {
+	case "", ConcurrentAllow, ConcurrentKickOldest, ConcurrentDeny:
+	default:
+		return fmt.Errorf("unknown concurrent login policy %q", s.ConcurrentLogins)
+	}
+	switch s.SameSite {
+	case "", SameSiteStrict, SameSiteLax, SameSiteNone:
+	default:
+		return fmt.Errorf("unknown sameSite value %q, use strict, lax or none", s.SameSite)
+	}
+	switch s.SecureCookie {
+	case "", SecureAuto, SecureAlways, SecureNever:
+	default:
+		return fmt.Errorf("unknown secureCookie value %q, use auto, always or never", s.SecureCookie)
+	}
+	if s.SameSite == SameSiteNone && s.SecureCookie != SecureAlways {
+		return fmt.Errorf("sameSite none requires secureCookie always, browsers drop insecure cross-site cookies")
+	}
+	if s.LifetimeHours < 0 || s.IdleMinutes < 0 || s.MaxPerUser < 0 {
+		return fmt.Errorf("session lifetime, idle timeout and maximum must not be negative")
+	}
+	if s.Lifetime() < s.Idle() {
+		return fmt.Errorf("the session lifetime must not be shorter than the idle timeout")
+	}
+	return nil
+}
@@ -21,11 +21,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"net"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // SMTPSettings holds the SMTP server configuration for sending alert emails
@@ -33,21 +37,340 @@ type SMTPSettings struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"password" settings:"secret"`
 	From     string `json:"from"`
 	UseTLS   bool   `json:"useTLS"`
 }
 
-// AppSettings holds the main UI settings and Fail2ban configuration
+// CloudRangeSource is a downloadable list of IP ranges of a cloud or VPN provider.
+// Format is one of "aws", "gcp", "azure" or "text" (one CIDR per line).
+type CloudRangeSource struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Format string `json:"format"`
+}
+
+// TorPolicy defines how bans of Tor exit nodes are handled in a jail.
+// Jail "*" (or empty) applies to all jails without a specific policy.
+// PermanentJail names a jail with bantime = -1 the exit node is additionally banned in.
+type TorPolicy struct {
+	Jail          string `json:"jail"`
+	PermanentJail string `json:"permanentJail"`
+	SuppressAlert bool   `json:"suppressAlert"`
+}
+
+// EscalationRule bans the IP of every event matching Expression additionally in Jail
+// (usually a jail with a long or permanent bantime).
+type EscalationRule struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Jail       string `json:"jail"`
+}
+
+// IntegrationSettings controls the periodically fetched third-party data.
+type IntegrationSettings struct {
+	RefreshHours      int                `json:"refreshHours"`
+	CloudRanges       bool               `json:"cloudRanges"`
+	CloudRangeSources []CloudRangeSource `json:"cloudRangeSources"`
+	TorExitList       bool               `json:"torExitList"`
+	TorExitListURL    string             `json:"torExitListURL"`
+	TorPolicies       []TorPolicy        `json:"torPolicies"`
+	UpdateCheck       bool               `json:"updateCheck"` // daily check for new releases on GitHub
+
+	Whois   WhoisSettings   `json:"whois"`
+	Metrics MetricsSettings `json:"metrics"`
+	GitOps  GitOpsSettings  `json:"gitops"`
+	History HistorySettings `json:"history"`
+}
+
+// HistorySettings control the SQLite ban history, see internal/store.
+type HistorySettings struct {
+	RetentionDays int `json:"retentionDays"` // default 365
+}
+
+// Retention returns how long events are kept in the history.
+func (h HistorySettings) Retention() time.Duration {
+	days := h.RetentionDays
+	if days <= 0 {
+		days = 365
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GeoIPSettings selects the GeoIP databases. Empty paths are detected
+// from the well-known locations, see internal/geoip.
+type GeoIPSettings struct {
+	CountryDB string `json:"countryDB"`
+	ASNDB     string `json:"asnDB"`
+	// Overrides take precedence over the databases, see geooverrides.go.
+	Overrides []GeoOverride `json:"overrides"`
+}
+
+// RefreshSettings control how often the dashboard polls the server, see
+// the bootstrap endpoint. Zero values use the defaults below.
+type RefreshSettings struct {
+	Interval      int `json:"interval"`      // seconds between dashboard refreshes
+	MaxInterval   int `json:"maxInterval"`   // upper bound when throttling under load
+	BusyThreshold int `json:"busyThreshold"` // API requests per minute considered high load
+}
+
+const (
+	defaultRefreshInterval    = 30
+	defaultMaxRefreshInterval = 300
+	defaultBusyThreshold      = 600
+)
+
+// Effective returns the settings with defaults applied.
+func (r RefreshSettings) Effective() RefreshSettings {
+	if r.Interval <= 0 {
+		r.Interval = defaultRefreshInterval
+	}
+	if r.MaxInterval < r.Interval {
+		r.MaxInterval = max(defaultMaxRefreshInterval, r.Interval)
+	}
+	if r.BusyThreshold <= 0 {
+		r.BusyThreshold = defaultBusyThreshold
+	}
+	return r
+}
+
+// Validate rejects negative values.
+func (r RefreshSettings) Validate() error {
+	if r.Interval < 0 || r.MaxInterval < 0 || r.BusyThreshold < 0 {
+		return fmt.Errorf("refresh values must not be negative")
+	}
+	return nil
+}
+
+// MetricsSettings configure the node_exporter textfile export of the
+// statistics served on /metrics. An empty path disables it.
+type MetricsSettings struct {
+	TextfilePath    string `json:"textfilePath"`    // e.g. /var/lib/node_exporter/textfile/fail2ban.prom
+	IntervalSeconds int    `json:"intervalSeconds"` // default 60
+}
+
+// Interval returns the time between two textfile writes.
+func (m MetricsSettings) Interval() time.Duration {
+	if m.IntervalSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(m.IntervalSeconds) * time.Second
+}
+
+// LimitSettings bound the size of request bodies and the number of
+// concurrent fail2ban-client processes. Zero values use the defaults.
+type LimitSettings struct {
+	MaxBodyKB    int `json:"maxBodyKB"`    // all API requests, default 1024
+	BanMaxBodyKB int `json:"banMaxBodyKB"` // ban notifications with whois and log lines, default 256
+
+	ClientParallel    int `json:"clientParallel"`    // fail2ban-client processes at a time, default 4
+	ClientQueue       int `json:"clientQueue"`       // calls waiting for a free slot, default 64
+	ClientWaitSeconds int `json:"clientWaitSeconds"` // longest wait for a slot, default 30
+
+	// Requests per client address, see pkg/web/ratelimit.go
+	RequestsPerMinute int `json:"requestsPerMinute"` // dashboard and API, default 600
+	RequestBurst      int `json:"requestBurst"`      // default 120
+	LoginsPerMinute   int `json:"loginsPerMinute"`   // login form posts, default 5
+	LoginBurst        int `json:"loginBurst"`        // default 5
+}
+
+const (
+	defaultMaxBodyKB         = 1024
+	defaultBanMaxBodyKB      = 256
+	defaultClientParallel    = 4
+	defaultClientQueue       = 64
+	defaultClientWaitSeconds = 30
+	defaultRequestsPerMinute = 600
+	defaultRequestBurst      = 120
+	defaultLoginsPerMinute   = 5
+	defaultLoginBurst        = 5
+)
+
+// MaxBody returns the body limit of API requests in bytes.
+func (l LimitSettings) MaxBody() int64 {
+	if l.MaxBodyKB <= 0 {
+		return defaultMaxBodyKB << 10
+	}
+	return int64(l.MaxBodyKB) << 10
+}
+
+// BanMaxBody returns the body limit of ban notifications in bytes.
+func (l LimitSettings) BanMaxBody() int64 {
+	if l.BanMaxBodyKB <= 0 {
+		return defaultBanMaxBodyKB << 10
+	}
+	return int64(l.BanMaxBodyKB) << 10
+}
+
+// Parallel returns how many fail2ban-client calls may run at the same time.
+func (l LimitSettings) Parallel() int {
+	if l.ClientParallel <= 0 {
+		return defaultClientParallel
+	}
+	return l.ClientParallel
+}
+
+// Queue returns how many fail2ban-client calls may wait for a free slot
+// before further calls are rejected.
+func (l LimitSettings) Queue() int {
+	if l.ClientQueue <= 0 {
+		return defaultClientQueue
+	}
+	return l.ClientQueue
+}
+
+// Wait returns how long a fail2ban-client call waits for a free slot.
+func (l LimitSettings) Wait() time.Duration {
+	if l.ClientWaitSeconds <= 0 {
+		return defaultClientWaitSeconds * time.Second
+	}
+	return time.Duration(l.ClientWaitSeconds) * time.Second
+}
+
+// RequestRate returns the sustained requests per minute of a client
+// address and the burst it may send at once.
+func (l LimitSettings) RequestRate() (perMinute, burst int) {
+	perMinute, burst = l.RequestsPerMinute, l.RequestBurst
+	if perMinute <= 0 {
+		perMinute = defaultRequestsPerMinute
+	}
+	if burst <= 0 {
+		burst = defaultRequestBurst
+	}
+	return perMinute, burst
+}
+
+// LoginRate returns the sustained login attempts per minute of a client
+// address and the burst it may send at once.
+func (l LimitSettings) LoginRate() (perMinute, burst int) {
+	perMinute, burst = l.LoginsPerMinute, l.LoginBurst
+	if perMinute <= 0 {
+		perMinute = defaultLoginsPerMinute
+	}
+	if burst <= 0 {
+		burst = defaultLoginBurst
+	}
+	return perMinute, burst
+}
+
+// Validate rejects negative limits and limits too small for a logo upload.
+func (l LimitSettings) Validate() error {
+	if l.MaxBodyKB < 0 || l.BanMaxBodyKB < 0 {
+		return fmt.Errorf("body limits must not be negative")
+	}
+	if l.ClientParallel < 0 || l.ClientQueue < 0 || l.ClientWaitSeconds < 0 {
+		return fmt.Errorf("fail2ban-client limits must not be negative")
+	}
+	if l.RequestsPerMinute < 0 || l.RequestBurst < 0 || l.LoginsPerMinute < 0 || l.LoginBurst < 0 {
+		return fmt.Errorf("rate limits must not be negative")
+	}
+	if l.MaxBody() < MaxLogoSize+64<<10 {
+		return fmt.Errorf("maxBodyKB must be at least %d to allow logo uploads", (MaxLogoSize+64<<10)>>10)
+	}
+	return nil
+}
+
+// Self-protection modes, see SelfProtectionSettings.
+const (
+	SelfProtectionOff       = ""
+	SelfProtectionWarn      = "warn"      // alert when an admin's IP gets banned
+	SelfProtectionWhitelist = "whitelist" // add admin IPs to the jails' ignore lists
+)
+
+// SelfProtectionSettings keep admins from banning themselves, e.g. while
+// testing filters. Addresses of UI users count as active for SessionMinutes
+// after their last request.
+type SelfProtectionSettings struct {
+	Mode           string `json:"mode"`
+	SessionMinutes int    `json:"sessionMinutes"`
+}
+
+const defaultSessionMinutes = 60
+
+// Session returns how long an admin address stays protected after its last request.
+func (p SelfProtectionSettings) Session() time.Duration {
+	if p.SessionMinutes <= 0 {
+		return defaultSessionMinutes * time.Minute
+	}
+	return time.Duration(p.SessionMinutes) * time.Minute
+}
+
+// Validate rejects unknown modes, and tracking admins without
+// authentication, where every client reaching the API would count as an
+// admin and, in whitelist mode, become immune to bans.
+func (p SelfProtectionSettings) Validate(authenticated bool) error {
+	switch p.Mode {
+	case SelfProtectionOff:
+	case SelfProtectionWarn, SelfProtectionWhitelist:
+		if !authenticated {
+			return fmt.Errorf("self-protection mode %q requires authentication", p.Mode)
+		}
+	default:
+		return fmt.Errorf("unknown self-protection mode %q", p.Mode)
+	}
+	if p.SessionMinutes < 0 {
+		return fmt.Errorf("session minutes must not be negative")
+	}
+	return nil
+}
+
+// AppSettings holds the UI settings, grouped into sections. Files and
+// requests in the flat layout of older versions are migrated on decoding,
+// see legacy.go.
 type AppSettings struct {
-	Language       string       `json:"language"`
-	Port           int          `json:"port"`
-	Debug          bool         `json:"debug"`
-	RestartNeeded  bool         `json:"restartNeeded"`
-	AlertCountries []string     `json:"alertCountries"`
-	SMTP           SMTPSettings `json:"smtp"`
-
-	// Fail2Ban [DEFAULT] section values from jail.local
+	Server        ServerSettings       `json:"server"`
+	Auth          AuthSettings         `json:"auth"`
+	Notifications NotificationSettings `json:"notifications"`
+	GeoIP         GeoIPSettings        `json:"geoip"`
+	Integrations  IntegrationSettings  `json:"integrations"`
+	Fail2ban      Fail2banSettings     `json:"fail2ban"`
+	// Features switch optional features on and off, see features.go.
+	Features FeatureFlags `json:"features"`
+}
+
+// ServerSettings configure the UI itself.
+type ServerSettings struct {
+	Language      string           `json:"language"`
+	Port          int              `json:"port"`
+	Listen        string           `json:"listen"`      // Unix socket or host:port instead of Port, see listen.go
+	SocketGroup   string           `json:"socketGroup"` // group of the Unix socket, e.g. the web server's
+	Debug         bool             `json:"debug"`
+	SampleData    bool             `json:"sampleData"`
+	RestartNeeded bool             `json:"restartNeeded" settings:"readonly"`
+	Branding      BrandingSettings `json:"branding" settings:"readonly"` // see the branding endpoints
+	Refresh       RefreshSettings  `json:"refresh"`
+	Limits        LimitSettings    `json:"limits"`
+	TLS           TLSSettings      `json:"tls"` // applied on restart, see tls.go
+}
+
+// NotificationSettings configure the alerts sent on bans.
+type NotificationSettings struct {
+	SMTP SMTPSettings `json:"smtp"`
+
+	// Language of alert emails per recipient address (lower case); others get Server.Language
+	RecipientLanguages map[string]string `json:"recipientLanguages"`
+
+	// Country filters per notification channel (email, webhook), see notifications.go
+	Policies       map[string]NotificationPolicy `json:"policies"`
+	AlertCountries []string                      `json:"alertCountries,omitempty" settings:"deprecated"` // Deprecated: migrated to Policies on load and save
+
+	// Expression (see internal/expr) restricting alerts
+	AlertExpression string `json:"alertExpression"`
+
+	// Routes sending alerts of matching bans to other recipients, see notifications.go
+	Routes []AlertRoute `json:"routes"`
+
+	Slack     SlackSettings     `json:"slack"`
+	MailReply MailReplySettings `json:"mailReply"`
+
+	// Alerts about panics and server errors of the UI itself
+	ErrorAlerts ErrorAlertSettings `json:"errorAlerts"`
+}
+
+// Fail2banSettings hold the values written to jail.local and the
+// handling of the daemon's bans and logs.
+type Fail2banSettings struct {
+	// [DEFAULT] section values from jail.local
 	BantimeIncrement bool   `json:"bantimeIncrement"`
 	IgnoreIP         string `json:"ignoreip"`
 	Bantime          string `json:"bantime"`
@@ -55,24 +378,65 @@ type AppSettings struct {
 	Maxretry         int    `json:"maxretry"`
 	Destemail        string `json:"destemail"`
 	//Sender           string `json:"sender"`
+
+	// Hostnames (e.g. of dynamic home IPs) whose addresses are added to
+	// ignoreip, and their last resolution, see ignorehosts.go
+	IgnoreHosts         []string            `json:"ignoreHosts"`
+	ResolvedIgnoreHosts map[string][]string `json:"resolvedIgnoreHosts" settings:"readonly"`
+
+	// Expressions (see internal/expr) escalating bans
+	EscalationRules []EscalationRule `json:"escalationRules"`
+
+	// LogSources replace the default fail2ban log file, e.g. to read the
+	// rotated archives or the journal as well.
+	LogSources []LogSource       `json:"logSources"`
+	LogHealth  LogHealthSettings `json:"logHealth"`
+
+	// Named presets of the values above, see profiles.go
+	Profiles      []SettingsProfile `json:"profiles"`
+	ActiveProfile string            `json:"activeProfile"`
+
+	// Socket is the daemon's command socket, used instead of running
+	// fail2ban-client where possible; "none" always runs fail2ban-client.
+	Socket string `json:"socket"`
+
+	// Labels of this host in the fleet view, besides the implicit "local"
+	Labels []string `json:"labels"`
+}
+
+// DefaultSocket is the command socket of a standard fail2ban installation.
+const DefaultSocket = "/var/run/fail2ban/fail2ban.sock"
+
+// SocketPath returns the command socket, or "" if it must not be used.
+func (f Fail2banSettings) SocketPath() string {
+	switch f.Socket {
+	case "":
+		return DefaultSocket
+	case "none":
+		return ""
+	}
+	return f.Socket
 }
 
 // init paths to key-files
 const (
-	settingsFile    = "fail2ban-ui-settings.json" // this file is created, relatively to where the app was started
+	settingsFile    = "fail2ban-ui-settings.json" // default, relative to where the app was started, see Options.SettingsPath
 	defaultJailFile = "/etc/fail2ban/jail.conf"
 	jailFile        = "/etc/fail2ban/jail.local" // Path to jail.local (to override conf-values from jail.conf)
 	jailDFile       = "/etc/fail2ban/jail.d/ui-custom-action.conf"
 	actionFile      = "/etc/fail2ban/action.d/ui-custom-action.conf"
+	fail2banLocal   = "/etc/fail2ban/fail2ban.local" // overrides fail2ban.conf, e.g. dbpurgeage
 )
 
 // in-memory copy of settings
 var (
 	currentSettings AppSettings
 	settingsLock    sync.RWMutex
+	settingsPath    = settingsFile
 )
 
-func init() {
+// loadAll reads the settings, see Load.
+func loadAll(configPath string) error {
 	// Attempt to load existing file; if it doesn't exist, create with defaults.
 	if err := loadSettings(); err != nil {
 		fmt.Println("App settings not found, initializing from jail.local (if exist)")
@@ -87,9 +451,16 @@ func init() {
 			fmt.Println("Failed to save default settings:", err)
 		}
 	}
+	if configPath != "" {
+		if err := applyConfigFile(configPath); err != nil {
+			return fmt.Errorf("failed to apply the config file: %w", err)
+		}
+		fmt.Println("Applied the settings of", configPath)
+	}
 	if err := initializeFail2banAction(); err != nil {
 		fmt.Println("Error initializing Fail2ban action:", err)
 	}
+	return nil
 }
 
 // setDefaults populates default values in currentSettings
@@ -97,47 +468,46 @@ func setDefaults() {
 	settingsLock.Lock()
 	defer settingsLock.Unlock()
 
-	if currentSettings.Language == "" {
-		currentSettings.Language = "en"
-	}
-	if currentSettings.Port == 0 {
-		currentSettings.Port = 8080
+	if currentSettings.Server.Language == "" {
+		currentSettings.Server.Language = "en"
 	}
-	if currentSettings.AlertCountries == nil {
-		currentSettings.AlertCountries = []string{"ALL"}
+	if currentSettings.Server.Port == 0 {
+		currentSettings.Server.Port = 8080
 	}
-	if currentSettings.Bantime == "" {
-		currentSettings.Bantime = "48h"
+	migrateAlertCountries(&currentSettings)
+	ensureMailReplySecret(&currentSettings)
+	if currentSettings.Fail2ban.Bantime == "" {
+		currentSettings.Fail2ban.Bantime = "48h"
 	}
-	if currentSettings.Findtime == "" {
-		currentSettings.Findtime = "30m"
+	if currentSettings.Fail2ban.Findtime == "" {
+		currentSettings.Fail2ban.Findtime = "30m"
 	}
-	if currentSettings.Maxretry == 0 {
-		currentSettings.Maxretry = 3
+	if currentSettings.Fail2ban.Maxretry == 0 {
+		currentSettings.Fail2ban.Maxretry = 3
 	}
-	if currentSettings.Destemail == "" {
-		currentSettings.Destemail = "alerts@example.com"
+	if currentSettings.Fail2ban.Destemail == "" {
+		currentSettings.Fail2ban.Destemail = "alerts@example.com"
 	}
-	if currentSettings.SMTP.Host == "" {
-		currentSettings.SMTP.Host = "smtp.office365.com"
+	if currentSettings.Notifications.SMTP.Host == "" {
+		currentSettings.Notifications.SMTP.Host = "smtp.office365.com"
 	}
-	if currentSettings.SMTP.Port == 0 {
-		currentSettings.SMTP.Port = 587
+	if currentSettings.Notifications.SMTP.Port == 0 {
+		currentSettings.Notifications.SMTP.Port = 587
 	}
-	if currentSettings.SMTP.Username == "" {
-		currentSettings.SMTP.Username = "noreply@swissmakers.ch"
+	if currentSettings.Notifications.SMTP.Username == "" {
+		currentSettings.Notifications.SMTP.Username = "noreply@swissmakers.ch"
 	}
-	if currentSettings.SMTP.Password == "" {
-		currentSettings.SMTP.Password = "password"
+	if currentSettings.Notifications.SMTP.Password == "" {
+		currentSettings.Notifications.SMTP.Password = "password"
 	}
-	if currentSettings.SMTP.From == "" {
-		currentSettings.SMTP.From = "noreply@swissmakers.ch"
+	if currentSettings.Notifications.SMTP.From == "" {
+		currentSettings.Notifications.SMTP.From = "noreply@swissmakers.ch"
 	}
-	if !currentSettings.SMTP.UseTLS {
-		currentSettings.SMTP.UseTLS = true
+	if !currentSettings.Notifications.SMTP.UseTLS {
+		currentSettings.Notifications.SMTP.UseTLS = true
 	}
-	if currentSettings.IgnoreIP == "" {
-		currentSettings.IgnoreIP = "127.0.0.1/8 ::1"
+	if currentSettings.Fail2ban.IgnoreIP == "" {
+		currentSettings.Fail2ban.IgnoreIP = "127.0.0.1/8 ::1"
 	}
 }
 
@@ -166,21 +536,21 @@ func initializeFromJailFile() error {
 	defer settingsLock.Unlock()
 
 	if val, ok := settings["bantime"]; ok {
-		currentSettings.Bantime = val
+		currentSettings.Fail2ban.Bantime = val
 	}
 	if val, ok := settings["findtime"]; ok {
-		currentSettings.Findtime = val
+		currentSettings.Fail2ban.Findtime = val
 	}
 	if val, ok := settings["maxretry"]; ok {
 		if maxRetry, err := strconv.Atoi(val); err == nil {
-			currentSettings.Maxretry = maxRetry
+			currentSettings.Fail2ban.Maxretry = maxRetry
 		}
 	}
 	if val, ok := settings["ignoreip"]; ok {
-		currentSettings.IgnoreIP = val
+		currentSettings.Fail2ban.IgnoreIP = val
 	}
 	if val, ok := settings["destemail"]; ok {
-		currentSettings.Destemail = val
+		currentSettings.Fail2ban.Destemail = val
 	}
 	/*if val, ok := settings["sender"]; ok {
 		currentSettings.Sender = val
@@ -264,7 +634,7 @@ func setupGeoCustomAction() error {
 
 	// Write back the modified lines
 	output := strings.Join(lines, "\n")
-	return os.WriteFile(jailFile, []byte(output), 0644)
+	return WriteFileAtomic(jailFile, []byte(output), 0644)
 }
 
 // copyFile copies a file from src to dst. If the destination file does not exist, it will be created.
@@ -303,7 +673,7 @@ action_mwlg = %(action_)s
              ui-custom-action[sender="%(sender)s", dest="%(destemail)s", logpath="%(logpath)s", chain="%(chain)s"]
 `
 	// Write the new configuration file
-	err := os.WriteFile(jailDFile, []byte(jailDConfig), 0644)
+	err := WriteFileAtomic(jailDFile, []byte(jailDConfig), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write jail.d config: %v", err)
 	}
@@ -317,7 +687,7 @@ func writeFail2banAction() error {
 	DebugLog("Running initial writeFail2banAction()") // entry point
 	DebugLog("----------------------------")
 	// Define the Fail2Ban action file content
-	actionConfig := `[INCLUDES]
+	actionConfig := fmt.Sprintf(`[INCLUDES]
 
 before = sendmail-common.conf
          mail-whois-common.conf
@@ -330,44 +700,70 @@ norestored = 1
 
 # Option: actionban
 # This executes a cURL request to notify our API when an IP is banned.
+# The matching log lines are collected by the UI itself, see /api/v1/jails/<jail>/logs/<ip>.
+# Whois is looked up by the UI in the background, see /api/v1/whois/<ip>.
 
-actionban = /usr/bin/curl -X POST http://127.0.0.1:8080/api/ban \
+actionban = %s \
      -H "Content-Type: application/json" \
      -d "$(jq -n --arg ip '<ip>' \
                  --arg jail '<name>' \
                  --arg hostname '<fq-hostname>' \
                  --arg failures '<failures>' \
-                 --arg whois "$(whois <ip> || echo 'missing whois program')" \
-                 --arg logs "$(tac <logpath> | grep <grepopts> -wF <ip>)" \
-                 '{ip: $ip, jail: $jail, hostname: $hostname, failures: $failures, whois: $whois, logs: $logs}')"
+                 '{ip: $ip, jail: $jail, hostname: $hostname, failures: $failures}')"
 
 [Init]
 
 # Default name of the chain
-name = default
+name = default`, banCallback())
 
-# Path to log files containing relevant lines for the abuser IP
-logpath = /dev/null
-
-# Number of log lines to include in the email
-grepmax = 200
-grepopts = -m <grepmax>`
+	// The content only depends on where and how the server listens, only
+	// write it if it changed.
+	current, err := os.ReadFile(actionFile)
+	if err == nil && string(current) == actionConfig {
+		DebugLog("Custom-action file %s is up to date", actionFile)
+		return nil
+	}
 
 	// Write the action file
-	err := os.WriteFile(actionFile, []byte(actionConfig), 0644)
-	if err != nil {
+	if err := WriteFileAtomic(actionFile, []byte(actionConfig), 0644); err != nil {
 		return fmt.Errorf("failed to write action file: %w", err)
 	}
-
 	DebugLog("Custom-action file successfully written to %s\n", actionFile)
+
+	// Fail2ban reads the action when the jails start, so a changed callback,
+	// e.g. after TLS was enabled, needs a reload.
+	if err == nil {
+		return MarkRestartNeeded()
+	}
 	return nil
 }
 
+// banCallback returns the curl command of the ban action reaching the
+// API where the server listens, over the Unix socket if it uses one.
+// With TLS, the server only speaks HTTPS on its port or socket. The
+// certificate is not checked (-k): it is issued for the public name, not
+// for the loopback address, and the request does not leave the host.
+func banCallback() string {
+	scheme, insecure := "http", ""
+	if GetSettings().Server.TLS.Enabled() {
+		scheme, insecure = "https", " -k"
+	}
+	network, address := ListenAddress()
+	if network == "unix" {
+		return "/usr/bin/curl" + insecure + " --unix-socket " + address + " -X POST " + scheme + "://localhost/api/v1/ban"
+	}
+	host, port, _ := net.SplitHostPort(address)
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "/usr/bin/curl" + insecure + " -X POST " + scheme + "://" + net.JoinHostPort(host, port) + "/api/v1/ban"
+}
+
 // loadSettings reads fail2ban-ui-settings.json into currentSettings.
 func loadSettings() error {
 	DebugLog("----------------------------")
 	DebugLog("loadSettings called (settings.go)") // entry point
-	data, err := os.ReadFile(settingsFile)
+	data, err := os.ReadFile(settingsPath)
 	if os.IsNotExist(err) {
 		return err // triggers setDefaults + save
 	}
@@ -383,6 +779,24 @@ func loadSettings() error {
 	settingsLock.Lock()
 	defer settingsLock.Unlock()
 	currentSettings = s
+	// Rewrite files of older versions in the sectioned layout, keeping
+	// the original next to it.
+	if isLegacySettings(data) {
+		if err := WriteFileAtomic(settingsPath+".legacy", data, 0600); err != nil {
+			return fmt.Errorf("failed to back up the legacy settings: %w", err)
+		}
+		migrateAlertCountries(&currentSettings)
+		ensureMailReplySecret(&currentSettings)
+		fmt.Println("Migrated the settings to the sectioned layout, the original is kept in", settingsPath+".legacy")
+		return saveSettings()
+	}
+	// Files written by older versions have no token secret yet.
+	if s.Notifications.MailReply.Secret == "" {
+		ensureMailReplySecret(&currentSettings)
+		if err := saveSettings(); err != nil {
+			fmt.Println("Failed to save the generated mail reply secret:", err)
+		}
+	}
 	return nil
 }
 
@@ -390,19 +804,27 @@ func loadSettings() error {
 func saveSettings() error {
 	DebugLog("----------------------------")
 	DebugLog("saveSettings called (settings.go)") // entry point
+	return writeSettings(currentSettings)
+}
 
-	b, err := json.MarshalIndent(currentSettings, "", "  ")
+// writeSettings writes s to the settings file.
+func writeSettings(s AppSettings) error {
+	b, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		DebugLog("Error marshalling settings: %v", err) // Debug
 		return err
 	}
 	DebugLog("Settings marshaled, writing to file...") // Log marshaling success
-	err = os.WriteFile(settingsFile, b, 0644)
-	if err != nil {
+	if err := WriteFileAtomic(settingsPath, b, 0644); err != nil {
 		DebugLog("Error writing to file: %v", err) // Debug
+		return err
 	}
-	// Write again the Fail2ban-UI action file (in the future not used anymore)
-	return writeFail2banAction()
+	return nil
+}
+
+// ManagedFiles returns the fail2ban configuration files written by fail2ban-ui.
+func ManagedFiles() []string {
+	return []string{jailFile, jailDFile, actionFile, fail2banLocal}
 }
 
 // GetSettings returns a copy of the current settings
@@ -412,12 +834,26 @@ func GetSettings() AppSettings {
 	return currentSettings
 }
 
+// CopySettings returns the current settings with their maps copied, so
+// they can be modified, e.g. by decoding a request onto them, without
+// touching the live settings.
+func CopySettings() AppSettings {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	s := currentSettings
+	s.Notifications.RecipientLanguages = maps.Clone(s.Notifications.RecipientLanguages)
+	s.Notifications.Policies = maps.Clone(s.Notifications.Policies)
+	s.Notifications.Routes = slices.Clone(s.Notifications.Routes)
+	s.Fail2ban.ResolvedIgnoreHosts = maps.Clone(s.Fail2ban.ResolvedIgnoreHosts)
+	return s
+}
+
 // MarkRestartNeeded sets restartNeeded = true and saves JSON
 func MarkRestartNeeded() error {
 	settingsLock.Lock()
 	defer settingsLock.Unlock()
 
-	currentSettings.RestartNeeded = true
+	currentSettings.Server.RestartNeeded = true
 	return saveSettings()
 }
 
@@ -426,7 +862,7 @@ func MarkRestartDone() error {
 	settingsLock.Lock()
 	defer settingsLock.Unlock()
 
-	currentSettings.RestartNeeded = false
+	currentSettings.Server.RestartNeeded = false
 	return saveSettings()
 }
 
@@ -438,18 +874,22 @@ func UpdateSettings(new AppSettings) (AppSettings, error) {
 	DebugLog("--- Locked settings for update ---") // Log lock acquisition
 
 	old := currentSettings
+	migrateAlertCountries(&new)
+	// The token signing secret is generated here, never taken from clients.
+	new.Notifications.MailReply.Secret = old.Notifications.MailReply.Secret
+	ensureMailReplySecret(&new)
 
 	// If certain fields change, we mark reload needed
-	if old.BantimeIncrement != new.BantimeIncrement ||
-		old.IgnoreIP != new.IgnoreIP ||
-		old.Bantime != new.Bantime ||
-		old.Findtime != new.Findtime ||
+	if old.Fail2ban.BantimeIncrement != new.Fail2ban.BantimeIncrement ||
+		old.Fail2ban.IgnoreIP != new.Fail2ban.IgnoreIP ||
+		old.Fail2ban.Bantime != new.Fail2ban.Bantime ||
+		old.Fail2ban.Findtime != new.Fail2ban.Findtime ||
 		//old.Maxretry != new.Maxretry ||
-		old.Maxretry != new.Maxretry {
-		new.RestartNeeded = true
+		old.Fail2ban.Maxretry != new.Fail2ban.Maxretry {
+		new.Server.RestartNeeded = true
 	} else {
 		// preserve previous RestartNeeded if it was already true
-		new.RestartNeeded = new.RestartNeeded || old.RestartNeeded
+		new.Server.RestartNeeded = new.Server.RestartNeeded || old.Server.RestartNeeded
 	}
 
 	currentSettings = new
@@ -0,0 +1,58 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// SlackSettings enable the Slack interactivity: alerts of webhooks in
+// Slack format carry "Unban" and "Whitelist" buttons, and the /fail2ban
+// slash command can be used. Slack sends both to /api/v1/slack.
+type SlackSettings struct {
+	// SigningSecret of the Slack app, used to verify Slack's requests.
+	// Interactivity is disabled while it is empty. Like all secrets it is
+	// blanked in GET /api/v1/settings, see RedactSecrets.
+	SigningSecret string `json:"signingSecret" settings:"secret"`
+	// AllowedUsers are the Slack user IDs ("U024BE7LH") allowed to unban
+	// and whitelist. Empty allows every member of the workspace.
+	AllowedUsers []string `json:"allowedUsers"`
+}
+
+var slackUserRegex = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// Enabled reports whether Slack requests are accepted.
+func (s SlackSettings) Enabled() bool {
+	return s.SigningSecret != ""
+}
+
+// Allows reports whether the Slack user may act on bans.
+func (s SlackSettings) Allows(userID string) bool {
+	return len(s.AllowedUsers) == 0 || slices.Contains(s.AllowedUsers, userID)
+}
+
+// Validate checks the format of the user IDs.
+func (s SlackSettings) Validate() error {
+	for _, id := range s.AllowedUsers {
+		if !slackUserRegex.MatchString(id) {
+			return fmt.Errorf("invalid Slack user ID %q, expected e.g. U024BE7LH", id)
+		}
+	}
+	return nil
+}
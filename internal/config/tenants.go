@@ -0,0 +1,70 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// Tenant scopes a group of users to a set of jails, so a service provider can
+// give customers access to their own jails only. Users that belong to no
+// tenant are operators of the UI instance and see everything.
+type Tenant struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	// Jails are jail names or shell patterns, e.g. "customer1-*".
+	Jails []string `json:"jails"`
+}
+
+// AllowsJail reports whether jail belongs to the tenant.
+func (t Tenant) AllowsJail(jail string) bool {
+	for _, pattern := range t.Jails {
+		if ok, _ := path.Match(pattern, jail); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the tenant's name and jail patterns.
+func (t Tenant) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("tenant name is required")
+	}
+	for _, pattern := range t.Jails {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("tenant %s: invalid jail pattern %q", t.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// TenantForUser returns the tenant the user belongs to. The second result is
+// false for users without tenant, which have unrestricted access.
+func TenantForUser(user string) (Tenant, bool) {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	for _, t := range currentSettings.Auth.Tenants {
+		for _, u := range t.Users {
+			if u == user {
+				return t, true
+			}
+		}
+	}
+	return Tenant{}, false
+}
@@ -0,0 +1,236 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Environment variables naming the certificate and key files, overriding
+// TLSSettings, e.g. for containers with mounted certificates.
+const (
+	TLSCertFileEnv = "FAIL2BAN_UI_TLS_CERT"
+	TLSKeyFileEnv  = "FAIL2BAN_UI_TLS_KEY"
+)
+
+// TLSSettings let the server answer HTTPS itself, without a reverse
+// proxy. The certificate is reloaded when its files change, so renewals
+// need no restart; changing the settings does.
+type TLSSettings struct {
+	CertFile string `json:"certFile"` // PEM, with the intermediate certificates
+	KeyFile  string `json:"keyFile"`
+	// RedirectPort, if set, serves redirects from plain HTTP to HTTPS on this port.
+	RedirectPort int `json:"redirectPort"`
+	// ACME obtains the certificate automatically instead of the files.
+	ACME ACMESettings `json:"acme"`
+	// ClientCerts authenticate machines on the API by client certificates.
+	ClientCerts ClientCertSettings `json:"clientCerts"`
+}
+
+// ClientCertSettings authenticate machines on the API, such as the curl
+// calls of the ban action or monitoring, by TLS client certificates issued
+// by the CAs of CAFile. They need native TLS. Browsers are only asked for
+// a certificate they have, and keep using the login of the dashboard.
+type ClientCertSettings struct {
+	CAFile string `json:"caFile"` // PEM bundle of the CAs issuing client certificates
+	// Names restricts the accepted certificates to these subject common
+	// names or DNS names; empty accepts every certificate of the CAs.
+	Names []string `json:"names"`
+	// Role of the requests authenticated by a certificate, default operator.
+	Role string `json:"role"`
+	// Required rejects API tokens, Basic auth and ingest tokens on the API
+	// unless the request also presents a valid certificate.
+	Required bool `json:"required"`
+}
+
+// Enabled reports whether client certificates are accepted.
+func (cc ClientCertSettings) Enabled() bool {
+	return cc.CAFile != ""
+}
+
+// CertRole returns the role of certificate-authenticated requests.
+func (cc ClientCertSettings) CertRole() string {
+	if cc.Role == "" {
+		return RoleOperator
+	}
+	return cc.Role
+}
+
+// Pool loads the CAs of CAFile.
+func (cc ClientCertSettings) Pool() (*x509.CertPool, error) {
+	pem, err := os.ReadFile(cc.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates in %s", cc.CAFile)
+	}
+	return pool, nil
+}
+
+// Accepts reports whether the verified certificate cert may authenticate,
+// and returns the name it authenticates as: the matching entry of Names,
+// or the subject common name.
+func (cc ClientCertSettings) Accepts(cert *x509.Certificate) (string, bool) {
+	if len(cc.Names) == 0 {
+		return cmp.Or(cert.Subject.CommonName, cert.SerialNumber.String()), true
+	}
+	for _, name := range cc.Names {
+		if strings.EqualFold(name, cert.Subject.CommonName) || slices.ContainsFunc(cert.DNSNames, func(dns string) bool {
+			return strings.EqualFold(name, dns)
+		}) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Validate checks the role and loads the CA bundle. Client certificates
+// need native TLS.
+func (cc ClientCertSettings) Validate(tlsEnabled bool) error {
+	if !cc.Enabled() {
+		if cc.Required {
+			return fmt.Errorf("requiring client certificates needs a CA file")
+		}
+		return nil
+	}
+	if !tlsEnabled {
+		return fmt.Errorf("client certificates need a TLS certificate or ACME")
+	}
+	if _, ok := roleRanks[cc.CertRole()]; !ok {
+		return fmt.Errorf("invalid client certificate role %q", cc.Role)
+	}
+	for _, name := range cc.Names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("empty client certificate name")
+		}
+	}
+	if _, err := cc.Pool(); err != nil {
+		return fmt.Errorf("cannot load client CA file: %w", err)
+	}
+	return nil
+}
+
+// ACMESettings obtain and renew the certificate from Let's Encrypt or
+// another ACME CA. The CA validates the hostnames on port 443 (TLS-ALPN)
+// or on port 80 (HTTP), so one of them must reach the UI: the server port
+// or the redirect port.
+type ACMESettings struct {
+	Enabled   bool     `json:"enabled"`
+	Hostnames []string `json:"hostnames"` // names the certificate is requested for
+	Email     string   `json:"email"`     // contact of the ACME account, optional
+	// CacheDir holds the account key and certificates, default
+	// "fail2ban-ui-acme"; relative paths are next to the settings file.
+	CacheDir string `json:"cacheDir"`
+	// DirectoryURL selects another CA, e.g. the Let's Encrypt staging
+	// environment; Let's Encrypt by default.
+	DirectoryURL string `json:"directoryURL"`
+}
+
+const defaultACMECacheDir = "fail2ban-ui-acme"
+
+// Cache returns the directory of the ACME account key and certificates.
+func (a ACMESettings) Cache() string {
+	if a.CacheDir == "" {
+		return DataPath(defaultACMECacheDir)
+	}
+	return DataPath(a.CacheDir)
+}
+
+// Validate checks the hostnames, email and directory URL.
+func (a ACMESettings) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if len(a.Hostnames) == 0 {
+		return fmt.Errorf("ACME needs at least one hostname")
+	}
+	for _, h := range a.Hostnames {
+		if h == "" || net.ParseIP(h) != nil || strings.ContainsAny(h, "*/: ") || !strings.Contains(h, ".") {
+			return fmt.Errorf("invalid ACME hostname %q, use a fully qualified domain name without wildcards", h)
+		}
+	}
+	if a.Email != "" {
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			return fmt.Errorf("invalid ACME email %q", a.Email)
+		}
+	}
+	if a.DirectoryURL != "" {
+		if u, err := url.Parse(a.DirectoryURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid ACME directory URL %q", a.DirectoryURL)
+		}
+	}
+	return nil
+}
+
+// Files returns the certificate and key files, from the environment or the settings.
+func (t TLSSettings) Files() (cert, key string) {
+	cert, key = t.CertFile, t.KeyFile
+	if env := os.Getenv(TLSCertFileEnv); env != "" {
+		cert = env
+	}
+	if env := os.Getenv(TLSKeyFileEnv); env != "" {
+		key = env
+	}
+	return cert, key
+}
+
+// Enabled reports whether the server serves HTTPS, with the certificate
+// files or ACME.
+func (t TLSSettings) Enabled() bool {
+	cert, key := t.Files()
+	return (cert != "" && key != "") || t.ACME.Enabled
+}
+
+// Validate requires both files or none and checks that they hold a
+// matching certificate and key, so a restart does not fail on them. The
+// files and ACME exclude each other.
+func (t TLSSettings) Validate() error {
+	if t.RedirectPort < 0 || t.RedirectPort > 65535 {
+		return fmt.Errorf("invalid redirect port %d", t.RedirectPort)
+	}
+	if err := t.ClientCerts.Validate(t.Enabled()); err != nil {
+		return err
+	}
+	cert, key := t.Files()
+	if t.ACME.Enabled {
+		if cert != "" || key != "" {
+			return fmt.Errorf("use either ACME or certificate files")
+		}
+		return t.ACME.Validate()
+	}
+	if cert == "" && key == "" {
+		return nil
+	}
+	if cert == "" || key == "" {
+		return fmt.Errorf("both the certificate and the key file are required")
+	}
+	if _, err := tls.LoadX509KeyPair(cert, key); err != nil {
+		return fmt.Errorf("cannot load certificate: %w", err)
+	}
+	return nil
+}
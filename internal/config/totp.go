@@ -0,0 +1,212 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TOTP parameters, the defaults of authenticator apps (RFC 6238).
+const (
+	totpPeriod    = 30 * time.Second
+	totpDigits    = 6
+	totpSkew      = 1 // steps accepted before and after the current one
+	totpIssuer    = "Fail2ban UI"
+	recoveryCodes = 10
+)
+
+// TOTPSettings hold the second factor of the admin login in password
+// mode. It is enabled once Secret is set by ConfirmTOTP.
+type TOTPSettings struct {
+	Secret string `json:"secret" settings:"secret"` // base32
+	// RecoveryCodes are SHA-256 hashes of the unused recovery codes.
+	RecoveryCodes []string `json:"recoveryCodes" settings:"secret"`
+}
+
+// Enabled reports whether logins require a second factor.
+func (t TOTPSettings) Enabled() bool {
+	return t.Secret != ""
+}
+
+var (
+	// pendingTOTP is the secret of an enrollment waiting for ConfirmTOTP.
+	pendingTOTP string
+	// lastTOTPStep is the time step of the last accepted code, so a code
+	// cannot be used twice.
+	lastTOTPStep uint64
+	totpLock     sync.Mutex
+)
+
+// BeginTOTPEnrollment creates a new secret for account and returns it with
+// the otpauth:// URL for authenticator apps. The second factor is only
+// enabled once ConfirmTOTP receives a valid code for it.
+func BeginTOTPEnrollment(account string) (secret, otpauthURL string, err error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return "", "", err
+	}
+	secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+	totpLock.Lock()
+	pendingTOTP = secret
+	totpLock.Unlock()
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", totpIssuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + totpIssuer + ":" + account, RawQuery: q.Encode()}
+	return secret, u.String(), nil
+}
+
+// ConfirmTOTP enables the second factor with the pending secret if code
+// is valid for it, and returns new recovery codes. Only their hashes are
+// stored, the codes are shown once.
+func ConfirmTOTP(code string) ([]string, error) {
+	totpLock.Lock()
+	secret := pendingTOTP
+	step, ok := checkTOTP(secret, code, time.Now())
+	if ok {
+		pendingTOTP = ""
+		lastTOTPStep = step
+	}
+	totpLock.Unlock()
+	if secret == "" {
+		return nil, fmt.Errorf("no two-factor enrollment in progress, start one first")
+	}
+	if !ok {
+		return nil, fmt.Errorf("the code is not valid, check the time of the device")
+	}
+
+	codes, hashes := newRecoveryCodes()
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	currentSettings.Auth.TOTP = TOTPSettings{Secret: secret, RecoveryCodes: hashes}
+	return codes, saveSettings()
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes.
+func RegenerateRecoveryCodes() ([]string, error) {
+	codes, hashes := newRecoveryCodes()
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	if !currentSettings.Auth.TOTP.Enabled() {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+	currentSettings.Auth.TOTP.RecoveryCodes = hashes
+	return codes, saveSettings()
+}
+
+// DisableTOTP turns the second factor off and drops the recovery codes.
+func DisableTOTP() error {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	currentSettings.Auth.TOTP = TOTPSettings{}
+	return saveSettings()
+}
+
+// VerifySecondFactor checks code against the TOTP secret, or else against
+// the recovery codes. A recovery code is used up by a successful login.
+func VerifySecondFactor(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	totp := GetSettings().Auth.TOTP
+	if !totp.Enabled() || code == "" {
+		return false
+	}
+
+	totpLock.Lock()
+	step, ok := checkTOTP(totp.Secret, code, time.Now())
+	if ok && step <= lastTOTPStep {
+		ok = false // replayed
+	}
+	if ok {
+		lastTOTPStep = step
+	}
+	totpLock.Unlock()
+	if ok {
+		return true
+	}
+
+	hash := hashToken(strings.ToLower(strings.ReplaceAll(code, "-", "")))
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	left := currentSettings.Auth.TOTP.RecoveryCodes
+	for i, h := range left {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			currentSettings.Auth.TOTP.RecoveryCodes = append(left[:i:i], left[i+1:]...)
+			if err := saveSettings(); err != nil {
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// checkTOTP reports whether code is valid for secret at now, allowing for
+// clock skew, and returns the matching time step.
+func checkTOTP(secret, code string, now time.Time) (uint64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if secret == "" || err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := uint64(now.Unix()) / uint64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the code of key for a time step (RFC 4226 HOTP).
+func totpCode(key []byte, step uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], step)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// newRecoveryCodes returns random recovery codes like "3f9a-c2d1-77b0"
+// and their hashes.
+func newRecoveryCodes() (codes, hashes []string) {
+	for range recoveryCodes {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		code := fmt.Sprintf("%x-%x-%x", b[0:2], b[2:4], b[4:6])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(strings.ReplaceAll(code, "-", "")))
+	}
+	return codes, hashes
+}
@@ -0,0 +1,108 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// TourSteps lists the steps of the guided tour in the order they are shown.
+var TourSteps = []string{"dashboard", "search", "unban", "filters", "jails", "settings"}
+
+// TourState holds the guided tour progress of a single user.
+type TourState struct {
+	CompletedSteps []string  `json:"completedSteps"`
+	Finished       bool      `json:"finished"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+const tourFile = "fail2ban-ui-tour.json" // stored next to the settings file
+
+var (
+	tourStates map[string]TourState
+	tourLock   sync.Mutex
+)
+
+// GetTourState returns the tour progress of the given user.
+func GetTourState(user string) TourState {
+	tourLock.Lock()
+	defer tourLock.Unlock()
+	loadTourStates()
+	return tourStates[user]
+}
+
+// CompleteTourStep marks a tour step as completed for the given user.
+func CompleteTourStep(user, step string) (TourState, error) {
+	if !isTourStep(step) {
+		return TourState{}, fmt.Errorf("unknown tour step: %s", step)
+	}
+
+	tourLock.Lock()
+	defer tourLock.Unlock()
+	loadTourStates()
+
+	state := tourStates[user]
+	for _, s := range state.CompletedSteps {
+		if s == step {
+			return state, nil
+		}
+	}
+	state.CompletedSteps = append(state.CompletedSteps, step)
+	state.Finished = len(state.CompletedSteps) == len(TourSteps)
+	state.UpdatedAt = time.Now()
+	tourStates[user] = state
+	return state, saveTourStates()
+}
+
+// ResetTour clears the tour progress of the given user.
+func ResetTour(user string) error {
+	tourLock.Lock()
+	defer tourLock.Unlock()
+	loadTourStates()
+
+	delete(tourStates, user)
+	return saveTourStates()
+}
+
+func isTourStep(step string) bool {
+	for _, s := range TourSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTourStates reads the tour file once. The caller must hold tourLock.
+func loadTourStates() {
+	if tourStates != nil {
+		return
+	}
+	tourStates = make(map[string]TourState)
+	if err := readJSONFile(tourFile, &tourStates); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", tourFile, err)
+		tourStates = make(map[string]TourState)
+	}
+}
+
+// saveTourStates writes all tour states to disk. The caller must hold tourLock.
+func saveTourStates() error {
+	return writeJSONFile(tourFile, tourStates)
+}
@@ -0,0 +1,225 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statuses of an UnbanRequest.
+const (
+	UnbanPending  = "pending"
+	UnbanApproved = "approved"
+	UnbanDenied   = "denied"
+)
+
+// UnbanRequest is a request of a banned visitor, submitted on the lockout
+// self-service page, to be unbanned. It waits for an operator to approve
+// or deny it.
+type UnbanRequest struct {
+	ID        string    `json:"id"`
+	IP        string    `json:"ip"`
+	Jails     []string  `json:"jails"` // jails the IP was banned in when submitted
+	Message   string    `json:"message,omitempty"`
+	Contact   string    `json:"contact,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	DecidedBy string    `json:"decidedBy,omitempty"`
+	DecidedAt time.Time `json:"decidedAt,omitempty"`
+	Note      string    `json:"note,omitempty"` // reason of the decision, sent to the requester
+	// NotifyError is why the requester could not be notified of the decision.
+	NotifyError string `json:"notifyError,omitempty"`
+}
+
+const (
+	unbanRequestsFile = "fail2ban-ui-unban-requests.json" // stored next to the settings file
+
+	// maxPendingUnbanRequests bounds the queue, as anyone banned can submit.
+	maxPendingUnbanRequests = 500
+	// unbanRequestRetention is how long decided requests are kept.
+	unbanRequestRetention = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrUnbanRequestNotFound is returned when a request does not exist.
+	ErrUnbanRequestNotFound = errors.New("unban request not found")
+	// ErrUnbanRequestPending is returned when the IP already has a pending request.
+	ErrUnbanRequestPending = errors.New("an unban request for this IP is already pending")
+	// ErrUnbanQueueFull is returned when too many requests are pending.
+	ErrUnbanQueueFull = errors.New("too many pending unban requests, try again later")
+	// ErrUnbanRequestDecided is returned when a request was approved or denied already.
+	ErrUnbanRequestDecided = errors.New("unban request was decided already")
+)
+
+var (
+	unbanRequests       []UnbanRequest
+	unbanRequestsLoaded bool
+	unbanRequestsLock   sync.Mutex
+)
+
+// Validate checks the lengths of the visitor's message and contact.
+func (r *UnbanRequest) Validate() error {
+	r.Message = strings.TrimSpace(r.Message)
+	r.Contact = strings.TrimSpace(r.Contact)
+	if len(r.Message) > 2000 {
+		return fmt.Errorf("message must not exceed 2000 characters")
+	}
+	if len(r.Contact) > 200 {
+		return fmt.Errorf("contact must not exceed 200 characters")
+	}
+	return nil
+}
+
+// GetUnbanRequests returns the unban requests, pending ones first, then
+// newest first.
+func GetUnbanRequests() []UnbanRequest {
+	unbanRequestsLock.Lock()
+	defer unbanRequestsLock.Unlock()
+	loadUnbanRequests()
+	out := append([]UnbanRequest(nil), unbanRequests...)
+	sort.Slice(out, func(i, j int) bool {
+		if pi, pj := out[i].Status == UnbanPending, out[j].Status == UnbanPending; pi != pj {
+			return pi
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out
+}
+
+// LatestUnbanRequest returns the newest request of ip, if any. As an IP
+// has one pending request at most, a pending request is always the newest.
+func LatestUnbanRequest(ip string) (UnbanRequest, bool) {
+	unbanRequestsLock.Lock()
+	defer unbanRequestsLock.Unlock()
+	loadUnbanRequests()
+	var latest UnbanRequest
+	found := false
+	for _, r := range unbanRequests {
+		if r.IP == ip && (!found || r.CreatedAt.After(latest.CreatedAt)) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// AddUnbanRequest validates and queues a new request. Each IP may have one
+// pending request at a time.
+func AddUnbanRequest(r UnbanRequest) (UnbanRequest, error) {
+	if err := r.Validate(); err != nil {
+		return UnbanRequest{}, err
+	}
+	unbanRequestsLock.Lock()
+	defer unbanRequestsLock.Unlock()
+	loadUnbanRequests()
+
+	pending := 0
+	for _, old := range unbanRequests {
+		if old.Status != UnbanPending {
+			continue
+		}
+		if old.IP == r.IP {
+			return UnbanRequest{}, ErrUnbanRequestPending
+		}
+		pending++
+	}
+	if pending >= maxPendingUnbanRequests {
+		return UnbanRequest{}, ErrUnbanQueueFull
+	}
+	r.ID = newID()
+	r.Status = UnbanPending
+	r.CreatedAt = time.Now()
+	r.DecidedBy, r.DecidedAt, r.Note, r.NotifyError = "", time.Time{}, "", ""
+	unbanRequests = append(pruneUnbanRequests(unbanRequests, r.CreatedAt), r)
+	return r, writeJSONFile(unbanRequestsFile, unbanRequests)
+}
+
+// DecideUnbanRequest marks a pending request as approved or denied by
+// user, with an optional note for the requester.
+func DecideUnbanRequest(id, status, user, note string) (UnbanRequest, error) {
+	if status != UnbanApproved && status != UnbanDenied {
+		return UnbanRequest{}, fmt.Errorf("invalid status %q", status)
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > 2000 {
+		return UnbanRequest{}, fmt.Errorf("note must not exceed 2000 characters")
+	}
+	unbanRequestsLock.Lock()
+	defer unbanRequestsLock.Unlock()
+	loadUnbanRequests()
+
+	for i := range unbanRequests {
+		r := &unbanRequests[i]
+		if r.ID != id {
+			continue
+		}
+		if r.Status != UnbanPending {
+			return *r, ErrUnbanRequestDecided
+		}
+		r.Status = status
+		r.DecidedBy = user
+		r.DecidedAt = time.Now()
+		r.Note = note
+		return *r, writeJSONFile(unbanRequestsFile, unbanRequests)
+	}
+	return UnbanRequest{}, ErrUnbanRequestNotFound
+}
+
+// SetUnbanNotifyError records why the requester of a decided request was
+// not notified.
+func SetUnbanNotifyError(id, msg string) error {
+	unbanRequestsLock.Lock()
+	defer unbanRequestsLock.Unlock()
+	loadUnbanRequests()
+
+	for i := range unbanRequests {
+		if unbanRequests[i].ID == id {
+			unbanRequests[i].NotifyError = msg
+			return writeJSONFile(unbanRequestsFile, unbanRequests)
+		}
+	}
+	return ErrUnbanRequestNotFound
+}
+
+// pruneUnbanRequests drops the requests decided longer than
+// unbanRequestRetention ago.
+func pruneUnbanRequests(reqs []UnbanRequest, now time.Time) []UnbanRequest {
+	kept := reqs[:0]
+	for _, r := range reqs {
+		if r.Status == UnbanPending || now.Sub(r.DecidedAt) < unbanRequestRetention {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// loadUnbanRequests reads the unban requests file once. The caller must
+// hold unbanRequestsLock.
+func loadUnbanRequests() {
+	if unbanRequestsLoaded {
+		return
+	}
+	unbanRequestsLoaded = true
+	if err := readJSONFile(unbanRequestsFile, &unbanRequests); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", unbanRequestsFile, err)
+	}
+}
@@ -0,0 +1,173 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchEntry is an IP address or CIDR range of special interest.
+type WatchEntry struct {
+	ID        string    `json:"id"`
+	Value     string    `json:"value"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const watchlistFile = "fail2ban-ui-watchlist.json" // stored next to the settings file
+
+// ErrWatchEntryNotFound is returned when a watchlist entry does not exist.
+var ErrWatchEntryNotFound = errors.New("watchlist entry not found")
+
+var (
+	watchlist       []WatchEntry
+	watchlistNets   []*net.IPNet // parallel to watchlist
+	watchlistLoaded bool
+	watchlistLock   sync.RWMutex
+)
+
+// GetWatchlist returns a copy of all watchlist entries.
+func GetWatchlist() []WatchEntry {
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+	loadWatchlist()
+	return append([]WatchEntry(nil), watchlist...)
+}
+
+// AddWatchEntry adds an IP or CIDR to the watchlist.
+func AddWatchEntry(value, note string) (WatchEntry, error) {
+	ipNet, err := parseNetwork(value)
+	if err != nil {
+		return WatchEntry{}, err
+	}
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+	loadWatchlist()
+
+	entry := WatchEntry{
+		ID:        newID(),
+		Value:     ipNet.String(),
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+	watchlist = append(watchlist, entry)
+	watchlistNets = append(watchlistNets, ipNet)
+	return entry, writeJSONFile(watchlistFile, watchlist)
+}
+
+// UpdateWatchEntry changes the value and note of an existing entry.
+func UpdateWatchEntry(id, value, note string) (WatchEntry, error) {
+	ipNet, err := parseNetwork(value)
+	if err != nil {
+		return WatchEntry{}, err
+	}
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+	loadWatchlist()
+
+	for i := range watchlist {
+		if watchlist[i].ID == id {
+			watchlist[i].Value = ipNet.String()
+			watchlist[i].Note = note
+			watchlistNets[i] = ipNet
+			return watchlist[i], writeJSONFile(watchlistFile, watchlist)
+		}
+	}
+	return WatchEntry{}, ErrWatchEntryNotFound
+}
+
+// DeleteWatchEntry removes an entry from the watchlist.
+func DeleteWatchEntry(id string) error {
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+	loadWatchlist()
+
+	for i := range watchlist {
+		if watchlist[i].ID == id {
+			watchlist = append(watchlist[:i], watchlist[i+1:]...)
+			watchlistNets = append(watchlistNets[:i], watchlistNets[i+1:]...)
+			return writeJSONFile(watchlistFile, watchlist)
+		}
+	}
+	return ErrWatchEntryNotFound
+}
+
+// MatchWatchlist returns the first watchlist entry containing ip.
+func MatchWatchlist(ip string) (WatchEntry, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return WatchEntry{}, false
+	}
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+	loadWatchlist()
+
+	for i, n := range watchlistNets {
+		if n != nil && n.Contains(parsed) {
+			return watchlist[i], true
+		}
+	}
+	return WatchEntry{}, false
+}
+
+// parseNetwork accepts a single IP or a CIDR and returns it as network.
+func parseNetwork(value string) (*net.IPNet, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address: %s", value)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR: %s", value)
+	}
+	return ipNet, nil
+}
+
+// loadWatchlist reads the watchlist file once. The caller must hold watchlistLock.
+func loadWatchlist() {
+	if watchlistLoaded {
+		return
+	}
+	watchlistLoaded = true
+	if err := readJSONFile(watchlistFile, &watchlist); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", watchlistFile, err)
+	}
+	watchlistNets = make([]*net.IPNet, len(watchlist))
+	for i, e := range watchlist {
+		if n, err := parseNetwork(e.Value); err == nil {
+			watchlistNets[i] = n
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Webhook is an outbound HTTP subscription for ban events.
+// Filter is an expression evaluated against every event; empty matches all.
+type Webhook struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Filter    string    `json:"filter"`
+	Secret    string    `json:"secret,omitempty"`
+	Format    string    `json:"format,omitempty"` // WebhookFormatJSON or WebhookFormatSlack
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Redacted returns w without its secret, for API responses.
+func (w Webhook) Redacted() Webhook {
+	w.Secret = ""
+	return w
+}
+
+// Payload formats of webhooks.
+const (
+	WebhookFormatJSON  = ""      // the event fields as a JSON object
+	WebhookFormatSlack = "slack" // a Slack message with unban buttons, for Slack incoming webhooks
+)
+
+const webhooksFile = "fail2ban-ui-webhooks.json" // stored next to the settings file
+
+// ErrWebhookNotFound is returned when a webhook does not exist.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+var (
+	webhooks       []Webhook
+	webhooksLoaded bool
+	webhooksLock   sync.Mutex
+)
+
+// GetWebhooks returns a copy of all webhooks.
+func GetWebhooks() []Webhook {
+	webhooksLock.Lock()
+	defer webhooksLock.Unlock()
+	loadWebhooks()
+	return append([]Webhook(nil), webhooks...)
+}
+
+// AddWebhook stores a new webhook and returns it with its generated ID.
+func AddWebhook(w Webhook) (Webhook, error) {
+	webhooksLock.Lock()
+	defer webhooksLock.Unlock()
+	loadWebhooks()
+
+	w.ID = newID()
+	w.CreatedAt = time.Now()
+	webhooks = append(webhooks, w)
+	return w, writeJSONFile(webhooksFile, webhooks)
+}
+
+// UpdateWebhook replaces the webhook with the given ID, keeping ID and
+// creation time. The secret is kept too if w has none, as it is not sent
+// to clients, see Redacted.
+func UpdateWebhook(id string, w Webhook) (Webhook, error) {
+	webhooksLock.Lock()
+	defer webhooksLock.Unlock()
+	loadWebhooks()
+
+	for i := range webhooks {
+		if webhooks[i].ID == id {
+			w.ID = id
+			w.CreatedAt = webhooks[i].CreatedAt
+			if w.Secret == "" {
+				w.Secret = webhooks[i].Secret
+			}
+			webhooks[i] = w
+			return w, writeJSONFile(webhooksFile, webhooks)
+		}
+	}
+	return Webhook{}, ErrWebhookNotFound
+}
+
+// DeleteWebhook removes the webhook with the given ID.
+func DeleteWebhook(id string) error {
+	webhooksLock.Lock()
+	defer webhooksLock.Unlock()
+	loadWebhooks()
+
+	for i := range webhooks {
+		if webhooks[i].ID == id {
+			webhooks = append(webhooks[:i], webhooks[i+1:]...)
+			return writeJSONFile(webhooksFile, webhooks)
+		}
+	}
+	return ErrWebhookNotFound
+}
+
+// loadWebhooks reads the webhooks file once. The caller must hold webhooksLock.
+func loadWebhooks() {
+	if webhooksLoaded {
+		return
+	}
+	webhooksLoaded = true
+	if err := readJSONFile(webhooksFile, &webhooks); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", webhooksFile, err)
+	}
+}
@@ -0,0 +1,120 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// WhoisSettings control the whois/RDAP lookups of banned IPs. Zero values
+// use the defaults below.
+type WhoisSettings struct {
+	// Providers are tried in this order: "rdap" (rdap.org, redirecting to
+	// the responsible registry) and "whois" (port 43, following the IANA
+	// referral). Cached records are the last fallback.
+	Providers []string `json:"providers"`
+	// CacheDays is how long a record is used before it is looked up again.
+	CacheDays int `json:"cacheDays"`
+	// RequestsPerMinute limits the queries sent to each provider and server.
+	RequestsPerMinute int `json:"requestsPerMinute"`
+}
+
+const (
+	WhoisRDAP   = "rdap"
+	WhoisPort43 = "whois"
+
+	defaultWhoisCacheDays  = 30
+	defaultWhoisRatePerMin = 10
+	whoisCacheFile         = "fail2ban-ui-whois.json" // stored next to the settings file
+)
+
+// ProviderChain returns the providers in the order they are tried.
+func (w WhoisSettings) ProviderChain() []string {
+	if len(w.Providers) == 0 {
+		return []string{WhoisRDAP, WhoisPort43}
+	}
+	return w.Providers
+}
+
+// CacheTTL returns how long cached records are fresh.
+func (w WhoisSettings) CacheTTL() time.Duration {
+	days := w.CacheDays
+	if days <= 0 {
+		days = defaultWhoisCacheDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// RateLimit returns the allowed queries per minute and provider.
+func (w WhoisSettings) RateLimit() int {
+	if w.RequestsPerMinute <= 0 {
+		return defaultWhoisRatePerMin
+	}
+	return w.RequestsPerMinute
+}
+
+// Validate checks the provider names.
+func (w WhoisSettings) Validate() error {
+	seen := make(map[string]bool)
+	for _, p := range w.Providers {
+		if p != WhoisRDAP && p != WhoisPort43 {
+			return fmt.Errorf("unknown whois provider %q, use %q or %q", p, WhoisRDAP, WhoisPort43)
+		}
+		if seen[p] {
+			return fmt.Errorf("whois provider %q is listed twice", p)
+		}
+		seen[p] = true
+	}
+	if w.CacheDays < 0 || w.RequestsPerMinute < 0 {
+		return fmt.Errorf("cache days and requests per minute must not be negative")
+	}
+	return nil
+}
+
+// WhoisRecord is the whois information of an allocated address range.
+// Records are cached by range, so all IPs of a range share one lookup.
+type WhoisRecord struct {
+	Range     string    `json:"range"` // CIDR or "start - end" as reported by the registry
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	Source    string    `json:"source"` // provider and server, e.g. "whois:whois.ripe.net"
+	Text      string    `json:"text"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+var whoisCacheLock sync.Mutex
+
+// LoadWhoisCache returns the persisted whois records.
+func LoadWhoisCache() []WhoisRecord {
+	whoisCacheLock.Lock()
+	defer whoisCacheLock.Unlock()
+	var records []WhoisRecord
+	if err := readJSONFile(whoisCacheFile, &records); err != nil && !os.IsNotExist(err) {
+		DebugLog("Error reading %s: %v", whoisCacheFile, err)
+	}
+	return records
+}
+
+// SaveWhoisCache persists the whois records.
+func SaveWhoisCache(records []WhoisRecord) error {
+	whoisCacheLock.Lock()
+	defer whoisCacheLock.Unlock()
+	return writeJSONFile(whoisCacheFile, records)
+}
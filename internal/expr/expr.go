@@ -0,0 +1,437 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expr implements the small boolean expression language used by
+// alert routing, webhook filters and escalation rules, e.g.
+//
+//	jail in ['sshd', 'nginx'] && country == 'RU' && repeat_count > 3
+//	'tor' in tags || (watched && !(country in ['CH', 'DE']))
+//	ip =~ '^203\.0\.113\.'
+//
+// Expressions are side-effect free and have no loops or function calls, so
+// evaluating user supplied input is safe.
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Program is a compiled expression.
+type Program struct {
+	src  string
+	root node
+}
+
+// Compile parses src. If fields is not nil, identifiers not contained in it are rejected.
+func Compile(src string, fields []string) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("empty expression")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	if fields != nil {
+		known := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			known[f] = true
+		}
+		for _, id := range p.idents {
+			if !known[id] {
+				return nil, fmt.Errorf("unknown field %q", id)
+			}
+		}
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the expression against vars and returns its truth value.
+// Missing variables evaluate to nil; type mismatches make comparisons false.
+func (p *Program) Eval(vars map[string]interface{}) bool {
+	return truthy(p.root.eval(vars))
+}
+
+// Match compiles src and evaluates it. An empty expression matches everything.
+func Match(src string, vars map[string]interface{}) (bool, error) {
+	if strings.TrimSpace(src) == "" {
+		return true, nil
+	}
+	prog, err := Compile(src, nil)
+	if err != nil {
+		return false, err
+	}
+	return prog.Eval(vars), nil
+}
+
+// ---- AST ----
+
+type node interface {
+	eval(vars map[string]interface{}) interface{}
+}
+
+type literal struct{ value interface{} }
+
+type ident struct{ name string }
+
+type list struct{ items []node }
+
+type unary struct{ operand node }
+
+type binary struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp // precompiled for =~ with a literal pattern
+}
+
+func (n literal) eval(map[string]interface{}) interface{} { return n.value }
+
+func (n ident) eval(vars map[string]interface{}) interface{} { return vars[n.name] }
+
+func (n list) eval(vars map[string]interface{}) interface{} {
+	out := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		out[i] = item.eval(vars)
+	}
+	return out
+}
+
+func (n unary) eval(vars map[string]interface{}) interface{} { return !truthy(n.operand.eval(vars)) }
+
+func (n binary) eval(vars map[string]interface{}) interface{} {
+	switch n.op {
+	case "&&":
+		return truthy(n.left.eval(vars)) && truthy(n.right.eval(vars))
+	case "||":
+		return truthy(n.left.eval(vars)) || truthy(n.right.eval(vars))
+	}
+	l, r := n.left.eval(vars), n.right.eval(vars)
+	switch n.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	case "in":
+		return contains(r, l)
+	case "not in":
+		return !contains(r, l)
+	case "=~":
+		re := n.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(fmt.Sprint(r)); err != nil {
+				return false
+			}
+		}
+		return re.MatchString(fmt.Sprint(l))
+	}
+	a, ok1 := toNumber(l)
+	b, ok2 := toNumber(r)
+	if !ok1 || !ok2 {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// ---- parser ----
+
+// maxDepth limits the nesting of parentheses, lists and negations, so
+// deeply nested input cannot exhaust the stack of the recursive parser.
+const maxDepth = 64
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	idents []string
+}
+
+// enter starts a nested construct opened by t; it is closed by leave.
+func (p *parser) enter(t token) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("expression nested too deeply at position %d", t.pos)
+	}
+	return nil
+}
+
+func (p *parser) leave() { p.depth-- }
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for (p.peek().kind == tokOp && p.peek().text == "||") || p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for (p.peek().kind == tokOp && p.peek().text == "&&") || p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if (p.peek().kind == tokOp && p.peek().text == "!") || p.isKeyword("not") {
+		if err := p.enter(p.next()); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unary{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	var op string
+	switch {
+	case t.kind == tokOp && t.text != "!" && t.text != "&&" && t.text != "||":
+		op = t.text
+		p.next()
+	case p.isKeyword("in"):
+		op = "in"
+		p.next()
+	case p.isKeyword("not") && p.pos+1 < len(p.tokens) &&
+		p.tokens[p.pos+1].kind == tokIdent && strings.EqualFold(p.tokens[p.pos+1].text, "in"):
+		op = "not in"
+		p.next()
+		p.next()
+	default:
+		return left, nil
+	}
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	b := binary{op: op, left: left, right: right}
+	if lit, ok := right.(literal); ok && op == "=~" {
+		re, err := regexp.Compile(fmt.Sprint(lit.value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		b.re = re
+	}
+	return b, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	if t.kind == tokLParen || t.kind == tokLBrack {
+		if err := p.enter(t); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+	}
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", t.pos)
+		}
+		return n, nil
+	case tokLBrack:
+		var items []node
+		for p.peek().kind != tokRBrack {
+			item, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if p.peek().kind == tokComma {
+				p.next()
+			} else if p.peek().kind != tokRBrack {
+				return nil, fmt.Errorf("expected ',' or ']' at position %d", p.peek().pos)
+			}
+		}
+		p.next()
+		return list{items: items}, nil
+	case tokString:
+		return literal{value: t.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return literal{value: n}, nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		case "null", "nil":
+			return literal{value: nil}, nil
+		}
+		p.idents = append(p.idents, t.text)
+		return ident{name: t.text}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// ---- value semantics ----
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case []string:
+		return len(x) > 0
+	case []interface{}:
+		return len(x) > 0
+	}
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+	return true
+}
+
+func equal(a, b interface{}) bool {
+	// A list field compared with a single value means "contains", so
+	// tags == 'tor' behaves like 'tor' in tags.
+	if list, ok := a.([]string); ok {
+		return contains(list, b)
+	}
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := a.(bool); ok {
+		return truthy(b) == x && isBool(b)
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func isBool(v interface{}) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+// contains reports whether the list value haystack contains needle.
+func contains(haystack, needle interface{}) bool {
+	switch h := haystack.(type) {
+	case []interface{}:
+		for _, v := range h {
+			if equal(v, needle) {
+				return true
+			}
+		}
+	case []string:
+		for _, v := range h {
+			if equal(v, needle) {
+				return true
+			}
+		}
+	case string:
+		return strings.Contains(h, fmt.Sprint(needle))
+	}
+	return false
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,227 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ban callbacks are the POSTs of the fail2ban-ui action to /api/ban. The
+// callback check compares them with the bans in the fail2ban log, so
+// callbacks that never arrived (curl failed, UI down, ...) are noticed.
+
+const (
+	// callbackGrace is how long a callback may take to arrive after the ban was logged.
+	callbackGrace = 2 * time.Minute
+	// callbackAction is the action sending the callbacks, see config.writeFail2banAction.
+	callbackAction = "ui-custom-action"
+	// maxMissedCallbacks is the number of missed callbacks kept for the API.
+	maxMissedCallbacks = 50
+)
+
+// MissedCallback is a ban found in the log without a matching callback.
+type MissedCallback struct {
+	Jail    string    `json:"jail"`
+	IP      string    `json:"ip"`
+	LogLine string    `json:"logLine"`
+	SeenAt  time.Time `json:"seenAt"`
+}
+
+// CallbackCheck summarizes the comparison of callbacks with the log.
+type CallbackCheck struct {
+	Verified  uint64           `json:"verified"` // logged bans with a callback
+	Missing   uint64           `json:"missing"`  // logged bans without a callback
+	Recent    []MissedCallback `json:"recent"`   // newest first
+	LogPath   string           `json:"logPath,omitempty"`
+	CheckedAt time.Time        `json:"checkedAt,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// pendingBan is a logged ban waiting for the grace period to pass.
+type pendingBan struct {
+	ev      BanEvent
+	since   time.Time // callbacks received after this time count for the ban
+	seenAt  time.Time
+	checkAt time.Time
+}
+
+var (
+	callbackLock     sync.Mutex
+	callbacks        = make(map[string]time.Time) // jail|ip -> last callback
+	pendingBans      []pendingBan
+	callbackOffset   int64 = -1 // read position in the log, -1 before the first check
+	lastCallbackScan time.Time
+	callbackCheck    = CallbackCheck{Recent: []MissedCallback{}}
+	jailActions      = make(map[string]jailActionsEntry)
+)
+
+type jailActionsEntry struct {
+	callback bool
+	at       time.Time
+}
+
+// RecordCallback notes that the ban callback for ip in jail arrived.
+func RecordCallback(jail, ip string) {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	callbacks[jail+"|"+ip] = time.Now()
+}
+
+// GetCallbackCheck returns the result of the callback checks so far.
+func GetCallbackCheck() CallbackCheck {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	check := callbackCheck
+	check.Recent = append([]MissedCallback(nil), callbackCheck.Recent...)
+	return check
+}
+
+// CheckCallbacks reads the bans logged since the last call and counts
+// those of jails using the fail2ban-ui action for which no callback
+// arrived within the grace period. The first call only remembers the end
+// of the log, older bans are not checked. Logged bans without a callback,
+// of jails with other actions or missed ones, are added to the event store,
+// which otherwise only learns about bans from the callbacks.
+func CheckCallbacks(logPath string) error {
+	now := time.Now()
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return setCallbackError(logPath, err)
+	}
+
+	callbackLock.Lock()
+	offset, since := callbackOffset, lastCallbackScan
+	callbackLock.Unlock()
+
+	var logged []BanEvent
+	switch {
+	case offset < 0:
+		offset = info.Size()
+	case info.Size() < offset:
+		// The log was rotated, read the new file from the start.
+		offset = 0
+		fallthrough
+	default:
+		out := make(chan BanEvent, 64)
+		done := make(chan error, 1)
+		go func() {
+			done <- parseChunk(logPath, offset, info.Size(), out, func(int64) {})
+			close(out)
+		}()
+		for ev := range out {
+			logged = append(logged, ev)
+		}
+		if err := <-done; err != nil {
+			return setCallbackError(logPath, err)
+		}
+		offset = info.Size()
+	}
+
+	// Jails with other actions never send callbacks.
+	expected := make(map[string]bool)
+	for _, ev := range logged {
+		if _, ok := expected[ev.Jail]; !ok {
+			expected[ev.Jail] = jailSendsCallbacks(ev.Jail, now)
+		}
+	}
+
+	var unreported []BanEvent
+	callbackLock.Lock()
+	callbackOffset = offset
+	lastCallbackScan = now
+	for _, ev := range logged {
+		if !expected[ev.Jail] {
+			unreported = append(unreported, ev)
+			continue
+		}
+		// The ban was logged between the last scan and now; allow the
+		// callback to arrive slightly before the log line was flushed.
+		pendingBans = append(pendingBans, pendingBan{ev: ev, since: since.Add(-time.Minute), seenAt: now, checkAt: now.Add(callbackGrace)})
+	}
+
+	remaining := pendingBans[:0]
+	for _, p := range pendingBans {
+		if received, ok := callbacks[p.ev.Jail+"|"+p.ev.IP]; ok && received.After(p.since) {
+			callbackCheck.Verified++
+			continue
+		}
+		if now.Before(p.checkAt) {
+			remaining = append(remaining, p)
+			continue
+		}
+		callbackCheck.Missing++
+		unreported = append(unreported, p.ev)
+		missed := MissedCallback{Jail: p.ev.Jail, IP: p.ev.IP, LogLine: p.ev.LogLine, SeenAt: p.seenAt}
+		callbackCheck.Recent = append([]MissedCallback{missed}, callbackCheck.Recent...)
+		if len(callbackCheck.Recent) > maxMissedCallbacks {
+			callbackCheck.Recent = callbackCheck.Recent[:maxMissedCallbacks]
+		}
+	}
+	pendingBans = remaining
+
+	// Forget callbacks no pending ban can refer to anymore.
+	for key, t := range callbacks {
+		if now.Sub(t) > time.Hour {
+			delete(callbacks, key)
+		}
+	}
+
+	callbackCheck.LogPath = logPath
+	callbackCheck.CheckedAt = now
+	callbackCheck.Error = ""
+	callbackLock.Unlock()
+
+	for _, ev := range unreported {
+		store.Add(ev)
+	}
+	return nil
+}
+
+func setCallbackError(logPath string, err error) error {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	callbackCheck.LogPath = logPath
+	callbackCheck.CheckedAt = time.Now()
+	callbackCheck.Error = err.Error()
+	return fmt.Errorf("callback check: %w", err)
+}
+
+// jailSendsCallbacks reports whether the actions of jail include the
+// fail2ban-ui action. Results are cached for ten minutes.
+func jailSendsCallbacks(jail string, now time.Time) bool {
+	callbackLock.Lock()
+	entry, ok := jailActions[jail]
+	callbackLock.Unlock()
+	if ok && now.Sub(entry.at) < 10*time.Minute {
+		return entry.callback
+	}
+	actions, err := getJailParam(context.Background(), jail, "actions")
+	if err != nil {
+		// Assume the default action, so problems are not hidden.
+		return true
+	}
+	entry = jailActionsEntry{callback: strings.Contains(actions, callbackAction), at: now}
+	callbackLock.Lock()
+	jailActions[jail] = entry
+	callbackLock.Unlock()
+	return entry.callback
+}
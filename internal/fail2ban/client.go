@@ -17,11 +17,15 @@
 package fail2ban
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,18 +35,55 @@ type JailInfo struct {
 	NewInLastHour int      `json:"newInLastHour"`
 	BannedIPs     []string `json:"bannedIPs"`
 	Enabled       bool     `json:"enabled"`
+	WatchedIPs    []string `json:"watchedIPs,omitempty"`
+	Demo          bool     `json:"demo,omitempty"`
+	// Counters split by address family.
+	BannedByFamily        FamilyCounts `json:"bannedByFamily"`
+	NewInLastHourByFamily FamilyCounts `json:"newInLastHourByFamily"`
+	// Failed attempts seen by the jail's filter, reported by the daemon.
+	// CurrentlyFailed counts the IPs within findtime that are not banned yet.
+	CurrentlyFailed int `json:"currentlyFailed"`
+	TotalFailed     int `json:"totalFailed"`
 }
 
-// Get active jails using "fail2ban-client status".
-func GetJails() ([]string, error) {
-	cmd := exec.Command("fail2ban-client", "status")
-	out, err := cmd.CombinedOutput()
+// JailStatus is the parsed output of "fail2ban-client status <jail>".
+type JailStatus struct {
+	CurrentlyFailed int
+	TotalFailed     int
+	BannedIPs       []string
+}
+
+// Get active jails using "status" over the socket or with fail2ban-client.
+// Like all queries of the daemon, it is abandoned when ctx ends.
+func GetJails(ctx context.Context) ([]string, error) {
+	v, err := socketCommand(ctx, "status")
+	if err == nil {
+		jails, err := socketJailList(v)
+		if !errors.Is(err, errSocketUnavailable) {
+			return jails, err
+		}
+	} else if !errors.Is(err, errSocketUnavailable) {
+		if errors.Is(err, ErrClientBusy) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error: unable to retrieve jail information. is your fail2ban service running? details: %w", err)
+	}
+
+	out, err := runClient(ctx, "status")
+	if errors.Is(err, ErrClientBusy) {
+		return nil, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error: unable to retrieve jail information. is your fail2ban service running? details: %v", err)
+		return nil, fmt.Errorf("error: unable to retrieve jail information. is your fail2ban service running? details: %w", err)
 	}
 
+	return parseJailList(string(out)), nil
+}
+
+// parseJailList parses the jail list printed by "fail2ban-client status".
+func parseJailList(out string) []string {
 	var jails []string
-	lines := strings.Split(string(out), "\n")
+	lines := strings.Split(out, "\n")
 	for _, line := range lines {
 		if strings.Contains(line, "Jail list:") {
 			parts := strings.Split(line, ":")
@@ -55,115 +96,331 @@ func GetJails() ([]string, error) {
 			}
 		}
 	}
-	return jails, nil
+	return jails
 }
 
 // GetBannedIPs returns a slice of currently banned IPs for a specific jail.
-func GetBannedIPs(jail string) ([]string, error) {
-	cmd := exec.Command("fail2ban-client", "status", jail)
-	out, err := cmd.CombinedOutput()
+func GetBannedIPs(ctx context.Context, jail string) ([]string, error) {
+	status, err := GetJailStatus(ctx, jail)
 	if err != nil {
-		return nil, fmt.Errorf("fail2ban-client status %s failed: %v", jail, err)
+		return nil, err
 	}
+	return status.BannedIPs, nil
+}
 
-	var bannedIPs []string
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "IP list:") {
-			parts := strings.Split(line, ":")
-			if len(parts) > 1 {
-				ips := strings.Fields(strings.TrimSpace(parts[1]))
-				bannedIPs = append(bannedIPs, ips...)
-			}
-			break
+// GetJailStatus returns the failure and ban counters and the banned IPs of a jail.
+func GetJailStatus(ctx context.Context, jail string) (JailStatus, error) {
+	if err := ValidateJailName(jail); err != nil {
+		return JailStatus{}, err
+	}
+	v, err := socketCommand(ctx, "status", jail)
+	if err == nil {
+		status, err := socketJailStatus(v)
+		if !errors.Is(err, errSocketUnavailable) {
+			return status, err
+		}
+	} else if !errors.Is(err, errSocketUnavailable) {
+		return JailStatus{}, fmt.Errorf("fail2ban status %s failed: %w", jail, err)
+	}
+
+	out, err := runClient(ctx, "status", jail)
+	if err != nil {
+		return JailStatus{}, fmt.Errorf("fail2ban-client status %s failed: %w", jail, err)
+	}
+	return parseJailStatus(string(out)), nil
+}
+
+// parseJailStatus parses the tree printed by "fail2ban-client status <jail>":
+//
+//	|- Filter
+//	|  |- Currently failed:	2
+//	|  |- Total failed:	17
+//	`- Actions
+//	   |- Currently banned:	1
+//	   |- Total banned:	4
+//	   `- Banned IP list:	192.0.2.10
+func parseJailStatus(out string) JailStatus {
+	var status JailStatus
+	for _, line := range strings.Split(out, "\n") {
+		// Split at the first colon only, IPv6 addresses contain colons.
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		label = strings.TrimLeft(label, "|`- ")
+		value = strings.TrimSpace(value)
+		switch label {
+		case "Currently failed":
+			status.CurrentlyFailed, _ = strconv.Atoi(value)
+		case "Total failed":
+			status.TotalFailed, _ = strconv.Atoi(value)
+		case "Banned IP list":
+			status.BannedIPs = append(status.BannedIPs, strings.Fields(value)...)
 		}
 	}
-	return bannedIPs, nil
+	return status
+}
+
+// BanIP bans an IP in the given jail.
+func BanIP(ctx context.Context, jail, ip string) error {
+	if err := ValidateJailName(jail); err != nil {
+		return err
+	}
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	out, err := clientCommand(ctx, "set", jail, "banip", ip)
+	if err != nil {
+		return fmt.Errorf("error banning IP %s in jail %s: %w%s", ip, jail, err, outputSuffix(out))
+	}
+	markChanged()
+	return nil
 }
 
 // UnbanIP unbans an IP from the given jail.
-func UnbanIP(jail, ip string) error {
-	// We assume "fail2ban-client set <jail> unbanip <ip>" works.
-	cmd := exec.Command("fail2ban-client", "set", jail, "unbanip", ip)
-	out, err := cmd.CombinedOutput()
+func UnbanIP(ctx context.Context, jail, ip string) error {
+	if err := ValidateJailName(jail); err != nil {
+		return err
+	}
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	out, err := clientCommand(ctx, "set", jail, "unbanip", ip)
+	if err != nil {
+		return fmt.Errorf("error unbanning IP %s from jail %s: %w%s", ip, jail, err, outputSuffix(out))
+	}
+	markChanged()
+	runUnbanHooks(jail, ip)
+	return nil
+}
+
+// BanIPs bans several IPs in the given jail with a single command.
+func BanIPs(ctx context.Context, jail string, ips []string) error {
+	return setIPs(ctx, jail, "banip", ips)
+}
+
+// UnbanIPs unbans several IPs from the given jail with a single command.
+func UnbanIPs(ctx context.Context, jail string, ips []string) error {
+	if err := setIPs(ctx, jail, "unbanip", ips); err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		runUnbanHooks(jail, ip)
+	}
+	return nil
+}
+
+// setIPs runs "set <jail> banip|unbanip <ip>...".
+func setIPs(ctx context.Context, jail, action string, ips []string) error {
+	if err := ValidateJailName(jail); err != nil {
+		return err
+	}
+	args := []string{"set", jail, action}
+	for _, ip := range ips {
+		ip, err := NormalizeIP(ip)
+		if err != nil {
+			return err
+		}
+		args = append(args, ip)
+	}
+	out, err := clientCommand(ctx, args...)
 	if err != nil {
-		return fmt.Errorf("error unbanning IP %s from jail %s: %v\nOutput: %s", ip, jail, err, out)
+		return fmt.Errorf("error running %s of %d IPs in jail %s: %w%s", action, len(ips), jail, err, outputSuffix(out))
 	}
+	markChanged()
+	return nil
+}
+
+// UnbanIPAll unbans an IP from all jails.
+func UnbanIPAll(ctx context.Context, ip string) error {
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	out, err := clientCommand(ctx, "unban", ip)
+	if err != nil {
+		return fmt.Errorf("error unbanning IP %s: %w%s", ip, err, outputSuffix(out))
+	}
+	markChanged()
+	runUnbanHooks("", ip)
+	return nil
+}
+
+// outputSuffix formats the output of fail2ban-client for error messages.
+// Commands run over the socket have none.
+func outputSuffix(out []byte) string {
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return ""
+	}
+	return "\nOutput: " + string(out)
+}
+
+// AddIgnoreIP adds an IP to the ignore list of a running jail. The change
+// is not persisted and is lost on the next reload.
+func AddIgnoreIP(ctx context.Context, jail, ip string) error {
+	return setIgnoreIP(ctx, jail, "addignoreip", ip)
+}
+
+// DelIgnoreIP removes an IP added with AddIgnoreIP from a running jail.
+func DelIgnoreIP(ctx context.Context, jail, ip string) error {
+	return setIgnoreIP(ctx, jail, "delignoreip", ip)
+}
+
+func setIgnoreIP(ctx context.Context, jail, action, ip string) error {
+	if err := ValidateJailName(jail); err != nil {
+		return err
+	}
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	out, err := clientCommand(ctx, "set", jail, action, ip)
+	if err != nil {
+		return fmt.Errorf("error running %s %s in jail %s: %w%s", action, ip, jail, err, outputSuffix(out))
+	}
+	markChanged()
 	return nil
 }
 
 // BuildJailInfos returns extended info for each jail:
 // - total banned count
 // - new banned in the last hour
+// - currently and total failed attempts
+// - both counters split by IPv4 and IPv6
 // - list of currently banned IPs
-func BuildJailInfos(logPath string) ([]JailInfo, error) {
-	jails, err := GetJails()
+//
+// Ban history is taken from the in-memory event store, so the log is not
+// re-read on every call. While the backfill is still running the
+// "newInLastHour" counters may be incomplete. The jails are queried in
+// parallel, see GetAllJailStatus.
+func BuildJailInfos(ctx context.Context) ([]JailInfo, error) {
+	snap, err := GetAllJailStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the log once, so we can determine "newInLastHour" per jail
-	// for performance reasons. We'll gather all ban timestamps by jail.
-	banHistory, err := ParseBanLog(logPath)
-	if err != nil {
-		// If fail2ban.log can't be read, we can still show partial info.
-		banHistory = make(map[string][]BanEvent)
-	}
-
 	oneHourAgo := time.Now().Add(-1 * time.Hour)
 
 	var results []JailInfo
-	for _, jail := range jails {
-		bannedIPs, err := GetBannedIPs(jail)
-		if err != nil {
+	for _, status := range snap.Jails {
+		if status.Error != "" {
 			// Just skip or handle error per jail
 			continue
 		}
 
-		// Count how many bans occurred in the last hour for this jail
-		newInLastHour := 0
-		if events, ok := banHistory[jail]; ok {
-			for _, e := range events {
-				if e.Time.After(oneHourAgo) {
-					newInLastHour++
-				}
-			}
-		}
-
+		recent, recentByFamily := store.CountSince(status.Jail, oneHourAgo)
 		jinfo := JailInfo{
-			JailName:      jail,
-			TotalBanned:   len(bannedIPs),
-			NewInLastHour: newInLastHour,
-			BannedIPs:     bannedIPs,
+			JailName:              status.Jail,
+			TotalBanned:           status.CurrentlyBanned,
+			NewInLastHour:         recent,
+			BannedIPs:             status.BannedIPs,
+			BannedByFamily:        status.BannedByFamily,
+			NewInLastHourByFamily: recentByFamily,
+			CurrentlyFailed:       status.CurrentlyFailed,
+			TotalFailed:           status.TotalFailed,
 		}
 		results = append(results, jinfo)
 	}
 	return results, nil
 }
 
+var (
+	reloadHooks     []func()
+	reloadHooksLock sync.RWMutex
+
+	unbanHooks     []func(jail, ip string)
+	unbanHooksLock sync.RWMutex
+)
+
+// OnUnban registers fn to be called after every successful unban through
+// UnbanIP or UnbanIPAll; jail is empty for unbans from all jails.
+func OnUnban(fn func(jail, ip string)) {
+	unbanHooksLock.Lock()
+	defer unbanHooksLock.Unlock()
+	unbanHooks = append(unbanHooks, fn)
+}
+
+func runUnbanHooks(jail, ip string) {
+	unbanHooksLock.RLock()
+	hooks := unbanHooks
+	unbanHooksLock.RUnlock()
+	for _, fn := range hooks {
+		fn(jail, ip)
+	}
+}
+
+// OnReload registers fn to be called after every successful reload or
+// restart of fail2ban, e.g. to restore runtime changes that are lost.
+func OnReload(fn func()) {
+	reloadHooksLock.Lock()
+	defer reloadHooksLock.Unlock()
+	reloadHooks = append(reloadHooks, fn)
+}
+
+// changedAt holds the time (unix nanoseconds) of the last change made to
+// the running daemon through this package.
+var changedAt atomic.Int64
+
+// ChangedAt returns when the UI last banned, unbanned, reloaded or otherwise
+// changed the running daemon. Cached daemon state older than this is stale.
+func ChangedAt() time.Time {
+	return time.Unix(0, changedAt.Load())
+}
+
+func markChanged() {
+	changedAt.Store(time.Now().UnixNano())
+}
+
+func runReloadHooks() {
+	markChanged()
+	reloadHooksLock.RLock()
+	hooks := reloadHooks
+	reloadHooksLock.RUnlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
 // ReloadFail2ban runs "fail2ban-client reload"
 func ReloadFail2ban() error {
-	cmd := exec.Command("fail2ban-client", "reload")
-	out, err := cmd.CombinedOutput()
+	_, err := reloadFail2ban()
+	return err
+}
+
+// reloadFail2ban runs "fail2ban-client reload" and returns its output.
+// Unlike queries, it is not bound to a request: abandoning it halfway
+// would leave the jails partly loaded.
+func reloadFail2ban() (string, error) {
+	out, err := runClient(context.Background(), "reload")
 	if err != nil {
-		return fmt.Errorf("fail2ban reload error: %v\noutput: %s", err, out)
+		return string(out), fmt.Errorf("fail2ban reload error: %w\noutput: %s", err, out)
 	}
-	return nil
+	runReloadHooks()
+	return string(out), nil
 }
 
 // RestartFail2ban restarts the Fail2ban service.
 func RestartFail2ban() error {
+	_, err := restartFail2ban()
+	return err
+}
+
+// restartFail2ban restarts the Fail2ban service and returns the command output.
+func restartFail2ban() (string, error) {
 
 	// Check if running inside a container.
 	if _, container := os.LookupEnv("CONTAINER"); container {
-		return fmt.Errorf("restart not supported inside container; please restart fail2ban on the host")
+		return "", fmt.Errorf("restart not supported inside container; please restart fail2ban on the host")
 	}
 	cmd := "systemctl restart fail2ban"
 	out, err := execCommand(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to restart fail2ban: %w - output: %s", err, out)
+		return out, fmt.Errorf("failed to restart fail2ban: %w - output: %s", err, out)
 	}
-	return nil
+	runReloadHooks()
+	return out, nil
 }
 
 // execCommand is a helper function to execute shell commands.
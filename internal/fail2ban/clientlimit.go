@@ -0,0 +1,140 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// ErrClientBusy is returned when too many fail2ban-client calls are
+// already waiting for a free slot, see config.LimitSettings.
+var ErrClientBusy = errors.New("fail2ban is busy, too many requests are waiting; please retry")
+
+// clientLimiter bounds the number of concurrent fail2ban-client processes,
+// so that many simultaneous dashboard requests do not slow down the daemon
+// itself. The limits are read from the settings on every call.
+type clientLimiter struct {
+	mu      sync.Mutex
+	active  int
+	waiting int
+	freed   chan struct{} // closed and replaced whenever a slot is released
+
+	rejected atomic.Int64
+}
+
+var limiter = &clientLimiter{freed: make(chan struct{})}
+
+// ClientStats reports the usage of the fail2ban-client slots.
+type ClientStats struct {
+	Active   int   `json:"active"`
+	Waiting  int   `json:"waiting"`
+	Rejected int64 `json:"rejected"`
+	Parallel int   `json:"parallel"`
+}
+
+// GetClientStats returns the current usage of the fail2ban-client slots.
+func GetClientStats() ClientStats {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return ClientStats{
+		Active:   limiter.active,
+		Waiting:  limiter.waiting,
+		Rejected: limiter.rejected.Load(),
+		Parallel: config.GetSettings().Server.Limits.Parallel(),
+	}
+}
+
+// acquire waits for a free slot. It fails with ErrClientBusy when the
+// queue is full and with the context error when ctx ends first.
+func (l *clientLimiter) acquire(ctx context.Context) error {
+	limits := config.GetSettings().Server.Limits
+	l.mu.Lock()
+	if l.active < limits.Parallel() {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.waiting >= limits.Queue() {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return ErrClientBusy
+	}
+	l.waiting++
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(limits.Wait())
+	defer timer.Stop()
+	for {
+		freed := l.freed
+		l.mu.Unlock()
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			l.rejected.Add(1)
+			return ErrClientBusy
+		}
+		l.mu.Lock()
+		if l.active < limits.Parallel() {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+	}
+}
+
+func (l *clientLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+// runClient runs fail2ban-client with args once a slot is free and returns
+// its combined output. Failures to reach the daemon are returned as
+// *StateError and update the backend state.
+func runClient(ctx context.Context, args ...string) ([]byte, error) {
+	if err := limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer limiter.release()
+	out, err := exec.CommandContext(ctx, "fail2ban-client", args...).CombinedOutput()
+	switch {
+	case ctx.Err() != nil:
+	case err != nil && unreachableOutput(out, err):
+		setCondition(StateUnreachable, fmt.Errorf("fail2ban-client %s: %w%s", strings.Join(args, " "), err, outputSuffix(out)))
+		err = &StateError{State: StateUnreachable, Err: err}
+	default:
+		setCondition(StateUnreachable, nil)
+	}
+	return out, err
+}
@@ -0,0 +1,188 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// ConfigRoot is the directory browsable with ListConfigFiles and ReadConfigFile.
+var ConfigRoot = "/etc/fail2ban"
+
+const (
+	// maxConfigFileSize is the largest file returned by ReadConfigFile.
+	maxConfigFileSize = 1 << 20
+	// maxConfigFiles bounds the listing of ConfigRoot.
+	maxConfigFiles = 5000
+)
+
+// ConfigFile describes a file below ConfigRoot.
+type ConfigFile struct {
+	Path    string    `json:"path"` // relative to ConfigRoot
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Managed bool      `json:"managed"` // written by fail2ban-ui
+	Symlink bool      `json:"symlink,omitempty"`
+}
+
+// ConfigSection is a "[section]" header of an INI style file.
+type ConfigSection struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// ConfigFileContent is a file with data for syntax highlighting.
+type ConfigFileContent struct {
+	ConfigFile
+	Language string          `json:"language"` // "ini" or "text"
+	Sections []ConfigSection `json:"sections"`
+	Content  string          `json:"content"`
+}
+
+// ListConfigFiles returns all files below ConfigRoot, sorted by path.
+// Symlinked directories are not followed.
+func ListConfigFiles() ([]ConfigFile, error) {
+	managed := managedConfigFiles()
+	root, err := filepath.EvalSymlinks(ConfigRoot)
+	if err != nil {
+		return nil, err
+	}
+	files := []ConfigFile{}
+	err = filepath.WalkDir(ConfigRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == ConfigRoot {
+				return err
+			}
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(files) >= maxConfigFiles {
+			return fs.SkipAll
+		}
+		symlink := d.Type()&fs.ModeSymlink != 0
+		if symlink {
+			// Only list links that ReadConfigFile would follow.
+			if target, err := filepath.EvalSymlinks(path); err != nil || !strings.HasPrefix(target, root+string(filepath.Separator)) {
+				return nil
+			}
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(ConfigRoot, path)
+		files = append(files, ConfigFile{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Managed: managed[filepath.Clean(path)],
+			Symlink: symlink,
+		})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, err
+}
+
+// ReadConfigFile returns the file at rel, a path relative to ConfigRoot.
+// Paths leaving ConfigRoot, also through symlinks, are rejected.
+func ReadConfigFile(rel string) (ConfigFileContent, error) {
+	invalid := &ValidationError{Field: "path", Value: rel, Hint: "use a path relative to " + ConfigRoot}
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "/")
+	if rel == "" || strings.ContainsRune(rel, 0) {
+		return ConfigFileContent{}, invalid
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if part == ".." {
+			return ConfigFileContent{}, invalid
+		}
+	}
+
+	root, err := filepath.EvalSymlinks(ConfigRoot)
+	if err != nil {
+		return ConfigFileContent{}, err
+	}
+	path := filepath.Join(ConfigRoot, filepath.FromSlash(rel))
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ConfigFileContent{}, err
+	}
+	if !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return ConfigFileContent{}, &ValidationError{Field: "path", Value: rel, Hint: "the file is outside of " + ConfigRoot}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return ConfigFileContent{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return ConfigFileContent{}, &ValidationError{Field: "path", Value: rel, Hint: "not a regular file"}
+	}
+	if info.Size() > maxConfigFileSize {
+		return ConfigFileContent{}, fmt.Errorf("%s is larger than %d bytes", rel, maxConfigFileSize)
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return ConfigFileContent{}, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return ConfigFileContent{}, &ValidationError{Field: "path", Value: rel, Hint: "binary files are not shown"}
+	}
+
+	content := ConfigFileContent{
+		ConfigFile: ConfigFile{
+			Path:    filepath.ToSlash(filepath.Clean(rel)),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Managed: managedConfigFiles()[filepath.Clean(path)],
+			Symlink: resolved != filepath.Join(root, filepath.FromSlash(rel)),
+		},
+		Language: "text",
+		Sections: []ConfigSection{},
+		Content:  string(data),
+	}
+	switch filepath.Ext(rel) {
+	case ".conf", ".local":
+		content.Language = "ini"
+		for i, line := range strings.Split(content.Content, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				content.Sections = append(content.Sections, ConfigSection{Name: strings.Trim(line, "[]"), Line: i + 1})
+			}
+		}
+	}
+	return content, nil
+}
+
+func managedConfigFiles() map[string]bool {
+	managed := make(map[string]bool)
+	for _, f := range config.ManagedFiles() {
+		managed[filepath.Clean(f)] = true
+	}
+	return managed
+}
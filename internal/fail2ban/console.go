@@ -0,0 +1,119 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	consoleTimeout   = 30 * time.Second
+	maxConsoleOutput = 64 * 1024
+)
+
+// consoleJailParams are the options "get <jail> <option>" may read.
+var consoleJailParams = map[string]bool{
+	"banned": true, "banip": true, "bantime": true, "findtime": true, "maxretry": true,
+	"maxlines": true, "maxmatches": true, "logpath": true, "logencoding": true,
+	"journalmatch": true, "ignoreip": true, "ignoreself": true, "ignorecommand": true,
+	"failregex": true, "ignoreregex": true, "actions": true, "usedns": true,
+	"datepattern": true, "prefregex": true, "bantime.increment": true,
+}
+
+// consoleGlobalParams are the options "get <option>" may read.
+var consoleGlobalParams = map[string]bool{
+	"loglevel": true, "logtarget": true, "syslogsocket": true, "dbfile": true, "dbmaxmatches": true, "dbpurgeage": true,
+}
+
+// ParseConsoleCommand checks a fail2ban-client command line against the
+// safelist of the web console and returns its arguments. Allowed are:
+//
+//	ping | version | status [<jail>] | banned [<ip>]
+//	get <option> | get <jail> <option>
+//	set <jail> banip <ip>... | set <jail> unbanip <ip>...
+func ParseConsoleCommand(line string) ([]string, error) {
+	args := strings.Fields(line)
+	if len(args) > 0 && args[0] == "fail2ban-client" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, &ValidationError{Field: "command", Value: line, Hint: "enter a fail2ban-client command, e.g. status sshd"}
+	}
+	denied := &ValidationError{Field: "command", Value: line,
+		Hint: "allowed are: ping, version, status [jail], banned [ip], get [jail] <option>, set <jail> banip|unbanip <ip>..."}
+
+	switch cmd := args[0]; {
+	case (cmd == "ping" || cmd == "version") && len(args) == 1:
+		return args, nil
+	case cmd == "status" && len(args) <= 2:
+		if len(args) == 2 {
+			if err := ValidateJailName(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		return args, nil
+	case cmd == "banned" && len(args) <= 2:
+		if len(args) == 2 {
+			ip, err := NormalizeIP(args[1])
+			if err != nil {
+				return nil, err
+			}
+			args[1] = ip
+		}
+		return args, nil
+	case cmd == "get" && len(args) == 2 && consoleGlobalParams[args[1]]:
+		return args, nil
+	case cmd == "get" && len(args) == 3 && consoleJailParams[args[2]]:
+		if err := ValidateJailName(args[1]); err != nil {
+			return nil, err
+		}
+		return args, nil
+	case cmd == "set" && len(args) >= 4 && (args[2] == "banip" || args[2] == "unbanip"):
+		if err := ValidateJailName(args[1]); err != nil {
+			return nil, err
+		}
+		for i := 3; i < len(args); i++ {
+			ip, err := NormalizeIP(args[i])
+			if err != nil {
+				return nil, err
+			}
+			args[i] = ip
+		}
+		return args, nil
+	}
+	return nil, denied
+}
+
+// RunConsoleCommand runs fail2ban-client with arguments returned by
+// ParseConsoleCommand and returns its combined output, truncated to 64KB.
+func RunConsoleCommand(ctx context.Context, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+	out, err := runClient(ctx, args...)
+	if len(out) > maxConsoleOutput {
+		out = append(out[:maxConsoleOutput], "\n[output truncated]"...)
+	}
+	// The command may have changed bans or jails.
+	markChanged()
+	if err != nil {
+		return string(out), fmt.Errorf("fail2ban-client %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
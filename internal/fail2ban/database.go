@@ -0,0 +1,233 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// DBInfo describes fail2ban's own sqlite database, which keeps the bans
+// across restarts. It grows with every ban until fail2ban purges the bans
+// older than dbpurgeage, but the file itself only shrinks by a vacuum.
+type DBInfo struct {
+	Path            string    `json:"path"` // empty if the database is disabled
+	SizeBytes       int64     `json:"sizeBytes"`
+	PurgeAgeSeconds int64     `json:"purgeAgeSeconds"`
+	Bans            int       `json:"bans"`            // rows in the bans table, -1 if unknown
+	VacuumAvailable bool      `json:"vacuumAvailable"` // sqlite3 is installed
+	Running         bool      `json:"running"`         // a maintenance is in progress
+	CheckedAt       time.Time `json:"checkedAt"`
+}
+
+// DBMaintenanceReport describes a purge and vacuum of the database.
+type DBMaintenanceReport struct {
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+	BansBefore int    `json:"bansBefore"`
+	BansAfter  int    `json:"bansAfter"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ErrMaintenanceRunning is returned while a database maintenance is in progress.
+var ErrMaintenanceRunning = errors.New("a database maintenance is already running")
+
+var dbMaintenance sync.Mutex
+
+// GetDBInfo asks fail2ban for its database file and purge age and
+// reads the size of the file.
+func GetDBInfo(ctx context.Context) (DBInfo, error) {
+	info := DBInfo{Bans: -1, CheckedAt: time.Now()}
+	if dbMaintenance.TryLock() {
+		dbMaintenance.Unlock()
+	} else {
+		info.Running = true
+		return info, nil
+	}
+	path, err := getServerOption(ctx, "dbfile")
+	if err != nil {
+		return info, err
+	}
+	if path == "" || path == "None" || path == ":memory:" || strings.Contains(path, "disabled") {
+		return info, nil
+	}
+	info.Path = path
+	if age, err := getServerOption(ctx, "dbpurgeage"); err == nil {
+		info.PurgeAgeSeconds = leadingInt(age)
+	}
+	if st, err := os.Stat(path); err == nil {
+		info.SizeBytes = st.Size()
+	}
+	if _, err := exec.LookPath("sqlite3"); err == nil {
+		info.VacuumAvailable = true
+		info.Bans = countBans(ctx, path)
+	}
+	return info, nil
+}
+
+// SetDBPurgeAge changes how long fail2ban keeps bans in its database,
+// right away and persistently in fail2ban.local.
+func SetDBPurgeAge(seconds int64) error {
+	if seconds < 3600 {
+		return fmt.Errorf("the purge age must be at least one hour")
+	}
+	value := strconv.FormatInt(seconds, 10)
+	if out, err := clientCommand(context.Background(), "set", "dbpurgeage", value); err != nil {
+		return fmt.Errorf("error setting dbpurgeage: %w%s", err, outputSuffix(out))
+	}
+	return config.UpdateFail2banLocal(map[string]string{"dbpurgeage": value})
+}
+
+// MaintainDB stops fail2ban, deletes the bans older than the purge age
+// like fail2ban's own purge, vacuums the database and starts fail2ban
+// again. fail2ban is started again even if the vacuum fails, and the
+// bans still in the database are restored by it.
+func MaintainDB() (DBMaintenanceReport, error) {
+	var report DBMaintenanceReport
+	if _, container := os.LookupEnv("CONTAINER"); container {
+		return report, fmt.Errorf("maintenance not supported inside container; please run it on the host")
+	}
+	info, err := GetDBInfo(context.Background())
+	if err != nil {
+		return report, err
+	}
+	if !dbMaintenance.TryLock() {
+		return report, ErrMaintenanceRunning
+	}
+	defer dbMaintenance.Unlock()
+	if info.Path == "" {
+		return report, fmt.Errorf("fail2ban runs without a database")
+	}
+	if !info.VacuumAvailable {
+		return report, fmt.Errorf("sqlite3 is not installed")
+	}
+	report.SizeBefore, report.BansBefore = info.SizeBytes, info.Bans
+
+	start := time.Now()
+	if out, err := execCommand("systemctl stop fail2ban"); err != nil {
+		return report, fmt.Errorf("failed to stop fail2ban: %w - output: %s", err, out)
+	}
+	vacuumErr := vacuumDB(info.Path, info.PurgeAgeSeconds)
+	out, err := execCommand("systemctl start fail2ban")
+	if err != nil {
+		err = fmt.Errorf("failed to start fail2ban: %w - output: %s", err, out)
+		log.Printf("❌ %v", err)
+		return report, err
+	}
+	waitForFail2ban(restartSettleTimeout)
+	runReloadHooks()
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if st, err := os.Stat(info.Path); err == nil {
+		report.SizeAfter = st.Size()
+	}
+	report.BansAfter = countBans(context.Background(), info.Path)
+	if vacuumErr != nil {
+		report.Error = vacuumErr.Error()
+	}
+	return report, nil
+}
+
+// vacuumDB purges and vacuums the database of the stopped fail2ban.
+func vacuumDB(path string, purgeAge int64) error {
+	tables := map[string]bool{}
+	out, err := exec.Command("sqlite3", path, "SELECT name FROM sqlite_master WHERE type = 'table'").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error reading the database: %w - output: %s", err, out)
+	}
+	for _, t := range strings.Fields(string(out)) {
+		tables[t] = true
+	}
+
+	// The statements of Fail2BanDb.purge, bans without an end (bantime -1) are kept.
+	cutoff := time.Now().Unix() - purgeAge
+	var sql []string
+	if purgeAge > 0 {
+		if tables["bans"] {
+			sql = append(sql, fmt.Sprintf("DELETE FROM bans WHERE timeofban < %d AND bantime != -1;", cutoff))
+		}
+		if tables["bips"] {
+			sql = append(sql, fmt.Sprintf("DELETE FROM bips WHERE timeofban < %d AND bantime != -1;", cutoff))
+		}
+		if tables["jails"] && tables["bans"] {
+			sql = append(sql, "DELETE FROM jails WHERE enabled = 0 AND NOT EXISTS (SELECT * FROM bans WHERE jail = jails.name);")
+		}
+	}
+	sql = append(sql, "VACUUM;")
+	out, err = exec.Command("sqlite3", "-bail", path, strings.Join(sql, " ")).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error vacuuming the database: %w - output: %s", err, out)
+	}
+	return nil
+}
+
+// countBans returns the rows of the bans table, or -1.
+func countBans(ctx context.Context, path string) int {
+	out, err := exec.CommandContext(ctx, "sqlite3", "-readonly", path, "SELECT COUNT(*) FROM bans").Output()
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// getServerOption returns a "get" value of the fail2ban server, e.g.
+// "dbfile", from the socket or the last line of fail2ban-client.
+func getServerOption(ctx context.Context, name string) (string, error) {
+	v, err := socketCommand(ctx, "get", name)
+	if err == nil {
+		if v == nil {
+			return "", nil
+		}
+		s, _ := socketString(v)
+		return s, nil
+	}
+	if !errors.Is(err, errSocketUnavailable) {
+		return "", err
+	}
+	out, err := runClient(ctx, "get", name)
+	if err != nil {
+		return "", fmt.Errorf("error getting %s: %w%s", name, err, outputSuffix(out))
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	return strings.TrimSpace(strings.TrimLeft(last, "`|-")), nil
+}
+
+// leadingInt parses the number at the start of s, e.g. "86400seconds".
+func leadingInt(s string) int64 {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.ParseInt(s[:end], 10, 64)
+	return n
+}
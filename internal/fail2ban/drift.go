@@ -0,0 +1,232 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DriftOptions are the jail options written by the settings and profiles,
+// compared between the configuration files and the running daemon.
+var DriftOptions = []string{"bantime", "findtime", "maxretry", "ignoreip"}
+
+// DriftItem is an option whose running value differs from the configuration.
+type DriftItem struct {
+	Jail       string `json:"jail"`
+	Option     string `json:"option"`
+	Configured string `json:"configured"`
+	Running    string `json:"running"`
+}
+
+// DriftReport compares the configured jail options with the running daemon.
+// Drift means the configuration was changed but not yet reloaded, or a
+// reload did not take effect.
+type DriftReport struct {
+	CheckedAt time.Time   `json:"checkedAt"`
+	InSync    bool        `json:"inSync"`
+	Jails     int         `json:"jails"`
+	Drift     []DriftItem `json:"drift"`
+	Errors    []string    `json:"errors,omitempty"`
+}
+
+var (
+	lastDrift     DriftReport
+	lastDriftLock sync.RWMutex
+)
+
+// LastDrift returns the result of the last CheckDrift, zero before the first.
+func LastDrift() DriftReport {
+	lastDriftLock.RLock()
+	defer lastDriftLock.RUnlock()
+	return lastDrift
+}
+
+// CheckDrift reads the DriftOptions of every running jail with
+// "fail2ban-client get" and compares them with the values resolved from
+// jail.conf, jail.local and jail.d, the jail's section before [DEFAULT].
+// Values that cannot be compared, such as interpolations, are skipped.
+func CheckDrift(ctx context.Context) (DriftReport, error) {
+	report := DriftReport{CheckedAt: time.Now(), Drift: []DriftItem{}}
+	jails, err := GetJails(ctx)
+	if err != nil {
+		return report, err
+	}
+	configured := readJailOptions(DriftOptions)
+	for _, jail := range jails {
+		if jail == "" {
+			continue
+		}
+		report.Jails++
+		for _, option := range DriftOptions {
+			want, ok := configured[jail][option]
+			if !ok {
+				want, ok = configured["DEFAULT"][option]
+			}
+			if !ok {
+				continue
+			}
+			running, err := getJailParam(ctx, jail, option)
+			if ctx.Err() != nil {
+				// A partial report would hide drift until the next check.
+				return report, ctx.Err()
+			}
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s %s: %v", jail, option, err))
+				continue
+			}
+			if same, comparable := sameOptionValue(option, want, running); comparable && !same {
+				report.Drift = append(report.Drift, DriftItem{Jail: jail, Option: option, Configured: want, Running: running})
+			}
+		}
+	}
+	report.InSync = len(report.Drift) == 0
+
+	lastDriftLock.Lock()
+	lastDrift = report
+	lastDriftLock.Unlock()
+	return report, nil
+}
+
+// sameOptionValue compares a configured value with the one reported by the
+// daemon. comparable is false if the configured value cannot be resolved.
+func sameOptionValue(option, configured, running string) (same, comparable bool) {
+	if strings.Contains(configured, "%(") {
+		return false, false
+	}
+	switch option {
+	case "bantime", "findtime":
+		want, ok := parseTimeValue(configured)
+		if !ok {
+			return false, false
+		}
+		got, ok := parseTimeValue(running)
+		return ok && want == got, ok
+	case "ignoreip":
+		return slices.Equal(addressList(configured), addressList(running)), true
+	default:
+		return strings.TrimSpace(configured) == strings.TrimSpace(running), true
+	}
+}
+
+var timeValueRegex = regexp.MustCompile(`(?i)^\s*(-?\d+(?:\.\d+)?)\s*(seconds?|secs?|s|months?|mo|minutes?|mins?|m|hours?|h|days?|d|weeks?|w|years?|y)?\s*`)
+
+var timeUnits = map[string]int64{
+	"": 1, "s": 1, "sec": 1, "secs": 1, "second": 1, "seconds": 1,
+	"m": 60, "min": 60, "mins": 60, "minute": 60, "minutes": 60,
+	"h": 3600, "hour": 3600, "hours": 3600,
+	"d": 86400, "day": 86400, "days": 86400,
+	"w": 7 * 86400, "week": 7 * 86400, "weeks": 7 * 86400,
+	"mo": 2629800, "month": 2629800, "months": 2629800,
+	"y": 31557600, "year": 31557600, "years": 31557600,
+}
+
+// parseTimeValue parses fail2ban's time abbreviations such as "600",
+// "10m" or "1h 30m" into seconds.
+func parseTimeValue(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	var total float64
+	for s != "" {
+		m := timeValueRegex.FindStringSubmatch(s)
+		if m == nil {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += n * float64(timeUnits[strings.ToLower(m[2])])
+		s = s[len(m[0]):]
+	}
+	return int64(total), true
+}
+
+// addressList splits an ignoreip value into its entries, with networks in
+// their canonical form since the daemon reports 127.0.0.1/8 as 127.0.0.0/8.
+func addressList(value string) []string {
+	var list []string
+	for _, entry := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			entry = network.String()
+		} else if ip := net.ParseIP(entry); ip != nil {
+			entry = ip.String()
+		}
+		list = append(list, entry)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// readJailOptions collects the given options of every section over all
+// jail configuration files. Later files override earlier ones.
+func readJailOptions(options []string) map[string]map[string]string {
+	wanted := make(map[string]bool, len(options))
+	for _, o := range options {
+		wanted[o] = true
+	}
+	values := make(map[string]map[string]string)
+	for _, path := range jailConfigFiles() {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		var section, lastKey string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			raw := scanner.Text()
+			line := strings.TrimSpace(raw)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section, lastKey = strings.TrimSpace(strings.Trim(line, "[]")), ""
+				continue
+			}
+			// Indented lines continue the previous value, e.g. of ignoreip.
+			if lastKey != "" && (raw[0] == ' ' || raw[0] == '\t') {
+				values[section][lastKey] += " " + line
+				continue
+			}
+			key, value, found := strings.Cut(line, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			lastKey = ""
+			if !found || section == "" || !wanted[key] {
+				continue
+			}
+			if values[section] == nil {
+				values[section] = make(map[string]string)
+			}
+			values[section][key] = strings.TrimSpace(value)
+			lastKey = key
+		}
+		file.Close()
+	}
+	return values
+}
@@ -0,0 +1,179 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EffectiveParams are the jail parameters queried from the running daemon.
+var EffectiveParams = []string{"bantime", "findtime", "maxretry", "ignoreip", "ignoreself", "usedns", "logpath", "actions"}
+
+// EffectiveConfig holds the values fail2ban actually uses after all overrides.
+// Jails are read from the running daemon, DEFAULT is resolved from the
+// configuration files since the daemon has no DEFAULT section at runtime.
+type EffectiveConfig struct {
+	Default map[string]string            `json:"default"`
+	Jails   map[string]map[string]string `json:"jails"`
+	Errors  []string                     `json:"errors,omitempty"`
+}
+
+// GetEffectiveConfig queries all running jails, or only the given jail if not empty.
+func GetEffectiveConfig(ctx context.Context, onlyJail string) (EffectiveConfig, error) {
+	if onlyJail != "" {
+		if err := ValidateJailName(onlyJail); err != nil {
+			return EffectiveConfig{}, err
+		}
+	}
+	cfg := EffectiveConfig{
+		Default: make(map[string]string),
+		Jails:   make(map[string]map[string]string),
+	}
+
+	defaults, err := readDefaultOptions()
+	if err != nil {
+		cfg.Errors = append(cfg.Errors, fmt.Sprintf("DEFAULT: %v", err))
+	}
+	for _, param := range EffectiveParams {
+		if v, ok := defaults[param]; ok {
+			cfg.Default[param] = v
+		}
+	}
+
+	jails := []string{onlyJail}
+	if onlyJail == "" {
+		if jails, err = GetJails(ctx); err != nil {
+			return cfg, err
+		}
+	}
+	for _, jail := range jails {
+		if jail == "" {
+			continue
+		}
+		values := make(map[string]string, len(EffectiveParams))
+		for _, param := range EffectiveParams {
+			v, err := getJailParam(ctx, jail, param)
+			if ctx.Err() != nil {
+				return cfg, ctx.Err()
+			}
+			if err != nil {
+				cfg.Errors = append(cfg.Errors, fmt.Sprintf("%s %s: %v", jail, param, err))
+				continue
+			}
+			values[param] = v
+		}
+		cfg.Jails[jail] = values
+	}
+	return cfg, nil
+}
+
+// getJailParam runs "fail2ban-client get <jail> <param>" and normalizes the output.
+func getJailParam(ctx context.Context, jail, param string) (string, error) {
+	if err := ValidateJailName(jail); err != nil {
+		return "", err
+	}
+	out, err := runClient(ctx, "get", jail, param)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return normalizeClientValue(string(out)), nil
+}
+
+// normalizeClientValue strips the human readable header and tree prefixes
+// of list outputs, e.g.
+//
+//	Current monitored log file(s):
+//	|- /var/log/auth.log
+//	`- /var/log/secure
+func normalizeClientValue(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) > 1 && strings.HasSuffix(strings.TrimSpace(lines[0]), ":") {
+		lines = lines[1:]
+	}
+	var values []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "|-")
+		line = strings.TrimPrefix(line, "`-")
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// readDefaultOptions resolves the [DEFAULT] section over all jail configuration files.
+func readDefaultOptions() (map[string]string, error) {
+	return readSectionOptions(jailConfigFiles(), "DEFAULT")
+}
+
+// readSectionOptions resolves section over files, later files overriding
+// earlier ones.
+func readSectionOptions(files []string, section string) (map[string]string, error) {
+	options := make(map[string]string)
+	readAny := false
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return options, err
+		}
+		readAny = true
+
+		inSection := false
+		lastKey := ""
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			raw := scanner.Text()
+			line := strings.TrimSpace(raw)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				inSection = strings.Trim(line, "[]") == section
+				lastKey = ""
+				continue
+			}
+			if !inSection {
+				continue
+			}
+			// Indented lines continue the previous value.
+			if lastKey != "" && (raw[0] == ' ' || raw[0] == '\t') {
+				options[lastKey] += " " + line
+				continue
+			}
+			if key, value, ok := strings.Cut(line, "="); ok {
+				lastKey = strings.ToLower(strings.TrimSpace(key))
+				options[lastKey] = strings.TrimSpace(value)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return options, err
+		}
+	}
+	if !readAny {
+		return options, os.ErrNotExist
+	}
+	return options, nil
+}
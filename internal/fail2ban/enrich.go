@@ -0,0 +1,64 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"errors"
+	"net"
+
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+)
+
+// enrichBatchSize is the number of IPs resolved between two progress updates.
+const enrichBatchSize = 500
+
+// EnrichGeo resolves country and ASN for all stored events that lack them.
+// progress is called after every batch with the number of IPs processed so far.
+// It returns the number of IPs that could be resolved.
+func EnrichGeo(setTotal func(int), progress func(int)) (int, error) {
+	if !geoip.Available() {
+		return 0, errors.New("GeoIP database not installed")
+	}
+	ips := store.IPsMissingGeo()
+	setTotal(len(ips))
+
+	resolved := 0
+	for i, ip := range ips {
+		info, err := geoip.Lookup(ip)
+		if err == nil && info.Country != "" {
+			store.SetGeo(ip, info)
+			resolved++
+		}
+		if (i+1)%enrichBatchSize == 0 {
+			progress(enrichBatchSize)
+		}
+	}
+	progress(len(ips) % enrichBatchSize)
+	return resolved, nil
+}
+
+// RefreshGeo looks up the country and ASN of the stored events in nets
+// again, so changed GeoIP overrides also apply to past bans. It returns
+// the number of IPs updated.
+func RefreshGeo(nets []*net.IPNet) int {
+	ips := store.IPsIn(nets)
+	for _, ip := range ips {
+		info, _ := geoip.Lookup(ip) // no country without database or override
+		store.ReplaceGeo(ip, info)
+	}
+	return len(ips)
+}
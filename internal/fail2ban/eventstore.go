@@ -0,0 +1,457 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"log"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+)
+
+// EventStore keeps all known ban events in memory. It is filled by the
+// log backfill job on startup and by incoming ban notifications afterwards,
+// plus the logged bans without a notification, see CheckCallbacks.
+// Lookups by IP, jail and country are served from indexes that are updated
+// incrementally on every Add.
+type EventStore struct {
+	mu        sync.RWMutex
+	events    []BanEvent
+	byIP      map[string][]int
+	byJail    map[string][]int
+	recent    map[string][]int // per jail, newest first, capped at recentPerJail
+	byCountry map[string]int
+	lookups   uint64
+	updatedAt time.Time
+
+	subMu       sync.RWMutex
+	subscribers []func(BanEvent)
+}
+
+// IndexStats describes the size of the in-memory indexes, for debugging.
+type IndexStats struct {
+	Events    int       `json:"events"`
+	IPs       int       `json:"ips"`
+	Jails     int       `json:"jails"`
+	Countries int       `json:"countries"`
+	Lookups   uint64    `json:"lookups"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// recentPerJail is the number of events kept in the per-jail recent index.
+const recentPerJail = 100
+
+// BackfillStatus reports the progress of the initial log backfill.
+type BackfillStatus struct {
+	Running    bool      `json:"running"`
+	Done       bool      `json:"done"`
+	LogPath    string    `json:"logPath"`
+	Sources    []string  `json:"sources"`
+	BytesRead  int64     `json:"bytesRead"`
+	BytesTotal int64     `json:"bytesTotal"`
+	Events     int       `json:"events"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+var (
+	store = newEventStore()
+
+	backfillLock   sync.RWMutex
+	backfillStatus BackfillStatus
+)
+
+func newEventStore() *EventStore {
+	return &EventStore{
+		byIP:      make(map[string][]int),
+		byJail:    make(map[string][]int),
+		recent:    make(map[string][]int),
+		byCountry: make(map[string]int),
+	}
+}
+
+// Events returns the process wide event store.
+func Events() *EventStore {
+	return store
+}
+
+// Subscribe registers fn to be called for every live event added with Add.
+// Historical events loaded by the backfill are not passed to subscribers.
+func (s *EventStore) Subscribe(fn func(BanEvent)) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Add records a single live ban event, updates all indexes and notifies subscribers.
+func (s *EventStore) Add(ev BanEvent) {
+	s.add(ev)
+
+	s.subMu.RLock()
+	subs := s.subscribers
+	s.subMu.RUnlock()
+	for _, fn := range subs {
+		fn(ev)
+	}
+}
+
+// add records a single ban event and updates all indexes.
+func (s *EventStore) add(ev BanEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := len(s.events)
+	s.events = append(s.events, ev)
+	s.byIP[ev.IP] = append(s.byIP[ev.IP], idx)
+	s.byJail[ev.Jail] = append(s.byJail[ev.Jail], idx)
+	if ev.Country != "" {
+		s.byCountry[ev.Country]++
+	}
+	s.insertRecent(ev.Jail, idx)
+	s.updatedAt = time.Now()
+}
+
+// insertRecent keeps the recent index of a jail sorted by time, newest first.
+func (s *EventStore) insertRecent(jail string, idx int) {
+	list := s.recent[jail]
+	t := s.events[idx].Time
+	pos := sort.Search(len(list), func(i int) bool { return s.events[list[i]].Time.Before(t) })
+	if pos >= recentPerJail {
+		return
+	}
+	list = append(list, 0)
+	copy(list[pos+1:], list[pos:])
+	list[pos] = idx
+	if len(list) > recentPerJail {
+		list = list[:recentPerJail]
+	}
+	s.recent[jail] = list
+}
+
+// SetGeo stores country and ASN of an IP on all of its events.
+// Events that already carry a country are left untouched.
+func (s *EventStore) SetGeo(ip string, info geoip.Info) {
+	if info.Country == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, idx := range s.byIP[ip] {
+		ev := &s.events[idx]
+		if ev.Country == "" {
+			ev.Country = info.Country
+			s.byCountry[info.Country]++
+		}
+		if ev.ASN == 0 {
+			ev.ASN = info.ASN
+			ev.ASOrg = info.ASOrg
+		}
+	}
+}
+
+// ReplaceGeo sets the country and AS of all events of ip, replacing what
+// they had, e.g. after a GeoIP override changed.
+func (s *EventStore) ReplaceGeo(ip string, info geoip.Info) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, idx := range s.byIP[ip] {
+		ev := &s.events[idx]
+		if ev.Country != info.Country {
+			if ev.Country != "" {
+				if s.byCountry[ev.Country]--; s.byCountry[ev.Country] <= 0 {
+					delete(s.byCountry, ev.Country)
+				}
+			}
+			if info.Country != "" {
+				s.byCountry[info.Country]++
+			}
+			ev.Country = info.Country
+		}
+		ev.ASN = info.ASN
+		ev.ASOrg = info.ASOrg
+	}
+}
+
+// IPsIn returns the stored IPs contained in one of nets.
+func (s *EventStore) IPsIn(nets []*net.IPNet) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ips []string
+	for ip := range s.byIP {
+		parsed := net.ParseIP(ip)
+		for _, n := range nets {
+			if parsed != nil && n.Contains(parsed) {
+				ips = append(ips, ip)
+				break
+			}
+		}
+	}
+	return ips
+}
+
+// IPsMissingGeo returns all IPs that have at least one event without country.
+func (s *EventStore) IPsMissingGeo() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ips []string
+	for ip, idxs := range s.byIP {
+		for _, idx := range idxs {
+			if s.events[idx].Country == "" {
+				ips = append(ips, ip)
+				break
+			}
+		}
+	}
+	return ips
+}
+
+// Len returns the number of stored events.
+func (s *EventStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.events)
+}
+
+// ByJail returns a copy of all events grouped by jail name.
+func (s *EventStore) ByJail() map[string][]BanEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string][]BanEvent, len(s.byJail))
+	for jail, idxs := range s.byJail {
+		out[jail] = s.collect(idxs)
+	}
+	return out
+}
+
+// ByIP returns all events of the given IP.
+func (s *EventStore) ByIP(ip string) []BanEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	return s.collect(s.byIP[ip])
+}
+
+// RecentByJail returns up to n of the most recent events of a jail, newest first.
+func (s *EventStore) RecentByJail(jail string, n int) []BanEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	idxs := s.recent[jail]
+	if len(idxs) > n {
+		idxs = idxs[:n]
+	}
+	return s.collect(idxs)
+}
+
+// CountryCounts returns the number of events per country code.
+func (s *EventStore) CountryCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	out := make(map[string]int, len(s.byCountry))
+	for c, n := range s.byCountry {
+		out[c] = n
+	}
+	return out
+}
+
+// IPCounts returns the number of events per IP.
+func (s *EventStore) IPCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]int, len(s.byIP))
+	for ip, idxs := range s.byIP {
+		out[ip] = len(idxs)
+	}
+	return out
+}
+
+// JailCounts returns the number of events per jail.
+func (s *EventStore) JailCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]int, len(s.byJail))
+	for jail, idxs := range s.byJail {
+		out[jail] = len(idxs)
+	}
+	return out
+}
+
+// Stats returns the current index sizes.
+func (s *EventStore) Stats() IndexStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return IndexStats{
+		Events:    len(s.events),
+		IPs:       len(s.byIP),
+		Jails:     len(s.byJail),
+		Countries: len(s.byCountry),
+		Lookups:   s.lookups,
+		UpdatedAt: s.updatedAt,
+	}
+}
+
+// CountSince returns how many events of the given jail happened after t,
+// in total and by address family.
+func (s *EventStore) CountSince(jail string, t time.Time) (int, FamilyCounts) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	var fam FamilyCounts
+	for _, idx := range s.byJail[jail] {
+		if ev := s.events[idx]; ev.Time.After(t) {
+			n++
+			fam.Add(ev.IP, 1)
+		}
+	}
+	return n, fam
+}
+
+// CountryFamilyCounts returns the number of events per country code and
+// address family of the jails accepted by visible (all if nil).
+func (s *EventStore) CountryFamilyCounts(visible func(jail string) bool) map[string]FamilyCounts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]FamilyCounts)
+	for _, ev := range s.events {
+		if ev.Country == "" || (visible != nil && !visible(ev.Jail)) {
+			continue
+		}
+		fam := out[ev.Country]
+		fam.Add(ev.IP, 1)
+		out[ev.Country] = fam
+	}
+	return out
+}
+
+// Latest returns the n most recent events over all jails, newest first.
+func (s *EventStore) Latest(n int) []BanEvent {
+	s.mu.RLock()
+	var all []BanEvent
+	for _, idxs := range s.recent {
+		all = append(all, s.collect(idxs)...)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Time.After(all[j].Time) })
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+// Filter returns up to limit events matching fn, newest first.
+func (s *EventStore) Filter(fn func(BanEvent) bool, limit int) []BanEvent {
+	s.mu.RLock()
+	var out []BanEvent
+	for _, ev := range s.events {
+		if fn(ev) {
+			out = append(out, ev)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// collect copies the events at the given positions. The caller must hold the lock.
+func (s *EventStore) collect(idxs []int) []BanEvent {
+	out := make([]BanEvent, len(idxs))
+	for i, idx := range idxs {
+		out[i] = s.events[idx]
+	}
+	return out
+}
+
+// StartBackfill parses logPath in the background and loads all ban events into the store.
+// It returns immediately; use GetBackfillStatus to follow the progress.
+func StartBackfill(logPath string) {
+	backfillLock.Lock()
+	if backfillStatus.Running {
+		backfillLock.Unlock()
+		return
+	}
+	sources := backfillSources(logPath)
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.String()
+	}
+	backfillStatus = BackfillStatus{Running: true, LogPath: logPath, Sources: names, StartedAt: time.Now()}
+	backfillLock.Unlock()
+
+	go runBackfill(sources)
+}
+
+// backfillSources returns the configured log sources, or logPath if there are none.
+func backfillSources(logPath string) []config.LogSource {
+	if sources := config.GetSettings().Fail2ban.LogSources; len(sources) > 0 {
+		return sources
+	}
+	return []config.LogSource{{Type: config.LogSourceFile, Path: logPath}}
+}
+
+// GetBackfillStatus returns a copy of the current backfill progress.
+func GetBackfillStatus() BackfillStatus {
+	backfillLock.RLock()
+	defer backfillLock.RUnlock()
+	return backfillStatus
+}
+
+func runBackfill(sources []config.LogSource) {
+	config.DebugLog("Starting ban log backfill from %v", sources)
+	count := 0
+	opts := ParseOptions{
+		Workers: runtime.NumCPU(),
+		Progress: func(done, total int64) {
+			backfillLock.Lock()
+			backfillStatus.BytesRead = done
+			backfillStatus.BytesTotal = total
+			backfillLock.Unlock()
+		},
+	}
+	err := StreamBanSources(sources, opts, func(ev BanEvent) {
+		// The ban log has no matched lines, only the jail tells the port.
+		ev.Port = JailPort(ev.Jail)
+		store.add(ev)
+		count++
+	})
+
+	backfillLock.Lock()
+	defer backfillLock.Unlock()
+	backfillStatus.Running = false
+	backfillStatus.Done = err == nil
+	backfillStatus.Events = count
+	backfillStatus.FinishedAt = time.Now()
+	if err != nil {
+		backfillStatus.Error = err.Error()
+		log.Printf("Ban log backfill failed (%d events loaded from the other sources): %v", count, err)
+		return
+	}
+	log.Printf("Ban log backfill finished: %d events loaded from %s in %s",
+		count, strings.Join(backfillStatus.Sources, ", "), backfillStatus.FinishedAt.Sub(backfillStatus.StartedAt).Round(time.Millisecond))
+}
@@ -0,0 +1,122 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failures ("Found <ip>" lines of the fail2ban log) are not stored; the
+// log is only scanned for them so hooks can react to IPs of interest,
+// e.g. the watchlist.
+
+var (
+	failureLock   sync.Mutex
+	failureOffset int64 = -1 // read position in the log, -1 before the first scan
+	failureHooks  []func(BanEvent)
+)
+
+// OnFailure registers fn to be called for every failure read by ScanFailures.
+// The event carries the time, jail, IP and log line of the failure.
+func OnFailure(fn func(BanEvent)) {
+	failureLock.Lock()
+	defer failureLock.Unlock()
+	failureHooks = append(failureHooks, fn)
+}
+
+// ScanFailures reads the failures logged since the last call and passes
+// them to the OnFailure hooks. The first call only remembers the end of
+// the log, older failures are not reported.
+func ScanFailures(logPath string) error {
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return fmt.Errorf("failure scan: %w", err)
+	}
+
+	failureLock.Lock()
+	offset := failureOffset
+	hooks := failureHooks
+	switch {
+	case offset < 0 || len(hooks) == 0:
+		failureOffset = info.Size()
+		failureLock.Unlock()
+		return nil
+	case info.Size() < offset:
+		// The log was rotated, read the new file from the start.
+		offset = 0
+	}
+	var found []BanEvent
+	offset, err = readFailures(logPath, offset, info.Size(), func(ev BanEvent) {
+		found = append(found, ev)
+	})
+	if err == nil {
+		failureOffset = offset
+	}
+	failureLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("failure scan: %w", err)
+	}
+
+	for _, ev := range found {
+		for _, fn := range hooks {
+			fn(ev)
+		}
+	}
+	return nil
+}
+
+// readFailures passes the failures between start and end of the log to fn
+// and returns the offset after the last complete line, where the next scan
+// continues.
+func readFailures(logPath string, start, end int64, fn func(BanEvent)) (int64, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return start, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return start, err
+	}
+	r := bufio.NewReader(io.LimitReader(f, end-start))
+	offset := start
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			// An incomplete last line is read again by the next scan.
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+		m := foundRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		t, err := time.Parse("2006-01-02 15:04:05,000", m[1])
+		if err != nil {
+			continue
+		}
+		fn(BanEvent{Time: t, Jail: m[2], IP: m[3], LogLine: strings.TrimRight(line, "\r\n")})
+	}
+}
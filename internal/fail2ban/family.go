@@ -0,0 +1,52 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import "net"
+
+// FamilyCounts splits a counter by address family.
+type FamilyCounts struct {
+	IPv4 int `json:"ipv4"`
+	IPv6 int `json:"ipv6"`
+}
+
+// Add counts n for the family of ip. Unparsable addresses are ignored.
+func (f *FamilyCounts) Add(ip string, n int) {
+	parsed := net.ParseIP(ip)
+	switch {
+	case parsed == nil:
+	case parsed.To4() != nil:
+		f.IPv4 += n
+	default:
+		f.IPv6 += n
+	}
+}
+
+// Merge adds the counts of o.
+func (f *FamilyCounts) Merge(o FamilyCounts) {
+	f.IPv4 += o.IPv4
+	f.IPv6 += o.IPv6
+}
+
+// CountFamilies counts the given IPs by address family.
+func CountFamilies(ips []string) FamilyCounts {
+	var f FamilyCounts
+	for _, ip := range ips {
+		f.Add(ip, 1)
+	}
+	return f
+}
@@ -19,14 +19,21 @@ package fail2ban
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
 )
 
+// FilterDir is the directory holding fail2ban filter definitions.
+var FilterDir = "/etc/fail2ban/filter.d"
+
 // GetFilterConfig returns the config content for a given jail filter.
 // Example: we assume each jail config is at /etc/fail2ban/filter.d/<jailname>.conf
 // Adapt this to your environment.
 func GetFilterConfig(jail string) (string, error) {
-	configPath := filepath.Join("/etc/fail2ban/filter.d", jail+".conf")
+	configPath, err := JoinConfigPath(FilterDir, jail, ".conf")
+	if err != nil {
+		return "", err
+	}
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read config for jail %s: %v", jail, err)
@@ -36,8 +43,11 @@ func GetFilterConfig(jail string) (string, error) {
 
 // SetFilterConfig overwrites the config file for a given jail with new content.
 func SetFilterConfig(jail, newContent string) error {
-	configPath := filepath.Join("/etc/fail2ban/filter.d", jail+".conf")
-	if err := os.WriteFile(configPath, []byte(newContent), 0644); err != nil {
+	configPath, err := JoinConfigPath(FilterDir, jail, ".conf")
+	if err != nil {
+		return err
+	}
+	if err := config.WriteFileAtomic(configPath, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("failed to write config for jail %s: %v", jail, err)
 	}
 	return nil
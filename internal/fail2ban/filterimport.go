@@ -0,0 +1,399 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of filter imports, so a wrong URL cannot fill the memory.
+const (
+	maxImportArchiveSize = 20 << 20
+	maxImportFilters     = 500
+	importTimeout        = 2 * time.Minute
+	importTTL            = time.Hour
+)
+
+// FilterImport is a set of filter definitions fetched from a Git repository
+// or a tarball, kept for preview until InstallImportedFilters is called.
+type FilterImport struct {
+	ID        string           `json:"id"`
+	Source    string           `json:"source"`
+	Revision  string           `json:"revision"` // git commit or SHA-256 of the archive
+	FetchedAt time.Time        `json:"fetchedAt"`
+	Filters   []ImportedFilter `json:"filters"`
+	Skipped   []string         `json:"skipped,omitempty"` // files that are no valid filters, with reason
+}
+
+// ImportedFilter is a filter of a FilterImport.
+type ImportedFilter struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`   // within the source
+	Status  string `json:"status"` // "new", "changed" or "unchanged" compared to FilterDir
+	Content string `json:"content"`
+	Current string `json:"current,omitempty"` // installed version of changed filters
+}
+
+// ImportSource describes where FetchFilterImport gets the filters from.
+type ImportSource struct {
+	// URL is an https Git repository (ending in .git) or a .tar.gz archive.
+	URL string `json:"url"`
+	// Ref is the branch or tag of a Git repository, default its HEAD.
+	Ref string `json:"ref"`
+	// Checksum is the SHA-256 of an archive (required) or the commit a Git
+	// repository must be at (optional, at least 7 hex digits).
+	Checksum string `json:"checksum"`
+}
+
+var (
+	imports     = make(map[string]*FilterImport)
+	importsLock sync.Mutex
+
+	gitRefRegex   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+	checksumRegex = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+)
+
+// IsGit reports whether the source is a Git repository rather than an archive.
+func (s ImportSource) IsGit() bool {
+	return strings.HasSuffix(strings.TrimSuffix(s.URL, "/"), ".git")
+}
+
+// Validate checks the URL, ref and checksum. Only https is accepted, which
+// also keeps git from running its local and command transports.
+func (s ImportSource) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return &ValidationError{Field: "source URL", Value: s.URL, Hint: "use an https URL of a Git repository (.git) or a .tar.gz archive"}
+	}
+	checksum := strings.ToLower(s.Checksum)
+	if s.IsGit() {
+		if s.Ref != "" && (!gitRefRegex.MatchString(s.Ref) || strings.Contains(s.Ref, "..")) {
+			return &ValidationError{Field: "ref", Value: s.Ref, Hint: "use a branch or tag name"}
+		}
+		if checksum != "" && !checksumRegex.MatchString(checksum) {
+			return &ValidationError{Field: "checksum", Value: s.Checksum, Hint: "use the commit hash (at least 7 hex digits)"}
+		}
+		return nil
+	}
+	if !strings.HasSuffix(u.Path, ".tar.gz") && !strings.HasSuffix(u.Path, ".tgz") {
+		return &ValidationError{Field: "source URL", Value: s.URL, Hint: "archives must be .tar.gz files"}
+	}
+	if len(checksum) != 64 || !checksumRegex.MatchString(checksum) {
+		return &ValidationError{Field: "checksum", Value: s.Checksum, Hint: "archives require their SHA-256 checksum (64 hex digits)"}
+	}
+	return nil
+}
+
+// FetchFilterImport downloads the filters of src and keeps them for preview.
+// Files named *.conf are taken from the filter.d directories of the source,
+// or from anywhere if it has none; files that are no filters are skipped.
+func FetchFilterImport(src ImportSource) (*FilterImport, error) {
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+	defer cancel()
+
+	var files map[string][]byte
+	var revision string
+	var err error
+	if src.IsGit() {
+		files, revision, err = fetchGitFilters(ctx, src)
+	} else {
+		files, revision, err = fetchArchiveFilters(ctx, src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", src.URL, err)
+	}
+
+	imp := &FilterImport{ID: randomID(), Source: src.URL, Revision: revision, FetchedAt: time.Now(), Filters: []ImportedFilter{}}
+	seen := make(map[string]string)
+	for _, p := range filterCandidates(files) {
+		name := strings.TrimSuffix(path.Base(p), ".conf")
+		content := files[p]
+		if reason := checkImportedFilter(name, content); reason != "" {
+			imp.Skipped = append(imp.Skipped, p+": "+reason)
+			continue
+		}
+		if first, ok := seen[name]; ok {
+			imp.Skipped = append(imp.Skipped, p+": same filter name as "+first)
+			continue
+		}
+		seen[name] = p
+		if len(imp.Filters) == maxImportFilters {
+			imp.Skipped = append(imp.Skipped, p+": more than "+fmt.Sprint(maxImportFilters)+" filters")
+			continue
+		}
+		f := ImportedFilter{Name: name, Path: p, Status: "new", Content: string(content)}
+		if current, err := GetFilterConfig(name); err == nil {
+			f.Status = "changed"
+			if current == f.Content {
+				f.Status = "unchanged"
+			} else {
+				f.Current = current
+			}
+		}
+		imp.Filters = append(imp.Filters, f)
+	}
+	if len(imp.Filters) == 0 {
+		return nil, fmt.Errorf("no filters found in %s", src.URL)
+	}
+
+	importsLock.Lock()
+	defer importsLock.Unlock()
+	for id, old := range imports {
+		if time.Since(old.FetchedAt) > importTTL {
+			delete(imports, id)
+		}
+	}
+	imports[imp.ID] = imp
+	return imp, nil
+}
+
+// ErrImportNotFound is returned for unknown or expired imports.
+var ErrImportNotFound = errors.New("import not found or expired, fetch it again")
+
+// GetFilterImport returns a fetched import.
+func GetFilterImport(id string) (*FilterImport, error) {
+	importsLock.Lock()
+	defer importsLock.Unlock()
+	imp, ok := imports[id]
+	if !ok || time.Since(imp.FetchedAt) > importTTL {
+		return nil, ErrImportNotFound
+	}
+	return imp, nil
+}
+
+// InstallImportedFilters writes the named filters of an import to FilterDir
+// and returns the names of the filters written. Unchanged filters are skipped.
+func InstallImportedFilters(id string, names []string) ([]string, error) {
+	imp, err := GetFilterImport(id)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]ImportedFilter, len(imp.Filters))
+	for _, f := range imp.Filters {
+		byName[f.Name] = f
+	}
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return nil, &ValidationError{Field: "filter", Value: name, Hint: "not part of import " + id}
+		}
+	}
+	installed := []string{}
+	for _, name := range names {
+		f := byName[name]
+		if f.Status == "unchanged" {
+			continue
+		}
+		if err := SetFilterConfig(f.Name, f.Content); err != nil {
+			return installed, err
+		}
+		installed = append(installed, f.Name)
+	}
+	return installed, nil
+}
+
+func fetchGitFilters(ctx context.Context, src ImportSource) (map[string][]byte, string, error) {
+	dir, err := os.MkdirTemp("", "fail2ban-ui-import-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+	args = append(args, "--", src.URL, dir)
+	if err := runGit(ctx, "", args...); err != nil {
+		return nil, "", err
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	revision := strings.TrimSpace(string(out))
+	if want := strings.ToLower(src.Checksum); want != "" && !strings.HasPrefix(revision, want) {
+		return nil, "", fmt.Errorf("repository is at commit %s, expected %s", revision, want)
+	}
+
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		// Symlinks are skipped, they could point anywhere on this host.
+		if !d.Type().IsRegular() || filepath.Ext(p) != ".conf" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxConfigFileSize {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, revision, err
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL=https")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func fetchArchiveFilters(ctx context.Context, src ImportSource) (map[string][]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportArchiveSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImportArchiveSize {
+		return nil, "", fmt.Errorf("archive is larger than %d MB", maxImportArchiveSize>>20)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	// The archive is only unpacked once it is known to be the expected one.
+	if checksum != strings.ToLower(src.Checksum) {
+		return nil, "", fmt.Errorf("checksum mismatch: archive has %s", checksum)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if hdr.Typeflag != tar.TypeReg || path.Ext(name) != ".conf" || hdr.Size > maxConfigFileSize {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxConfigFileSize))
+		if err != nil {
+			return nil, "", err
+		}
+		files[name] = content
+	}
+	return files, checksum, nil
+}
+
+// filterCandidates returns the paths of files that should contain filters,
+// sorted by path.
+func filterCandidates(files map[string][]byte) []string {
+	var inFilterDir, all []string
+	for p := range files {
+		all = append(all, p)
+		if path.Base(path.Dir(p)) == "filter.d" {
+			inFilterDir = append(inFilterDir, p)
+		}
+	}
+	if len(inFilterDir) > 0 {
+		all = inFilterDir
+	}
+	sort.Strings(all)
+	return all
+}
+
+// checkImportedFilter returns why content cannot be installed as filter
+// name, or "" if it can.
+func checkImportedFilter(name string, content []byte) string {
+	if err := ValidateJailName(name); err != nil {
+		return "invalid filter name"
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return "binary file"
+	}
+	var definition, failregex bool
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			definition = strings.EqualFold(strings.TrimSpace(strings.Trim(line, "[]")), "Definition")
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); ok && definition && strings.EqualFold(strings.TrimSpace(key), "failregex") {
+			failregex = true
+		}
+	}
+	if !failregex {
+		return "no failregex in a [Definition] section"
+	}
+	return ""
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,167 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxMatchLines limits the log lines tested by MatchFilters.
+const MaxMatchLines = 200
+
+const (
+	matchParallel = 4                // fail2ban-regex processes running at once
+	matchTimeout  = 30 * time.Second // per filter
+)
+
+// LineMatch lists the filters whose failregex matches a log line.
+type LineMatch struct {
+	Line    string   `json:"line"`
+	Filters []string `json:"filters"`
+}
+
+// FilterMatchError reports a filter fail2ban-regex could not test.
+type FilterMatchError struct {
+	Filter string `json:"filter"`
+	Error  string `json:"error"`
+}
+
+// MatchFilters tests the log lines against every filter in FilterDir with
+// fail2ban-regex and returns, per line, the filters matching it. Filters
+// that fail to load are reported separately and do not fail the test.
+func MatchFilters(ctx context.Context, lines []string) ([]LineMatch, []FilterMatchError, error) {
+	if len(lines) == 0 {
+		return nil, nil, errors.New("no log lines given")
+	}
+	if len(lines) > MaxMatchLines {
+		return nil, nil, fmt.Errorf("at most %d log lines can be tested at once", MaxMatchLines)
+	}
+	if _, err := exec.LookPath("fail2ban-regex"); err != nil {
+		return nil, nil, errors.New("fail2ban-regex is not installed")
+	}
+	filters, err := filepath.Glob(filepath.Join(FilterDir, "*.conf"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logFile, err := os.CreateTemp("", "fail2ban-ui-match-*.log")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(logFile.Name())
+	for _, line := range lines {
+		fmt.Fprintln(logFile, strings.TrimRight(line, "\r\n"))
+	}
+	if err := logFile.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		matched  = make(map[string][]string) // line -> filters
+		failures []FilterMatchError
+		slots    = make(chan struct{}, matchParallel)
+	)
+	for _, path := range filters {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			name := strings.TrimSuffix(filepath.Base(path), ".conf")
+			hits, err := matchFilter(ctx, path, logFile.Name())
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, FilterMatchError{Filter: name, Error: err.Error()})
+				return
+			}
+			for _, line := range hits {
+				matched[strings.TrimSpace(line)] = append(matched[strings.TrimSpace(line)], name)
+			}
+		}(path)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]LineMatch, len(lines))
+	for i, line := range lines {
+		names := matched[strings.TrimSpace(line)]
+		sort.Strings(names)
+		results[i] = LineMatch{Line: strings.TrimRight(line, "\r\n"), Filters: append([]string{}, names...)}
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Filter < failures[j].Filter })
+	return results, failures, nil
+}
+
+// matchFilter runs fail2ban-regex with the filter file on the log file and
+// returns the matched lines.
+func matchFilter(ctx context.Context, filter, logFile string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, matchTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "fail2ban-regex", "--usedns=no", "--print-all-matched", "--print-no-missed", "--print-no-ignored", logFile, filter)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New(lastLine(exitErr.Stderr))
+		}
+		return nil, err
+	}
+	return matchedLines(out), nil
+}
+
+// matchedLines extracts the lines of the "Matched line(s)" block of the
+// fail2ban-regex report, which are prefixed with "|  ".
+func matchedLines(out []byte) []string {
+	var lines []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "|- Matched line(s):"):
+			inBlock = true
+		case !inBlock:
+		case strings.HasPrefix(line, "|  "):
+			lines = append(lines, strings.TrimPrefix(line, "|  "))
+		default:
+			inBlock = false
+		}
+	}
+	return lines
+}
+
+func lastLine(b []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,179 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FilterMeta describes a filter with the metadata found in its file and
+// the jails of the configuration using it.
+type FilterMeta struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"` // leading comment of the file
+	Author      string   `json:"author,omitempty"`
+	Service     string   `json:"service,omitempty"` // systemd unit of the journalmatch, or named in the description
+	Port        string   `json:"port,omitempty"`    // port of the first jail using the filter
+	LogPath     string   `json:"logpath,omitempty"` // log path of the first jail using the filter
+	Jails       []string `json:"jails,omitempty"`
+}
+
+var (
+	filterAuthorRegex  = regexp.MustCompile(`(?i)^(?:original\s+)?authors?\s*:\s*(.+)$`)
+	filterUnitRegex    = regexp.MustCompile(`_SYSTEMD_UNIT=([\w@.-]+?)(?:\.service)?(?:\s|$)`)
+	filterServiceRegex = regexp.MustCompile(`(?i)\bfilter(?:\s+configuration)?\s+(?:for|to match)\s+(?:the\s+)?(.+?)\.?$`)
+)
+
+// maxFilterDescription caps the description taken from the leading comment.
+const maxFilterDescription = 300
+
+// ListFilterMeta returns the metadata of all filters in FilterDir, sorted by name.
+func ListFilterMeta() ([]FilterMeta, error) {
+	files, err := filepath.Glob(filepath.Join(FilterDir, "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	jails := filterJails()
+	var filters []FilterMeta
+	for _, path := range files {
+		meta := readFilterMeta(path)
+		for _, j := range jails[meta.Name] {
+			meta.Jails = append(meta.Jails, j.name)
+			if meta.Port == "" {
+				meta.Port = j.port
+			}
+			if meta.LogPath == "" {
+				meta.LogPath = j.logpath
+			}
+		}
+		filters = append(filters, meta)
+	}
+	sort.Slice(filters, func(i, k int) bool { return filters[i].Name < filters[k].Name })
+	return filters, nil
+}
+
+// readFilterMeta reads the description from the comment paragraphs before
+// the first section, the author from an "Author:" comment anywhere and
+// the service from the journalmatch of [Init].
+func readFilterMeta(path string) FilterMeta {
+	meta := FilterMeta{Name: strings.TrimSuffix(filepath.Base(path), ".conf")}
+	file, err := os.Open(path)
+	if err != nil {
+		return meta
+	}
+	defer file.Close()
+
+	var paragraphs []string
+	var current []string
+	header := true
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			header = false
+		}
+		comment, isComment := strings.CutPrefix(line, "#")
+		comment = strings.TrimSpace(comment)
+		if isComment {
+			if m := filterAuthorRegex.FindStringSubmatch(comment); m != nil && meta.Author == "" {
+				meta.Author = m[1]
+				continue
+			}
+		}
+		if header {
+			switch {
+			case isComment && comment != "":
+				current = append(current, comment)
+			case len(current) > 0:
+				paragraphs = append(paragraphs, strings.Join(current, " "))
+				current = nil
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "journalmatch" {
+			if m := filterUnitRegex.FindStringSubmatch(value); m != nil {
+				meta.Service = m[1]
+			}
+		}
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+
+	// "Fail2Ban filter for openssh" alone says little, add the next paragraph.
+	for i, p := range paragraphs {
+		if i > 0 && len(meta.Description) > 60 {
+			break
+		}
+		if meta.Description != "" && !strings.HasSuffix(meta.Description, ".") {
+			meta.Description += "."
+		}
+		meta.Description = strings.TrimSpace(meta.Description + " " + p)
+		if i == 0 && meta.Service == "" {
+			if m := filterServiceRegex.FindStringSubmatch(p); m != nil {
+				meta.Service = m[1]
+			}
+		}
+	}
+	if len(meta.Description) > maxFilterDescription {
+		meta.Description = strings.TrimSpace(meta.Description[:maxFilterDescription]) + "…"
+	}
+	return meta
+}
+
+type filterJail struct {
+	name, port, logpath string
+}
+
+// filterJails maps filter names to the jails of the configuration using
+// them. Jails without a filter option use the filter named like the jail.
+func filterJails() map[string][]filterJail {
+	options := readJailOptions([]string{"filter", "port", "logpath"})
+	defaults := options["DEFAULT"]
+	jails := make(map[string][]filterJail)
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "DEFAULT" || name == "INCLUDES" || name == "Definition" || name == "Init" {
+			continue
+		}
+		opts := options[name]
+		filter := opts["filter"]
+		if filter == "" || strings.Contains(filter, "%(__name__)s") {
+			filter = name
+		}
+		// "sshd[mode=aggressive]" selects a mode of the sshd filter.
+		filter, _, _ = strings.Cut(filter, "[")
+		port := opts["port"]
+		if port == "" {
+			port = defaults["port"]
+		}
+		if port != "" && !strings.Contains(port, "%(") {
+			port = normalizePorts(port)
+		}
+		jails[strings.TrimSpace(filter)] = append(jails[strings.TrimSpace(filter)], filterJail{name: name, port: port, logpath: opts["logpath"]})
+	}
+	return jails
+}
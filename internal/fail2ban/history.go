@@ -0,0 +1,289 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+	"sort"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// HistoricalSummary describes the bans of a past period, independent of
+// what is banned right now.
+type HistoricalSummary struct {
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	TotalBans    int            `json:"totalBans"`
+	UniqueIPs    int            `json:"uniqueIPs"`
+	Jails        []JailTotal    `json:"jails"`
+	TopIPs       []OffenderStat `json:"topIPs"`
+	TopCountries []CountryStat  `json:"topCountries"`
+	Days         []DayStat      `json:"days"`
+	// Bans per targeted port and interface, see Target.
+	Ports      map[string]int `json:"ports"`
+	Interfaces map[string]int `json:"interfaces"`
+	// The counters above split by address family. The top countries are
+	// ranked separately for each family.
+	TotalBansByFamily    FamilyCounts             `json:"totalBansByFamily"`
+	UniqueIPsByFamily    FamilyCounts             `json:"uniqueIPsByFamily"`
+	TopCountriesByFamily map[string][]CountryStat `json:"topCountriesByFamily"`
+	// Incidents overlapping the period, set by the caller.
+	Incidents []config.Incident `json:"incidents"`
+}
+
+// JailTotal is the number of bans of a jail in a period.
+type JailTotal struct {
+	Jail      string `json:"jail"`
+	Bans      int    `json:"bans"`
+	UniqueIPs int    `json:"uniqueIPs"`
+	// Bans by address family.
+	FamilyCounts
+}
+
+// OffenderStat is an IP with its bans in a period.
+type OffenderStat struct {
+	IP       string    `json:"ip"`
+	Bans     int       `json:"bans"`
+	Country  string    `json:"country,omitempty"`
+	Jails    []string  `json:"jails"`
+	LastBan  time.Time `json:"lastBan"`
+	jailSeen map[string]bool
+}
+
+// CountryStat is the number of bans from a country in a period.
+type CountryStat struct {
+	Country string `json:"country"`
+	Bans    int    `json:"bans"`
+	// Bans by address family.
+	FamilyCounts
+}
+
+// DayStat is the number of bans on a day ("2006-01-02").
+type DayStat struct {
+	Day  string `json:"day"`
+	Bans int    `json:"bans"`
+	// Bans by address family.
+	FamilyCounts
+}
+
+// Summarize computes the summary of all bans in [from, to) of the jails
+// accepted by visible (all if nil), with the top offenders and countries
+// limited to top entries.
+func (s *EventStore) Summarize(from, to time.Time, visible func(jail string) bool, top int) HistoricalSummary {
+	sum := HistoricalSummary{From: from, To: to, Ports: make(map[string]int), Interfaces: make(map[string]int)}
+	jails := make(map[string]*JailTotal)
+	jailIPs := make(map[string]map[string]bool)
+	ips := make(map[string]*OffenderStat)
+	countries := make(map[string]*CountryStat)
+	days := make(map[string]*DayStat)
+
+	s.mu.RLock()
+	for _, ev := range s.events {
+		if ev.Time.Before(from) || !ev.Time.Before(to) || (visible != nil && !visible(ev.Jail)) {
+			continue
+		}
+		sum.TotalBans++
+		sum.TotalBansByFamily.Add(ev.IP, 1)
+
+		jt := jails[ev.Jail]
+		if jt == nil {
+			jt = &JailTotal{Jail: ev.Jail}
+			jails[ev.Jail] = jt
+			jailIPs[ev.Jail] = make(map[string]bool)
+		}
+		jt.Bans++
+		jt.Add(ev.IP, 1)
+		jailIPs[ev.Jail][ev.IP] = true
+		if ev.Port != "" {
+			sum.Ports[ev.Port]++
+		}
+		if ev.Interface != "" {
+			sum.Interfaces[ev.Interface]++
+		}
+
+		o := ips[ev.IP]
+		if o == nil {
+			o = &OffenderStat{IP: ev.IP, jailSeen: make(map[string]bool)}
+			ips[ev.IP] = o
+		}
+		o.Bans++
+		if ev.Country != "" {
+			o.Country = ev.Country
+			cs := countries[ev.Country]
+			if cs == nil {
+				cs = &CountryStat{Country: ev.Country}
+				countries[ev.Country] = cs
+			}
+			cs.Bans++
+			cs.Add(ev.IP, 1)
+		}
+		if !o.jailSeen[ev.Jail] {
+			o.jailSeen[ev.Jail] = true
+			o.Jails = append(o.Jails, ev.Jail)
+		}
+		if ev.Time.After(o.LastBan) {
+			o.LastBan = ev.Time
+		}
+		day := ev.Time.Format("2006-01-02")
+		ds := days[day]
+		if ds == nil {
+			ds = &DayStat{Day: day}
+			days[day] = ds
+		}
+		ds.Bans++
+		ds.Add(ev.IP, 1)
+	}
+	s.mu.RUnlock()
+
+	sum.UniqueIPs = len(ips)
+	for ip := range ips {
+		sum.UniqueIPsByFamily.Add(ip, 1)
+	}
+	sum.Jails = make([]JailTotal, 0, len(jails))
+	for name, jt := range jails {
+		jt.UniqueIPs = len(jailIPs[name])
+		sum.Jails = append(sum.Jails, *jt)
+	}
+	sort.Slice(sum.Jails, func(i, j int) bool {
+		if sum.Jails[i].Bans != sum.Jails[j].Bans {
+			return sum.Jails[i].Bans > sum.Jails[j].Bans
+		}
+		return sum.Jails[i].Jail < sum.Jails[j].Jail
+	})
+
+	sum.TopIPs = make([]OffenderStat, 0, len(ips))
+	for _, o := range ips {
+		sort.Strings(o.Jails)
+		sum.TopIPs = append(sum.TopIPs, *o)
+	}
+	sort.Slice(sum.TopIPs, func(i, j int) bool {
+		if sum.TopIPs[i].Bans != sum.TopIPs[j].Bans {
+			return sum.TopIPs[i].Bans > sum.TopIPs[j].Bans
+		}
+		return sum.TopIPs[i].IP < sum.TopIPs[j].IP
+	})
+	if len(sum.TopIPs) > top {
+		sum.TopIPs = sum.TopIPs[:top]
+	}
+
+	sum.TopCountries = topCountries(countries, top, func(cs CountryStat) int { return cs.Bans })
+	sum.TopCountriesByFamily = map[string][]CountryStat{
+		"ipv4": topCountries(countries, top, func(cs CountryStat) int { return cs.IPv4 }),
+		"ipv6": topCountries(countries, top, func(cs CountryStat) int { return cs.IPv6 }),
+	}
+
+	sum.Days = make([]DayStat, 0, len(days))
+	for _, ds := range days {
+		sum.Days = append(sum.Days, *ds)
+	}
+	sort.Slice(sum.Days, func(i, j int) bool { return sum.Days[i].Day < sum.Days[j].Day })
+	return sum
+}
+
+// BanSeries is the number of bans per interval of a group of bans, e.g. of
+// a jail, see BanTimeSeries.
+type BanSeries struct {
+	Name   string `json:"name"`
+	Counts []int  `json:"counts"`
+}
+
+// BanTimeSeries counts the bans in [from, to) of the jails accepted by
+// visible (all if nil) per step, Counts[i] being the bans of the interval
+// starting at from + i*step. The bans are split into one series per
+// value of group, sorted by name; with a nil group there is a single
+// series "bans", also if there were none.
+func (s *EventStore) BanTimeSeries(from, to time.Time, step time.Duration, visible func(jail string) bool, group func(BanEvent) string) []BanSeries {
+	buckets := int((to.Sub(from) + step - 1) / step)
+	counts := make(map[string][]int)
+	if group == nil {
+		counts["bans"] = make([]int, buckets)
+	}
+
+	s.mu.RLock()
+	for _, ev := range s.events {
+		if ev.Time.Before(from) || !ev.Time.Before(to) || (visible != nil && !visible(ev.Jail)) {
+			continue
+		}
+		name := "bans"
+		if group != nil {
+			name = group(ev)
+		}
+		if counts[name] == nil {
+			counts[name] = make([]int, buckets)
+		}
+		counts[name][int(ev.Time.Sub(from)/step)]++
+	}
+	s.mu.RUnlock()
+
+	series := make([]BanSeries, 0, len(counts))
+	for name, c := range counts {
+		series = append(series, BanSeries{Name: name, Counts: c})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	return series
+}
+
+// BannedCountries ranks the countries of the currently banned IPs of
+// jails. IPs banned in several jails count once, IPs without a known
+// country are left out. Countries are resolved with the memoized GeoIP
+// lookup, so only new bans hit the database.
+func BannedCountries(jails []JailInfo) []CountryStat {
+	seen := make(map[string]bool)
+	var ips []string
+	for _, j := range jails {
+		for _, ip := range j.BannedIPs {
+			if !seen[ip] {
+				seen[ip] = true
+				ips = append(ips, ip)
+			}
+		}
+	}
+	countries := make(map[string]*CountryStat)
+	for ip, country := range geoip.CachedCountries(ips) {
+		cs, ok := countries[country]
+		if !ok {
+			cs = &CountryStat{Country: country}
+			countries[country] = cs
+		}
+		cs.Bans++
+		cs.FamilyCounts.Add(ip, 1)
+	}
+	return topCountries(countries, len(countries), func(cs CountryStat) int { return cs.Bans })
+}
+
+// topCountries returns the countries with a non-zero count, ranked by count
+// and limited to top entries.
+func topCountries(countries map[string]*CountryStat, top int, count func(CountryStat) int) []CountryStat {
+	out := make([]CountryStat, 0, len(countries))
+	for _, cs := range countries {
+		if count(*cs) > 0 {
+			out = append(out, *cs)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ci, cj := count(out[i]), count(out[j]); ci != cj {
+			return ci > cj
+		}
+		return out[i].Country < out[j].Country
+	})
+	if len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
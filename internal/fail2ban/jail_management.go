@@ -94,6 +94,11 @@ func parseJailConfigFile(path string) ([]JailInfo, error) {
 // UpdateJailEnabledStates updates the enabled state for each jail based on the provided updates map.
 // It updates /etc/fail2ban/jail.local and attempts to update any jail.d files as well.
 func UpdateJailEnabledStates(updates map[string]bool) error {
+	for jail := range updates {
+		if err := ValidateJailName(jail); err != nil {
+			return err
+		}
+	}
 	// Update jail.local file
 	localPath := "/etc/fail2ban/jail.local"
 	if err := updateJailConfigFile(localPath, updates); err != nil {
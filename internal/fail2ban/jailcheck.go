@@ -0,0 +1,208 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JailCheck compares the jails enabled in the configuration with the jails
+// reported by the running fail2ban daemon.
+type JailCheck struct {
+	Configured    []string  `json:"configured"`
+	Running       []string  `json:"running"`
+	FailedToStart []string  `json:"failedToStart"`
+	Unexpected    []string  `json:"unexpected"`
+	Error         string    `json:"error,omitempty"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// OK reports whether every configured jail is running.
+func (c JailCheck) OK() bool {
+	return c.Error == "" && len(c.FailedToStart) == 0
+}
+
+var (
+	lastJailCheck     JailCheck
+	lastJailCheckLock sync.RWMutex
+)
+
+// CheckJails runs a new comparison and remembers it as the last result,
+// unless ctx ended before it was complete.
+func CheckJails(ctx context.Context) JailCheck {
+	check := JailCheck{
+		Configured:    []string{},
+		Running:       []string{},
+		FailedToStart: []string{},
+		Unexpected:    []string{},
+		CheckedAt:     time.Now(),
+	}
+
+	configured, err := EnabledJails()
+	if err != nil {
+		check.Error = err.Error()
+	} else {
+		check.Configured = configured
+	}
+	running, err := GetJails(ctx)
+	if ctx.Err() != nil {
+		check.Error = ctx.Err().Error()
+		return check
+	}
+	if err != nil {
+		check.Error = err.Error()
+	} else {
+		for _, j := range running {
+			if j != "" {
+				check.Running = append(check.Running, j)
+			}
+		}
+		sort.Strings(check.Running)
+	}
+
+	if check.Error == "" {
+		isRunning := make(map[string]bool, len(check.Running))
+		for _, j := range check.Running {
+			isRunning[j] = true
+		}
+		isConfigured := make(map[string]bool, len(check.Configured))
+		for _, j := range check.Configured {
+			isConfigured[j] = true
+			if !isRunning[j] {
+				check.FailedToStart = append(check.FailedToStart, j)
+			}
+		}
+		for _, j := range check.Running {
+			if !isConfigured[j] {
+				check.Unexpected = append(check.Unexpected, j)
+			}
+		}
+	}
+
+	lastJailCheckLock.Lock()
+	lastJailCheck = check
+	lastJailCheckLock.Unlock()
+	return check
+}
+
+// LastJailCheck returns the result of the most recent CheckJails call.
+func LastJailCheck() JailCheck {
+	lastJailCheckLock.RLock()
+	defer lastJailCheckLock.RUnlock()
+	return lastJailCheck
+}
+
+// EnabledJails returns the sorted names of all jails enabled in the configuration.
+// Files are read in fail2ban's order (jail.conf, jail.d/*.conf, jail.local,
+// jail.d/*.local), later files override earlier ones and a jail without an
+// explicit "enabled" inherits the value from [DEFAULT] (false by default).
+func EnabledJails() ([]string, error) {
+	files := jailConfigFiles()
+
+	defaultEnabled := false
+	sections := make(map[string]*bool)
+	readAny := false
+	for _, path := range files {
+		if err := readEnabledFlags(path, &defaultEnabled, sections); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		readAny = true
+	}
+	if !readAny {
+		return nil, os.ErrNotExist
+	}
+
+	jails := []string{}
+	for name, enabled := range sections {
+		if (enabled == nil && defaultEnabled) || (enabled != nil && *enabled) {
+			jails = append(jails, name)
+		}
+	}
+	sort.Strings(jails)
+	return jails, nil
+}
+
+// jailConfigFiles returns the jail configuration files in the order fail2ban reads them.
+func jailConfigFiles() []string {
+	files := []string{"/etc/fail2ban/jail.conf"}
+	confs, _ := filepath.Glob("/etc/fail2ban/jail.d/*.conf")
+	sort.Strings(confs)
+	files = append(files, confs...)
+	files = append(files, "/etc/fail2ban/jail.local")
+	locals, _ := filepath.Glob("/etc/fail2ban/jail.d/*.local")
+	sort.Strings(locals)
+	return append(files, locals...)
+}
+
+// readEnabledFlags collects the "enabled" option of every section in path.
+func readEnabledFlags(path string, defaultEnabled *bool, sections map[string]*bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var section string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			if section != "DEFAULT" && section != "INCLUDES" {
+				if _, ok := sections[section]; !ok {
+					sections[section] = nil
+				}
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "enabled") {
+			continue
+		}
+		enabled := isTrue(strings.TrimSpace(value))
+		switch section {
+		case "DEFAULT":
+			*defaultEnabled = enabled
+		case "", "INCLUDES":
+		default:
+			sections[section] = &enabled
+		}
+	}
+	return scanner.Err()
+}
+
+// isTrue interprets fail2ban's boolean option values.
+func isTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Lint finding severities.
+const (
+	LintError   = "error"   // fail2ban will reject the configuration or the jail
+	LintWarning = "warning" // valid, but probably not what was intended
+)
+
+// LintFinding is a single problem found in the jail configuration.
+type LintFinding struct {
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Jail     string `json:"jail,omitempty"`
+	Message  string `json:"message"`
+}
+
+// LintResult holds the findings of a lint pass.
+type LintResult struct {
+	Findings  []LintFinding `json:"findings"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// durationOptions are the options holding fail2ban time values.
+var durationOptions = map[string]bool{
+	"bantime": true, "findtime": true, "bantime.maxtime": true, "bantime.rndtime": true, "dbpurgeage": true,
+}
+
+// durationPattern matches fail2ban time values like "600", "-1", "1h 30m" or "2 days".
+var durationPattern = regexp.MustCompile(`(?i)^(-?\d+(\.\d+)?|(\d+(\.\d+)?\s*(y(ears?)?|mo(n(ths?)?)?|w(eeks?)?|d(ays?)?|h(ours?)?|m(in(utes?)?)?|s(ec(onds?)?)?)\s*)+)$`)
+
+var (
+	lastLint     LintResult
+	lastLintLock sync.RWMutex
+)
+
+// LintConfig checks jail.conf, jail.local, jail.d and filter.d for problems
+// fail2ban would only report on the next reload, and remembers the result.
+func LintConfig() LintResult {
+	result := lintConfig(jailConfigFiles(), FilterDir)
+	lastLintLock.Lock()
+	lastLint = result
+	lastLintLock.Unlock()
+	return result
+}
+
+// LastLint returns the result of the most recent LintConfig call.
+func LastLint() LintResult {
+	lastLintLock.RLock()
+	defer lastLintLock.RUnlock()
+	return lastLint
+}
+
+// lintOption is an option value with the place it was set.
+type lintOption struct {
+	value string
+	file  string
+	line  int
+}
+
+func lintConfig(files []string, filterDir string) LintResult {
+	result := LintResult{Findings: []LintFinding{}, CheckedAt: time.Now()}
+	add := func(severity string, opt lintOption, jail, format string, args ...any) {
+		result.Findings = append(result.Findings, LintFinding{
+			Severity: severity, File: opt.file, Line: opt.line, Jail: jail, Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Options of every section, later files override earlier ones.
+	sections := map[string]map[string]lintOption{"DEFAULT": {}}
+	for _, path := range files {
+		err := readLintSections(path, func(section string, key string, opt lintOption) {
+			if sections[section] == nil {
+				sections[section] = map[string]lintOption{}
+			}
+			sections[section][key] = opt
+			jail := section
+			if jail == "DEFAULT" {
+				jail = ""
+			}
+			switch {
+			case durationOptions[key]:
+				if !validDuration(opt.value) {
+					add(LintError, opt, jail, "%s has an invalid time value %q", key, opt.value)
+				}
+			case key == "ignoreip":
+				for _, msg := range overlappingIgnoreIPs(opt.value) {
+					add(LintWarning, opt, jail, "ignoreip %s", msg)
+				}
+			}
+		}, func(section string, first, dup lintOption) {
+			add(LintWarning, dup, section, "section [%s] is defined twice in this file (first on line %d)", section, first.line)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			add(LintError, lintOption{file: path}, "", "cannot read file: %v", err)
+		}
+	}
+
+	defaults := sections["DEFAULT"]
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		if name != "DEFAULT" && name != "INCLUDES" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, jail := range names {
+		opts := sections[jail]
+		enabled, ok := opts["enabled"]
+		if !ok {
+			enabled = defaults["enabled"]
+		}
+		if !isTrue(enabled.value) {
+			continue
+		}
+		filter, ok := opts["filter"]
+		if !ok {
+			filter, ok = defaults["filter"]
+		}
+		if !ok {
+			// fail2ban uses the filter named like the jail.
+			filter = lintOption{value: jail, file: files[0]}
+		}
+		name := filterName(filter.value, jail)
+		if name == "" {
+			continue
+		}
+		if !fileExists(filepath.Join(filterDir, name+".conf")) && !fileExists(filepath.Join(filterDir, name+".local")) {
+			add(LintError, filter, jail, "jail %s uses filter %q, but %s does not exist", jail, name, filepath.Join(filterDir, name+".conf"))
+		}
+	}
+	return result
+}
+
+// readLintSections calls option for every option in path and dup for every
+// section defined more than once in it.
+func readLintSections(path string, option func(section, key string, opt lintOption), dup func(section string, first, dup lintOption)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	seen := make(map[string]lintOption)
+	var section, lastKey string
+	var last lintOption
+	flush := func() {
+		if lastKey != "" && section != "" {
+			option(section, lastKey, last)
+		}
+		lastKey = ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		// Indented lines continue the previous value.
+		if lastKey != "" && (raw[0] == ' ' || raw[0] == '\t') {
+			last.value += " " + line
+			continue
+		}
+		flush()
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			here := lintOption{file: path, line: lineNo}
+			if first, ok := seen[section]; ok {
+				dup(section, first, here)
+			} else {
+				seen[section] = here
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			lastKey = strings.ToLower(strings.TrimSpace(key))
+			last = lintOption{value: strings.TrimSpace(value), file: path, line: lineNo}
+		}
+	}
+	flush()
+	return scanner.Err()
+}
+
+// validDuration reports whether value is a time value fail2ban accepts.
+// Values using interpolation are not checked.
+func validDuration(value string) bool {
+	if strings.Contains(value, "%(") {
+		return true
+	}
+	return durationPattern.MatchString(strings.TrimSpace(value))
+}
+
+// filterName resolves the filter file name from a filter option, e.g.
+// "%(__name__)s[mode=aggressive]" for jail "sshd" is "sshd". It returns ""
+// if the name depends on other interpolations.
+func filterName(value, jail string) string {
+	name, _, _ := strings.Cut(value, "[")
+	name = strings.TrimSpace(strings.ReplaceAll(name, "%(__name__)s", jail))
+	if strings.Contains(name, "%(") {
+		return ""
+	}
+	return name
+}
+
+// overlappingIgnoreIPs describes duplicate entries and entries already
+// covered by a network in the same ignoreip list.
+func overlappingIgnoreIPs(value string) []string {
+	type entry struct {
+		text string
+		net  *net.IPNet
+	}
+	var entries []entry
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if n, err := parseNetwork(field); err == nil {
+			entries = append(entries, entry{field, n})
+		}
+	}
+
+	var msgs []string
+	for i, a := range entries {
+		for j, b := range entries {
+			if i == j {
+				continue
+			}
+			aOnes, _ := a.net.Mask.Size()
+			bOnes, _ := b.net.Mask.Size()
+			switch {
+			case aOnes == bOnes && a.net.IP.Equal(b.net.IP):
+				if i < j {
+					msgs = append(msgs, fmt.Sprintf("lists %s more than once", a.text))
+				}
+			case aOnes > bOnes && b.net.Contains(a.net.IP):
+				msgs = append(msgs, fmt.Sprintf("entry %s is already covered by %s", a.text, b.text))
+			}
+		}
+	}
+	return msgs
+}
+
+// parseNetwork parses an IP or CIDR into a network.
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %q", value)
+		}
+		if v4 := ip.To4(); v4 != nil {
+			return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(value)
+	return n, err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
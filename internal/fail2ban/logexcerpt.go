@@ -0,0 +1,231 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of a log excerpt. Only the end of each log file is scanned, which
+// is where the lines leading to a fresh ban are.
+const (
+	excerptMaxLines     = 200
+	excerptMaxBytes     = 64 * 1024
+	excerptScanBytes    = 8 * 1024 * 1024
+	excerptCacheTTL     = 5 * time.Minute
+	excerptCacheEntries = 256
+	logPathCacheTTL     = 10 * time.Minute
+)
+
+type excerptEntry struct {
+	text string
+	at   time.Time
+}
+
+type logPathEntry struct {
+	paths []string
+	at    time.Time
+}
+
+var (
+	excerptLock  sync.Mutex
+	excerptCache = make(map[string]excerptEntry)
+	logPathCache = make(map[string]logPathEntry)
+)
+
+// LogExcerpt returns the most recent log lines of the jail's log files that
+// mention ip, newest first. Results are cached for a few minutes so repeated
+// bans and alert retries do not rescan the logs.
+func LogExcerpt(ctx context.Context, jail, ip string) (string, error) {
+	if err := ValidateJailName(jail); err != nil {
+		return "", err
+	}
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return "", err
+	}
+
+	key := jail + "|" + ip
+	excerptLock.Lock()
+	if e, ok := excerptCache[key]; ok && time.Since(e.at) < excerptCacheTTL {
+		excerptLock.Unlock()
+		return e.text, nil
+	}
+	excerptLock.Unlock()
+
+	paths, err := jailLogPaths(ctx, jail)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, path := range paths {
+		found, err := grepTail(ctx, path, ip, excerptMaxLines-len(lines))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, found...)
+		if len(lines) >= excerptMaxLines {
+			break
+		}
+	}
+	text := TruncateExcerpt(strings.Join(lines, "\n"))
+
+	excerptLock.Lock()
+	defer excerptLock.Unlock()
+	if len(excerptCache) >= excerptCacheEntries {
+		evictOldestExcerpt()
+	}
+	excerptCache[key] = excerptEntry{text: text, at: time.Now()}
+	return text, nil
+}
+
+// TruncateExcerpt cuts a log excerpt to the excerpt size limit at a line boundary.
+func TruncateExcerpt(text string) string {
+	if len(text) <= excerptMaxBytes {
+		return text
+	}
+	cut := strings.LastIndexByte(text[:excerptMaxBytes], '\n')
+	if cut <= 0 {
+		cut = excerptMaxBytes
+	}
+	return text[:cut] + "\n[... truncated]"
+}
+
+// evictOldestExcerpt drops the oldest cache entry. The caller must hold excerptLock.
+func evictOldestExcerpt() {
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range excerptCache {
+		if oldestKey == "" || e.at.Before(oldest) {
+			oldestKey, oldest = k, e.at
+		}
+	}
+	delete(excerptCache, oldestKey)
+}
+
+// jailLogPaths returns the log files monitored by a jail, as reported by the daemon.
+// Jails using the systemd backend have none.
+func jailLogPaths(ctx context.Context, jail string) ([]string, error) {
+	excerptLock.Lock()
+	if e, ok := logPathCache[jail]; ok && time.Since(e.at) < logPathCacheTTL {
+		excerptLock.Unlock()
+		return e.paths, nil
+	}
+	excerptLock.Unlock()
+
+	value, err := getJailParam(ctx, jail, "logpath")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log paths of jail %s: %w", jail, err)
+	}
+	var paths []string
+	for _, p := range strings.Split(value, ", ") {
+		if strings.HasPrefix(p, "/") {
+			paths = append(paths, p)
+		}
+	}
+
+	excerptLock.Lock()
+	logPathCache[jail] = logPathEntry{paths: paths, at: time.Now()}
+	excerptLock.Unlock()
+	return paths, nil
+}
+
+// grepTail returns up to max lines from the end of path that contain ip as a
+// whole word, newest first. Missing files are skipped.
+func grepTail(ctx context.Context, path, ip string, max int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, &StateError{State: StateLogUnreadable, Err: err}
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	start := info.Size() - excerptScanBytes
+	if start < 0 {
+		start = 0
+	}
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReaderSize(file, maxLogLineSize)
+	if start > 0 {
+		// The first line is most likely cut off.
+		if _, err := skipLine(reader); err != nil {
+			return nil, nil
+		}
+	}
+
+	needle := []byte(ip)
+	var matches []string
+	for n := 0; ; n++ {
+		if n%1024 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		line, _, err := readBoundedLine(reader)
+		if line != "" && containsWord([]byte(line), needle) {
+			matches = append(matches, line)
+			if len(matches) > max {
+				matches = matches[1:]
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, nil
+}
+
+// containsWord reports whether word occurs in line delimited by non-word
+// characters, like grep -w. This keeps 10.0.0.1 from matching 10.0.0.12.
+func containsWord(line, word []byte) bool {
+	for offset := 0; ; {
+		i := bytes.Index(line[offset:], word)
+		if i < 0 {
+			return false
+		}
+		i += offset
+		end := i + len(word)
+		if (i == 0 || !isWordChar(line[i-1])) && (end == len(line) || !isWordChar(line[end])) {
+			return true
+		}
+		offset = i + 1
+	}
+}
+
+func isWordChar(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
@@ -0,0 +1,228 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// LogLevels are the log levels of the fail2ban server, most severe first.
+var LogLevels = []string{"CRITICAL", "ERROR", "WARNING", "NOTICE", "INFO", "DEBUG", "TRACEDEBUG", "HEAVYDEBUG"}
+
+// logTargets are the log targets other than a file.
+var logTargets = []string{"STDOUT", "STDERR", "SYSLOG", "SYSOUT", "SYSTEMD-JOURNAL"}
+
+// MaxLoggingRevert is the longest time a temporary logging change may last.
+const MaxLoggingRevert = 24 * time.Hour
+
+// LoggingInfo describes the log level and target of the fail2ban server:
+// the ones it uses now and the ones configured in fail2ban.conf and
+// fail2ban.local, which apply again when it restarts.
+type LoggingInfo struct {
+	Level            string `json:"level"`
+	Target           string `json:"target"`
+	ConfiguredLevel  string `json:"configuredLevel"`
+	ConfiguredTarget string `json:"configuredTarget"`
+	// RevertAt is when a temporary change is undone.
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+	// RevertLevel and RevertTarget are restored then.
+	RevertLevel  string `json:"revertLevel,omitempty"`
+	RevertTarget string `json:"revertTarget,omitempty"`
+}
+
+// loggingRevert is the pending undo of a temporary logging change. It
+// only lives in memory: if fail2ban-ui stops before, the temporary values
+// stay until fail2ban restarts and reads its configuration again.
+var loggingRevert struct {
+	sync.Mutex
+	timer         *time.Timer
+	at            time.Time
+	level, target string
+}
+
+// GetLogging asks fail2ban for its log level and target and reads the
+// configured ones.
+func GetLogging(ctx context.Context) (LoggingInfo, error) {
+	var info LoggingInfo
+	options, err := readSectionOptions(fail2banConfigFiles(), "Definition")
+	if err == nil {
+		info.ConfiguredLevel = strings.ToUpper(options["loglevel"])
+		info.ConfiguredTarget = options["logtarget"]
+	}
+	if info.Level, info.Target, err = runningLogging(ctx); err != nil {
+		return info, err
+	}
+	loggingRevert.Lock()
+	if loggingRevert.timer != nil {
+		at := loggingRevert.at
+		info.RevertAt = &at
+		info.RevertLevel, info.RevertTarget = loggingRevert.level, loggingRevert.target
+	}
+	loggingRevert.Unlock()
+	return info, nil
+}
+
+// SetLogging changes the log level and target of the fail2ban server;
+// empty values are kept. Without revertAfter the change is also written
+// to fail2ban.local. With revertAfter it only applies to the running
+// server and is undone after that time, e.g. to debug a filter for a
+// while; changing again meanwhile keeps the values to return to. A
+// persistent change ends a temporary one, restoring what it leaves alone.
+func SetLogging(ctx context.Context, level, target string, revertAfter time.Duration) error {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	target = strings.TrimSpace(target)
+	if err := ValidateLogging(level, target); err != nil {
+		return err
+	}
+	if revertAfter < 0 || revertAfter > MaxLoggingRevert {
+		return fmt.Errorf("a temporary change may last at most %s", MaxLoggingRevert)
+	}
+	loggingRevert.Lock()
+	defer loggingRevert.Unlock()
+
+	var previousLevel, previousTarget string
+	if revertAfter > 0 && loggingRevert.timer == nil {
+		var err error
+		if previousLevel, previousTarget, err = runningLogging(ctx); err != nil {
+			return err
+		}
+	}
+	applyLevel, applyTarget := level, target
+	if revertAfter == 0 && loggingRevert.timer != nil {
+		applyLevel, applyTarget = cmp.Or(level, loggingRevert.level), cmp.Or(target, loggingRevert.target)
+	}
+	if err := applyLogging(ctx, applyLevel, applyTarget); err != nil {
+		return err
+	}
+
+	if revertAfter == 0 {
+		if loggingRevert.timer != nil {
+			loggingRevert.timer.Stop()
+			loggingRevert.timer = nil
+		}
+		values := make(map[string]string, 2)
+		if level != "" {
+			values["loglevel"] = level
+		}
+		if target != "" {
+			values["logtarget"] = target
+		}
+		return config.UpdateFail2banLocal(values)
+	}
+	if loggingRevert.timer != nil {
+		loggingRevert.timer.Stop()
+	} else {
+		loggingRevert.level, loggingRevert.target = previousLevel, previousTarget
+	}
+	loggingRevert.at = time.Now().Add(revertAfter)
+	loggingRevert.timer = time.AfterFunc(revertAfter, revertLogging)
+	return nil
+}
+
+// revertLogging restores the log level and target of before a temporary change.
+func revertLogging() {
+	loggingRevert.Lock()
+	defer loggingRevert.Unlock()
+	if loggingRevert.timer == nil {
+		return
+	}
+	loggingRevert.timer = nil
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := applyLogging(ctx, loggingRevert.level, loggingRevert.target); err != nil {
+		log.Printf("⚠️ Could not revert the fail2ban log level to %s: %v", loggingRevert.level, err)
+		return
+	}
+	log.Printf("📝 Reverted the fail2ban log level to %s and the target to %s", loggingRevert.level, loggingRevert.target)
+}
+
+// runningLogging asks the server for its log level and target.
+func runningLogging(ctx context.Context) (level, target string, err error) {
+	if level, err = getServerOption(ctx, "loglevel"); err != nil {
+		return "", "", err
+	}
+	if target, err = getServerOption(ctx, "logtarget"); err != nil {
+		return "", "", err
+	}
+	return strings.ToUpper(quotedValue(level)), target, nil
+}
+
+// applyLogging sets the non-empty values on the running server.
+func applyLogging(ctx context.Context, level, target string) error {
+	if target != "" {
+		if out, err := clientCommand(ctx, "set", "logtarget", target); err != nil {
+			return fmt.Errorf("error setting logtarget: %w%s", err, outputSuffix(out))
+		}
+	}
+	if level != "" {
+		if out, err := clientCommand(ctx, "set", "loglevel", level); err != nil {
+			return fmt.Errorf("error setting loglevel: %w%s", err, outputSuffix(out))
+		}
+	}
+	return nil
+}
+
+// ValidateLogging checks a log level and target; empty values are allowed.
+// Targets are STDOUT, STDERR, SYSLOG, SYSOUT, SYSTEMD-JOURNAL or the
+// absolute path of a log file.
+func ValidateLogging(level, target string) error {
+	if level != "" && !slices.Contains(LogLevels, strings.ToUpper(level)) {
+		return fmt.Errorf("invalid log level %q, use one of %s", level, strings.Join(LogLevels, ", "))
+	}
+	if target == "" || slices.Contains(logTargets, strings.ToUpper(target)) {
+		return nil
+	}
+	if !filepath.IsAbs(target) || filepath.Clean(target) != target || strings.ContainsAny(target, " \t\r\n") {
+		return fmt.Errorf("invalid log target %q, use %s or the absolute path of a file", target, strings.Join(logTargets, ", "))
+	}
+	return nil
+}
+
+// quotedValue returns the part of s in single quotes, as in fail2ban-client's
+// "Current logging level is 'INFO'", or s.
+func quotedValue(s string) string {
+	if _, rest, ok := strings.Cut(s, "'"); ok {
+		if v, _, ok := strings.Cut(rest, "'"); ok {
+			return v
+		}
+	}
+	return s
+}
+
+// fail2banConfigFiles returns the configuration files of the fail2ban
+// server in the order they are read.
+func fail2banConfigFiles() []string {
+	files := []string{"/etc/fail2ban/fail2ban.conf"}
+	confs, _ := filepath.Glob("/etc/fail2ban/fail2ban.d/*.conf")
+	sort.Strings(confs)
+	files = append(files, confs...)
+	files = append(files, "/etc/fail2ban/fail2ban.local")
+	locals, _ := filepath.Glob("/etc/fail2ban/fail2ban.d/*.local")
+	sort.Strings(locals)
+	return append(files, locals...)
+}
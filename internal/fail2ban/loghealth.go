@@ -0,0 +1,163 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// States of a monitored log file, see LogFileHealth.
+const (
+	LogFileOK         = "ok"
+	LogFileStale      = "stale"      // no new lines for longer than the configured time
+	LogFileMissing    = "missing"    // the file does not exist
+	LogFileUnreadable = "unreadable" // the file cannot be opened, e.g. permission denied
+)
+
+// LogFileHealth is the state of a log file monitored by a jail.
+type LogFileHealth struct {
+	Jail    string    `json:"jail"`
+	Path    string    `json:"path"`
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	ModTime time.Time `json:"modTime,omitempty"`
+	Size    int64     `json:"size"`
+	// Since is when the current problem was first detected.
+	Since time.Time `json:"since,omitempty"`
+}
+
+// LogHealthReport is the result of the last log health check.
+type LogHealthReport struct {
+	CheckedAt time.Time       `json:"checkedAt"`
+	Files     []LogFileHealth `json:"files"`
+	Error     string          `json:"error,omitempty"`
+}
+
+var (
+	logHealthLock   sync.RWMutex
+	logHealth       LogHealthReport
+	logHealthHooks  []func([]LogFileHealth)
+	logProblemSince = make(map[string]time.Time) // by jail + path
+)
+
+// OnLogProblems registers fn to be called with the log files that turned
+// unhealthy during a check. Files that stay unhealthy are reported once.
+func OnLogProblems(fn func([]LogFileHealth)) {
+	logHealthLock.Lock()
+	defer logHealthLock.Unlock()
+	logHealthHooks = append(logHealthHooks, fn)
+}
+
+// GetLogHealth returns the result of the last log health check.
+func GetLogHealth() LogHealthReport {
+	logHealthLock.RLock()
+	defer logHealthLock.RUnlock()
+	return logHealth
+}
+
+// CheckLogHealth checks the log files of all running jails for files that
+// are missing, unreadable or have not been written to for staleAfter.
+// Jails using the systemd backend have no log files and are skipped.
+func CheckLogHealth(staleAfter time.Duration) error {
+	ctx := context.Background()
+	jails, err := GetJails(ctx)
+	if err != nil {
+		logHealthLock.Lock()
+		logHealth.CheckedAt = time.Now()
+		logHealth.Error = err.Error()
+		logHealthLock.Unlock()
+		return err
+	}
+
+	now := time.Now()
+	files := []LogFileHealth{}
+	for _, jail := range jails {
+		if jail == "" {
+			continue
+		}
+		paths, err := jailLogPaths(ctx, jail)
+		if err != nil {
+			continue
+		}
+		for _, path := range paths {
+			files = append(files, checkLogFile(jail, path, staleAfter, now))
+		}
+	}
+
+	logHealthLock.Lock()
+	var turned []LogFileHealth
+	seen := make(map[string]bool, len(files))
+	for i, f := range files {
+		key := f.Jail + "\x00" + f.Path
+		if f.Status == LogFileOK {
+			continue
+		}
+		seen[key] = true
+		since, known := logProblemSince[key]
+		if !known {
+			since = now
+			logProblemSince[key] = now
+			turned = append(turned, f)
+		}
+		files[i].Since = since
+	}
+	for key := range logProblemSince {
+		if !seen[key] {
+			delete(logProblemSince, key)
+		}
+	}
+	logHealth = LogHealthReport{CheckedAt: now, Files: files}
+	hooks := logHealthHooks
+	logHealthLock.Unlock()
+
+	if len(turned) > 0 {
+		for _, fn := range hooks {
+			fn(turned)
+		}
+	}
+	return nil
+}
+
+// checkLogFile returns the state of a single log file.
+func checkLogFile(jail, path string, staleAfter time.Duration, now time.Time) LogFileHealth {
+	h := LogFileHealth{Jail: jail, Path: path, Status: LogFileOK}
+	fi, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		h.Status = LogFileMissing
+		return h
+	}
+	if err != nil {
+		h.Status, h.Error = LogFileUnreadable, err.Error()
+		return h
+	}
+	h.ModTime, h.Size = fi.ModTime(), fi.Size()
+	file, err := os.Open(path)
+	if err != nil {
+		h.Status, h.Error = LogFileUnreadable, err.Error()
+		return h
+	}
+	file.Close()
+	if now.Sub(fi.ModTime()) > staleAfter {
+		h.Status = LogFileStale
+	}
+	return h
+}
@@ -18,69 +18,277 @@ package fail2ban
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultLogPath is the fail2ban log file read by the UI.
+const DefaultLogPath = "/var/log/fail2ban.log"
+
+const (
+	// maxLogLineSize bounds the memory used per log line. Longer lines are truncated.
+	maxLogLineSize = 64 * 1024
+	// minChunkSize is the smallest byte range handed to a single parser worker.
+	minChunkSize = 4 * 1024 * 1024
+)
+
 var (
 	// Typical fail2ban log line:
 	//  2023-01-20 10:15:30,123 fail2ban.actions [1234]: NOTICE  [sshd] Ban 192.168.0.101
 	logRegex = regexp.MustCompile(`^(\S+\s+\S+) fail2ban\.actions.*?\[\d+\]: NOTICE\s+\[(\S+)\]\s+Ban\s+(\S+)`)
+	// Like logRegex, but also matching "Restore Ban" after a restart and "Unban".
+	actionRegex = regexp.MustCompile(`^(\S+\s+\S+) fail2ban\.actions.*?\[\d+\]: NOTICE\s+\[(\S+)\]\s+(Ban|Restore Ban|Unban)\s+(\S+)`)
+)
+
+// Actions of an ActionEvent
+const (
+	ActionBan     = "ban"
+	ActionRestore = "restore" // ban restored from fail2ban's database after a restart
+	ActionUnban   = "unban"
 )
 
+// ActionEvent is a ban, restored ban or unban read from the fail2ban log.
+type ActionEvent struct {
+	Time   time.Time
+	Action string
+	Jail   string
+	IP     string
+}
+
+// ParseActionLine parses a Ban, Restore Ban or Unban line of the fail2ban log.
+func ParseActionLine(line string) (ActionEvent, bool) {
+	m := actionRegex.FindStringSubmatch(line)
+	if m == nil {
+		return ActionEvent{}, false
+	}
+	t, err := time.Parse("2006-01-02 15:04:05,000", m[1])
+	if err != nil {
+		return ActionEvent{}, false
+	}
+	action := ActionBan
+	switch m[3] {
+	case "Restore Ban":
+		action = ActionRestore
+	case "Unban":
+		action = ActionUnban
+	}
+	return ActionEvent{Time: t, Action: action, Jail: m[2], IP: m[4]}, true
+}
+
 // BanEvent holds details about a ban
 type BanEvent struct {
 	Time    time.Time
 	Jail    string
 	IP      string
-	LogLine string
+	Country string
+	ASN     uint   `json:",omitempty"`
+	ASOrg   string `json:",omitempty"`
+	// Port and Interface of the attacked service, see Target.
+	Port      string `json:",omitempty"`
+	Interface string `json:",omitempty"`
+	LogLine   string
+	Demo      bool     `json:",omitempty"`
+	Watched   bool     `json:",omitempty"`
+	Tags      []string `json:",omitempty"`
+	Note      string   `json:",omitempty"` // operator note on the IP, see config.IPNote
+	// Incidents are the IDs of the incidents the ban belongs to, see config.Incident.
+	Incidents []string `json:",omitempty"`
+}
+
+// ParseOptions tunes how StreamBanLog reads a log file.
+type ParseOptions struct {
+	// Workers is the number of parallel chunk parsers. Values <= 1 parse sequentially.
+	Workers int
+	// Progress, if set, is called with the number of bytes processed so far and the file size.
+	Progress func(done, total int64)
 }
 
 // ParseBanLog returns a map[jailName]BanEvents and also the last 5 ban events overall.
 func ParseBanLog(logPath string) (map[string][]BanEvent, error) {
+	eventsByJail := make(map[string][]BanEvent)
+	err := StreamBanLog(logPath, ParseOptions{}, func(ev BanEvent) {
+		eventsByJail[ev.Jail] = append(eventsByJail[ev.Jail], ev)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventsByJail, nil
+}
+
+// StreamBanLog parses logPath with a bounded line buffer and calls fn for every ban event.
+// With more than one worker the file is split into newline-aligned chunks which are parsed
+// concurrently; fn is always called from a single goroutine, but events of different chunks
+// may arrive out of order.
+func StreamBanLog(logPath string, opts ParseOptions, fn func(BanEvent)) error {
+	file, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to open fail2ban log: %v", err)
+	}
+	info, err := file.Stat()
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to stat fail2ban log: %v", err)
+	}
+	total := info.Size()
+
+	workers := opts.Workers
+	if maxWorkers := int(total / minChunkSize); workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var done int64
+	report := func(n int64) {
+		if opts.Progress != nil {
+			opts.Progress(atomic.AddInt64(&done, n), total)
+		}
+	}
+
+	events := make(chan BanEvent, 1024)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	chunk := total / int64(workers)
+	for i := 0; i < workers; i++ {
+		start := int64(i) * chunk
+		end := start + chunk
+		if i == workers-1 {
+			end = total
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if err := parseChunk(logPath, start, end, events, report); err != nil {
+				errs <- err
+			}
+		}(start, end)
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+		close(errs)
+	}()
+
+	for ev := range events {
+		fn(ev)
+	}
+	return <-errs
+}
+
+// parseChunk parses all lines starting inside [start, end) of logPath.
+// A line that begins before start belongs to the previous chunk and is skipped.
+func parseChunk(logPath string, start, end int64, out chan<- BanEvent, report func(int64)) error {
 	file, err := os.Open(logPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open fail2ban log: %v", err)
+		return fmt.Errorf("failed to open fail2ban log: %v", err)
 	}
 	defer file.Close()
 
-	eventsByJail := make(map[string][]BanEvent)
+	pos := start
+	if start > 0 {
+		// Step back one byte so a chunk starting exactly at a line boundary keeps that line.
+		pos = start - 1
+	}
+	if _, err := file.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(file, maxLogLineSize)
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		matches := logRegex.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			// matches[1] -> "2023-01-20 10:15:30,123"
-			// matches[2] -> jail name, e.g. "sshd"
-			// matches[3] -> IP, e.g. "192.168.0.101"
-			timestampStr := matches[1]
-			jail := matches[2]
-			ip := matches[3]
-
-			// parse "2023-01-20 10:15:30,123" -> time.Time
-			parsedTime, err := time.Parse("2006-01-02 15:04:05,000", timestampStr)
-			if err != nil {
-				// If parse fails, skip or set parsedTime=zero
-				continue
+	if start > 0 {
+		n, err := skipLine(reader)
+		pos += n
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
 			}
+			return err
+		}
+	}
 
-			ev := BanEvent{
-				Time:    parsedTime,
-				Jail:    jail,
-				IP:      ip,
-				LogLine: line,
+	reported := start
+	for pos < end {
+		line, n, err := readBoundedLine(reader)
+		pos += n
+		if len(line) > 0 {
+			if ev, ok := parseBanLine(line); ok {
+				out <- ev
 			}
+		}
+		if pos-reported >= minChunkSize/4 {
+			report(pos - reported)
+			reported = pos
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+	}
+	if end > reported {
+		report(end - reported)
+	}
+	return nil
+}
+
+// readBoundedLine reads the next line without its newline. Lines longer than
+// maxLogLineSize are truncated; the remainder is consumed and discarded.
+func readBoundedLine(r *bufio.Reader) (string, int64, error) {
+	data, err := r.ReadSlice('\n')
+	n := int64(len(data))
+	line := string(trimEOL(data))
+	for errors.Is(err, bufio.ErrBufferFull) {
+		data, err = r.ReadSlice('\n')
+		n += int64(len(data))
+	}
+	return line, n, err
+}
 
-			eventsByJail[jail] = append(eventsByJail[jail], ev)
+// skipLine discards input up to and including the next newline.
+func skipLine(r *bufio.Reader) (int64, error) {
+	var n int64
+	for {
+		data, err := r.ReadSlice('\n')
+		n += int64(len(data))
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return n, err
 		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+func trimEOL(b []byte) []byte {
+	for len(b) > 0 && (b[len(b)-1] == '\n' || b[len(b)-1] == '\r') {
+		b = b[:len(b)-1]
 	}
-	return eventsByJail, nil
+	return b
+}
+
+// parseBanLine extracts a BanEvent from a single fail2ban log line.
+func parseBanLine(line string) (BanEvent, bool) {
+	matches := logRegex.FindStringSubmatch(line)
+	if len(matches) != 4 {
+		return BanEvent{}, false
+	}
+	// matches[1] -> "2023-01-20 10:15:30,123"
+	// matches[2] -> jail name, e.g. "sshd"
+	// matches[3] -> IP, e.g. "192.168.0.101"
+	parsedTime, err := time.Parse("2006-01-02 15:04:05,000", matches[1])
+	if err != nil {
+		return BanEvent{}, false
+	}
+	return BanEvent{
+		Time:    parsedTime,
+		Jail:    matches[2],
+		IP:      matches[3],
+		LogLine: line,
+	}, true
 }
@@ -0,0 +1,264 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// journalBanRegex matches ban messages in the journal, which have no timestamp prefix.
+var journalBanRegex = regexp.MustCompile(`NOTICE\s+\[(\S+)\]\s+Ban\s+(\S+)`)
+
+// banKey identifies a ban across log sources.
+type banKey struct {
+	jail, ip string
+	second   int64
+}
+
+// StreamBanSources calls fn for every ban event of all sources. A ban
+// logged by several sources (same jail, IP and second) is reported once.
+// Sources that cannot be read are skipped, their errors are returned
+// together after all other sources were read.
+func StreamBanSources(sources []config.LogSource, opts ParseOptions, fn func(BanEvent)) error {
+	var files []string
+	var units []string
+	for _, s := range sources {
+		switch s.Type {
+		case config.LogSourceFile:
+			matches, err := filepath.Glob(s.Path)
+			if err != nil || len(matches) == 0 {
+				matches = []string{s.Path} // report the missing file below
+			}
+			sort.Strings(matches)
+			files = append(files, matches...)
+		case config.LogSourceJournal:
+			units = append(units, s.Unit())
+		}
+	}
+
+	// A single file needs no deduplication, keep the memory for large logs.
+	emit := fn
+	if len(files)+len(units) > 1 {
+		seen := make(map[banKey]struct{})
+		emit = func(ev BanEvent) {
+			key := banKey{ev.Jail, ev.IP, ev.Time.Unix()}
+			if _, dup := seen[key]; dup {
+				return
+			}
+			seen[key] = struct{}{}
+			fn(ev)
+		}
+	}
+
+	var total int64
+	sizes := make(map[string]int64, len(files))
+	for _, f := range dedupStrings(files) {
+		if info, err := os.Stat(f); err == nil {
+			sizes[f] = info.Size()
+			total += info.Size()
+		}
+	}
+
+	var errs []error
+	var done int64
+	for _, f := range dedupStrings(files) {
+		var err error
+		if strings.HasSuffix(f, ".gz") {
+			err = streamGzipLog(f, emit)
+		} else {
+			base := done
+			err = StreamBanLog(f, ParseOptions{
+				Workers: opts.Workers,
+				Progress: func(n, _ int64) {
+					if opts.Progress != nil {
+						opts.Progress(base+n, total)
+					}
+				},
+			}, emit)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f, err))
+		}
+		done += sizes[f]
+		if opts.Progress != nil {
+			opts.Progress(done, total)
+		}
+	}
+	for _, unit := range dedupStrings(units) {
+		if err := streamJournal(unit, emit); err != nil {
+			errs = append(errs, fmt.Errorf("journal %s: %w", unit, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// streamGzipLog parses a compressed, rotated fail2ban log.
+func streamGzipLog(path string, fn func(BanEvent)) error {
+	return readLogLines(path, func(line string) {
+		if ev, ok := parseBanLine(line); ok {
+			fn(ev)
+		}
+	})
+}
+
+// readLogLines calls fn for every line of a log file, which is
+// decompressed if its name ends in .gz.
+func readLogLines(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := bufio.NewReaderSize(r, maxLogLineSize)
+	for {
+		line, _, err := readBoundedLine(reader)
+		if len(line) > 0 {
+			fn(line)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// journalTimeout bounds reading the journal of a unit.
+const journalTimeout = 5 * time.Minute
+
+// streamJournal parses the ban messages of a systemd unit from the journal.
+func streamJournal(unit string, fn func(BanEvent)) error {
+	return readJournal(unit, time.Time{}, func(line string) {
+		if ev, ok := parseJournalEntry(line); ok {
+			fn(ev)
+		}
+	})
+}
+
+// readJournal calls fn for every entry of a systemd unit in the journal
+// since the given time (all if zero), as a line of "journalctl -o json"
+// output.
+func readJournal(unit string, since time.Time, fn func(line string)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), journalTimeout)
+	defer cancel()
+	args := []string{"--no-pager", "-o", "json", "-u", unit}
+	if !since.IsZero() {
+		args = append(args, "--since", "@"+strconv.FormatInt(since.Unix(), 10))
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(out, maxLogLineSize)
+	for {
+		line, _, readErr := readBoundedLine(reader)
+		if line != "" {
+			fn(line)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	return cmd.Wait()
+}
+
+// parseJournalEntry parses a line of "journalctl -o json" output.
+func parseJournalEntry(line string) (BanEvent, bool) {
+	msg, wall, ok := journalMessage(line)
+	if !ok {
+		return BanEvent{}, false
+	}
+	matches := journalBanRegex.FindStringSubmatch(msg)
+	if matches == nil {
+		return BanEvent{}, false
+	}
+	return BanEvent{
+		Time:    wall,
+		Jail:    matches[1],
+		IP:      matches[2],
+		LogLine: msg,
+	}, true
+}
+
+// journalMessage returns the message and time of a line of "journalctl -o
+// json" output.
+func journalMessage(line string) (string, time.Time, bool) {
+	var entry struct {
+		Message  any    `json:"MESSAGE"` // a byte array for non UTF-8 messages
+		Realtime string `json:"__REALTIME_TIMESTAMP"`
+	}
+	if json.Unmarshal([]byte(line), &entry) != nil {
+		return "", time.Time{}, false
+	}
+	msg, ok := entry.Message.(string)
+	if !ok {
+		return "", time.Time{}, false
+	}
+	usec, err := strconv.ParseInt(entry.Realtime, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	// parseBanLine keeps the local wall clock time of the log file as UTC,
+	// do the same so bans logged to both sources are recognized.
+	local := time.UnixMicro(usec).In(time.Local)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	return msg, wall, true
+}
+
+func dedupStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,233 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	tailPollInterval = 500 * time.Millisecond
+	// tailBacklogBytes is how far from the end the backlog is searched.
+	tailBacklogBytes = 256 * 1024
+)
+
+// TailOptions select the lines passed on by TailLogs.
+type TailOptions struct {
+	Jail    string // only lines of the fail2ban log mentioning "[jail]"
+	IP      string // only lines containing the IP as a whole word
+	Backlog int    // matching lines from the end of the files sent first
+}
+
+// TailLine is a line of a followed log file.
+type TailLine struct {
+	Path string `json:"path"`
+	Line string `json:"line"`
+}
+
+// matches reports whether line passes the jail and IP filters.
+func (o TailOptions) matches(line string) bool {
+	if o.Jail != "" && !strings.Contains(line, "["+o.Jail+"]") {
+		return false
+	}
+	return o.IP == "" || containsWord([]byte(line), []byte(o.IP))
+}
+
+// JailLogPaths returns the log files monitored by a jail, as reported by
+// the daemon. Jails using the systemd backend have none.
+func JailLogPaths(ctx context.Context, jail string) ([]string, error) {
+	if err := ValidateJailName(jail); err != nil {
+		return nil, err
+	}
+	return jailLogPaths(ctx, jail)
+}
+
+// tailFile follows a single log file like tail -F: it is reopened when it
+// is rotated or truncated, and waited for while it is missing.
+type tailFile struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	pos     int64
+	partial []byte
+}
+
+// TailLogs follows the files in paths and calls fn for every line matching
+// opts until ctx is done or fn returns an error.
+func TailLogs(ctx context.Context, paths []string, opts TailOptions, fn func(TailLine) error) error {
+	files := make([]*tailFile, len(paths))
+	for i, path := range paths {
+		files[i] = &tailFile{path: path}
+		defer files[i].close()
+		backlog, err := files[i].open(opts)
+		if err != nil {
+			return err
+		}
+		for _, line := range backlog {
+			if err := fn(TailLine{Path: path, Line: line}); err != nil {
+				return err
+			}
+		}
+	}
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, f := range files {
+			if err := f.follow(opts, fn); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// open positions the file at its end and returns the last opts.Backlog
+// matching lines. A missing file is not an error, it is opened once it
+// appears.
+func (f *tailFile) open(opts TailOptions) ([]string, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, &StateError{State: StateLogUnreadable, Err: err}
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.file, f.info, f.pos = file, info, info.Size()
+	if opts.Backlog <= 0 {
+		_, err := file.Seek(f.pos, io.SeekStart)
+		f.reader = bufio.NewReaderSize(file, maxLogLineSize)
+		return nil, err
+	}
+
+	start := max(f.pos-tailBacklogBytes, 0)
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	f.reader = bufio.NewReaderSize(file, maxLogLineSize)
+	if start > 0 {
+		// The first line is most likely cut off.
+		n, _ := skipLine(f.reader)
+		start += n
+	}
+	var lines []string
+	for start < f.pos {
+		line, n, err := readBoundedLine(f.reader)
+		start += n
+		if line != "" && opts.matches(line) {
+			lines = append(lines, line)
+			if len(lines) > opts.Backlog {
+				lines = lines[1:]
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	// Continue right after the backlog, lines appended meanwhile follow.
+	f.pos = start
+	return lines, nil
+}
+
+// follow passes the lines appended since the last call to fn. After a
+// rotation the rest of the old file is read before the new one.
+func (f *tailFile) follow(opts TailOptions, fn func(TailLine) error) error {
+	if !f.rotated() {
+		return f.drain(opts, fn)
+	}
+	if err := f.drain(opts, fn); err != nil {
+		return err
+	}
+	f.close()
+	f.partial = f.partial[:0]
+	if _, err := f.open(TailOptions{}); err != nil || f.file == nil {
+		return err
+	}
+	// Read the new file from the beginning.
+	f.pos = 0
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f.reader.Reset(f.file)
+	return f.drain(opts, fn)
+}
+
+// drain passes the complete lines read from the open file to fn.
+func (f *tailFile) drain(opts TailOptions, fn func(TailLine) error) error {
+	if f.file == nil {
+		return nil
+	}
+	for {
+		data, err := f.reader.ReadSlice('\n')
+		f.pos += int64(len(data))
+		if len(f.partial) < maxLogLineSize {
+			f.partial = append(f.partial, data[:min(len(data), maxLogLineSize-len(f.partial))]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			// Keep an incomplete last line until the rest is written.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line := string(trimEOL(f.partial))
+		f.partial = f.partial[:0]
+		if line != "" && opts.matches(line) {
+			if err := fn(TailLine{Path: f.path, Line: line}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// rotated reports whether the path now refers to another file or the file
+// was truncated, or whether a missing file appeared.
+func (f *tailFile) rotated() bool {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false
+	}
+	if f.file == nil {
+		return true
+	}
+	return !os.SameFile(info, f.info) || info.Size() < f.pos
+}
+
+func (f *tailFile) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
@@ -0,0 +1,545 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The fail2ban daemon exchanges Python pickles over its command socket.
+// picklePack and pickleUnpack implement the subset needed for it: the
+// client sends a list of strings, the daemon answers with tuples, lists,
+// dicts, numbers, strings and the occasional object (IP addresses,
+// exceptions). Tuples and sets decode to []any, dicts to map[string]any
+// with the keys formatted by fmt, and objects to *pyObject.
+
+// pyObject is an instance of a Python class, e.g. an exception.
+type pyObject struct {
+	Module string
+	Name   string
+	Args   []any
+	State  any
+}
+
+// String returns the first string argument or the "_raw" attribute, which
+// is how fail2ban's IP addresses pickle, or "" if there is neither.
+func (o *pyObject) String() string {
+	for _, a := range o.Args {
+		if s, ok := a.(string); ok {
+			return s
+		}
+	}
+	state := o.State
+	if t, ok := state.([]any); ok && len(t) == 2 {
+		state = t[1] // (dict, slots)
+	}
+	if m, ok := state.(map[string]any); ok {
+		if s, ok := m["_raw"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// Error formats an exception raised by the daemon.
+func (o *pyObject) Error() string {
+	var args []string
+	for _, a := range o.Args {
+		args = append(args, fmt.Sprint(a))
+	}
+	return o.Name + ": " + strings.Join(args, ", ")
+}
+
+// pyList is a list or set while unpickling. Lists are mutable and may be
+// memoized before their items are appended, so they are kept by reference
+// until the end.
+type pyList struct{ items []any }
+
+// pyGlobal is a class reference on the unpickler stack.
+type pyGlobal struct{ module, name string }
+
+// pickleMark separates the items of MARK-based opcodes on the stack.
+type pickleMark struct{}
+
+var errPickle = errors.New("unsupported pickle data")
+
+// picklePack encodes args as a protocol 2 pickle of a list of strings.
+func picklePack(args []string) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0x80, 2, ']', '('})
+	for _, a := range args {
+		b.WriteByte('X')
+		binary.Write(&b, binary.LittleEndian, uint32(len(a)))
+		b.WriteString(a)
+	}
+	b.Write([]byte{'e', '.'})
+	return b.Bytes()
+}
+
+// pickleUnpack decodes a pickle of protocol 0 to 5.
+func pickleUnpack(data []byte) (any, error) {
+	u := unpickler{data: data, memo: make(map[int]any)}
+	return u.run()
+}
+
+type unpickler struct {
+	data  []byte
+	pos   int
+	stack []any
+	memo  map[int]any
+}
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || u.pos+n > len(u.data) {
+		return nil, fmt.Errorf("%w: truncated", errPickle)
+	}
+	b := u.data[u.pos : u.pos+n]
+	u.pos += n
+	return b, nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	i := bytes.IndexByte(u.data[u.pos:], '\n')
+	if i < 0 {
+		return "", fmt.Errorf("%w: truncated", errPickle)
+	}
+	line := string(u.data[u.pos : u.pos+i])
+	u.pos += i + 1
+	return line, nil
+}
+
+func (u *unpickler) readUint(n int) (int, error) {
+	b, err := u.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i := n - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	if v > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: length %d", errPickle, v)
+	}
+	return int(v), nil
+}
+
+func (u *unpickler) readString(lenBytes int) (string, error) {
+	n, err := u.readUint(lenBytes)
+	if err != nil {
+		return "", err
+	}
+	b, err := u.read(n)
+	return string(b), err
+}
+
+func (u *unpickler) push(v any) { u.stack = append(u.stack, v) }
+
+func (u *unpickler) pop() (any, error) {
+	if len(u.stack) == 0 {
+		return nil, fmt.Errorf("%w: stack underflow", errPickle)
+	}
+	v := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	return v, nil
+}
+
+func (u *unpickler) top() (any, error) {
+	if len(u.stack) == 0 {
+		return nil, fmt.Errorf("%w: stack underflow", errPickle)
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// popMark returns the items pushed since the last MARK.
+func (u *unpickler) popMark() ([]any, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(pickleMark); ok {
+			items := append([]any(nil), u.stack[i+1:]...)
+			u.stack = u.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: missing mark", errPickle)
+}
+
+func (u *unpickler) popN(n int) ([]any, error) {
+	if len(u.stack) < n {
+		return nil, fmt.Errorf("%w: stack underflow", errPickle)
+	}
+	items := append([]any(nil), u.stack[len(u.stack)-n:]...)
+	u.stack = u.stack[:len(u.stack)-n]
+	return items, nil
+}
+
+func dictKey(k any) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+func (u *unpickler) run() (any, error) {
+	for {
+		b, err := u.read(1)
+		if err != nil {
+			return nil, err
+		}
+		op := b[0]
+		switch op {
+		case 0x80: // PROTO
+			if _, err := u.read(1); err != nil {
+				return nil, err
+			}
+		case 0x95: // FRAME
+			if _, err := u.read(8); err != nil {
+				return nil, err
+			}
+		case '.': // STOP
+			v, err := u.pop()
+			return unwrapLists(v), err
+		case '(': // MARK
+			u.push(pickleMark{})
+		case ']', 0x8f: // EMPTY_LIST, EMPTY_SET
+			u.push(&pyList{})
+		case ')': // EMPTY_TUPLE
+			u.push([]any{})
+		case '}': // EMPTY_DICT
+			u.push(map[string]any{})
+		case 'N':
+			u.push(nil)
+		case 0x88:
+			u.push(true)
+		case 0x89:
+			u.push(false)
+		case 'J': // BININT
+			v, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int(int32(binary.LittleEndian.Uint32(v))))
+		case 'K': // BININT1
+			v, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int(v[0]))
+		case 'M': // BININT2
+			v, err := u.read(2)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int(binary.LittleEndian.Uint16(v)))
+		case 0x8a, 0x8b: // LONG1, LONG4
+			size := 1
+			if op == 0x8b {
+				size = 4
+			}
+			n, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			v, err := u.read(n)
+			if err != nil {
+				return nil, err
+			}
+			u.push(decodeLong(v))
+		case 'I', 'L': // INT, LONG
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			switch line = strings.TrimSuffix(line, "L"); line {
+			case "00":
+				u.push(false)
+			case "01":
+				u.push(true)
+			default:
+				v, err := strconv.Atoi(line)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %v", errPickle, err)
+				}
+				u.push(v)
+			}
+		case 'G': // BINFLOAT
+			v, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(v)))
+		case 'X', 'T', 'B': // BINUNICODE, BINSTRING, BINBYTES
+			s, err := u.readString(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 0x8c, 'U', 'C': // SHORT_BINUNICODE, SHORT_BINSTRING, SHORT_BINBYTES
+			s, err := u.readString(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 0x8d, 0x8e, 0x96: // BINUNICODE8, BINBYTES8, BYTEARRAY8
+			s, err := u.readString(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 'V': // UNICODE
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.ValidString(line) {
+				return nil, fmt.Errorf("%w: invalid unicode", errPickle)
+			}
+			u.push(line)
+		case 'a': // APPEND
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			list, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			l, ok := list.(*pyList)
+			if !ok {
+				return nil, fmt.Errorf("%w: append to %T", errPickle, list)
+			}
+			l.items = append(l.items, v)
+		case 'e', 0x90: // APPENDS, ADDITEMS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			list, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			l, ok := list.(*pyList)
+			if !ok {
+				return nil, fmt.Errorf("%w: append to %T", errPickle, list)
+			}
+			l.items = append(l.items, items...)
+		case 's', 'u': // SETITEM, SETITEMS
+			var items []any
+			if op == 's' {
+				items, err = u.popN(2)
+			} else {
+				items, err = u.popMark()
+			}
+			if err != nil {
+				return nil, err
+			}
+			dict, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			d, ok := dict.(map[string]any)
+			if !ok || len(items)%2 != 0 {
+				return nil, fmt.Errorf("%w: set item on %T", errPickle, dict)
+			}
+			for i := 0; i < len(items); i += 2 {
+				d[dictKey(items[i])] = items[i+1]
+			}
+		case 't', 0x91: // TUPLE, FROZENSET
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(items)
+		case 'l': // LIST
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pyList{items: items})
+		case 'd': // DICT
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if len(items)%2 != 0 {
+				return nil, fmt.Errorf("%w: odd dict items", errPickle)
+			}
+			d := make(map[string]any, len(items)/2)
+			for i := 0; i < len(items); i += 2 {
+				d[dictKey(items[i])] = items[i+1]
+			}
+			u.push(d)
+		case 0x85, 0x86, 0x87: // TUPLE1, TUPLE2, TUPLE3
+			items, err := u.popN(int(op - 0x84))
+			if err != nil {
+				return nil, err
+			}
+			u.push(items)
+		case 0x94: // MEMOIZE
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[len(u.memo)] = v
+		case 'q', 'r': // BINPUT, LONG_BINPUT
+			size := 1
+			if op == 'r' {
+				size = 4
+			}
+			idx, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[idx] = v
+		case 'p': // PUT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errPickle, err)
+			}
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[idx] = v
+		case 'h', 'j', 'g': // BINGET, LONG_BINGET, GET
+			var idx int
+			switch op {
+			case 'h':
+				idx, err = u.readUint(1)
+			case 'j':
+				idx, err = u.readUint(4)
+			default:
+				var line string
+				if line, err = u.readLine(); err == nil {
+					idx, err = strconv.Atoi(line)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			v, ok := u.memo[idx]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown memo %d", errPickle, idx)
+			}
+			u.push(v)
+		case 'c': // GLOBAL
+			module, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			name, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pyGlobal{module, name})
+		case 0x93: // STACK_GLOBAL
+			items, err := u.popN(2)
+			if err != nil {
+				return nil, err
+			}
+			module, _ := items[0].(string)
+			name, _ := items[1].(string)
+			u.push(&pyGlobal{module, name})
+		case 'R', 0x81, 0x92: // REDUCE, NEWOBJ, NEWOBJ_EX
+			n := 2
+			if op == 0x92 {
+				n = 3 // the keyword arguments are ignored
+			}
+			items, err := u.popN(n)
+			if err != nil {
+				return nil, err
+			}
+			cls, ok := items[0].(*pyGlobal)
+			if !ok {
+				return nil, fmt.Errorf("%w: call of %T", errPickle, items[0])
+			}
+			args, _ := items[1].([]any)
+			u.push(&pyObject{Module: cls.module, Name: cls.name, Args: args})
+		case 'b': // BUILD
+			state, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok := v.(*pyObject); ok {
+				obj.State = state
+			}
+		default:
+			return nil, fmt.Errorf("%w: opcode 0x%02x", errPickle, op)
+		}
+	}
+}
+
+// unwrapLists replaces the lists in v by their items.
+func unwrapLists(v any) any {
+	switch v := v.(type) {
+	case *pyList:
+		return unwrapLists(v.items)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = unwrapLists(item)
+		}
+		return out
+	case map[string]any:
+		for k, item := range v {
+			v[k] = unwrapLists(item)
+		}
+		return v
+	case *pyObject:
+		v.Args, _ = unwrapLists(v.Args).([]any)
+		v.State = unwrapLists(v.State)
+		return v
+	}
+	return v
+}
+
+// decodeLong decodes a little-endian two's complement integer.
+func decodeLong(b []byte) any {
+	if len(b) == 0 {
+		return 0
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	if v.IsInt64() {
+		return int(v.Int64())
+	}
+	return v.String()
+}
@@ -0,0 +1,180 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+)
+
+// maxClusterMembers is the number of IPs listed per cluster.
+const maxClusterMembers = 50
+
+// RelatedIP summarizes the bans of one IP in a cluster.
+type RelatedIP struct {
+	IP      string    `json:"ip"`
+	Bans    int       `json:"bans"`
+	Jails   []string  `json:"jails"`
+	LastBan time.Time `json:"lastBan"`
+	Country string    `json:"country,omitempty"`
+}
+
+// IPCluster groups the other banned IPs that share a network or an AS
+// with the investigated IP.
+type IPCluster struct {
+	Key     string      `json:"key"`             // "203.0.113.0/24" or "AS64500"
+	Label   string      `json:"label,omitempty"` // AS organisation
+	IPs     int         `json:"ips"`
+	Bans    int         `json:"bans"`
+	Members []RelatedIP `json:"members"` // most banned first, capped at maxClusterMembers
+}
+
+// RelatedIPs is the result of EventStore.Related.
+type RelatedIPs struct {
+	IP      string      `json:"ip"`
+	Subnets []IPCluster `json:"subnets"` // narrowest first
+	ASN     *IPCluster  `json:"asn,omitempty"`
+}
+
+// clusterPrefixes returns the networks of ip checked for related IPs,
+// narrowest first: /24 and /16 for IPv4, /64 and /48 for IPv6.
+func clusterPrefixes(ip net.IP) []*net.IPNet {
+	sizes, bits := []int{24, 16}, 32
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	} else {
+		sizes, bits = []int{64, 48}, 128
+	}
+	nets := make([]*net.IPNet, len(sizes))
+	for i, size := range sizes {
+		mask := net.CIDRMask(size, bits)
+		nets[i] = &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+	}
+	return nets
+}
+
+// Related returns the other banned IPs in the same subnets and the same AS
+// as ip, to help decide whether a range block is warranted. Only events
+// accepted by keep are considered.
+func (s *EventStore) Related(ip string, keep func(BanEvent) bool) (RelatedIPs, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return RelatedIPs{}, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	nets := clusterPrefixes(parsed)
+	asn, asOrg := s.asnOf(ip)
+
+	subnetIPs := make([]map[string]*RelatedIP, len(nets))
+	for i := range subnetIPs {
+		subnetIPs[i] = make(map[string]*RelatedIP)
+	}
+	asnIPs := make(map[string]*RelatedIP)
+
+	s.mu.RLock()
+	for other, idxs := range s.byIP {
+		otherIP := net.ParseIP(other)
+		if other == ip || otherIP == nil {
+			continue
+		}
+		for _, idx := range idxs {
+			ev := s.events[idx]
+			if !keep(ev) {
+				continue
+			}
+			for i, n := range nets {
+				if n.Contains(otherIP) {
+					addRelated(subnetIPs[i], ev)
+				}
+			}
+			if asn != 0 && ev.ASN == asn {
+				addRelated(asnIPs, ev)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	res := RelatedIPs{IP: ip}
+	for i, n := range nets {
+		res.Subnets = append(res.Subnets, newCluster(n.String(), "", subnetIPs[i]))
+	}
+	if asn != 0 {
+		c := newCluster(fmt.Sprintf("AS%d", asn), asOrg, asnIPs)
+		res.ASN = &c
+	}
+	return res, nil
+}
+
+// asnOf returns the AS of ip from its events, or from a GeoIP lookup if the
+// events carry none (e.g. no ASN database during the backfill).
+func (s *EventStore) asnOf(ip string) (uint, string) {
+	s.mu.RLock()
+	for _, idx := range s.byIP[ip] {
+		if ev := s.events[idx]; ev.ASN != 0 {
+			s.mu.RUnlock()
+			return ev.ASN, ev.ASOrg
+		}
+	}
+	s.mu.RUnlock()
+	if info, err := geoip.Lookup(ip); err == nil {
+		return info.ASN, info.ASOrg
+	}
+	return 0, ""
+}
+
+func addRelated(ips map[string]*RelatedIP, ev BanEvent) {
+	r, ok := ips[ev.IP]
+	if !ok {
+		r = &RelatedIP{IP: ev.IP}
+		ips[ev.IP] = r
+	}
+	r.Bans++
+	if ev.Time.After(r.LastBan) {
+		r.LastBan = ev.Time
+	}
+	if ev.Country != "" {
+		r.Country = ev.Country
+	}
+	for _, j := range r.Jails {
+		if j == ev.Jail {
+			return
+		}
+	}
+	r.Jails = append(r.Jails, ev.Jail)
+}
+
+func newCluster(key, label string, ips map[string]*RelatedIP) IPCluster {
+	c := IPCluster{Key: key, Label: label, IPs: len(ips), Members: []RelatedIP{}}
+	for _, r := range ips {
+		c.Bans += r.Bans
+		sort.Strings(r.Jails)
+		c.Members = append(c.Members, *r)
+	}
+	sort.Slice(c.Members, func(i, j int) bool {
+		if c.Members[i].Bans != c.Members[j].Bans {
+			return c.Members[i].Bans > c.Members[j].Bans
+		}
+		return c.Members[i].LastBan.After(c.Members[j].LastBan)
+	})
+	if len(c.Members) > maxClusterMembers {
+		c.Members = c.Members[:maxClusterMembers]
+	}
+	return c
+}
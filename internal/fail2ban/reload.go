@@ -0,0 +1,161 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// JailState is the ban count of a running jail at a point in time.
+type JailState struct {
+	Jail   string `json:"jail"`
+	Banned int    `json:"banned"`
+}
+
+// ReloadReport describes the effect of a reload or restart of fail2ban.
+type ReloadReport struct {
+	Action       string        `json:"action"`
+	Success      bool          `json:"success"`
+	DurationMs   int64         `json:"durationMs"`
+	Before       []JailState   `json:"before"`
+	After        []JailState   `json:"after"`
+	AddedJails   []string      `json:"addedJails"`
+	RemovedJails []string      `json:"removedJails"`
+	Warnings     []string      `json:"warnings"`
+	JailCheck    JailCheck     `json:"jailCheck"`
+	Lint         []LintFinding `json:"lint"` // configuration problems, helps to explain a failed reload
+	Output       string        `json:"output,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	// State is the backend state that caused a failure: config-error,
+	// or fail2ban-unreachable if the daemon did not answer.
+	State string `json:"state,omitempty"`
+}
+
+// restartSettleTimeout is how long we wait for fail2ban to answer after a restart.
+const restartSettleTimeout = 15 * time.Second
+
+// ReloadWithReport reloads fail2ban and reports the jail state before and after.
+func ReloadWithReport() ReloadReport {
+	return runWithReport("reload", reloadFail2ban)
+}
+
+// RestartWithReport restarts fail2ban and reports the jail state before and after.
+func RestartWithReport() ReloadReport {
+	return runWithReport("restart", restartFail2ban)
+}
+
+// runWithReport is not bound to a request either, see reloadFail2ban.
+func runWithReport(action string, fn func() (string, error)) ReloadReport {
+	report := ReloadReport{Action: action, Before: snapshotJails()}
+
+	start := time.Now()
+	out, err := fn()
+	if err == nil && action == "restart" {
+		waitForFail2ban(restartSettleTimeout)
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	report.Output = strings.TrimSpace(out)
+	report.Warnings = parseWarnings(out)
+	report.Success = err == nil
+	if err != nil {
+		report.Error = err.Error()
+		report.State = StateConfigError
+	}
+	// A failed restart may just be unsupported, e.g. in a container, so
+	// only reloads raise the config-error state.
+	if ErrorState(err) == StateUnreachable {
+		report.State = StateUnreachable
+	} else if err == nil || action == "reload" {
+		setCondition(StateConfigError, err)
+	}
+	report.After = snapshotJails()
+	report.AddedJails, report.RemovedJails = diffJails(report.Before, report.After)
+	if report.Success {
+		report.JailCheck = CheckJails(context.Background())
+	}
+	report.Lint = LintConfig().Findings
+	return report
+}
+
+// snapshotJails returns the running jails with their ban counts. Errors yield an empty list.
+func snapshotJails() []JailState {
+	states := []JailState{}
+	jails, err := GetJails(context.Background())
+	if err != nil {
+		return states
+	}
+	for _, jail := range jails {
+		if jail == "" {
+			continue
+		}
+		ips, err := GetBannedIPs(context.Background(), jail)
+		if err != nil {
+			continue
+		}
+		states = append(states, JailState{Jail: jail, Banned: len(ips)})
+	}
+	return states
+}
+
+// waitForFail2ban polls the fail2ban socket until it answers or timeout expires.
+func waitForFail2ban(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := GetJails(context.Background()); err == nil {
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// diffJails returns the jails only present after and only present before.
+func diffJails(before, after []JailState) (added, removed []string) {
+	seen := make(map[string]bool, len(before))
+	for _, j := range before {
+		seen[j.Jail] = true
+	}
+	now := make(map[string]bool, len(after))
+	added, removed = []string{}, []string{}
+	for _, j := range after {
+		now[j.Jail] = true
+		if !seen[j.Jail] {
+			added = append(added, j.Jail)
+		}
+	}
+	for _, j := range before {
+		if !now[j.Jail] {
+			removed = append(removed, j.Jail)
+		}
+	}
+	return added, removed
+}
+
+// parseWarnings extracts warning and error lines from fail2ban output.
+func parseWarnings(out string) []string {
+	warnings := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		if strings.Contains(upper, "WARNING") || strings.Contains(upper, "ERROR") {
+			warnings = append(warnings, line)
+		}
+	}
+	return warnings
+}
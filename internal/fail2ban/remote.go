@@ -0,0 +1,357 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// remoteTimeout bounds a single command on a remote host.
+const remoteTimeout = 30 * time.Second
+
+// remoteLogLines is how much of the end of a remote fail2ban log is read
+// for the recent bans.
+const remoteLogLines = 5000
+
+// Health states of remote hosts.
+const (
+	HostUnknown = "unknown" // not checked yet
+	HostOK      = "ok"
+	HostError   = "error"
+)
+
+// HostHealth is the result of the last connection check of a remote host.
+type HostHealth struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latencyMs,omitempty"`
+	CheckedAt time.Time `json:"checkedAt,omitempty"`
+}
+
+// sshDir holds the keys, known hosts and connection sockets of the remote
+// hosts, next to the settings file.
+const sshDir = "fail2ban-ui-ssh"
+
+var (
+	hostHealth     = make(map[string]HostHealth)
+	hostHealthLock sync.RWMutex
+
+	sshFilesLock sync.Mutex
+)
+
+// remoteRun runs a command on h over SSH and returns its combined output.
+// The arguments are quoted for the remote shell. Connections are shared
+// for a minute, so the several commands of a summary log in only once.
+func remoteRun(ctx context.Context, h config.RemoteHost, args ...string) ([]byte, error) {
+	host, port := h.HostPort()
+	command := shellQuote(args)
+	if h.Sudo {
+		command = "sudo -n " + command
+	}
+	dir, keyFile, knownHosts, err := sshFiles(h)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", h.Name, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ssh",
+		"-i", keyFile, "-p", port, "-l", h.Login(),
+		"-o", "BatchMode=yes", "-o", "IdentitiesOnly=yes",
+		"-o", "StrictHostKeyChecking=yes", "-o", "UpdateHostKeys=no",
+		"-o", "UserKnownHostsFile="+sshQuote(knownHosts), "-o", "GlobalKnownHostsFile=/dev/null",
+		"-o", "ConnectTimeout=10",
+		"-o", "ControlMaster=auto", "-o", "ControlPersist=60",
+		"-o", "ControlPath="+sshQuote(filepath.Join(dir, "%C")),
+		"--", host, command)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("ssh %s: %w: %s", h.Name, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// sshFiles writes the known hosts file of h, and its private key unless it
+// uses a key file, to the private directory of the SSH files and returns
+// their paths. Only the host keys of h are trusted.
+func sshFiles(h config.RemoteHost) (dir, keyFile, knownHosts string, err error) {
+	if h.ID == "" || h.ID != filepath.Base(h.ID) {
+		return "", "", "", fmt.Errorf("invalid host ID %q", h.ID)
+	}
+	keys, err := config.ParseHostKeys(h.HostKeys)
+	if err != nil {
+		return "", "", "", err
+	}
+	host, port := h.HostPort()
+	pattern := host
+	if port != "22" {
+		pattern = "[" + host + "]:" + port
+	}
+	var known strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&known, "%s %s\n", pattern, key)
+	}
+
+	sshFilesLock.Lock()
+	defer sshFilesLock.Unlock()
+	dir = config.DataPath(sshDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", "", err
+	}
+	// The directory may have been created with other permissions.
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", "", "", err
+	}
+	knownHosts = filepath.Join(dir, h.ID+".known_hosts")
+	if err := writePrivateFile(knownHosts, known.String()); err != nil {
+		return "", "", "", err
+	}
+	keyFile = h.KeyFile
+	if h.PrivateKey != "" {
+		keyFile = filepath.Join(dir, h.ID+".key")
+		if err := writePrivateFile(keyFile, strings.TrimSpace(h.PrivateKey)+"\n"); err != nil {
+			return "", "", "", err
+		}
+	}
+	return dir, keyFile, knownHosts, nil
+}
+
+// writePrivateFile writes content to path, readable only by the owner,
+// unless the file already has this content.
+func writePrivateFile(path, content string) error {
+	if old, err := os.ReadFile(path); err == nil && string(old) == content {
+		return nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// removeSSHFiles deletes the keys and known hosts of hosts not in known.
+func removeSSHFiles(known map[string]bool) {
+	sshFilesLock.Lock()
+	defer sshFilesLock.Unlock()
+	dir := config.DataPath(sshDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".key")
+		if !ok {
+			id, ok = strings.CutSuffix(e.Name(), ".known_hosts")
+		}
+		if ok && !known[id] {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// sshQuote quotes an ssh option value, which may contain spaces.
+func sshQuote(value string) string {
+	return `"` + value + `"`
+}
+
+// shellQuote joins args into a POSIX shell command line.
+func shellQuote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// CheckRemoteHost pings the fail2ban daemon of h and records the result,
+// unless ctx ended before the daemon answered.
+func CheckRemoteHost(ctx context.Context, h config.RemoteHost) HostHealth {
+	start := time.Now()
+	out, err := remoteRun(ctx, h, "fail2ban-client", "ping")
+	health := HostHealth{Status: HostOK, CheckedAt: time.Now(), LatencyMS: time.Since(start).Milliseconds()}
+	switch {
+	case err != nil:
+		health = HostHealth{Status: HostError, Error: err.Error(), CheckedAt: health.CheckedAt}
+	case !strings.Contains(string(out), "pong"):
+		health = HostHealth{Status: HostError, Error: "unexpected reply: " + strings.TrimSpace(string(out)), CheckedAt: health.CheckedAt}
+	}
+	if ctx.Err() != nil {
+		return health
+	}
+
+	hostHealthLock.Lock()
+	previous := hostHealth[h.ID]
+	hostHealth[h.ID] = health
+	hostHealthLock.Unlock()
+	if previous.Status != health.Status {
+		config.DebugLog("Remote host %s is %s %s (remote.go)", h.Name, health.Status, health.Error)
+	}
+	return health
+}
+
+// CheckRemoteHosts checks all hosts in parallel and forgets removed ones,
+// including their SSH files.
+func CheckRemoteHosts(hosts []config.RemoteHost) {
+	var wg sync.WaitGroup
+	known := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		known[h.ID] = true
+		wg.Add(1)
+		go func(h config.RemoteHost) {
+			defer wg.Done()
+			CheckRemoteHost(context.Background(), h)
+		}(h)
+	}
+	wg.Wait()
+
+	hostHealthLock.Lock()
+	for id := range hostHealth {
+		if !known[id] {
+			delete(hostHealth, id)
+		}
+	}
+	hostHealthLock.Unlock()
+	removeSSHFiles(known)
+}
+
+// GetHostHealth returns the last check result of the host with the given ID.
+func GetHostHealth(id string) HostHealth {
+	hostHealthLock.RLock()
+	defer hostHealthLock.RUnlock()
+	if health, ok := hostHealth[id]; ok {
+		return health
+	}
+	return HostHealth{Status: HostUnknown}
+}
+
+// RemoteJailInfos returns the jails of h with their banned IPs and
+// counters, like BuildJailInfos for the local host. The bans of the last
+// hour are counted in events, the recent bans of the host.
+func RemoteJailInfos(ctx context.Context, h config.RemoteHost, events []BanEvent) ([]JailInfo, error) {
+	out, err := remoteRun(ctx, h, "fail2ban-client", "status")
+	if err != nil {
+		return nil, err
+	}
+	oneHourAgo := time.Now().Add(-time.Hour)
+	var results []JailInfo
+	for _, jail := range parseJailList(string(out)) {
+		if ValidateJailName(jail) != nil {
+			continue
+		}
+		out, err := remoteRun(ctx, h, "fail2ban-client", "status", jail)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		status := parseJailStatus(string(out))
+		info := JailInfo{
+			JailName:        jail,
+			TotalBanned:     len(status.BannedIPs),
+			BannedIPs:       status.BannedIPs,
+			Enabled:         true,
+			BannedByFamily:  CountFamilies(status.BannedIPs),
+			CurrentlyFailed: status.CurrentlyFailed,
+			TotalFailed:     status.TotalFailed,
+		}
+		for _, ev := range events {
+			if ev.Jail == jail && ev.Time.After(oneHourAgo) {
+				info.NewInLastHour++
+				info.NewInLastHourByFamily.Add(ev.IP, 1)
+			}
+		}
+		results = append(results, info)
+	}
+	return results, nil
+}
+
+// RemoteBans returns the bans found at the end of the fail2ban log of h,
+// newest first.
+func RemoteBans(ctx context.Context, h config.RemoteHost) ([]BanEvent, error) {
+	out, err := remoteRun(ctx, h, "tail", "-n", strconv.Itoa(remoteLogLines), h.Log())
+	if err != nil {
+		return nil, err
+	}
+	var events []BanEvent
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		if ev, ok := parseBanLine(scanner.Text()); ok {
+			events = append(events, ev)
+		}
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// RemoteBanIP bans ip in jail on h.
+func RemoteBanIP(ctx context.Context, h config.RemoteHost, jail, ip string) error {
+	return remoteSet(ctx, h, jail, "banip", ip)
+}
+
+// RemoteUnbanIP unbans ip from jail on h.
+func RemoteUnbanIP(ctx context.Context, h config.RemoteHost, jail, ip string) error {
+	return remoteSet(ctx, h, jail, "unbanip", ip)
+}
+
+// RemoteUnbanAll unbans ip from all jails on h.
+func RemoteUnbanAll(ctx context.Context, h config.RemoteHost, ip string) error {
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	if _, err := remoteRun(ctx, h, "fail2ban-client", "unban", ip); err != nil {
+		return fmt.Errorf("error unbanning %s: %w", ip, err)
+	}
+	return nil
+}
+
+// RemoteReload reloads fail2ban on h. Like a local reload, it is not bound
+// to a request.
+func RemoteReload(h config.RemoteHost) error {
+	if _, err := remoteRun(context.Background(), h, "fail2ban-client", "reload"); err != nil {
+		return fmt.Errorf("fail2ban reload error: %w", err)
+	}
+	return nil
+}
+
+func remoteSet(ctx context.Context, h config.RemoteHost, jail, action, ip string) error {
+	if err := ValidateJailName(jail); err != nil {
+		return err
+	}
+	ip, err := NormalizeIP(ip)
+	if err != nil {
+		return err
+	}
+	if _, err := remoteRun(ctx, h, "fail2ban-client", "set", jail, action, ip); err != nil {
+		return fmt.Errorf("error running %s %s in jail %s: %w", action, ip, jail, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"fmt"
+	"time"
+)
+
+// sampleBans are representative bans shown in sample-data mode.
+// The addresses are taken from the documentation ranges (RFC 5737 / RFC 3849).
+var sampleBans = []struct {
+	jail    string
+	ip      string
+	country string
+	port    string
+	ago     time.Duration
+}{
+	{"sshd", "203.0.113.17", "CN", "22", 4 * time.Minute},
+	{"sshd", "198.51.100.42", "RU", "22", 23 * time.Minute},
+	{"nginx-http-auth", "192.0.2.101", "US", "80,443", 41 * time.Minute},
+	{"sshd", "2001:db8::1337", "DE", "22", 2 * time.Hour},
+	{"postfix-sasl", "203.0.113.200", "BR", "25,465,587", 5 * time.Hour},
+	{"nginx-http-auth", "198.51.100.7", "NL", "80,443", 9 * time.Hour},
+}
+
+// SampleJailInfos returns fake jails flagged as demo data.
+func SampleJailInfos() []JailInfo {
+	byJail := make(map[string][]string)
+	var order []string
+	recent := make(map[string]int)
+	recentByFamily := make(map[string]FamilyCounts)
+	for _, b := range sampleBans {
+		if _, ok := byJail[b.jail]; !ok {
+			order = append(order, b.jail)
+		}
+		byJail[b.jail] = append(byJail[b.jail], b.ip)
+		if b.ago < time.Hour {
+			recent[b.jail]++
+			fam := recentByFamily[b.jail]
+			fam.Add(b.ip, 1)
+			recentByFamily[b.jail] = fam
+		}
+	}
+
+	jails := make([]JailInfo, 0, len(order))
+	for _, name := range order {
+		jails = append(jails, JailInfo{
+			JailName:              name,
+			TotalBanned:           len(byJail[name]),
+			NewInLastHour:         recent[name],
+			BannedIPs:             byJail[name],
+			Enabled:               true,
+			Demo:                  true,
+			BannedByFamily:        CountFamilies(byJail[name]),
+			NewInLastHourByFamily: recentByFamily[name],
+			// Roughly maxretry failures per ban plus some pending ones.
+			CurrentlyFailed: recent[name] + 1,
+			TotalFailed:     len(byJail[name])*5 + 3,
+		})
+	}
+	return jails
+}
+
+// SampleBanEvents returns fake ban events flagged as demo data, newest first.
+func SampleBanEvents() []BanEvent {
+	now := time.Now()
+	events := make([]BanEvent, 0, len(sampleBans))
+	for _, b := range sampleBans {
+		t := now.Add(-b.ago)
+		events = append(events, BanEvent{
+			Time:    t,
+			Jail:    b.jail,
+			IP:      b.ip,
+			Country: b.country,
+			Port:    b.port,
+			LogLine: fmt.Sprintf("%s fail2ban.actions [1]: NOTICE  [%s] Ban %s", t.Format("2006-01-02 15:04:05,000"), b.jail, b.ip),
+			Demo:    true,
+		})
+	}
+	return events
+}
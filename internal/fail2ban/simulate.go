@@ -0,0 +1,319 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// MaxSimulationDays bounds the period a simulation replays.
+const MaxSimulationDays = 90
+
+var (
+	// Typical failure line of a filter:
+	//  2023-01-20 10:15:28,456 fail2ban.filter [1234]: INFO    [sshd] Found 192.168.0.101 - 2023-01-20 10:15:28
+	foundRegex = regexp.MustCompile(`^(\S+\s+\S+) fail2ban\.filter.*?\[\d+\]: INFO\s+\[(\S+)\]\s+Found\s+(\S+)`)
+	// journalFoundRegex matches failures in the journal, which have no timestamp prefix.
+	journalFoundRegex = regexp.MustCompile(`INFO\s+\[(\S+)\]\s+Found\s+(\S+)`)
+)
+
+// simulationLock runs one simulation at a time, each reads all log sources.
+var simulationLock sync.Mutex
+
+// ErrSimulationRunning is returned by Simulate while another simulation runs.
+var ErrSimulationRunning = errors.New("a simulation is already running")
+
+// SimulationParams are the jail settings to replay the logged failures
+// against. Empty values keep the jail's current setting.
+type SimulationParams struct {
+	Maxretry int    `json:"maxretry"`
+	Findtime string `json:"findtime"`
+	Bantime  string `json:"bantime"`
+}
+
+// Validate checks the values and their time abbreviations.
+func (p SimulationParams) Validate() error {
+	if p.Maxretry < 0 {
+		return errors.New("maxretry must not be negative")
+	}
+	if p.Findtime != "" {
+		if v, ok := parseTimeValue(p.Findtime); !ok || v <= 0 {
+			return fmt.Errorf("invalid findtime %q", p.Findtime)
+		}
+	}
+	if p.Bantime != "" {
+		if _, ok := parseTimeValue(p.Bantime); !ok {
+			return fmt.Errorf("invalid bantime %q", p.Bantime)
+		}
+	}
+	return nil
+}
+
+// SimulationOutcome is the result of replaying a jail's failures with one
+// set of settings. Times are in seconds, a negative bantime bans forever.
+type SimulationOutcome struct {
+	Maxretry int   `json:"maxretry"`
+	Findtime int64 `json:"findtime"`
+	Bantime  int64 `json:"bantime"`
+	Bans     int   `json:"bans"`
+	IPs      int   `json:"ips"`
+}
+
+// JailSimulation compares a jail's current and proposed settings.
+// ActualBans are the bans of the event store in the same period.
+type JailSimulation struct {
+	Jail       string            `json:"jail"`
+	Failures   int               `json:"failures"`
+	FailingIPs int               `json:"failingIps"`
+	ActualBans int               `json:"actualBans"`
+	Current    SimulationOutcome `json:"current"`
+	Proposed   SimulationOutcome `json:"proposed"`
+}
+
+// Simulation is the result of Simulate.
+type Simulation struct {
+	Since        time.Time        `json:"since"`
+	Sources      []string         `json:"sources"`
+	Jails        []JailSimulation `json:"jails"`
+	Failures     int              `json:"failures"`
+	ActualBans   int              `json:"actualBans"`
+	CurrentBans  int              `json:"currentBans"`
+	ProposedBans int              `json:"proposedBans"`
+	Errors       []string         `json:"errors,omitempty"`
+}
+
+// Simulate replays the failures ("Found" lines) logged since the given
+// time against the current settings of each jail and against params, and
+// reports how many bans either would have caused. Only onlyJail is
+// simulated if not empty, and only jails accepted by visible if not nil.
+//
+// The replay follows fail2ban's counting: an IP is banned once it failed
+// maxretry times within findtime, and its failures during the ban are
+// ignored. bantime.increment is not modelled. Failures of IPs the daemon
+// had already banned were never logged, so looser settings than the
+// current ones may be under-reported.
+func Simulate(ctx context.Context, sources []config.LogSource, since time.Time, onlyJail string, visible func(jail string) bool, params SimulationParams) (Simulation, error) {
+	if err := params.Validate(); err != nil {
+		return Simulation{}, err
+	}
+	if onlyJail != "" {
+		if err := ValidateJailName(onlyJail); err != nil {
+			return Simulation{}, err
+		}
+	}
+	if !simulationLock.TryLock() {
+		return Simulation{}, ErrSimulationRunning
+	}
+	defer simulationLock.Unlock()
+
+	sim := Simulation{Since: since, Jails: []JailSimulation{}}
+	for _, s := range sources {
+		sim.Sources = append(sim.Sources, s.String())
+	}
+	wanted := func(jail string) bool {
+		return (onlyJail == "" || jail == onlyJail) && (visible == nil || visible(jail))
+	}
+
+	failures := make(map[string]map[string][]int64)
+	err := streamFailures(sources, since, func(t time.Time, jail, ip string) {
+		if !wanted(jail) {
+			return
+		}
+		if failures[jail] == nil {
+			failures[jail] = make(map[string][]int64)
+		}
+		failures[jail][ip] = append(failures[jail][ip], t.Unix())
+	})
+	if err != nil {
+		sim.Errors = append(sim.Errors, err.Error())
+	}
+
+	actual := make(map[string]int)
+	for _, ev := range Events().Filter(func(ev BanEvent) bool { return !ev.Demo && !ev.Time.Before(since) && wanted(ev.Jail) }, math.MaxInt) {
+		actual[ev.Jail]++
+	}
+	jails := make([]string, 0, len(failures)+len(actual))
+	for jail := range failures {
+		jails = append(jails, jail)
+	}
+	for jail := range actual {
+		if failures[jail] == nil {
+			jails = append(jails, jail)
+		}
+	}
+	sort.Strings(jails)
+
+	configured := readJailOptions([]string{"maxretry", "findtime", "bantime"})
+	for _, jail := range jails {
+		if ctx.Err() != nil {
+			return sim, ctx.Err()
+		}
+		current := currentSimulationOutcome(ctx, jail, configured)
+		proposed := current
+		if params.Maxretry > 0 {
+			proposed.Maxretry = params.Maxretry
+		}
+		if v, ok := parseTimeValue(params.Findtime); ok {
+			proposed.Findtime = v
+		}
+		if v, ok := parseTimeValue(params.Bantime); ok {
+			proposed.Bantime = v
+		}
+
+		js := JailSimulation{Jail: jail, FailingIPs: len(failures[jail]), ActualBans: actual[jail], Current: current, Proposed: proposed}
+		for _, times := range failures[jail] {
+			sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+			js.Failures += len(times)
+			if n := replayFailures(times, current); n > 0 {
+				js.Current.Bans += n
+				js.Current.IPs++
+			}
+			if n := replayFailures(times, proposed); n > 0 {
+				js.Proposed.Bans += n
+				js.Proposed.IPs++
+			}
+		}
+		sim.Jails = append(sim.Jails, js)
+		sim.Failures += js.Failures
+		sim.ActualBans += js.ActualBans
+		sim.CurrentBans += js.Current.Bans
+		sim.ProposedBans += js.Proposed.Bans
+	}
+	return sim, nil
+}
+
+// currentSimulationOutcome resolves the settings a jail uses: the running
+// daemon's, else the jail's configured ones over [DEFAULT] over the
+// defaults of the UI settings.
+func currentSimulationOutcome(ctx context.Context, jail string, configured map[string]map[string]string) SimulationOutcome {
+	defaults := config.GetSettings().Fail2ban
+	value := func(option, fallback string) string {
+		if v, err := getJailParam(ctx, jail, option); err == nil && v != "" {
+			return v
+		}
+		return cmp.Or(configured[jail][option], configured["DEFAULT"][option], fallback)
+	}
+	out := SimulationOutcome{Maxretry: defaults.Maxretry}
+	if n, err := strconv.Atoi(value("maxretry", strconv.Itoa(defaults.Maxretry))); err == nil && n > 0 {
+		out.Maxretry = n
+	}
+	out.Findtime, _ = parseTimeValue(value("findtime", defaults.Findtime))
+	out.Bantime, _ = parseTimeValue(value("bantime", defaults.Bantime))
+	return out
+}
+
+// replayFailures counts the bans the failure times (sorted, in seconds)
+// of one IP cause with the given settings.
+func replayFailures(times []int64, o SimulationOutcome) int {
+	if o.Maxretry <= 0 {
+		return 0
+	}
+	bans := 0
+	var window []int64
+	bannedUntil := int64(-1 << 63)
+	for _, t := range times {
+		if o.Bantime < 0 && bans > 0 {
+			break // banned forever
+		}
+		if t < bannedUntil {
+			continue
+		}
+		window = append(window, t)
+		for len(window) > 0 && window[0] <= t-o.Findtime {
+			window = window[1:]
+		}
+		if len(window) >= o.Maxretry {
+			bans++
+			bannedUntil = t + o.Bantime
+			window = window[:0]
+		}
+	}
+	return bans
+}
+
+// streamFailures calls fn for every failure logged by the sources since
+// the given time. Rotated copies of a log file (e.g. fail2ban.log.1 or
+// fail2ban.log.2.gz) are read too, unless they were last written before.
+func streamFailures(sources []config.LogSource, since time.Time, fn func(t time.Time, jail, ip string)) error {
+	var files, units []string
+	for _, s := range sources {
+		switch s.Type {
+		case config.LogSourceFile:
+			pattern := s.Path
+			if !strings.ContainsAny(pattern, "*?[") {
+				pattern += "*"
+			}
+			matches, err := filepath.Glob(pattern)
+			if err != nil || len(matches) == 0 {
+				matches = []string{s.Path} // report the missing file below
+			}
+			sort.Strings(matches)
+			files = append(files, matches...)
+		case config.LogSourceJournal:
+			units = append(units, s.Unit())
+		}
+	}
+
+	var errs []error
+	for _, f := range dedupStrings(files) {
+		if info, err := os.Stat(f); err == nil && info.ModTime().Before(since) {
+			continue
+		}
+		err := readLogLines(f, func(line string) {
+			m := foundRegex.FindStringSubmatch(line)
+			if m == nil {
+				return
+			}
+			t, err := time.Parse("2006-01-02 15:04:05,000", m[1])
+			if err == nil && !t.Before(since) {
+				fn(t, m[2], m[3])
+			}
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f, err))
+		}
+	}
+	for _, unit := range dedupStrings(units) {
+		err := readJournal(unit, since, func(line string) {
+			msg, t, ok := journalMessage(line)
+			if !ok || t.Before(since) {
+				return
+			}
+			if m := journalFoundRegex.FindStringSubmatch(msg); m != nil {
+				fn(t, m[1], m[2])
+			}
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("journal %s: %w", unit, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// JailSnapshot is the status of one jail in a Snapshot. Error is set
+// instead of the counters when the jail could not be queried.
+type JailSnapshot struct {
+	Jail            string       `json:"jail"`
+	CurrentlyFailed int          `json:"currentlyFailed"`
+	TotalFailed     int          `json:"totalFailed"`
+	CurrentlyBanned int          `json:"currentlyBanned"`
+	BannedByFamily  FamilyCounts `json:"bannedByFamily"`
+	BannedIPs       []string     `json:"bannedIPs"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// Snapshot is the status of all jails, taken in one call.
+type Snapshot struct {
+	Jails    []JailSnapshot `json:"jails"`
+	TakenAt  time.Time      `json:"takenAt"`
+	Duration string         `json:"duration"`
+}
+
+// GetAllJailStatus returns the status of every running jail, like
+// "fail2ban-client status --all" on recent versions. The jails are queried
+// by a pool of workers as large as the configured number of parallel
+// fail2ban-client calls, so the lookups neither run one after the other
+// nor overflow the client queue. Jails that cannot be queried are reported
+// with their error; a busy client or the end of ctx fails the whole call,
+// since incomplete results would look like lifted bans.
+func GetAllJailStatus(ctx context.Context) (Snapshot, error) {
+	start := time.Now()
+	jails, err := GetJails(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]JailSnapshot, len(jails))
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for range min(config.GetSettings().Server.Limits.Parallel(), len(jails)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				status, err := GetJailStatus(ctx, jails[i])
+				if errors.Is(err, ErrClientBusy) {
+					cancel(err)
+				}
+				results[i] = jailSnapshot(jails[i], status, err)
+			}
+		}()
+	}
+feed:
+	for i := range jails {
+		select {
+		case idx <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(idx)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{
+		Jails:    results,
+		TakenAt:  start,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}, nil
+}
+
+// jailSnapshot builds the snapshot entry of a jail from its status.
+func jailSnapshot(jail string, status JailStatus, err error) JailSnapshot {
+	if err != nil {
+		return JailSnapshot{Jail: jail, BannedIPs: []string{}, Error: err.Error()}
+	}
+	banned := status.BannedIPs
+	if banned == nil {
+		banned = []string{}
+	}
+	return JailSnapshot{
+		Jail:            jail,
+		CurrentlyFailed: status.CurrentlyFailed,
+		TotalFailed:     status.TotalFailed,
+		CurrentlyBanned: len(banned),
+		BannedByFamily:  CountFamilies(banned),
+		BannedIPs:       banned,
+	}
+}
@@ -0,0 +1,207 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// Markers of fail2ban's socket protocol (fail2ban/protocol.py, CSPROTO).
+const (
+	socketEnd   = "<F2B_END_COMMAND>"
+	socketClose = "<F2B_CLOSE_COMMAND>"
+)
+
+// socketTimeout bounds a socket command without a context deadline.
+const socketTimeout = 30 * time.Second
+
+// errSocketUnavailable is returned when the command socket cannot be used
+// and the caller should run fail2ban-client instead.
+var errSocketUnavailable = errors.New("fail2ban socket unavailable")
+
+// socketUnsupported is set once a reply could not be decoded, so later
+// commands go straight to fail2ban-client.
+var socketUnsupported atomic.Bool
+
+// socketCommand runs a command over the daemon's command socket, like
+// fail2ban-client does, and returns the decoded reply. Errors raised by
+// the daemon are returned as *pyObject. It returns errSocketUnavailable
+// when the socket is disabled, missing or not accessible.
+func socketCommand(ctx context.Context, args ...string) (any, error) {
+	path := config.GetSettings().Fail2ban.SocketPath()
+	if path == "" || socketUnsupported.Load() {
+		return nil, errSocketUnavailable
+	}
+	if err := limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer limiter.release()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		config.DebugLog("Cannot use the fail2ban socket %s, running fail2ban-client: %v", path, err)
+		return nil, fmt.Errorf("%w: %v", errSocketUnavailable, err)
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(socketTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write(append(picklePack(args), socketEnd...)); err != nil {
+		return nil, fmt.Errorf("fail2ban socket: %w", err)
+	}
+	reply, err := readSocketReply(conn)
+	if err != nil {
+		return nil, fmt.Errorf("fail2ban socket: %w", err)
+	}
+	conn.Write([]byte(socketClose + socketEnd))
+
+	setCondition(StateUnreachable, nil)
+
+	v, err := pickleUnpack(reply)
+	if err != nil {
+		socketUnsupported.Store(true)
+		log.Printf("⚠️ Unsupported reply on the fail2ban socket, using fail2ban-client from now on: %v", err)
+		return nil, fmt.Errorf("%w: %v", errSocketUnavailable, err)
+	}
+	// The daemon replies (code, result); code 0 is success, otherwise
+	// result is the exception.
+	t, ok := v.([]any)
+	if !ok || len(t) != 2 {
+		return nil, fmt.Errorf("fail2ban socket: unexpected reply %v", v)
+	}
+	if code, _ := t[0].(int); code != 0 {
+		if exc, ok := t[1].(*pyObject); ok {
+			return nil, exc
+		}
+		return nil, fmt.Errorf("fail2ban: %v", t[1])
+	}
+	return t[1], nil
+}
+
+// readSocketReply reads until the end marker.
+func readSocketReply(conn net.Conn) ([]byte, error) {
+	var reply []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if bytes.HasSuffix(reply, []byte(socketEnd)) {
+			return reply[:len(reply)-len(socketEnd)], nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// clientCommand runs a command whose output is not needed, over the socket
+// if possible and with fail2ban-client otherwise. The output is returned
+// for error messages.
+func clientCommand(ctx context.Context, args ...string) ([]byte, error) {
+	_, err := socketCommand(ctx, args...)
+	if !errors.Is(err, errSocketUnavailable) {
+		return nil, err
+	}
+	return runClient(ctx, args...)
+}
+
+// statusFields turns the [(label, value), ...] lists of the status
+// commands into a map, descending into nested lists such as
+// [("Filter", [...]), ("Actions", [...])].
+func statusFields(v any, fields map[string]any) {
+	list, _ := v.([]any)
+	for _, item := range list {
+		pair, ok := item.([]any)
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		label, _ := pair[0].(string)
+		if nested, ok := pair[1].([]any); ok && len(nested) > 0 {
+			if first, ok := nested[0].([]any); ok && len(first) == 2 {
+				if _, ok := first[0].(string); ok {
+					statusFields(nested, fields)
+					continue
+				}
+			}
+		}
+		fields[label] = pair[1]
+	}
+}
+
+// socketString converts a value of a socket reply to text.
+func socketString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case *pyObject:
+		s := v.String()
+		return s, s != ""
+	}
+	return "", false
+}
+
+// socketJailList parses the reply to "status".
+func socketJailList(v any) ([]string, error) {
+	fields := make(map[string]any)
+	statusFields(v, fields)
+	raw, ok := fields["Jail list"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no jail list in %v", errSocketUnavailable, v)
+	}
+	var jails []string
+	for _, jail := range strings.Split(raw, ",") {
+		jails = append(jails, strings.TrimSpace(jail))
+	}
+	return jails, nil
+}
+
+// socketJailStatus parses the reply to "status <jail>".
+func socketJailStatus(v any) (JailStatus, error) {
+	fields := make(map[string]any)
+	statusFields(v, fields)
+	status := JailStatus{}
+	status.CurrentlyFailed, _ = fields["Currently failed"].(int)
+	status.TotalFailed, _ = fields["Total failed"].(int)
+	ips, _ := fields["Banned IP list"].([]any)
+	for _, item := range ips {
+		ip, ok := socketString(item)
+		if !ok {
+			return JailStatus{}, fmt.Errorf("%w: cannot read banned IP %v", errSocketUnavailable, item)
+		}
+		status.BannedIPs = append(status.BannedIPs, ip)
+	}
+	return status, nil
+}
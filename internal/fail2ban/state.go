@@ -0,0 +1,182 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Backend states, from the most to the least severe. The dashboard shows
+// the most severe active one; StateOK means that nothing is degraded.
+const (
+	StateUnreachable   = "fail2ban-unreachable" // the daemon does not answer
+	StateConfigError   = "config-error"         // the last reload failed or jails did not start
+	StateLogUnreadable = "log-unreadable"       // log files of running jails are missing or unreadable
+	StateReadOnly      = "read-only"            // the configuration directory cannot be written
+	StateOK            = "ok"
+)
+
+var stateOrder = []string{StateUnreachable, StateConfigError, StateLogUnreadable, StateReadOnly}
+
+// StateError is an error caused by a degraded backend, so handlers can
+// report the state instead of a plain failure.
+type StateError struct {
+	State string
+	Err   error
+}
+
+func (e *StateError) Error() string { return e.Err.Error() }
+func (e *StateError) Unwrap() error { return e.Err }
+
+// ErrorState returns the backend state err was caused by, or StateOK for
+// errors unrelated to the backend state.
+func ErrorState(err error) string {
+	var serr *StateError
+	switch {
+	case errors.As(err, &serr):
+		return serr.State
+	case errors.Is(err, fs.ErrPermission), errors.Is(err, syscall.EROFS):
+		return StateReadOnly
+	}
+	return StateOK
+}
+
+// StateCondition is an active problem of the backend.
+type StateCondition struct {
+	State   string    `json:"state"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since,omitempty"`
+}
+
+// BackendState is the overall state with all active conditions, the most
+// severe first.
+type BackendState struct {
+	State      string           `json:"state"`
+	Conditions []StateCondition `json:"conditions"`
+	CheckedAt  time.Time        `json:"checkedAt"`
+}
+
+var (
+	stateLock sync.Mutex
+	// observed holds the conditions noticed while talking to the daemon,
+	// by state. The others are derived from the last checks.
+	observed = make(map[string]StateCondition)
+)
+
+// setCondition records the outcome of an operation: a non-nil err raises
+// the condition, nil clears it.
+func setCondition(state string, err error) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	if err == nil {
+		delete(observed, state)
+		return
+	}
+	cond, ok := observed[state]
+	if !ok {
+		cond = StateCondition{State: state, Since: time.Now()}
+	}
+	cond.Message = strings.TrimSpace(err.Error())
+	observed[state] = cond
+}
+
+// unreachableOutput reports whether a failed fail2ban-client call means
+// that the daemon is not running or the client is not installed.
+func unreachableOutput(out []byte, err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+	s := string(out)
+	return strings.Contains(s, "Failed to access socket path") || strings.Contains(s, "Is fail2ban running?")
+}
+
+// GetState returns the current backend state.
+func GetState() BackendState {
+	now := time.Now()
+	stateLock.Lock()
+	conds := make([]StateCondition, 0, len(observed)+3)
+	for _, c := range observed {
+		conds = append(conds, c)
+	}
+	stateLock.Unlock()
+
+	if check := LastJailCheck(); check.Error == "" && len(check.FailedToStart) > 0 {
+		conds = append(conds, StateCondition{
+			State:   StateConfigError,
+			Message: "jails failed to start: " + strings.Join(check.FailedToStart, ", "),
+			Since:   check.CheckedAt,
+		})
+	}
+	if c, ok := logCondition(); ok {
+		conds = append(conds, c)
+	}
+	if err := checkWritable(ConfigRoot); err != nil {
+		conds = append(conds, StateCondition{State: StateReadOnly, Message: err.Error()})
+	}
+
+	slices.SortStableFunc(conds, func(a, b StateCondition) int {
+		return slices.Index(stateOrder, a.State) - slices.Index(stateOrder, b.State)
+	})
+	state := StateOK
+	if len(conds) > 0 {
+		state = conds[0].State
+	}
+	return BackendState{State: state, Conditions: conds, CheckedAt: now}
+}
+
+// logCondition summarizes the missing and unreadable files of the last
+// log health check. Stale files are not a degradation of the UI.
+func logCondition() (StateCondition, bool) {
+	var bad []string
+	var since time.Time
+	for _, f := range GetLogHealth().Files {
+		if f.Status != LogFileMissing && f.Status != LogFileUnreadable {
+			continue
+		}
+		bad = append(bad, f.Path+" ("+f.Status+")")
+		if since.IsZero() || f.Since.Before(since) {
+			since = f.Since
+		}
+	}
+	if len(bad) == 0 {
+		return StateCondition{}, false
+	}
+	return StateCondition{State: StateLogUnreadable, Message: strings.Join(bad, ", "), Since: since}, true
+}
+
+// checkWritable returns an error unless files can be created in dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".ui-write-check-*")
+	if err != nil {
+		var perr *fs.PathError
+		if errors.As(err, &perr) {
+			err = perr.Err
+		}
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
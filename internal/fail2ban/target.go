@@ -0,0 +1,176 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target is the local service a banned IP attacked. Port is the targeted
+// port, or the ports of the jail ("80,443") when the log lines do not
+// tell. Interface is the network interface, or the local address the
+// attack was aimed at when only that is logged.
+type Target struct {
+	Port      string `json:"port,omitempty"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// Patterns of log lines naming the local end of a connection.
+var (
+	netfilterIn   = regexp.MustCompile(`\bIN=(\S+)`)                         // kernel/iptables log
+	netfilterDst  = regexp.MustCompile(`\bDST=([0-9a-fA-F.:]+)`)             // kernel/iptables log
+	netfilterPort = regexp.MustCompile(`\bDPT=(\d+)`)                        // kernel/iptables log
+	listenerOn    = regexp.MustCompile(`\bon ([0-9a-fA-F.:]+) port (\d+)\b`) // sshd with LogLevel VERBOSE
+	localIP       = regexp.MustCompile(`\blip=([0-9a-fA-F.:]+)`)             // dovecot
+	localPort     = regexp.MustCompile(`\blport=(\d+)`)                      // dovecot
+)
+
+// ParseTarget extracts the targeted port and interface from matched log
+// lines. Fields not found in any line are left empty.
+func ParseTarget(lines string) Target {
+	var t Target
+	var addr string
+	for _, line := range strings.Split(lines, "\n") {
+		if t.Interface == "" {
+			if m := netfilterIn.FindStringSubmatch(line); m != nil {
+				t.Interface = m[1]
+			}
+		}
+		if addr == "" {
+			if m := netfilterDst.FindStringSubmatch(line); m != nil {
+				addr = m[1]
+			} else if m := localIP.FindStringSubmatch(line); m != nil {
+				addr = m[1]
+			}
+		}
+		if t.Port == "" {
+			if m := netfilterPort.FindStringSubmatch(line); m != nil {
+				t.Port = m[1]
+			} else if m := localPort.FindStringSubmatch(line); m != nil {
+				t.Port = m[1]
+			}
+		}
+		if m := listenerOn.FindStringSubmatch(line); m != nil {
+			if addr == "" {
+				addr = m[1]
+			}
+			if t.Port == "" {
+				t.Port = m[2]
+			}
+		}
+	}
+	if t.Interface == "" && net.ParseIP(addr) != nil {
+		t.Interface = addr
+	}
+	return t
+}
+
+// TargetFor returns the target of a ban in jail from its matched log
+// lines, falling back to the port setting of the jail.
+func TargetFor(jail, lines string) Target {
+	t := ParseTarget(lines)
+	if t.Port == "" {
+		t.Port = JailPort(jail)
+	}
+	return t
+}
+
+const jailPortCacheTTL = 10 * time.Minute
+
+var (
+	jailPortLock  sync.Mutex
+	jailPortCache map[string]string
+	jailPortAt    time.Time
+)
+
+func init() {
+	// The port settings may have changed with the configuration.
+	OnReload(func() {
+		jailPortLock.Lock()
+		jailPortCache = nil
+		jailPortLock.Unlock()
+	})
+}
+
+// JailPort returns the port setting of jail from the configuration files,
+// with service names resolved to numbers, e.g. "80,443" for "http,https".
+func JailPort(jail string) string {
+	jailPortLock.Lock()
+	defer jailPortLock.Unlock()
+	if jailPortCache == nil || time.Since(jailPortAt) > jailPortCacheTTL {
+		jailPortCache = readJailPorts()
+		jailPortAt = time.Now()
+	}
+	if port, ok := jailPortCache[jail]; ok {
+		return port
+	}
+	return jailPortCache["DEFAULT"]
+}
+
+// readJailPorts collects the "port" option of every section over all jail
+// configuration files. Later files override earlier ones.
+func readJailPorts() map[string]string {
+	ports := make(map[string]string)
+	for _, path := range jailConfigFiles() {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		var section string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section = strings.TrimSpace(strings.Trim(line, "[]"))
+				continue
+			}
+			key, value, found := strings.Cut(line, "=")
+			if !found || section == "" || !strings.EqualFold(strings.TrimSpace(key), "port") {
+				continue
+			}
+			ports[section] = normalizePorts(strings.TrimSpace(value))
+		}
+		file.Close()
+	}
+	return ports
+}
+
+// normalizePorts resolves the service names of a port list to numbers.
+// Ranges ("1000:2000") and interpolations are kept as they are.
+func normalizePorts(value string) string {
+	parts := strings.Split(value, ",")
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if _, err := strconv.Atoi(p); err != nil && !strings.ContainsAny(p, ":%") {
+			if n, err := net.LookupPort("tcp", p); err == nil {
+				p = strconv.Itoa(n)
+			}
+		}
+		parts[i] = p
+	}
+	return strings.Join(parts, ",")
+}
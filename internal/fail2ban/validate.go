@@ -0,0 +1,86 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ValidationError is returned for user input that must not be passed to fail2ban.
+type ValidationError struct {
+	Field string
+	Value string
+	Hint  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Hint)
+}
+
+// maxJailNameLength is generous; fail2ban itself has no hard limit.
+const maxJailNameLength = 64
+
+// Jail names may contain letters, digits, dots, dashes, underscores and "@"
+// (e.g. "nginx-http-auth", "apache.badbots", "sshd@ddos"), but never path
+// separators or whitespace.
+var jailNameRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.@-]*$`)
+
+// ValidateJailName checks that name is a safe fail2ban jail name.
+func ValidateJailName(name string) error {
+	if name == "" || len(name) > maxJailNameLength || !jailNameRegex.MatchString(name) || strings.Contains(name, "..") {
+		return &ValidationError{
+			Field: "jail name",
+			Value: name,
+			Hint:  fmt.Sprintf("use up to %d letters, digits, '.', '-', '_' or '@', starting with a letter or digit", maxJailNameLength),
+		}
+	}
+	return nil
+}
+
+// JoinConfigPath validates name as a jail or filter name and joins it with
+// suffix below dir. The cleaned result must stay inside dir, so a name that
+// slipped past validation can never address a file elsewhere on disk.
+func JoinConfigPath(dir, name, suffix string) (string, error) {
+	if err := ValidateJailName(name); err != nil {
+		return "", err
+	}
+	base := filepath.Clean(dir)
+	path := filepath.Join(base, name+suffix)
+	if filepath.Dir(path) != base {
+		return "", &ValidationError{Field: "jail name", Value: name, Hint: "must not contain path separators"}
+	}
+	return path, nil
+}
+
+// NormalizeIP validates an IPv4 or IPv6 address and returns its canonical form.
+// IPv6 addresses in URLs may be given in brackets ("[2001:db8::1]").
+func NormalizeIP(ip string) (string, error) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(ip), "["), "]")
+	parsed := net.ParseIP(trimmed)
+	if parsed == nil {
+		return "", &ValidationError{
+			Field: "IP address",
+			Value: ip,
+			Hint:  "expected an IPv4 (192.0.2.1) or IPv6 (2001:db8::1) address; URL-encode ':' as %3A if your client requires it",
+		}
+	}
+	return parsed.String(), nil
+}
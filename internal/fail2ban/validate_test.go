@@ -0,0 +1,131 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fail2ban
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateJailName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"sshd", true},
+		{"nginx-http-auth", true},
+		{"apache.badbots", true},
+		{"sshd@ddos", true},
+		{"postfix_sasl", true},
+		{"0day", true},
+		{"", false},
+		{"..", false},
+		{"../etc/passwd", false},
+		{"../../filter.d/sshd", false},
+		{"sshd..conf", false},
+		{"/etc/passwd", false},
+		{"filter.d/sshd", false},
+		{`filter.d\sshd`, false},
+		{"sshd\x00.conf", false},
+		{"sshd\n", false},
+		{"ssh d", false},
+		{".hidden", false},
+		{"-sshd", false},
+		{strings.Repeat("a", maxJailNameLength), true},
+		{strings.Repeat("a", maxJailNameLength+1), false},
+	}
+	for _, tt := range tests {
+		err := ValidateJailName(tt.name)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateJailName(%q) = %v, want nil", tt.name, err)
+		}
+		if !tt.valid {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("ValidateJailName(%q) = %v, want a ValidationError", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestJoinConfigPath(t *testing.T) {
+	const dir = "/etc/fail2ban/filter.d"
+	tests := []struct {
+		name string
+		want string // empty if the name is rejected
+	}{
+		{"sshd", "/etc/fail2ban/filter.d/sshd.conf"},
+		{"nginx-http-auth", "/etc/fail2ban/filter.d/nginx-http-auth.conf"},
+		{"apache.badbots", "/etc/fail2ban/filter.d/apache.badbots.conf"},
+		{"../jail", ""},
+		{"../../../etc/shadow", ""},
+		{"/etc/shadow", ""},
+		{"sub/sshd", ""},
+		{"sshd\x00", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := JoinConfigPath(dir, tt.name, ".conf")
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("JoinConfigPath(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("JoinConfigPath(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	// A directory given with a trailing slash or dots is cleaned first.
+	if got, err := JoinConfigPath(dir+"/./", "sshd", ".local"); err != nil || got != dir+"/sshd.local" {
+		t.Errorf("JoinConfigPath with unclean dir = %q, %v", got, err)
+	}
+}
+
+func TestFilterConfigStaysInFilterDir(t *testing.T) {
+	root := t.TempDir()
+	prev := FilterDir
+	FilterDir = filepath.Join(root, "filter.d")
+	t.Cleanup(func() { FilterDir = prev })
+	if err := os.Mkdir(FilterDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetFilterConfig("sshd", "[Definition]\nfailregex = x\n"); err != nil {
+		t.Fatalf("SetFilterConfig: %v", err)
+	}
+	content, err := GetFilterConfig("sshd")
+	if err != nil || content != "[Definition]\nfailregex = x\n" {
+		t.Fatalf("GetFilterConfig = %q, %v", content, err)
+	}
+
+	for _, name := range []string{"../outside", "/tmp/outside", "a/../../outside", "outside\x00"} {
+		if err := SetFilterConfig(name, "x"); err == nil {
+			t.Errorf("SetFilterConfig(%q) succeeded", name)
+		}
+		if _, err := GetFilterConfig(name); err == nil {
+			t.Errorf("GetFilterConfig(%q) succeeded", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside.conf")); !os.IsNotExist(err) {
+		t.Errorf("a file was written outside the filter directory: %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geoip resolves IP addresses to country and autonomous system.
+//
+// MaxMind GeoLite2, DB-IP Lite (both .mmdb) and IP2Location LITE (.BIN)
+// databases are supported; the format is detected from the file content.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// Default database locations as installed by geoipupdate.
+const (
+	CountryDBPath = "/usr/share/GeoIP/GeoLite2-Country.mmdb"
+	ASNDBPath     = "/usr/share/GeoIP/GeoLite2-ASN.mmdb"
+)
+
+// CountryDBPaths are tried in order when no country database is configured.
+var CountryDBPaths = []string{
+	CountryDBPath,
+	"/usr/share/GeoIP/dbip-country-lite.mmdb",
+	"/usr/share/GeoIP/IP2LOCATION-LITE-DB1.IPV6.BIN",
+	"/usr/share/GeoIP/IP2LOCATION-LITE-DB1.BIN",
+}
+
+// ASNDBPaths are tried in order when no ASN database is configured.
+var ASNDBPaths = []string{
+	ASNDBPath,
+	"/usr/share/GeoIP/dbip-asn-lite.mmdb",
+}
+
+// Info is the result of a lookup.
+type Info struct {
+	Country string `json:"country"`
+	ASN     uint   `json:"asn,omitempty"`
+	ASOrg   string `json:"asOrg,omitempty"`
+}
+
+// DBStatus describes the database used for one kind of lookup.
+type DBStatus struct {
+	Path   string `json:"path,omitempty"`
+	Format string `json:"format,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Status describes the databases currently in use.
+type Status struct {
+	Country DBStatus `json:"country"`
+	ASN     DBStatus `json:"asn"`
+}
+
+type openDB struct {
+	provider Provider
+	modTime  time.Time
+}
+
+var (
+	dbs     = make(map[string]*openDB)
+	dbsLock sync.Mutex
+)
+
+// Lookup returns the country and, if an ASN database is installed, the
+// autonomous system of ip. A missing ASN database is not an error. A
+// matching override (config.GeoOverride) takes precedence over the
+// databases; without an ASN of its own the ASN database still applies.
+func Lookup(ip string) (Info, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return Info{}, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	var info Info
+	override, overridden := config.MatchGeoOverride(parsedIP)
+	if overridden {
+		info = Info{Country: override.Country, ASN: override.ASN, ASOrg: override.ASOrg}
+	} else {
+		var err error
+		if info, err = lookup(countryDB(), parsedIP); err != nil {
+			return Info{}, err
+		}
+	}
+	if info.ASN == 0 {
+		if asn, err := lookup(asnDB(), parsedIP); err == nil && asn.ASN != 0 {
+			info.ASN = asn.ASN
+			info.ASOrg = asn.ASOrg
+		}
+	}
+	return info, nil
+}
+
+// LookupCountry finds the country ISO code for a given IP.
+func LookupCountry(ip string) (string, error) {
+	info, err := Lookup(ip)
+	return info.Country, err
+}
+
+// countryCacheSize bounds the number of memoized countries, the cache is
+// started over once it is full.
+const countryCacheSize = 100000
+
+// countryCache memoizes the countries of IPs for the country database
+// file and the overrides it was filled from.
+var countryCache struct {
+	sync.Mutex
+	path      string
+	modTime   time.Time
+	overrides string
+	countries map[string]string
+}
+
+// CachedCountries returns the country ISO codes of ips, memoized per IP
+// so that repeated calls, like for every dashboard refresh, only look up
+// new IPs. The cache is dropped when the database file or the overrides
+// change. IPs without a known country are left out.
+func CachedCountries(ips []string) map[string]string {
+	out := make(map[string]string, len(ips))
+	path := countryDB()
+	var modTime time.Time
+	if st, err := os.Stat(path); err == nil {
+		modTime = st.ModTime()
+	}
+	overrides := config.GetSettings().GeoIP.Overrides
+	if modTime.IsZero() && len(overrides) == 0 {
+		return out
+	}
+	var key strings.Builder
+	for _, o := range overrides {
+		key.WriteString(o.Network + "=" + o.Country + ",")
+	}
+
+	countryCache.Lock()
+	defer countryCache.Unlock()
+	if countryCache.path != path || !countryCache.modTime.Equal(modTime) || countryCache.overrides != key.String() || len(countryCache.countries) > countryCacheSize {
+		countryCache.path, countryCache.modTime, countryCache.overrides = path, modTime, key.String()
+		countryCache.countries = make(map[string]string)
+	}
+	for _, ip := range ips {
+		country, ok := countryCache.countries[ip]
+		if !ok {
+			if parsed := net.ParseIP(ip); parsed != nil {
+				if o, found := config.MatchGeoOverride(parsed); found {
+					country = o.Country
+				} else {
+					info, _ := lookup(path, parsed)
+					country = info.Country
+				}
+			}
+			countryCache.countries[ip] = country
+		}
+		if country != "" {
+			out[ip] = country
+		}
+	}
+	return out
+}
+
+// Available reports whether a country database is installed.
+func Available() bool {
+	_, err := os.Stat(countryDB())
+	return err == nil
+}
+
+// GetStatus reports which databases are used and their detected format.
+func GetStatus() Status {
+	return Status{Country: dbStatus(countryDB()), ASN: dbStatus(asnDB())}
+}
+
+func dbStatus(path string) DBStatus {
+	st := DBStatus{Path: path}
+	p, err := open(path)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	st.Format = p.Format()
+	return st
+}
+
+// countryDB returns the configured country database or the first existing default.
+func countryDB() string {
+	if path := config.GetSettings().GeoIP.CountryDB; path != "" {
+		return path
+	}
+	return firstExisting(defaultPaths(CountryDBPaths))
+}
+
+// asnDB returns the configured ASN database or the first existing default.
+func asnDB() string {
+	if path := config.GetSettings().GeoIP.ASNDB; path != "" {
+		return path
+	}
+	return firstExisting(defaultPaths(ASNDBPaths))
+}
+
+// defaultPaths returns paths moved to the GeoIP directory of the startup
+// options, if one is set.
+func defaultPaths(paths []string) []string {
+	dir := config.GetOptions().GeoIPPath
+	if dir == "" {
+		return paths
+	}
+	moved := make([]string, len(paths))
+	for i, p := range paths {
+		moved[i] = filepath.Join(dir, filepath.Base(p))
+	}
+	return moved
+}
+
+// firstExisting returns the first path that exists, or the first path if none does.
+func firstExisting(paths []string) string {
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return paths[0]
+}
+
+// lookup queries the database at path.
+func lookup(path string, ip net.IP) (Info, error) {
+	p, err := open(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	info, err := p.Lookup(ip)
+	if err != nil {
+		return Info{}, fmt.Errorf("GeoIP lookup error: %w", err)
+	}
+	return info, nil
+}
+
+// open returns a cached provider for path and reopens it when the file was updated.
+func open(path string) (Provider, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dbsLock.Lock()
+	defer dbsLock.Unlock()
+	if db, ok := dbs[path]; ok && db.modTime.Equal(st.ModTime()) {
+		return db.provider, nil
+	}
+	provider, err := openProvider(path)
+	if err != nil {
+		return nil, err
+	}
+	// Providers of an older file may still be in use by concurrent lookups,
+	// so the previous one is left to the garbage collector.
+	dbs[path] = &openDB{provider: provider, modTime: st.ModTime()}
+	return provider, nil
+}
@@ -0,0 +1,265 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ip2locationProvider reads IP2Location LITE BIN databases (DB1 and up).
+// Only the country column is used, which all database types share.
+//
+// The file starts with a header describing two sorted tables of rows, one
+// for IPv4 and one for IPv6. Each row starts with the first address of a
+// range followed by 32-bit pointers to the column strings; the next row
+// marks the end of the range. All offsets in the file are 1-based.
+type ip2locationProvider struct {
+	f *os.File
+
+	dbType, dbColumns        uint8
+	year, month, day         uint8
+	v4Count, v4Addr          uint32
+	v6Count, v6Addr          uint32
+	v4IndexAddr, v6IndexAddr uint32
+}
+
+// ip2locationHeaderSize is the size of the BIN header read on open.
+const ip2locationHeaderSize = 64
+
+// errNotFound is returned by the table search for addresses outside all ranges.
+var errNotFound = errors.New("address not found in database")
+
+// uint128 holds an IPv6 address number; the BIN format stores them little-endian.
+type uint128 struct{ hi, lo uint64 }
+
+func (a uint128) less(b uint128) bool {
+	return a.hi < b.hi || (a.hi == b.hi && a.lo < b.lo)
+}
+
+func newIP2Location(f *os.File) (*ip2locationProvider, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := make([]byte, ip2locationHeaderSize)
+	if _, err := f.ReadAt(h, 0); err != nil {
+		return nil, err
+	}
+	p := &ip2locationProvider{
+		f:           f,
+		dbType:      h[0],
+		dbColumns:   h[1],
+		year:        h[2],
+		month:       h[3],
+		day:         h[4],
+		v4Count:     binary.LittleEndian.Uint32(h[5:]),
+		v4Addr:      binary.LittleEndian.Uint32(h[9:]),
+		v6Count:     binary.LittleEndian.Uint32(h[13:]),
+		v6Addr:      binary.LittleEndian.Uint32(h[17:]),
+		v4IndexAddr: binary.LittleEndian.Uint32(h[21:]),
+		v6IndexAddr: binary.LittleEndian.Uint32(h[25:]),
+	}
+	productCode := h[29]
+
+	// Sanity checks: database types 1-26 exist, every type has at least the
+	// country column, and files since 2021 carry product code 1.
+	switch {
+	case p.dbType < 1 || p.dbType > 26, p.dbColumns < 2 || p.dbColumns > 32:
+		return nil, errors.New("not an IP2Location BIN file")
+	case p.year >= 21 && productCode != 1:
+		return nil, errors.New("not an IP2Location BIN file")
+	case p.v4Count == 0 && p.v6Count == 0:
+		return nil, errors.New("empty IP2Location BIN file")
+	}
+	v4End := int64(p.v4Addr) + int64(p.v4Count)*int64(p.v4RowSize())
+	v6End := int64(p.v6Addr) + int64(p.v6Count)*int64(p.v6RowSize())
+	if v4End > st.Size()+1 || v6End > st.Size()+1 {
+		return nil, errors.New("truncated IP2Location BIN file")
+	}
+	return p, nil
+}
+
+func (p *ip2locationProvider) v4RowSize() uint32 { return uint32(p.dbColumns) * 4 }
+func (p *ip2locationProvider) v6RowSize() uint32 { return 16 + uint32(p.dbColumns-1)*4 }
+
+// Lookup implements Provider.
+func (p *ip2locationProvider) Lookup(ip net.IP) (Info, error) {
+	var (
+		rowOffset uint32
+		err       error
+	)
+	if v4 := ip.To4(); v4 != nil {
+		rowOffset, err = p.findV4(binary.BigEndian.Uint32(v4))
+	} else if v6 := ip.To16(); v6 != nil {
+		rowOffset, err = p.findV6(uint128{binary.BigEndian.Uint64(v6[:8]), binary.BigEndian.Uint64(v6[8:])})
+	} else {
+		return Info{}, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	if err == errNotFound {
+		return Info{}, nil
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	ptr, err := p.readUint32(rowOffset)
+	if err != nil {
+		return Info{}, err
+	}
+	country, err := p.readString(ptr)
+	if err != nil {
+		return Info{}, err
+	}
+	// Unallocated ranges are listed with country "-".
+	if country == "-" {
+		country = ""
+	}
+	return Info{Country: strings.ToUpper(country)}, nil
+}
+
+// findV4 returns the offset of the country pointer of the row containing n.
+func (p *ip2locationProvider) findV4(n uint32) (uint32, error) {
+	if p.v4Count == 0 {
+		return 0, errNotFound
+	}
+	if n == ^uint32(0) {
+		n--
+	}
+	low, high := uint32(0), p.v4Count
+	if p.v4IndexAddr > 0 {
+		var err error
+		idx := (n>>16)<<3 + p.v4IndexAddr
+		if low, err = p.readUint32(idx); err != nil {
+			return 0, err
+		}
+		if high, err = p.readUint32(idx + 4); err != nil {
+			return 0, err
+		}
+	}
+	size := p.v4RowSize()
+	for low <= high {
+		mid := low + (high-low)/2
+		row := p.v4Addr + mid*size
+		from, err := p.readUint32(row)
+		if err != nil {
+			return 0, err
+		}
+		to, err := p.readUint32(row + size)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case n < from:
+			if mid == 0 {
+				return 0, errNotFound
+			}
+			high = mid - 1
+		case n >= to:
+			low = mid + 1
+		default:
+			return row + 4, nil
+		}
+	}
+	return 0, errNotFound
+}
+
+// findV6 returns the offset of the country pointer of the row containing n.
+func (p *ip2locationProvider) findV6(n uint128) (uint32, error) {
+	if p.v6Count == 0 {
+		return 0, errNotFound
+	}
+	if n.hi == ^uint64(0) && n.lo == ^uint64(0) {
+		n.lo--
+	}
+	low, high := uint32(0), p.v6Count
+	if p.v6IndexAddr > 0 {
+		var err error
+		idx := uint32(n.hi>>48)<<3 + p.v6IndexAddr
+		if low, err = p.readUint32(idx); err != nil {
+			return 0, err
+		}
+		if high, err = p.readUint32(idx + 4); err != nil {
+			return 0, err
+		}
+	}
+	size := p.v6RowSize()
+	for low <= high {
+		mid := low + (high-low)/2
+		row := p.v6Addr + mid*size
+		from, err := p.readUint128(row)
+		if err != nil {
+			return 0, err
+		}
+		to, err := p.readUint128(row + size)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case n.less(from):
+			if mid == 0 {
+				return 0, errNotFound
+			}
+			high = mid - 1
+		case !n.less(to):
+			low = mid + 1
+		default:
+			return row + 16, nil
+		}
+	}
+	return 0, errNotFound
+}
+
+// readUint32 reads a little-endian uint32 at the 1-based offset pos.
+func (p *ip2locationProvider) readUint32(pos uint32) (uint32, error) {
+	var b [4]byte
+	if _, err := p.f.ReadAt(b[:], int64(pos)-1); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+// readUint128 reads a little-endian 128-bit number at the 1-based offset pos.
+func (p *ip2locationProvider) readUint128(pos uint32) (uint128, error) {
+	var b [16]byte
+	if _, err := p.f.ReadAt(b[:], int64(pos)-1); err != nil {
+		return uint128{}, err
+	}
+	return uint128{hi: binary.LittleEndian.Uint64(b[8:]), lo: binary.LittleEndian.Uint64(b[:8])}, nil
+}
+
+// readString reads a length-prefixed string at the 0-based offset pos.
+func (p *ip2locationProvider) readString(pos uint32) (string, error) {
+	var n [1]byte
+	if _, err := p.f.ReadAt(n[:], int64(pos)); err != nil {
+		return "", err
+	}
+	b := make([]byte, n[0])
+	if _, err := p.f.ReadAt(b, int64(pos)+1); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Format implements Provider.
+func (p *ip2locationProvider) Format() string {
+	return fmt.Sprintf("IP2Location BIN (DB%d, 20%02d-%02d-%02d)", p.dbType, p.year, p.month, p.day)
+}
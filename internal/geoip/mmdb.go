@@ -0,0 +1,58 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// mmdbProvider reads MaxMind DB files. Besides the MaxMind GeoLite2
+// databases this covers DB-IP Lite, which is published in the same format
+// and record layout without requiring an account.
+type mmdbProvider struct {
+	reader *maxminddb.Reader
+}
+
+func openMMDB(path string) (Provider, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &mmdbProvider{reader: reader}, nil
+}
+
+// Lookup implements Provider.
+func (p *mmdbProvider) Lookup(ip net.IP) (Info, error) {
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+		ASN   uint   `maxminddb:"autonomous_system_number"`
+		ASOrg string `maxminddb:"autonomous_system_organization"`
+	}
+	if err := p.reader.Lookup(ip, &record); err != nil {
+		return Info{}, err
+	}
+	return Info{Country: record.Country.ISOCode, ASN: record.ASN, ASOrg: record.ASOrg}, nil
+}
+
+// Format implements Provider.
+func (p *mmdbProvider) Format() string {
+	return "mmdb (" + p.reader.Metadata.DatabaseType + ")"
+}
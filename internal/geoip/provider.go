@@ -0,0 +1,80 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Provider resolves addresses using a single database file.
+type Provider interface {
+	// Lookup returns whatever the database knows about ip. Country databases
+	// fill Info.Country, ASN databases fill Info.ASN and Info.ASOrg.
+	Lookup(ip net.IP) (Info, error)
+	// Format names the detected database format, e.g. "mmdb (DBIP-Country-Lite)".
+	Format() string
+}
+
+// mmdbMarker starts the metadata section at the end of every MaxMind DB file.
+var mmdbMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// openProvider detects the format of the database at path and opens it.
+// MaxMind DB files (GeoLite2, DB-IP Lite) are recognised by their metadata
+// marker; IP2Location LITE BIN files by their header.
+func openProvider(path string) (Provider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	isMMDB, err := hasMMDBMarker(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if isMMDB {
+		f.Close()
+		return openMMDB(path)
+	}
+	p, err := newIP2Location(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: unknown GeoIP database format", path)
+	}
+	return p, nil
+}
+
+// hasMMDBMarker checks the last 128KiB of f for the MaxMind metadata marker.
+func hasMMDBMarker(f *os.File) (bool, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	size := st.Size()
+	tail := int64(128 * 1024)
+	if tail > size {
+		tail = size
+	}
+	buf := make([]byte, tail)
+	if _, err := f.ReadAt(buf, size-tail); err != nil && err != io.EOF {
+		return false, err
+	}
+	return bytes.Contains(buf, mmdbMarker), nil
+}
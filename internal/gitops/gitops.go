@@ -0,0 +1,287 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitops keeps the history of the fail2ban configuration in a Git
+// repository. Changes made through the UI are committed with the user and
+// a description, and pushed to a remote if one is configured.
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// Status describes the repository and the last commit and push.
+type Status struct {
+	Enabled      bool      `json:"enabled"`
+	Repo         string    `json:"repo"`
+	Remote       string    `json:"remote,omitempty"`
+	Branch       string    `json:"branch"`
+	Pending      int       `json:"pending"` // changes waiting for the next commit
+	LastCommit   string    `json:"lastCommit,omitempty"`
+	LastCommitAt time.Time `json:"lastCommitAt,omitempty"`
+	LastPushAt   time.Time `json:"lastPushAt,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Commit is an entry of the repository history.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+type change struct {
+	user, message string
+}
+
+// debounce is how long Record waits for further changes before committing,
+// so that e.g. a settings save rewriting several files becomes one commit.
+const debounce = 2 * time.Second
+
+// maxShowSize bounds the output of Show.
+const maxShowSize = 1 << 20
+
+var (
+	pending   []change
+	timer     *time.Timer
+	status    Status
+	stateLock sync.Mutex
+	// gitLock serializes the git commands on the repository.
+	gitLock sync.Mutex
+
+	hashRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// Record queues a change made by user for the next commit. It does nothing
+// unless GitOps is enabled.
+func Record(user, message string) {
+	if !config.GetSettings().Integrations.GitOps.Enabled {
+		return
+	}
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	pending = append(pending, change{user: user, message: message})
+	if timer == nil {
+		timer = time.AfterFunc(debounce, func() {
+			if err := Sync(); err != nil {
+				log.Printf("⚠️ GitOps: %v", err)
+			}
+		})
+	} else {
+		timer.Reset(debounce)
+	}
+}
+
+// GetStatus returns the repository status.
+func GetStatus() Status {
+	g := config.GetSettings().Integrations.GitOps
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	st := status
+	st.Enabled, st.Repo, st.Remote, st.Branch = g.Enabled, g.Repo(), g.Remote, g.BranchName()
+	st.Pending = len(pending)
+	return st
+}
+
+// Sync commits the pending changes, and any other modification of the
+// configuration directory, and pushes the branch to the remote.
+func Sync() error {
+	g := config.GetSettings().Integrations.GitOps
+	stateLock.Lock()
+	changes := pending
+	pending = nil
+	if timer != nil {
+		timer.Stop()
+		timer = nil
+	}
+	stateLock.Unlock()
+	if !g.Enabled {
+		return nil
+	}
+
+	gitLock.Lock()
+	defer gitLock.Unlock()
+	hash, pushed, err := commitAndPush(g, changes)
+
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if hash != "" {
+		status.LastCommit, status.LastCommitAt = hash, time.Now()
+	}
+	if pushed {
+		status.LastPushAt = time.Now()
+	}
+	return err
+}
+
+func commitAndPush(g config.GitOpsSettings, changes []change) (hash string, pushed bool, err error) {
+	if err := initRepo(g); err != nil {
+		return "", false, err
+	}
+	if _, err := git(g, "add", "--all"); err != nil {
+		return "", false, err
+	}
+	// "diff --cached --quiet" exits with 1 if there is something to commit.
+	if _, err := git(g, "diff", "--cached", "--quiet"); err != nil {
+		subject, body, author := commitMessage(changes)
+		args := []string{"-c", "user.name=fail2ban-ui", "-c", "user.email=fail2ban-ui@localhost",
+			"commit", "--quiet", "--no-verify", "--author", author, "-m", subject}
+		if body != "" {
+			args = append(args, "-m", body)
+		}
+		if _, err := git(g, args...); err != nil {
+			return "", false, err
+		}
+		out, err := git(g, "rev-parse", "HEAD")
+		if err != nil {
+			return "", false, err
+		}
+		hash = strings.TrimSpace(out)
+		log.Printf("📝 GitOps: committed %s: %s", hash[:min(7, len(hash))], subject)
+	}
+	if g.Remote == "" {
+		return hash, false, nil
+	}
+	if _, err := git(g, "push", "--quiet", "--", g.Remote, "HEAD:refs/heads/"+g.BranchName()); err != nil {
+		return hash, false, err
+	}
+	return hash, true, nil
+}
+
+// commitMessage summarizes the changes. The author is the user of the
+// first change; changes without a UI user (e.g. edits on the host) are
+// attributed to fail2ban-ui.
+func commitMessage(changes []change) (subject, body, author string) {
+	author = "fail2ban-ui <fail2ban-ui@localhost>"
+	switch len(changes) {
+	case 0:
+		return "Update configuration", "", author
+	case 1:
+		subject = changes[0].message
+	default:
+		subject = fmt.Sprintf("%s (and %d more changes)", changes[0].message, len(changes)-1)
+	}
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("- %s: %s", c.user, c.message))
+	}
+	if user := changes[0].user; user != "" {
+		author = fmt.Sprintf("%s <%s@fail2ban-ui>", user, strings.ReplaceAll(user, " ", "."))
+	}
+	return subject, strings.Join(lines, "\n"), author
+}
+
+// initRepo creates the Git directory on first use. Temporary files of the
+// atomic writes and editor backups are excluded.
+func initRepo(g config.GitOpsSettings) error {
+	if _, err := os.Stat(filepath.Join(g.Repo(), "HEAD")); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(g.Repo(), 0700); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "core.bare", "false"},
+		{"symbolic-ref", "HEAD", "refs/heads/" + g.BranchName()},
+	} {
+		if _, err := git(g, args...); err != nil {
+			return err
+		}
+	}
+	exclude := ".*.tmp-*\n*.swp\n*~\n*.pyc\n__pycache__/\n"
+	if err := os.WriteFile(filepath.Join(g.Repo(), "info", "exclude"), []byte(exclude), 0600); err != nil {
+		return err
+	}
+	log.Printf("📝 GitOps: created repository %s for %s", g.Repo(), fail2ban.ConfigRoot)
+	return nil
+}
+
+// History returns the last n commits, newest first.
+func History(n int) ([]Commit, error) {
+	g := config.GetSettings().Integrations.GitOps
+	commits := []Commit{}
+	if _, err := os.Stat(filepath.Join(g.Repo(), "HEAD")); err != nil {
+		return commits, nil
+	}
+	gitLock.Lock()
+	defer gitLock.Unlock()
+	out, err := git(g, "log", fmt.Sprintf("--max-count=%d", n), "--format=%H%x1f%an%x1f%aI%x1f%s")
+	if err != nil {
+		// A repository without commits has no HEAD yet.
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return commits, nil
+		}
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{Hash: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// Show returns the message and diff of a commit.
+func Show(hash string) (string, error) {
+	if !hashRegex.MatchString(hash) {
+		return "", &fail2ban.ValidationError{Field: "commit", Value: hash, Hint: "use a commit hash"}
+	}
+	g := config.GetSettings().Integrations.GitOps
+	gitLock.Lock()
+	defer gitLock.Unlock()
+	out, err := git(g, "show", "--stat", "--patch", "--format=fuller", hash, "--")
+	if len(out) > maxShowSize {
+		out = out[:maxShowSize] + "\n[diff truncated]\n"
+	}
+	return out, err
+}
+
+// git runs a git command on the repository with /etc/fail2ban as work tree.
+func git(g config.GitOpsSettings, args ...string) (string, error) {
+	full := append([]string{"--git-dir=" + g.Repo(), "--work-tree=" + fail2ban.ConfigRoot}, args...)
+	cmd := exec.Command("git", full...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL=ssh:https")
+	if g.DeployKey != "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -i '"+g.DeployKey+"' -o IdentitiesOnly=yes -o BatchMode=yes -o StrictHostKeyChecking=accept-new")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
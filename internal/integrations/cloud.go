@@ -0,0 +1,246 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// DefaultCloudRangeSources are used when no sources are configured.
+// Azure publishes its ranges under a weekly changing URL, so it has to be
+// added manually with format "azure". VPN providers publish no ranges of
+// their own; the "vpn" source is the community maintained list of the
+// X4BNet/lists_vpn project. Lists of single providers can be added with
+// format "text".
+var DefaultCloudRangeSources = []config.CloudRangeSource{
+	{Name: "aws", URL: "https://ip-ranges.amazonaws.com/ip-ranges.json", Format: "aws"},
+	{Name: "gcp", URL: "https://www.gstatic.com/ipranges/cloud.json", Format: "gcp"},
+	{Name: "cloudflare", URL: "https://www.cloudflare.com/ips-v4", Format: "text"},
+	{Name: "cloudflare", URL: "https://www.cloudflare.com/ips-v6", Format: "text"},
+	{Name: "vpn", URL: "https://raw.githubusercontent.com/X4BNet/lists_vpn/main/output/vpn/ipv4.txt", Format: "text"},
+}
+
+type providerRanges struct {
+	name string
+	nets []*net.IPNet
+}
+
+var (
+	cloudRanges     []providerRanges
+	cloudRangesLock sync.RWMutex
+)
+
+func init() {
+	Register(Job{
+		Name:     "cloud-ranges",
+		Interval: refreshInterval,
+		Run:      refreshCloudRanges,
+	})
+}
+
+// refreshInterval returns the configured refresh interval of downloaded lists.
+func refreshInterval() time.Duration {
+	hours := config.GetSettings().Integrations.RefreshHours
+	if hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// CloudProvider returns the name of the cloud/VPN provider owning ip, or "".
+func CloudProvider(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	cloudRangesLock.RLock()
+	defer cloudRangesLock.RUnlock()
+	for _, p := range cloudRanges {
+		for _, n := range p.nets {
+			if n.Contains(parsed) {
+				return p.name
+			}
+		}
+	}
+	return ""
+}
+
+// refreshCloudRanges downloads all configured provider range lists.
+// A source that fails to download keeps its previously loaded ranges.
+func refreshCloudRanges() error {
+	settings := config.GetSettings().Integrations
+	if !settings.CloudRanges {
+		return nil
+	}
+	sources := settings.CloudRangeSources
+	if len(sources) == 0 {
+		sources = DefaultCloudRangeSources
+	}
+
+	cloudRangesLock.RLock()
+	previous := make(map[string][]*net.IPNet)
+	for _, p := range cloudRanges {
+		previous[p.name] = p.nets
+	}
+	cloudRangesLock.RUnlock()
+
+	loaded := make(map[string][]*net.IPNet)
+	failed := make(map[string]bool)
+	var order []string
+	var errs []string
+	for _, src := range sources {
+		if _, ok := loaded[src.Name]; !ok {
+			order = append(order, src.Name)
+			loaded[src.Name] = nil
+		}
+		nets, err := loadCloudSource(src)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", src.Name, err))
+			failed[src.Name] = true
+			continue
+		}
+		loaded[src.Name] = append(loaded[src.Name], nets...)
+	}
+
+	ranges := make([]providerRanges, 0, len(order))
+	total := 0
+	for _, name := range order {
+		nets := loaded[name]
+		if failed[name] {
+			nets = previous[name]
+		}
+		total += len(nets)
+		ranges = append(ranges, providerRanges{name: name, nets: nets})
+	}
+
+	cloudRangesLock.Lock()
+	cloudRanges = ranges
+	cloudRangesLock.Unlock()
+	config.DebugLog("Loaded %d cloud provider ranges from %d providers", total, len(ranges))
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load cloud ranges: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func loadCloudSource(src config.CloudRangeSource) ([]*net.IPNet, error) {
+	data, err := fetch(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	var prefixes []string
+	switch src.Format {
+	case "aws":
+		var doc struct {
+			Prefixes []struct {
+				IPPrefix string `json:"ip_prefix"`
+			} `json:"prefixes"`
+			IPv6Prefixes []struct {
+				IPv6Prefix string `json:"ipv6_prefix"`
+			} `json:"ipv6_prefixes"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, p := range doc.Prefixes {
+			prefixes = append(prefixes, p.IPPrefix)
+		}
+		for _, p := range doc.IPv6Prefixes {
+			prefixes = append(prefixes, p.IPv6Prefix)
+		}
+	case "gcp":
+		var doc struct {
+			Prefixes []struct {
+				IPv4Prefix string `json:"ipv4Prefix"`
+				IPv6Prefix string `json:"ipv6Prefix"`
+			} `json:"prefixes"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, p := range doc.Prefixes {
+			prefixes = append(prefixes, p.IPv4Prefix, p.IPv6Prefix)
+		}
+	case "azure":
+		var doc struct {
+			Values []struct {
+				Properties struct {
+					AddressPrefixes []string `json:"addressPrefixes"`
+				} `json:"properties"`
+			} `json:"values"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, v := range doc.Values {
+			prefixes = append(prefixes, v.Properties.AddressPrefixes...)
+		}
+	case "text", "":
+		prefixes = parseTextList(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", src.Format)
+	}
+	return parseCIDRs(prefixes), nil
+}
+
+// parseTextList returns the non-empty, non-comment lines of a plain text list.
+func parseTextList(data []byte) []string {
+	var out []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, strings.Fields(line)[0])
+	}
+	return out
+}
+
+// parseCIDRs converts CIDRs and single IPs to networks, skipping invalid entries.
+func parseCIDRs(values []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			if ip := net.ParseIP(v); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		if _, n, err := net.ParseCIDR(v); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}
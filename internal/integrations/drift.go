@@ -0,0 +1,50 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// The config-drift job compares the configured jail options with the
+// running daemon, see fail2ban.CheckDrift. It also runs after every reload
+// and settings change.
+func init() {
+	Register(Job{
+		Name:     "config-drift",
+		Interval: func() time.Duration { return 5 * time.Minute },
+		Run: func() error {
+			report, err := fail2ban.CheckDrift(context.Background())
+			if err != nil {
+				return err
+			}
+			if !report.InSync {
+				log.Printf("⚠️ %d jail options differ between the configuration and the running fail2ban, a reload is needed", len(report.Drift))
+			}
+			return nil
+		},
+	})
+	fail2ban.OnReload(func() {
+		if err := RunNow("config-drift"); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/gitops"
+)
+
+// The gitops job records edits of /etc/fail2ban made outside the UI, e.g.
+// by configuration management, so the history stays complete.
+func init() {
+	Register(Job{
+		Name:     "gitops",
+		Interval: func() time.Duration { return time.Hour },
+		Run: func() error {
+			if !config.GetSettings().Integrations.GitOps.Enabled {
+				return nil
+			}
+			return gitops.Sync()
+		},
+	})
+}
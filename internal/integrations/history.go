@@ -0,0 +1,61 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"log"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/store"
+)
+
+// The history job copies the bans and unbans of the fail2ban log into the
+// SQLite history, see store.Ingest, and drops events past the retention.
+func init() {
+	Register(Job{
+		Name:     "history",
+		Interval: func() time.Duration { return 30 * time.Second },
+		Run: func() error {
+			settings := config.GetSettings()
+			if !settings.Features.Enabled(config.FeatureHistory) {
+				return nil
+			}
+			s, err := store.Default()
+			if err != nil {
+				return err
+			}
+			logPath := fail2ban.GetBackfillStatus().LogPath
+			if logPath == "" {
+				logPath = fail2ban.DefaultLogPath
+			}
+			added, err := s.Ingest(logPath)
+			if added > 0 {
+				config.DebugLog("Stored %d ban history events from %s", added, logPath)
+			}
+			if err != nil {
+				return err
+			}
+			pruned, err := s.Prune(time.Now().Add(-settings.Integrations.History.Retention()))
+			if pruned > 0 {
+				log.Printf("🧹 Removed %d ban history events older than the retention", pruned)
+			}
+			return err
+		},
+	})
+}
@@ -0,0 +1,96 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+const (
+	// ignoreHostsInterval is how often the ignore hosts are resolved.
+	ignoreHostsInterval = 5 * time.Minute
+	resolveTimeout      = 10 * time.Second
+)
+
+func init() {
+	Register(Job{
+		Name:     "ignore-hosts",
+		Interval: func() time.Duration { return ignoreHostsInterval },
+		Run:      resolveIgnoreHosts,
+	})
+}
+
+// resolveIgnoreHosts resolves the ignore hosts and reloads fail2ban when
+// their addresses changed. A host that fails to resolve keeps its previous
+// addresses, so a DNS outage does not get the admin banned.
+func resolveIgnoreHosts() error {
+	settings := config.GetSettings()
+	resolved := make(map[string][]string, len(settings.Fail2ban.IgnoreHosts))
+	var failed []string
+	for _, host := range settings.Fail2ban.IgnoreHosts {
+		ips, err := resolveHost(host)
+		if err != nil {
+			failed = append(failed, host)
+			config.DebugLog("Resolving ignore host %s failed: %v", host, err)
+			if prev, ok := settings.Fail2ban.ResolvedIgnoreHosts[host]; ok {
+				resolved[host] = prev
+			}
+			continue
+		}
+		resolved[host] = ips
+	}
+
+	changed, err := config.SetResolvedIgnoreHosts(resolved)
+	if err != nil {
+		return err
+	}
+	if changed {
+		log.Printf("🔄 Addresses of the ignore hosts changed, reloading fail2ban")
+		if err := fail2ban.ReloadFail2ban(); err != nil {
+			return fmt.Errorf("ignoreip was updated in jail.local, but the reload failed: %w", err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not resolve %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// resolveHost returns the sorted IPv4 and IPv6 addresses of host.
+func resolveHost(host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP.String())
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
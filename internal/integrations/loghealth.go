@@ -0,0 +1,40 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// The log-health job watches the log files of the jails, see
+// fail2ban.CheckLogHealth.
+func init() {
+	Register(Job{
+		Name:     "log-health",
+		Interval: func() time.Duration { return 10 * time.Minute },
+		Run: func() error {
+			settings := config.GetSettings()
+			if !settings.Features.Enabled(config.FeatureLogHealth) {
+				return nil
+			}
+			return fail2ban.CheckLogHealth(settings.Fail2ban.LogHealth.StaleAfter())
+		},
+	})
+}
@@ -0,0 +1,173 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package integrations fetches third-party data (IP range lists, reputation
+// feeds, ...) on a schedule and uses it to tag banned IPs.
+package integrations
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// Job is a periodically executed integration task.
+type Job struct {
+	Name string
+	// Interval returns the time between two runs. It is evaluated after every
+	// run so changed settings take effect without a restart.
+	Interval func() time.Duration
+	// Run performs the work. It should return quickly if the integration is disabled.
+	Run func() error
+}
+
+// JobStatus describes the last and next execution of a job.
+type JobStatus struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	LastRun   time.Time `json:"lastRun,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+	NextRun   time.Time `json:"nextRun,omitempty"`
+}
+
+type scheduledJob struct {
+	job     Job
+	status  JobStatus
+	trigger chan struct{}
+}
+
+var (
+	jobs     []*scheduledJob
+	jobsLock sync.RWMutex
+	started  bool
+
+	httpClient = &http.Client{Timeout: 60 * time.Second}
+)
+
+// maxFetchBytes limits the size of downloaded lists.
+const maxFetchBytes = 32 << 20
+
+// Register adds a job to the scheduler. Jobs registered after Start are started immediately.
+func Register(job Job) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	sj := &scheduledJob{job: job, status: JobStatus{Name: job.Name}, trigger: make(chan struct{}, 1)}
+	jobs = append(jobs, sj)
+	if started {
+		go sj.loop()
+	}
+}
+
+// Start runs all registered jobs in the background.
+func Start() {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	if started {
+		return
+	}
+	started = true
+	for _, sj := range jobs {
+		go sj.loop()
+	}
+}
+
+// Status returns the status of all registered jobs.
+func Status() []JobStatus {
+	jobsLock.RLock()
+	defer jobsLock.RUnlock()
+	out := make([]JobStatus, len(jobs))
+	for i, sj := range jobs {
+		out[i] = sj.status
+	}
+	return out
+}
+
+// RunNow triggers an immediate run of the named job.
+func RunNow(name string) error {
+	jobsLock.RLock()
+	defer jobsLock.RUnlock()
+	for _, sj := range jobs {
+		if sj.job.Name == name {
+			select {
+			case sj.trigger <- struct{}{}:
+			default: // a run is already pending
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown integration job: %s", name)
+}
+
+func (sj *scheduledJob) loop() {
+	for {
+		sj.run()
+
+		next := time.Now().Add(sj.job.Interval())
+		jobsLock.Lock()
+		sj.status.NextRun = next
+		jobsLock.Unlock()
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-sj.trigger:
+		}
+	}
+}
+
+func (sj *scheduledJob) run() {
+	jobsLock.Lock()
+	sj.status.Running = true
+	jobsLock.Unlock()
+
+	config.DebugLog("Running integration job %s", sj.job.Name)
+	err := sj.job.Run()
+
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	sj.status.Running = false
+	sj.status.LastRun = time.Now()
+	sj.status.LastError = ""
+	if err != nil {
+		sj.status.LastError = err.Error()
+		log.Printf("Integration job %s failed: %v", sj.job.Name, err)
+	}
+}
+
+// fetch downloads url and returns the response body, which may be at
+// most maxFetchBytes large.
+func fetch(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFetchBytes {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", url, maxFetchBytes)
+	}
+	return data, nil
+}
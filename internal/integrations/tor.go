@@ -0,0 +1,100 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"net"
+	"sync"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// DefaultTorExitListURL is the bulk exit list published by the Tor project.
+const DefaultTorExitListURL = "https://check.torproject.org/torbulkexitlist"
+
+var (
+	torExits     map[string]struct{}
+	torExitsLock sync.RWMutex
+)
+
+func init() {
+	Register(Job{
+		Name:     "tor-exits",
+		Interval: refreshInterval,
+		Run:      refreshTorExits,
+	})
+}
+
+// IsTorExit reports whether ip is a known Tor exit node.
+func IsTorExit(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	torExitsLock.RLock()
+	defer torExitsLock.RUnlock()
+	_, ok := torExits[parsed.String()]
+	return ok
+}
+
+// TorPolicyFor returns the Tor policy that applies to jail. A policy for
+// the jail itself wins over the wildcard policy ("*" or empty jail name).
+func TorPolicyFor(jail string) (config.TorPolicy, bool) {
+	var fallback *config.TorPolicy
+	policies := config.GetSettings().Integrations.TorPolicies
+	for i, p := range policies {
+		if p.Jail == jail {
+			return p, true
+		}
+		if (p.Jail == "*" || p.Jail == "") && fallback == nil {
+			fallback = &policies[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return config.TorPolicy{}, false
+}
+
+// refreshTorExits downloads the Tor exit list.
+func refreshTorExits() error {
+	settings := config.GetSettings().Integrations
+	if !settings.TorExitList {
+		return nil
+	}
+	url := settings.TorExitListURL
+	if url == "" {
+		url = DefaultTorExitListURL
+	}
+	data, err := fetch(url)
+	if err != nil {
+		return err
+	}
+
+	exits := make(map[string]struct{})
+	for _, entry := range parseTextList(data) {
+		if ip := net.ParseIP(entry); ip != nil {
+			exits[ip.String()] = struct{}{}
+		}
+	}
+
+	torExitsLock.Lock()
+	torExits = exits
+	torExitsLock.Unlock()
+	config.DebugLog("Loaded %d Tor exit nodes", len(exits))
+	return nil
+}
@@ -0,0 +1,285 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrations
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/version"
+)
+
+// LatestReleaseURL is queried for the newest release.
+const LatestReleaseURL = "https://api.github.com/repos/swissmakers/fail2ban-ui/releases/latest"
+
+// UpdateStatus is the result of the last update check.
+type UpdateStatus struct {
+	Current    string    `json:"current"`
+	Latest     string    `json:"latest,omitempty"`
+	URL        string    `json:"url,omitempty"` // release notes
+	Available  bool      `json:"available"`
+	CheckedAt  time.Time `json:"checkedAt,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Downloaded string    `json:"downloaded,omitempty"` // verified binary ready for ApplyUpdate
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+type release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+var (
+	updateStatus  = UpdateStatus{Current: version.Current()}
+	latestRelease *release
+	updateLock    sync.RWMutex
+)
+
+func init() {
+	Register(Job{
+		Name:     "update-check",
+		Interval: func() time.Duration { return 24 * time.Hour },
+		Run: func() error {
+			if !config.GetSettings().Integrations.UpdateCheck {
+				return nil
+			}
+			return CheckForUpdate()
+		},
+	})
+}
+
+// GetUpdateStatus returns the result of the last update check.
+func GetUpdateStatus() UpdateStatus {
+	updateLock.RLock()
+	defer updateLock.RUnlock()
+	return updateStatus
+}
+
+// CheckForUpdate compares the running version with the latest GitHub release.
+func CheckForUpdate() error {
+	data, err := fetch(LatestReleaseURL)
+	var rel release
+	if err == nil {
+		err = json.Unmarshal(data, &rel)
+	}
+
+	updateLock.Lock()
+	defer updateLock.Unlock()
+	updateStatus.CheckedAt = time.Now()
+	if err != nil {
+		updateStatus.Error = err.Error()
+		return err
+	}
+	updateStatus.Error = ""
+	updateStatus.Latest = rel.TagName
+	updateStatus.URL = rel.HTMLURL
+	// Development builds have no version to compare.
+	updateStatus.Available = updateStatus.Current != "dev" && compareVersions(rel.TagName, updateStatus.Current) > 0
+	if latestRelease == nil || latestRelease.TagName != rel.TagName {
+		updateStatus.Downloaded = ""
+	}
+	latestRelease = &rel
+	return nil
+}
+
+// DownloadUpdate downloads the binary of the latest release for this
+// platform next to the running executable and verifies its SHA-256
+// checksum against the checksum file of the release.
+func DownloadUpdate() (string, error) {
+	updateLock.RLock()
+	rel, available := latestRelease, updateStatus.Available
+	updateLock.RUnlock()
+	if rel == nil || !available {
+		return "", errors.New("no update available, run an update check first")
+	}
+
+	binary, checksums := findReleaseAssets(rel.Assets)
+	if binary == nil {
+		return "", fmt.Errorf("release %s has no binary for %s/%s", rel.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	if checksums == nil {
+		return "", fmt.Errorf("release %s has no checksum file, refusing to install an unverified binary", rel.TagName)
+	}
+	sums, err := fetch(checksums.URL)
+	if err != nil {
+		return "", err
+	}
+	want, ok := checksumFor(sums, binary.Name)
+	if !ok {
+		return "", fmt.Errorf("no checksum for %s in %s", binary.Name, checksums.Name)
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return "", err
+	}
+	target := exe + ".new"
+	if err := downloadFile(binary.URL, target, want); err != nil {
+		return "", err
+	}
+
+	updateLock.Lock()
+	updateStatus.Downloaded = target
+	updateLock.Unlock()
+	return target, nil
+}
+
+// ApplyUpdate replaces the running executable with the downloaded binary,
+// keeping the previous one as ".old". The new version runs after the
+// service manager restarts the process.
+func ApplyUpdate() error {
+	if _, container := os.LookupEnv("CONTAINER"); container {
+		return errors.New("self-update is not supported in containers, pull the new image instead")
+	}
+	updateLock.RLock()
+	downloaded := updateStatus.Downloaded
+	updateLock.RUnlock()
+	if downloaded == "" {
+		return errors.New("no downloaded update")
+	}
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(exe, exe+".old"); err != nil {
+		return err
+	}
+	if err := os.Rename(downloaded, exe); err != nil {
+		// Put the running binary back, so a restart still works.
+		os.Rename(exe+".old", exe)
+		return err
+	}
+	updateLock.Lock()
+	updateStatus.Downloaded = ""
+	updateLock.Unlock()
+	return nil
+}
+
+// findReleaseAssets returns the binary for this platform, e.g.
+// "fail2ban-ui-linux-amd64", and the checksum file of a release.
+func findReleaseAssets(assets []releaseAsset) (binary, checksums *releaseAsset) {
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	for i, a := range assets {
+		name := strings.ToLower(a.Name)
+		switch {
+		case strings.Contains(name, "checksums") || strings.HasSuffix(name, "sha256sums"):
+			checksums = &assets[i]
+		case strings.HasSuffix(name, platform) && binary == nil:
+			binary = &assets[i]
+		}
+	}
+	return binary, checksums
+}
+
+// checksumFor finds the checksum of name in a sha256sum style file.
+func checksumFor(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+func downloadFile(url, path, sha256sum string) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && hex.EncodeToString(h.Sum(nil)) != sha256sum {
+		err = errors.New("checksum mismatch")
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("download of %s failed: %w", url, err)
+	}
+	return nil
+}
+
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// compareVersions compares two "v1.2.3" style versions. A pre-release
+// ("v1.2.3-rc1") sorts before its release.
+func compareVersions(a, b string) int {
+	parse := func(v string) ([3]int, bool) {
+		v = strings.TrimPrefix(v, "v")
+		core, pre, _ := strings.Cut(v, "-")
+		var n [3]int
+		for i, part := range strings.SplitN(core, ".", 3) {
+			n[i], _ = strconv.Atoi(part)
+		}
+		return n, pre != ""
+	}
+	na, preA := parse(a)
+	nb, preB := parse(b)
+	for i := range na {
+		if na[i] != nb[i] {
+			if na[i] > nb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	switch {
+	case preA && !preB:
+		return -1
+	case !preA && preB:
+		return 1
+	}
+	return 0
+}
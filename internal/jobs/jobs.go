@@ -0,0 +1,168 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs runs one-off background tasks and tracks their progress.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job states.
+const (
+	StateRunning  = "running"
+	StateFinished = "finished"
+	StateFailed   = "failed"
+)
+
+// maxFinishedJobs is the number of finished jobs kept for the jobs API.
+const maxFinishedJobs = 50
+
+// ErrAlreadyRunning is returned when a job of the same kind is still running.
+var ErrAlreadyRunning = errors.New("a job of this kind is already running")
+
+// Status is the externally visible state of a job.
+type Status struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	State      string    `json:"state"`
+	Done       int       `json:"done"`
+	Total      int       `json:"total"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// Progress lets a running job report how far it got.
+type Progress struct {
+	id string
+}
+
+var (
+	all     = make(map[string]*Status)
+	allLock sync.RWMutex
+)
+
+// Start runs fn in the background as a job of the given kind.
+// Only one job per kind may run at a time.
+func Start(kind string, fn func(p *Progress) error) (Status, error) {
+	allLock.Lock()
+	for _, st := range all {
+		if st.Kind == kind && st.State == StateRunning {
+			allLock.Unlock()
+			return *st, ErrAlreadyRunning
+		}
+	}
+	st := &Status{ID: newID(), Kind: kind, State: StateRunning, StartedAt: time.Now()}
+	all[st.ID] = st
+	pruneLocked()
+	// Copy while locked, the job may already update its progress.
+	started := *st
+	allLock.Unlock()
+
+	go func() {
+		err := fn(&Progress{id: st.ID})
+
+		allLock.Lock()
+		defer allLock.Unlock()
+		st.FinishedAt = time.Now()
+		st.State = StateFinished
+		if err != nil {
+			st.State = StateFailed
+			st.Error = err.Error()
+			log.Printf("Job %s (%s) failed: %v", st.ID, kind, err)
+		}
+	}()
+	return started, nil
+}
+
+// Get returns the status of a single job.
+func Get(id string) (Status, bool) {
+	allLock.RLock()
+	defer allLock.RUnlock()
+	st, ok := all[id]
+	if !ok {
+		return Status{}, false
+	}
+	return *st, true
+}
+
+// List returns all known jobs, newest first.
+func List() []Status {
+	allLock.RLock()
+	out := make([]Status, 0, len(all))
+	for _, st := range all {
+		out = append(out, *st)
+	}
+	allLock.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out
+}
+
+// SetTotal sets the amount of work the job has to do.
+func (p *Progress) SetTotal(total int) {
+	p.update(func(st *Status) { st.Total = total })
+}
+
+// Add marks n more units of work as done.
+func (p *Progress) Add(n int) {
+	p.update(func(st *Status) { st.Done += n })
+}
+
+// SetMessage sets a human readable progress message.
+func (p *Progress) SetMessage(msg string) {
+	p.update(func(st *Status) { st.Message = msg })
+}
+
+func (p *Progress) update(fn func(st *Status)) {
+	allLock.Lock()
+	defer allLock.Unlock()
+	if st, ok := all[p.id]; ok {
+		fn(st)
+	}
+}
+
+// pruneLocked drops the oldest finished jobs. The caller must hold allLock.
+func pruneLocked() {
+	var finished []*Status
+	for _, st := range all {
+		if st.State != StateRunning {
+			finished = append(finished, st)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].StartedAt.Before(finished[j].StartedAt) })
+	for _, st := range finished[:len(finished)-maxFinishedJobs] {
+		delete(all, st.ID)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
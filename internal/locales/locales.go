@@ -0,0 +1,118 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package locales gives server-side code, such as alert emails, access to
+// the translation catalogs the web UI loads from /locales.
+package locales
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is used for keys missing in the requested language.
+const DefaultLanguage = "en"
+
+//go:embed *.json
+var files embed.FS
+
+var (
+	catalogs     map[string]map[string]string
+	catalogsOnce sync.Once
+)
+
+// FS returns the bundled catalogs, e.g. to serve them to the web UI.
+func FS() fs.FS {
+	return files
+}
+
+// T returns the translation of key in lang. Placeholders like {ip} are
+// replaced from args, given as name/value pairs. Regional variants fall back
+// to their base language (de_ch to de), then to English, then to the key.
+func T(lang, key string, args ...string) string {
+	catalogsOnce.Do(load)
+
+	text := key
+	for _, l := range fallbacks(lang) {
+		if s, ok := catalogs[l][key]; ok {
+			text = s
+			break
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Languages returns the codes of all bundled catalogs, sorted.
+func Languages() []string {
+	catalogsOnce.Do(load)
+	langs := make([]string, 0, len(catalogs))
+	for l := range catalogs {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Has reports whether a catalog for lang is bundled.
+func Has(lang string) bool {
+	catalogsOnce.Do(load)
+	_, ok := catalogs[lang]
+	return ok
+}
+
+func fallbacks(lang string) []string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "-", "_"))
+	out := []string{lang}
+	if i := strings.IndexByte(lang, '_'); i > 0 {
+		out = append(out, lang[:i])
+	}
+	return append(out, DefaultLanguage)
+}
+
+func load() {
+	catalogs = make(map[string]map[string]string)
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		log.Printf("Failed to read translation catalogs: %v", err)
+		return
+	}
+	for _, e := range entries {
+		data, err := files.ReadFile(e.Name())
+		if err != nil {
+			log.Printf("Failed to read %s: %v", e.Name(), err)
+			continue
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			log.Printf("Failed to parse %s: %v", e.Name(), err)
+			continue
+		}
+		catalogs[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = catalog
+	}
+}
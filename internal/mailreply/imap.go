@@ -0,0 +1,193 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailreply
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxMessageSize is the largest message read; replies are small.
+const maxMessageSize = 1 << 20
+
+var literalRegex = regexp.MustCompile(`\{(\d+)\}$`)
+
+// imapConn is a minimal IMAP4rev1 client for the few commands needed to
+// read and flag replies. Only implicit TLS is supported.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is an untagged response line and the literals it contained.
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// dialIMAP connects to addr ("host:port") and reads the greeting.
+func dialIMAP(ctx context.Context, addr string) (*imapConn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	d := tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting %q", greeting.line)
+	}
+	return c, nil
+}
+
+// readResponse reads one response line including its literals.
+func (c *imapConn) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, fmt.Errorf("failed to read IMAP response: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		resp.line += line
+		m := literalRegex.FindStringSubmatch(line)
+		if m == nil {
+			return resp, nil
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return resp, err
+		}
+		keep := n
+		if keep > maxMessageSize {
+			keep = maxMessageSize
+		}
+		literal := make([]byte, keep)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return resp, err
+		}
+		if _, err := io.CopyN(io.Discard, c.r, int64(n-keep)); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// cmd sends a command and returns its untagged responses. A response
+// other than OK is an error.
+func (c *imapConn) cmd(command string) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%03d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+	var untagged []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(resp.line, tag+" "); ok {
+			if !strings.HasPrefix(rest, "OK") {
+				verb, _, _ := strings.Cut(command, " ")
+				return nil, fmt.Errorf("IMAP %s failed: %s", verb, rest)
+			}
+			return untagged, nil
+		}
+		untagged = append(untagged, resp)
+	}
+}
+
+func (c *imapConn) login(user, password string) error {
+	_, err := c.cmd("LOGIN " + quote(user) + " " + quote(password))
+	return err
+}
+
+func (c *imapConn) selectMailbox(name string) error {
+	_, err := c.cmd("SELECT " + quote(name))
+	return err
+}
+
+// searchUnseen returns the UIDs of the unread messages.
+func (c *imapConn) searchUnseen() ([]string, error) {
+	resps, err := c.cmd("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []string
+	for _, r := range resps {
+		rest, ok := strings.CutPrefix(r.line, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, uid := range strings.Fields(rest) {
+			if _, err := strconv.ParseUint(uid, 10, 32); err == nil {
+				uids = append(uids, uid)
+			}
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns the raw message without marking it as read.
+func (c *imapConn) fetch(uid string) ([]byte, error) {
+	resps, err := c.cmd("UID FETCH " + uid + " (BODY.PEEK[])")
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resps {
+		if len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %s not found", uid)
+}
+
+func (c *imapConn) markSeen(uid string) error {
+	_, err := c.cmd("UID STORE " + uid + ` +FLAGS.SILENT (\Seen)`)
+	return err
+}
+
+func (c *imapConn) close() {
+	c.cmd("LOGOUT")
+	c.conn.Close()
+}
+
+// quote returns s as an IMAP quoted string.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
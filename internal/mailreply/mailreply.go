@@ -0,0 +1,264 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mailreply lets alert recipients unban an IP by replying "UNBAN"
+// to its alert email. Every alert carries a signed token, which is also
+// part of its Message-ID, so the reply identifies the ban even if the
+// mail client drops the quoted text. Tokens can be used once.
+package mailreply
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+const (
+	// maxPerPoll limits the messages handled per check of the mailbox.
+	maxPerPoll  = 50
+	pollTimeout = 2 * time.Minute
+	// CommandUnban is the command expected in the first line of a reply.
+	CommandUnban = "UNBAN"
+)
+
+var htmlTagRegex = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// Poll processes the unread messages of the mailbox and marks them read.
+func Poll() error {
+	settings := config.GetSettings()
+	mr := settings.Notifications.MailReply
+	if !mr.Enabled {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pollTimeout)
+	defer cancel()
+	c, err := dialIMAP(ctx, mr.IMAPServer)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	if err := c.login(mr.Username, mr.Password); err != nil {
+		return err
+	}
+	if err := c.selectMailbox(mr.MailboxName()); err != nil {
+		return err
+	}
+	uids, err := c.searchUnseen()
+	if err != nil {
+		return err
+	}
+	if len(uids) > maxPerPoll {
+		uids = uids[:maxPerPoll]
+	}
+	for _, uid := range uids {
+		raw, err := c.fetch(uid)
+		if err != nil {
+			return err
+		}
+		if err := Process(raw, settings); err != nil {
+			log.Printf("❌ Mail reply %s: %v", uid, err)
+		}
+		if err := c.markSeen(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Process handles one reply: it checks the sender, the command and the
+// token and unbans the IP. Every reply with a token is audited.
+func Process(raw []byte, settings config.AppSettings) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	if auto := msg.Header.Get("Auto-Submitted"); auto != "" && !strings.EqualFold(auto, "no") {
+		config.DebugLog("Ignoring automatic mail reply (Auto-Submitted: %s)", auto)
+		return nil
+	}
+	subject := decodeHeader(msg.Header.Get("Subject"))
+	text, err := plainText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return err
+	}
+	token := tokenRegex.FindString(strings.Join([]string{
+		msg.Header.Get("In-Reply-To"), msg.Header.Get("References"), subject, text,
+	}, "\n"))
+	if token == "" {
+		config.DebugLog("Ignoring mail %q without unban token", subject)
+		return nil
+	}
+
+	entry := config.AuditEntry{Action: "mail unban"}
+	err = unban(msg, token, text, settings, &entry)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if auditErr := config.RecordAudit(entry); auditErr != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", auditErr)
+	}
+	if err == nil {
+		log.Printf("✅ %s unbanned %s by mail reply", entry.User, entry.Detail)
+	}
+	return err
+}
+
+func unban(msg *mail.Message, token, text string, settings config.AppSettings, entry *config.AuditEntry) error {
+	from, err := mail.ParseAddress(decodeHeader(msg.Header.Get("From")))
+	if err != nil {
+		entry.User = "mail"
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+	entry.User = "mail:" + from.Address
+	if !senderAllowed(from.Address, settings) {
+		return fmt.Errorf("%s is not allowed to unban by mail", from.Address)
+	}
+	if command := firstLine(text); !strings.EqualFold(command, CommandUnban) {
+		return fmt.Errorf("unknown command %q, reply with %s", command, CommandUnban)
+	}
+	t, err := ParseToken(settings.Notifications.MailReply.Secret, token)
+	if err != nil {
+		return err
+	}
+	entry.Detail = strings.TrimSpace(t.IP + " " + t.Jail)
+	fresh, err := config.UseMailToken(t.Nonce, t.Expires)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("the token was already used")
+	}
+	return fail2ban.UnbanIP(context.Background(), t.Jail, t.IP)
+}
+
+// senderAllowed reports whether addr may reply, by default only the
+// recipients of the alerts, including those of the alert routes.
+func senderAllowed(addr string, settings config.AppSettings) bool {
+	allowed := settings.Notifications.MailReply.AllowedSenders
+	if len(allowed) == 0 {
+		allowed = strings.FieldsFunc(settings.Fail2ban.Destemail, func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+		for _, route := range settings.Notifications.Routes {
+			allowed = append(allowed, route.Recipients...)
+		}
+	}
+	for _, a := range allowed {
+		if parsed, err := mail.ParseAddress(a); err == nil && strings.EqualFold(parsed.Address, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first word of the first line that is neither
+// empty nor quoted, i.e. what the user wrote on top of the reply.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ">") {
+			continue
+		}
+		word, _, _ := strings.Cut(line, " ")
+		return strings.Trim(word, ".!")
+	}
+	return ""
+}
+
+func decodeHeader(v string) string {
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(v); err == nil {
+		return decoded
+	}
+	return v
+}
+
+// plainText returns the text of a message body: the first text/plain
+// part, or the first text/html part without tags.
+func plainText(contentType, encoding string, body io.Reader) (string, error) {
+	text, htmlText, err := textParts(contentType, encoding, body, 0)
+	if err != nil {
+		return "", err
+	}
+	if text == "" && htmlText != "" {
+		lines := strings.NewReplacer("<br>", "\n", "<br/>", "\n", "</p>", "\n", "</div>", "\n").Replace(htmlText)
+		text = html.UnescapeString(htmlTagRegex.ReplaceAllString(lines, ""))
+	}
+	return text, nil
+}
+
+func textParts(contentType, encoding string, body io.Reader, depth int) (string, string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth > 5 {
+			return "", "", errors.New("too deeply nested message")
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		var text, htmlText string
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", fmt.Errorf("invalid multipart message: %w", err)
+			}
+			t, h, err := textParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil {
+				return "", "", err
+			}
+			if text == "" {
+				text = t
+			}
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+		return text, htmlText, nil
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxMessageSize))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode message: %w", err)
+	}
+	if mediaType == "text/html" {
+		return "", string(data), nil
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), "", nil
+}
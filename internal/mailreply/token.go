@@ -0,0 +1,88 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailreply
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenPrefix marks tokens in emails, e.g. "F2B-MS4yLjMuNHxzc2hkfDE3...".
+const tokenPrefix = "F2B-"
+
+var tokenRegex = regexp.MustCompile(tokenPrefix + `[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+
+// Token identifies the ban an alert email was sent for.
+type Token struct {
+	IP      string
+	Jail    string
+	Expires time.Time
+	Nonce   string
+}
+
+// NewToken returns a signed token for the ban of ip in jail, valid for ttl.
+func NewToken(secret, ip, jail string, ttl time.Duration) string {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	payload := strings.Join([]string{ip, jail, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10), hex.EncodeToString(nonce)}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return tokenPrefix + encoded + "." + sign(secret, encoded)
+}
+
+// ParseToken verifies the signature and expiry of a token.
+func ParseToken(secret, token string) (Token, error) {
+	encoded, sig, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ".")
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return Token{}, errors.New("malformed token")
+	}
+	if !hmac.Equal([]byte(sig), []byte(sign(secret, encoded))) {
+		return Token{}, errors.New("invalid token signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Token{}, errors.New("malformed token")
+	}
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 4 {
+		return Token{}, errors.New("malformed token")
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Token{}, errors.New("malformed token")
+	}
+	t := Token{IP: parts[0], Jail: parts[1], Expires: time.Unix(exp, 0), Nonce: parts[3]}
+	if time.Now().After(t.Expires) {
+		return Token{}, errors.New("the token has expired")
+	}
+	return t, nil
+}
+
+func sign(secret, encoded string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
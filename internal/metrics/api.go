@@ -0,0 +1,108 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// apiBuckets are the upper bounds in seconds of the API latency histograms.
+var apiBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type apiRoute struct {
+	method, route string
+}
+
+var (
+	apiLock     sync.Mutex
+	apiLatency  = make(map[apiRoute]*histogram)
+	apiRequests = make(map[apiRoute]map[string]uint64) // by status class, e.g. "2xx"
+)
+
+// ObserveAPI records an API request to the route pattern, e.g.
+// "/api/v1/jails/:jail/unban/:ip", with its status and duration.
+func ObserveAPI(method, route string, status int, d time.Duration) {
+	key := apiRoute{method: method, route: route}
+	apiLock.Lock()
+	defer apiLock.Unlock()
+	h := apiLatency[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(apiBuckets))}
+		apiLatency[key] = h
+		apiRequests[key] = make(map[string]uint64)
+	}
+	s := d.Seconds()
+	for i, le := range apiBuckets {
+		if s <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += s
+	h.count++
+	apiRequests[key][strconv.Itoa(status/100)+"xx"]++
+}
+
+// writeAPI renders the API request metrics.
+func writeAPI(w io.Writer) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	keys := make([]apiRoute, 0, len(apiLatency))
+	for key := range apiLatency {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	metric(w, "fail2ban_ui_api_requests_total", "counter", "Number of API requests, by route and status class.")
+	for _, key := range keys {
+		classes := make([]string, 0, len(apiRequests[key]))
+		for class := range apiRequests[key] {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(w, "fail2ban_ui_api_requests_total{method=\"%s\",route=\"%s\",status=\"%s\"} %d\n",
+				key.method, escape(key.route), class, apiRequests[key][class])
+		}
+	}
+
+	metric(w, "fail2ban_ui_api_request_duration_seconds", "histogram", "Time spent handling API requests, by route.")
+	for _, key := range keys {
+		h := apiLatency[key]
+		labels := fmt.Sprintf("method=\"%s\",route=\"%s\"", key.method, escape(key.route))
+		var cumulative uint64
+		for i, le := range apiBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "fail2ban_ui_api_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(le, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "fail2ban_ui_api_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "fail2ban_ui_api_request_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(w, "fail2ban_ui_api_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+}
@@ -0,0 +1,150 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// Outcomes of ban callbacks (POST /api/ban).
+const (
+	CallbackOK      = "ok"      // accepted and queued
+	CallbackInvalid = "invalid" // rejected request, e.g. missing fields
+	CallbackError   = "error"   // not queued, or processing failed later, e.g. the alert email could not be sent
+)
+
+// callbackBuckets are the upper bounds in seconds of the latency histograms.
+var callbackBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+var (
+	callbackLock     sync.Mutex
+	callbackResults  = make(map[string]uint64)
+	callbackLatency  = make(map[string]*histogram)
+	callbackLastSeen time.Time
+)
+
+// CallbackStats summarizes the ban callbacks handled so far.
+type CallbackStats struct {
+	Results  map[string]uint64          `json:"results"`
+	Latency  map[string]CallbackLatency `json:"latency"`
+	LastSeen time.Time                  `json:"lastSeen,omitempty"`
+	Check    fail2ban.CallbackCheck     `json:"check"`
+}
+
+// CallbackLatency is the number and average duration of a processing stage.
+type CallbackLatency struct {
+	Count      uint64  `json:"count"`
+	AvgSeconds float64 `json:"avgSeconds"`
+}
+
+// CallbackResult counts a ban callback with the given outcome.
+func CallbackResult(result string) {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	callbackResults[result]++
+	callbackLastSeen = time.Now()
+}
+
+// ObserveCallback records how long a stage of the ban callback processing
+// took, e.g. "geoip", "email" or "total".
+func ObserveCallback(stage string, d time.Duration) {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	h := callbackLatency[stage]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(callbackBuckets))}
+		callbackLatency[stage] = h
+	}
+	s := d.Seconds()
+	for i, le := range callbackBuckets {
+		if s <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += s
+	h.count++
+}
+
+// GetCallbackStats returns the callback counters and latencies.
+func GetCallbackStats() CallbackStats {
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+	stats := CallbackStats{
+		Results:  make(map[string]uint64, len(callbackResults)),
+		Latency:  make(map[string]CallbackLatency, len(callbackLatency)),
+		LastSeen: callbackLastSeen,
+		Check:    fail2ban.GetCallbackCheck(),
+	}
+	for result, n := range callbackResults {
+		stats.Results[result] = n
+	}
+	for stage, h := range callbackLatency {
+		stats.Latency[stage] = CallbackLatency{Count: h.count, AvgSeconds: h.sum / float64(h.count)}
+	}
+	return stats
+}
+
+// writeCallbacks renders the callback metrics.
+func writeCallbacks(w io.Writer) {
+	check := fail2ban.GetCallbackCheck()
+
+	callbackLock.Lock()
+	defer callbackLock.Unlock()
+
+	metric(w, "fail2ban_ui_ban_callbacks_total", "counter", "Number of ban callbacks received from the fail2ban action, by outcome.")
+	for _, result := range []string{CallbackOK, CallbackInvalid, CallbackError} {
+		fmt.Fprintf(w, "fail2ban_ui_ban_callbacks_total{result=\"%s\"} %d\n", result, callbackResults[result])
+	}
+
+	stages := make([]string, 0, len(callbackLatency))
+	for stage := range callbackLatency {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	metric(w, "fail2ban_ui_ban_callback_duration_seconds", "histogram", "Time spent processing ban callbacks, by stage.")
+	for _, stage := range stages {
+		h := callbackLatency[stage]
+		var cumulative uint64
+		for i, le := range callbackBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "fail2ban_ui_ban_callback_duration_seconds_bucket{stage=\"%s\",le=\"%s\"} %d\n",
+				escape(stage), strconv.FormatFloat(le, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "fail2ban_ui_ban_callback_duration_seconds_bucket{stage=\"%s\",le=\"+Inf\"} %d\n", escape(stage), h.count)
+		fmt.Fprintf(w, "fail2ban_ui_ban_callback_duration_seconds_sum{stage=\"%s\"} %g\n", escape(stage), h.sum)
+		fmt.Fprintf(w, "fail2ban_ui_ban_callback_duration_seconds_count{stage=\"%s\"} %d\n", escape(stage), h.count)
+	}
+
+	metric(w, "fail2ban_ui_ban_callbacks_verified_total", "counter", "Number of bans in the fail2ban log for which a callback arrived.")
+	fmt.Fprintf(w, "fail2ban_ui_ban_callbacks_verified_total %d\n", check.Verified)
+	metric(w, "fail2ban_ui_ban_callbacks_missing_total", "counter", "Number of bans in the fail2ban log for which no callback arrived, i.e. silently dropped alerts.")
+	fmt.Fprintf(w, "fail2ban_ui_ban_callbacks_missing_total %d\n", check.Missing)
+}
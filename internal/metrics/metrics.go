@@ -0,0 +1,139 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics renders jail statistics in the Prometheus text format,
+// served on /metrics and optionally written for the node_exporter
+// textfile collector.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// Write renders the current jail statistics to w. Querying fail2ban is
+// abandoned when ctx ends.
+func Write(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	jails, err := fail2ban.BuildJailInfos(ctx)
+	up := 1
+	if err != nil {
+		up = 0
+	}
+	metric(bw, "fail2ban_ui_fail2ban_up", "gauge", "Whether fail2ban could be queried.")
+	fmt.Fprintf(bw, "fail2ban_ui_fail2ban_up %d\n", up)
+
+	sort.Slice(jails, func(i, j int) bool { return jails[i].JailName < jails[j].JailName })
+	metric(bw, "fail2ban_ui_jail_banned_ips", "gauge", "Number of currently banned IPs per jail.")
+	for _, j := range jails {
+		fmt.Fprintf(bw, "fail2ban_ui_jail_banned_ips{jail=\"%s\"} %d\n", escape(j.JailName), j.TotalBanned)
+	}
+	metric(bw, "fail2ban_ui_jail_bans_last_hour", "gauge", "Number of bans per jail in the last hour.")
+	for _, j := range jails {
+		fmt.Fprintf(bw, "fail2ban_ui_jail_bans_last_hour{jail=\"%s\"} %d\n", escape(j.JailName), j.NewInLastHour)
+	}
+
+	metric(bw, "fail2ban_ui_jail_failed_current", "gauge", "Number of IPs per jail with failed attempts within findtime that are not banned yet.")
+	for _, j := range jails {
+		fmt.Fprintf(bw, "fail2ban_ui_jail_failed_current{jail=\"%s\"} %d\n", escape(j.JailName), j.CurrentlyFailed)
+	}
+	metric(bw, "fail2ban_ui_jail_failed_total", "counter", "Number of failed attempts per jail since the jail was started.")
+	for _, j := range jails {
+		fmt.Fprintf(bw, "fail2ban_ui_jail_failed_total{jail=\"%s\"} %d\n", escape(j.JailName), j.TotalFailed)
+	}
+
+	counts := fail2ban.Events().JailCounts()
+	names := make([]string, 0, len(counts))
+	for jail := range counts {
+		names = append(names, jail)
+	}
+	sort.Strings(names)
+	metric(bw, "fail2ban_ui_jail_bans_total", "counter", "Number of known ban events per jail, including the log history.")
+	for _, jail := range names {
+		fmt.Fprintf(bw, "fail2ban_ui_jail_bans_total{jail=\"%s\"} %d\n", escape(jail), counts[jail])
+	}
+
+	stats := fail2ban.Events().Stats()
+	metric(bw, "fail2ban_ui_banned_ips_known", "gauge", "Number of distinct IPs in the ban history.")
+	fmt.Fprintf(bw, "fail2ban_ui_banned_ips_known %d\n", stats.IPs)
+
+	reloadNeeded := 0
+	if config.GetSettings().Server.RestartNeeded {
+		reloadNeeded = 1
+	}
+	metric(bw, "fail2ban_ui_reload_needed", "gauge", "Whether configuration changes wait for a fail2ban reload.")
+	fmt.Fprintf(bw, "fail2ban_ui_reload_needed %d\n", reloadNeeded)
+
+	writeCallbacks(bw)
+	writeNotifications(bw)
+	writeAPI(bw)
+	writePanics(bw)
+
+	client := fail2ban.GetClientStats()
+	metric(bw, "fail2ban_ui_client_calls_active", "gauge", "Number of running fail2ban-client processes.")
+	fmt.Fprintf(bw, "fail2ban_ui_client_calls_active %d\n", client.Active)
+	metric(bw, "fail2ban_ui_client_calls_waiting", "gauge", "Number of fail2ban-client calls waiting for a free slot.")
+	fmt.Fprintf(bw, "fail2ban_ui_client_calls_waiting %d\n", client.Waiting)
+	metric(bw, "fail2ban_ui_client_calls_rejected_total", "counter", "Number of fail2ban-client calls rejected because too many were waiting.")
+	fmt.Fprintf(bw, "fail2ban_ui_client_calls_rejected_total %d\n", client.Rejected)
+
+	return bw.Flush()
+}
+
+// WriteTextfile writes the statistics to path, a .prom file in the
+// directory of the node_exporter textfile collector. The file is replaced
+// atomically, so the collector never reads a partial file.
+func WriteTextfile(path string) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".fail2ban-ui-*.prom.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(context.Background(), tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelEscaper escapes label values as required by the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,72 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Outcomes of notification deliveries.
+const (
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+type notificationKey struct {
+	channel, result string
+}
+
+var (
+	notificationLock   sync.Mutex
+	notificationCounts = make(map[notificationKey]uint64)
+)
+
+// Notification counts a delivery on channel, e.g. "email" or "webhook",
+// as sent when err is nil and as failed otherwise.
+func Notification(channel string, err error) {
+	key := notificationKey{channel: channel, result: NotificationSent}
+	if err != nil {
+		key.result = NotificationFailed
+	}
+	notificationLock.Lock()
+	notificationCounts[key]++
+	notificationLock.Unlock()
+}
+
+// writeNotifications renders the notification delivery counters.
+func writeNotifications(w io.Writer) {
+	notificationLock.Lock()
+	defer notificationLock.Unlock()
+
+	keys := make([]notificationKey, 0, len(notificationCounts))
+	for key := range notificationCounts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].channel != keys[j].channel {
+			return keys[i].channel < keys[j].channel
+		}
+		return keys[i].result < keys[j].result
+	})
+	metric(w, "fail2ban_ui_notifications_total", "counter", "Number of notification deliveries, by channel and result.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "fail2ban_ui_notifications_total{channel=\"%s\",result=\"%s\"} %d\n", escape(key.channel), key.result, notificationCounts[key])
+	}
+}
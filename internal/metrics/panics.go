@@ -0,0 +1,52 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	panicLock   sync.Mutex
+	panicCounts = make(map[string]uint64) // by route pattern
+)
+
+// Panic counts a panic of the handler of route, e.g. "/api/v1/summary".
+func Panic(route string) {
+	panicLock.Lock()
+	panicCounts[route]++
+	panicLock.Unlock()
+}
+
+// writePanics renders the panic counters.
+func writePanics(w io.Writer) {
+	panicLock.Lock()
+	defer panicLock.Unlock()
+
+	routes := make([]string, 0, len(panicCounts))
+	for route := range panicCounts {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	metric(w, "fail2ban_ui_panics_total", "counter", "Number of recovered panics of request handlers, by route.")
+	for _, route := range routes {
+		fmt.Fprintf(w, "fail2ban_ui_panics_total{route=\"%s\"} %d\n", escape(route), panicCounts[route])
+	}
+}
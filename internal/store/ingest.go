@@ -0,0 +1,131 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+)
+
+// ingestBatch is the number of events inserted per transaction.
+const ingestBatch = 1000
+
+// Ingest reads the lines added to the fail2ban log at path since the last
+// call and stores its bans and unbans. A rotated log (other first line or
+// smaller than the last read position) is read from the start; on the
+// first call the previous log (path.1) is read as well. It returns the
+// number of new events.
+func (s *Store) Ingest(path string) (int, error) {
+	offset, head, found, err := s.sourceState(path)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	if !found {
+		if n, _, err := s.ingestFile(path+".1", 0); err == nil {
+			added += n
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return added, err
+	}
+	first, err := firstLine(f)
+	f.Close()
+	if err != nil {
+		return added, err
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return added, err
+	}
+	if first != head || st.Size() < offset {
+		offset = 0
+	}
+	n, offset, err := s.ingestFile(path, offset)
+	added += n
+	if err != nil {
+		return added, err
+	}
+	return added, s.setSourceState(path, offset, first)
+}
+
+// ingestFile stores the events of the complete lines of path after offset
+// and returns the position after the last complete line.
+func (s *Store) ingestFile(path string, offset int64) (int, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, offset, err
+	}
+
+	added := 0
+	var batch []Event
+	flush := func() error {
+		n, err := s.Insert(batch)
+		added += n
+		batch = batch[:0]
+		return err
+	}
+	countries := map[string]string{}
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			// An incomplete last line is read again on the next call.
+			break
+		}
+		offset += int64(len(line))
+		ev, ok := fail2ban.ParseActionLine(strings.TrimRight(line, "\r\n"))
+		if !ok {
+			continue
+		}
+		country, ok := countries[ev.IP]
+		if !ok {
+			country, _ = geoip.LookupCountry(ev.IP)
+			countries[ev.IP] = country
+		}
+		batch = append(batch, Event{Time: ev.Time, Action: ev.Action, Jail: ev.Jail, IP: ev.IP, Country: country})
+		if len(batch) >= ingestBatch {
+			if err := flush(); err != nil {
+				return added, offset, err
+			}
+		}
+	}
+	return added, offset, flush()
+}
+
+// firstLine returns the first line of f, at most 256 bytes, to recognize
+// a rotated file.
+func firstLine(f *os.File) (string, error) {
+	buf := make([]byte, 256)
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(buf[:n]), "\n")
+	return line, nil
+}
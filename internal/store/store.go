@@ -0,0 +1,238 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package store keeps the ban history in an SQLite database, so bans and
+// unbans stay queryable after the fail2ban log has been rotated away.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	_ "modernc.org/sqlite" // pure Go driver, the binary is built without cgo
+)
+
+// DefaultPath is the history database, stored next to the settings file.
+const DefaultPath = "fail2ban-ui-history.db"
+
+// Event is a ban, restored ban or unban in the history.
+type Event struct {
+	ID      int64     `json:"id"`
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"` // see fail2ban.ActionBan, ActionRestore and ActionUnban
+	Jail    string    `json:"jail"`
+	IP      string    `json:"ip"`
+	Country string    `json:"country,omitempty"`
+}
+
+// Query selects events. Zero fields do not filter.
+type Query struct {
+	IP     string
+	Jail   string
+	Action string
+	From   time.Time
+	To     time.Time
+	Jails  func(jail string) bool // visible jails, nil for all
+	Limit  int
+	Offset int
+}
+
+// Stats describes the content of the store.
+type Stats struct {
+	Events int       `json:"events"`
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+}
+
+// Store is the history database.
+type Store struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	id      INTEGER PRIMARY KEY,
+	time    INTEGER NOT NULL, -- unix milliseconds
+	action  TEXT NOT NULL,
+	jail    TEXT NOT NULL,
+	ip      TEXT NOT NULL,
+	country TEXT NOT NULL DEFAULT '',
+	UNIQUE (time, action, jail, ip)
+);
+CREATE INDEX IF NOT EXISTS events_ip ON events (ip, time);
+CREATE INDEX IF NOT EXISTS events_jail ON events (jail, time);
+CREATE INDEX IF NOT EXISTS events_time ON events (time);
+CREATE TABLE IF NOT EXISTS sources (
+	path   TEXT PRIMARY KEY,
+	pos    INTEGER NOT NULL, -- bytes read
+	head   TEXT NOT NULL
+);`
+
+// Open opens or creates the database at path.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked".
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating the history schema in %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+var (
+	defaultStore *Store
+	defaultErr   error
+	defaultOnce  sync.Once
+)
+
+// Default returns the store at DefaultPath, opened on first use.
+func Default() (*Store, error) {
+	defaultOnce.Do(func() {
+		defaultStore, defaultErr = Open(config.DataPath(DefaultPath))
+	})
+	return defaultStore, defaultErr
+}
+
+// Insert adds events, skipping those already stored, and returns how many were new.
+func (s *Store) Insert(events []Event) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO events (time, action, jail, ip, country) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	added := 0
+	for _, ev := range events {
+		res, err := stmt.Exec(ev.Time.UnixMilli(), ev.Action, ev.Jail, ev.IP, ev.Country)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+	return added, tx.Commit()
+}
+
+// Find returns the events matching q, newest first, and the number of all
+// matching events regardless of Limit and Offset. The query is abandoned
+// when ctx ends.
+func (s *Store) Find(ctx context.Context, q Query) ([]Event, int, error) {
+	var where []string
+	var args []any
+	if q.IP != "" {
+		where, args = append(where, "ip = ?"), append(args, q.IP)
+	}
+	if q.Jail != "" {
+		where, args = append(where, "jail = ?"), append(args, q.Jail)
+	}
+	if q.Action != "" {
+		where, args = append(where, "action = ?"), append(args, q.Action)
+	}
+	if !q.From.IsZero() {
+		where, args = append(where, "time >= ?"), append(args, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		where, args = append(where, "time < ?"), append(args, q.To.UnixMilli())
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, time, action, jail, ip, country FROM events`+cond+` ORDER BY time DESC, id DESC`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	events := []Event{}
+	total := 0
+	for rows.Next() {
+		var ev Event
+		var ms int64
+		if err := rows.Scan(&ev.ID, &ms, &ev.Action, &ev.Jail, &ev.IP, &ev.Country); err != nil {
+			return nil, 0, err
+		}
+		// Jail visibility is a function, so it is applied here rather than in SQL.
+		if q.Jails != nil && !q.Jails(ev.Jail) {
+			continue
+		}
+		total++
+		if total <= q.Offset || (q.Limit > 0 && len(events) >= q.Limit) {
+			continue
+		}
+		ev.Time = time.UnixMilli(ms).UTC()
+		events = append(events, ev)
+	}
+	return events, total, rows.Err()
+}
+
+// Stats returns the number of events and their time range.
+func (s *Store) Stats(ctx context.Context) (Stats, error) {
+	var st Stats
+	var oldest, newest sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*), MIN(time), MAX(time) FROM events`).Scan(&st.Events, &oldest, &newest)
+	if oldest.Valid {
+		st.Oldest, st.Newest = time.UnixMilli(oldest.Int64).UTC(), time.UnixMilli(newest.Int64).UTC()
+	}
+	return st, err
+}
+
+// Prune deletes the events before t and returns how many were deleted.
+func (s *Store) Prune(t time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM events WHERE time < ?`, t.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// sourceState returns the read offset and first line of an ingested log file.
+func (s *Store) sourceState(path string) (offset int64, head string, found bool, err error) {
+	err = s.db.QueryRow(`SELECT pos, head FROM sources WHERE path = ?`, path).Scan(&offset, &head)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", false, nil
+	}
+	return offset, head, err == nil, err
+}
+
+func (s *Store) setSourceState(path string, offset int64, head string) error {
+	_, err := s.db.Exec(`INSERT INTO sources (path, pos, head) VALUES (?, ?, ?)
+		ON CONFLICT (path) DO UPDATE SET pos = excluded.pos, head = excluded.head`, path, offset, head)
+	return err
+}
@@ -0,0 +1,108 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Action IDs of the buttons in Slack alerts.
+const (
+	SlackActionUnban     = "unban"
+	SlackActionWhitelist = "whitelist"
+)
+
+// SlackTarget is the value of a Slack alert button.
+type SlackTarget struct {
+	Jail string `json:"jail"`
+	IP   string `json:"ip"`
+}
+
+// slackMessage renders a ban event as a Slack Block Kit message. The
+// buttons only work if the Slack app owning the incoming webhook has
+// interactivity enabled, with its request URL set to /api/v1/slack.
+func slackMessage(fields map[string]interface{}) map[string]interface{} {
+	ip, jail := stringField(fields, "ip"), stringField(fields, "jail")
+	text := fmt.Sprintf("Banned *%s* in jail *%s*", slackEscape(ip), slackEscape(jail))
+	var details []string
+	if country := stringField(fields, "country"); country != "" {
+		details = append(details, country)
+	}
+	if org := stringField(fields, "as_org"); org != "" {
+		details = append(details, org)
+	}
+	if port := stringField(fields, "port"); port != "" {
+		details = append(details, "port "+port)
+	}
+	if n, ok := fields["repeat_count"].(int); ok && n > 1 {
+		details = append(details, fmt.Sprintf("%d bans", n))
+	}
+	if len(details) > 0 {
+		text += " (" + slackEscape(strings.Join(details, ", ")) + ")"
+	}
+	if note := stringField(fields, "note"); note != "" {
+		text += "\n📝 " + slackEscape(note)
+	}
+
+	value, _ := json.Marshal(SlackTarget{Jail: jail, IP: ip})
+	button := func(actionID, label, style string) map[string]interface{} {
+		b := map[string]interface{}{
+			"type":      "button",
+			"action_id": actionID,
+			"text":      map[string]interface{}{"type": "plain_text", "text": label},
+			"value":     string(value),
+		}
+		if style != "" {
+			b["style"] = style
+		}
+		return b
+	}
+	whitelist := button(SlackActionWhitelist, "Whitelist", "danger")
+	whitelist["confirm"] = map[string]interface{}{
+		"title":   map[string]interface{}{"type": "plain_text", "text": "Whitelist " + ip + "?"},
+		"text":    map[string]interface{}{"type": "plain_text", "text": "The IP is unbanned and never banned again by any jail."},
+		"confirm": map[string]interface{}{"type": "plain_text", "text": "Whitelist"},
+		"deny":    map[string]interface{}{"type": "plain_text", "text": "Cancel"},
+	}
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf("Banned %s in jail %s", ip, jail),
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": text},
+			},
+			map[string]interface{}{
+				"type":     "actions",
+				"block_id": "fail2ban-ui",
+				"elements": []interface{}{button(SlackActionUnban, "Unban", "primary"), whitelist},
+			},
+		},
+	}
+}
+
+// slackWarning renders an operational warning as a plain Slack message.
+func slackWarning(message string) map[string]interface{} {
+	return map[string]interface{}{"text": "⚠️ " + slackEscape(message)}
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn text.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
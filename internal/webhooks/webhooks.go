@@ -0,0 +1,206 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhooks delivers ban events to user-registered HTTP endpoints.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/expr"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+// EventWarning marks the payloads of DispatchWarning.
+const EventWarning = "warning"
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the body if a secret is set.
+const SignatureHeader = "X-Fail2ban-UI-Signature"
+
+// Delivery is the outcome of the last delivery attempt of a webhook.
+type Delivery struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+var (
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+
+	lastDelivery     = make(map[string]Delivery)
+	lastDeliveryLock sync.RWMutex
+)
+
+// Dispatch sends fields to every enabled webhook whose filter matches,
+// provided the country passes the webhook notification policy.
+// Deliveries run in the background.
+func Dispatch(fields map[string]interface{}) {
+	settings := config.GetSettings()
+	if !settings.Features.Enabled(config.FeatureWebhooks) {
+		return
+	}
+	country := stringField(fields, "country")
+	if policy := settings.PolicyFor(config.ChannelWebhook); !policy.AllowsCountry(country) {
+		config.DebugLog("Skipping webhooks for %s: country %s is not in the webhook alert countries %v", stringField(fields, "ip"), country, policy.Countries)
+		return
+	}
+	for _, w := range config.GetWebhooks() {
+		if !w.Enabled {
+			continue
+		}
+		matched, err := expr.Match(w.Filter, fields)
+		if err != nil {
+			config.DebugLog("Skipping webhook %s with invalid filter: %v", w.Name, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		go deliver(w, fields)
+	}
+}
+
+// DispatchWarning sends an operational warning to every enabled webhook.
+// Filters and country policies are written for ban events and do not
+// apply. The payload has "event": "warning", the kind of warning, a
+// human readable message and the given fields.
+func DispatchWarning(kind, message string, fields map[string]interface{}) {
+	if !config.GetSettings().Features.Enabled(config.FeatureWebhooks) {
+		return
+	}
+	payload := map[string]interface{}{
+		"event":   EventWarning,
+		"warning": kind,
+		"message": message,
+		"time":    time.Now().Format(time.RFC3339),
+	}
+	for k, v := range fields {
+		payload[k] = v
+	}
+	for _, w := range config.GetWebhooks() {
+		if w.Enabled {
+			go deliver(w, payload)
+		}
+	}
+}
+
+// LastDeliveries returns the last delivery result per webhook ID.
+func LastDeliveries() map[string]Delivery {
+	lastDeliveryLock.RLock()
+	defer lastDeliveryLock.RUnlock()
+	out := make(map[string]Delivery, len(lastDelivery))
+	for id, d := range lastDelivery {
+		out[id] = d
+	}
+	return out
+}
+
+// Send delivers fields to a single webhook synchronously, ignoring its filter.
+// Every attempt is recorded in the notification log.
+func Send(w config.Webhook, fields map[string]interface{}) Delivery {
+	d := send(w, fields)
+	rec := config.NotificationRecord{
+		Channel:   config.ChannelWebhook,
+		Recipient: w.URL,
+		Subject:   w.Name,
+		IP:        stringField(fields, "ip"),
+		Jail:      stringField(fields, "jail"),
+		Status:    config.NotificationSent,
+		Error:     d.Error,
+		StartedAt: d.Time,
+	}
+	if d.Error != "" {
+		rec.Status = config.NotificationFailed
+	}
+	if err := config.RecordNotification(rec); err != nil {
+		log.Printf("Failed to record webhook delivery: %v", err)
+	}
+	return d
+}
+
+func stringField(fields map[string]interface{}, name string) string {
+	s, _ := fields[name].(string)
+	return s
+}
+
+func send(w config.Webhook, fields map[string]interface{}) Delivery {
+	d := Delivery{Time: time.Now()}
+	var payload interface{} = fields
+	if w.Format == config.WebhookFormatSlack {
+		if stringField(fields, "event") == EventWarning {
+			payload = slackWarning(stringField(fields, "message"))
+		} else {
+			payload = slackMessage(fields)
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fail2ban-ui")
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	resp.Body.Close()
+	d.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 300 {
+		d.Error = fmt.Sprintf("unexpected status %s", resp.Status)
+	}
+	return d
+}
+
+func deliver(w config.Webhook, fields map[string]interface{}) {
+	d := Send(w, fields)
+	var err error
+	if d.Error != "" {
+		log.Printf("❌ Webhook %s (%s) failed: %s", w.Name, w.URL, d.Error)
+		err = errors.New(d.Error)
+	}
+	channel := config.ChannelWebhook
+	if w.Format == config.WebhookFormatSlack {
+		channel = "slack"
+	}
+	metrics.Notification(channel, err)
+	lastDeliveryLock.Lock()
+	lastDelivery[w.ID] = d
+	lastDeliveryLock.Unlock()
+}
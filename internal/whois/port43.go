@@ -0,0 +1,186 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whois
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// ianaServer answers which regional registry is responsible for an IP.
+var ianaServer = "whois.iana.org:43"
+
+var whoisServerRegex = regexp.MustCompile(`^[a-z0-9.-]+$`)
+
+// Referrals of IANA, cached by the range they cover (e.g. a /8 for IPv4).
+type referral struct {
+	start, end net.IP
+	server     string
+}
+
+var (
+	referrals     []referral
+	referralsLock sync.Mutex
+)
+
+// lookupPort43 asks IANA for the responsible registry and queries its
+// whois server for ip.
+func lookupPort43(ctx context.Context, ip net.IP, perMinute int) (config.WhoisRecord, net.IP, net.IP, error) {
+	server, err := referralFor(ctx, ip, perMinute)
+	if err != nil {
+		return config.WhoisRecord{}, nil, nil, err
+	}
+	query := ip.String()
+	if server == "whois.arin.net" {
+		// Only network records, the most specific last.
+		query = "n + " + query
+	}
+	text, err := queryServer(ctx, server+":43", query, perMinute)
+	if err != nil {
+		return config.WhoisRecord{}, nil, nil, err
+	}
+	rec := config.WhoisRecord{Source: config.WhoisPort43 + ":" + server, Text: stripComments(text)}
+	rangeText := lastValue(text, "inetnum", "inet6num", "netrange")
+	if rangeText == "" {
+		rangeText = lastValue(text, "cidr")
+	}
+	rec.Range = rangeText
+	start, end := parseRange(rangeText)
+	return rec, start, end, nil
+}
+
+// referralFor returns the whois server of the registry responsible for ip.
+func referralFor(ctx context.Context, ip net.IP, perMinute int) (string, error) {
+	referralsLock.Lock()
+	for _, r := range referrals {
+		if contains(r.start, r.end, ip) {
+			referralsLock.Unlock()
+			return r.server, nil
+		}
+	}
+	referralsLock.Unlock()
+
+	text, err := queryServer(ctx, ianaServer, ip.String(), perMinute)
+	if err != nil {
+		return "", err
+	}
+	server := strings.ToLower(lastValue(text, "refer"))
+	if server == "" {
+		server = strings.ToLower(lastValue(text, "whois"))
+	}
+	if !whoisServerRegex.MatchString(server) {
+		return "", fmt.Errorf("IANA has no whois server for %s", ip)
+	}
+	if start, end := parseRange(lastValue(text, "inetnum", "inet6num")); start != nil {
+		referralsLock.Lock()
+		referrals = append(referrals, referral{start: start, end: end, server: server})
+		referralsLock.Unlock()
+	}
+	return server, nil
+}
+
+// queryServer sends query to a whois server and returns its answer.
+func queryServer(ctx context.Context, addr, query string, perMinute int) (string, error) {
+	if err := allow(config.WhoisPort43+":"+addr, perMinute); err != nil {
+		return "", err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := io.WriteString(conn, query+"\r\n"); err != nil {
+		return "", err
+	}
+	answer, err := io.ReadAll(io.LimitReader(conn, 256<<10))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the answer of %s: %w", addr, err)
+	}
+	return string(answer), nil
+}
+
+// lastValue returns the value of the last "key: value" line with one of
+// the keys, ignoring case.
+func lastValue(text string, keys ...string) string {
+	value := ""
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, v, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		for _, k := range keys {
+			if key == k {
+				value = strings.TrimSpace(v)
+			}
+		}
+	}
+	return value
+}
+
+// stripComments removes the comment and blank lines of a whois answer.
+func stripComments(text string) string {
+	var out strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r ")
+		if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+// parseRange returns the first and last address of "start - end" or a
+// CIDR ("192.0.2.0/24"; of a list "a/24, b/23" the first is used).
+func parseRange(s string) (net.IP, net.IP) {
+	if first, last, ok := strings.Cut(s, " - "); ok {
+		start, end := net.ParseIP(strings.TrimSpace(first)), net.ParseIP(strings.TrimSpace(last))
+		if start == nil || end == nil {
+			return nil, nil
+		}
+		start, end = normalize(start), normalize(end)
+		if len(start) != len(end) {
+			return nil, nil
+		}
+		return start, end
+	}
+	cidr, _, _ := strings.Cut(s, ",")
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, nil
+	}
+	start := normalize(network.IP)
+	end := make(net.IP, len(start))
+	for i := range start {
+		end[i] = start[i] | ^network.Mask[len(network.Mask)-len(start)+i]
+	}
+	return start, end
+}
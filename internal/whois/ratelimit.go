@@ -0,0 +1,72 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whois
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimitError is returned instead of querying a provider that already
+// got its share of queries in the last minute.
+type rateLimitError struct {
+	key  string
+	wait time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("%s is rate limited for %s", e.key, e.wait.Round(time.Second))
+}
+
+var (
+	queries     = make(map[string][]time.Time)
+	queriesLock sync.Mutex
+)
+
+// allow records a query to key (a provider or server) if fewer than
+// perMinute queries were sent to it in the last minute.
+func allow(key string, perMinute int) error {
+	queriesLock.Lock()
+	defer queriesLock.Unlock()
+	now := time.Now()
+	recent := queries[key][:0]
+	for _, t := range queries[key] {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= perMinute {
+		queries[key] = recent
+		return &rateLimitError{key: key, wait: time.Minute - now.Sub(recent[0])}
+	}
+	queries[key] = append(recent, now)
+	return nil
+}
+
+// block makes key refuse queries for d, e.g. after a server answered
+// "429 Too Many Requests".
+func block(key string, d time.Duration, perMinute int) {
+	queriesLock.Lock()
+	defer queriesLock.Unlock()
+	until := time.Now().Add(d - time.Minute)
+	blocked := make([]time.Time, perMinute)
+	for i := range blocked {
+		blocked[i] = until
+	}
+	queries[key] = blocked
+}
@@ -0,0 +1,181 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// rdapURL is the bootstrap service redirecting to the registry of an IP.
+var rdapURL = "https://rdap.org/ip/"
+
+var rdapClient = &http.Client{Timeout: 20 * time.Second}
+
+type rdapNetwork struct {
+	Handle       string       `json:"handle"`
+	StartAddress string       `json:"startAddress"`
+	EndAddress   string       `json:"endAddress"`
+	Name         string       `json:"name"`
+	Type         string       `json:"type"`
+	Country      string       `json:"country"`
+	Port43       string       `json:"port43"`
+	Entities     []rdapEntity `json:"entities"`
+	Remarks      []struct {
+		Title       string   `json:"title"`
+		Description []string `json:"description"`
+	} `json:"remarks"`
+	Cidrs []struct {
+		V4Prefix string `json:"v4prefix"`
+		V6Prefix string `json:"v6prefix"`
+		Length   int    `json:"length"`
+	} `json:"cidr0_cidrs"`
+}
+
+type rdapEntity struct {
+	Handle     string          `json:"handle"`
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity    `json:"entities"`
+}
+
+// lookupRDAP queries the RDAP service for ip.
+func lookupRDAP(ctx context.Context, ip net.IP, perMinute int) (config.WhoisRecord, net.IP, net.IP, error) {
+	if err := allow(config.WhoisRDAP, perMinute); err != nil {
+		return config.WhoisRecord{}, nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rdapURL+ip.String(), nil)
+	if err != nil {
+		return config.WhoisRecord{}, nil, nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	resp, err := rdapClient.Do(req)
+	if err != nil {
+		return config.WhoisRecord{}, nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait := time.Minute
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+		block(config.WhoisRDAP, wait, perMinute)
+		return config.WhoisRecord{}, nil, nil, &rateLimitError{key: config.WhoisRDAP, wait: wait}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return config.WhoisRecord{}, nil, nil, fmt.Errorf("RDAP server answered %s", resp.Status)
+	}
+
+	var network rdapNetwork
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&network); err != nil {
+		return config.WhoisRecord{}, nil, nil, fmt.Errorf("invalid RDAP response: %w", err)
+	}
+
+	rec := config.WhoisRecord{Source: config.WhoisRDAP}
+	if host := resp.Request.URL.Host; host != "" {
+		rec.Source += ":" + host
+	}
+	var cidrs []string
+	for _, c := range network.Cidrs {
+		prefix := c.V4Prefix
+		if prefix == "" {
+			prefix = c.V6Prefix
+		}
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", prefix, c.Length))
+	}
+	rec.Range = strings.Join(cidrs, ", ")
+	if rec.Range == "" && network.StartAddress != "" {
+		rec.Range = network.StartAddress + " - " + network.EndAddress
+	}
+
+	var text strings.Builder
+	line := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&text, "%-12s%s\n", key+":", value)
+		}
+	}
+	line("inetnum", strings.TrimSpace(network.StartAddress+" - "+network.EndAddress))
+	line("cidr", strings.Join(cidrs, ", "))
+	line("netname", network.Name)
+	line("handle", network.Handle)
+	line("type", network.Type)
+	line("country", network.Country)
+	walkEntities(network.Entities, func(e rdapEntity) {
+		name, email := vcard(e.VCardArray)
+		for _, role := range e.Roles {
+			line(role, strings.TrimSpace(strings.Join([]string{name, email}, " ")))
+		}
+	})
+	for _, r := range network.Remarks {
+		line("remarks", strings.TrimSpace(r.Title+" "+strings.Join(r.Description, " ")))
+	}
+	line("source", rec.Source)
+	rec.Text = text.String()
+
+	start, end := parseRange(network.StartAddress + " - " + network.EndAddress)
+	if start == nil && len(cidrs) > 0 {
+		start, end = parseRange(cidrs[0])
+	}
+	return rec, start, end, nil
+}
+
+// walkEntities calls fn for every entity, including nested ones such as
+// the abuse contact of the registrant.
+func walkEntities(entities []rdapEntity, fn func(rdapEntity)) {
+	for _, e := range entities {
+		fn(e)
+		walkEntities(e.Entities, fn)
+	}
+}
+
+// vcard returns the formatted name and email of a jCard
+// (["vcard", [["fn", {}, "text", "Example Org"], ...]]).
+func vcard(raw json.RawMessage) (name, email string) {
+	var card []json.RawMessage
+	if json.Unmarshal(raw, &card) != nil || len(card) < 2 {
+		return "", ""
+	}
+	var props [][]json.RawMessage
+	if json.Unmarshal(card[1], &props) != nil {
+		return "", ""
+	}
+	for _, p := range props {
+		if len(p) < 4 {
+			continue
+		}
+		var key, value string
+		if json.Unmarshal(p[0], &key) != nil || json.Unmarshal(p[3], &value) != nil {
+			continue
+		}
+		switch {
+		case key == "fn" && name == "":
+			name = value
+		case key == "email" && email == "":
+			email = "<" + value + ">"
+		}
+	}
+	return name, email
+}
@@ -0,0 +1,306 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package whois looks up the registration data of banned IPs in the
+// background, so neither fail2ban's ban action nor the alert emails wait
+// for slow whois servers.
+//
+// Lookups try RDAP first and fall back to the regional whois servers.
+// Every provider and server is rate limited and the results are cached
+// by allocated range, so a mass ban from one network costs one query.
+package whois
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+const (
+	maxRecords    = 10000
+	maxTextLen    = 8 << 10
+	queueSize     = 1000
+	failedBackoff = 15 * time.Minute
+	lookupTimeout = 30 * time.Second
+	saveDelay     = 30 * time.Second
+)
+
+// ErrNotPublic is returned for private, loopback and other addresses
+// that are not registered with a registry.
+var ErrNotPublic = errors.New("the address is not publicly routed")
+
+// ErrDisabled is returned while the whois feature is switched off.
+var ErrDisabled = errors.New("whois lookups are disabled")
+
+type entry struct {
+	rec        config.WhoisRecord
+	start, end net.IP
+}
+
+var (
+	cache      []entry // in fetch order, oldest first
+	cacheOnce  sync.Once
+	cacheLock  sync.RWMutex
+	saveTimer  *time.Timer
+	queue      = make(chan string, queueSize)
+	workerOnce sync.Once
+	pending    = make(map[string]bool)
+	failed     = make(map[string]time.Time)
+	stateLock  sync.Mutex
+)
+
+// Lookup returns the cached record of the range containing ip. If there is
+// none or it is older than the configured cache time, the IP is looked up
+// in the background and a stale record is returned meanwhile.
+func Lookup(ip string) (config.WhoisRecord, bool, error) {
+	if !config.GetSettings().Features.Enabled(config.FeatureWhois) {
+		return config.WhoisRecord{}, false, ErrDisabled
+	}
+	parsed, err := publicIP(ip)
+	if err != nil {
+		return config.WhoisRecord{}, false, err
+	}
+	rec, ok := cached(parsed)
+	if !ok || time.Since(rec.FetchedAt) > config.GetSettings().Integrations.Whois.CacheTTL() {
+		enqueue(parsed.String())
+	}
+	return rec, ok, nil
+}
+
+// Pending reports whether ip is waiting to be looked up.
+func Pending(ip string) bool {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	return pending[ip]
+}
+
+// publicIP parses ip and rejects addresses no registry knows about.
+func publicIP(ip string) (net.IP, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	if parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsLinkLocalUnicast() ||
+		parsed.IsUnspecified() || parsed.IsMulticast() {
+		return nil, ErrNotPublic
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4, nil
+	}
+	return parsed, nil
+}
+
+// cached returns the most specific cached range containing ip.
+func cached(ip net.IP) (config.WhoisRecord, bool) {
+	cacheOnce.Do(loadCache)
+	cacheLock.RLock()
+	defer cacheLock.RUnlock()
+	var best *entry
+	for i := range cache {
+		e := &cache[i]
+		if !contains(e.start, e.end, ip) {
+			continue
+		}
+		if best == nil || bytes.Compare(e.start, best.start) > 0 ||
+			(e.start.Equal(best.start) && bytes.Compare(e.end, best.end) < 0) {
+			best = e
+		}
+	}
+	if best == nil {
+		return config.WhoisRecord{}, false
+	}
+	return best.rec, true
+}
+
+func contains(start, end, ip net.IP) bool {
+	return len(start) == len(ip) && bytes.Compare(start, ip) <= 0 && bytes.Compare(ip, end) <= 0
+}
+
+// loadCache reads the persisted records once.
+func loadCache() {
+	for _, rec := range config.LoadWhoisCache() {
+		start, end := net.ParseIP(rec.Start), net.ParseIP(rec.End)
+		if start == nil || end == nil {
+			continue
+		}
+		cache = append(cache, entry{rec: rec, start: normalize(start), end: normalize(end)})
+	}
+}
+
+// normalize returns IPv4 addresses in their 4-byte form.
+func normalize(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+// store adds rec to the cache, replacing a record of the same range, and
+// schedules writing the cache file.
+func store(rec config.WhoisRecord, start, end net.IP) {
+	cacheOnce.Do(loadCache)
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	for i := range cache {
+		if cache[i].start.Equal(start) && cache[i].end.Equal(end) {
+			cache = append(cache[:i], cache[i+1:]...)
+			break
+		}
+	}
+	cache = append(cache, entry{rec: rec, start: start, end: end})
+	if len(cache) > maxRecords {
+		cache = append([]entry(nil), cache[len(cache)-maxRecords:]...)
+	}
+	if saveTimer == nil {
+		saveTimer = time.AfterFunc(saveDelay, saveCache)
+	}
+}
+
+func saveCache() {
+	cacheLock.Lock()
+	saveTimer = nil
+	records := make([]config.WhoisRecord, len(cache))
+	for i, e := range cache {
+		records[i] = e.rec
+	}
+	cacheLock.Unlock()
+	if err := config.SaveWhoisCache(records); err != nil {
+		log.Printf("⚠️ Failed to save the whois cache: %v", err)
+	}
+}
+
+// enqueue schedules a background lookup of ip unless it is already queued
+// or failed recently. When the queue is full the IP is dropped; it is
+// queued again the next time it is requested.
+func enqueue(ip string) {
+	workerOnce.Do(func() { go worker() })
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	if pending[ip] || time.Since(failed[ip]) < failedBackoff {
+		return
+	}
+	select {
+	case queue <- ip:
+		pending[ip] = true
+	default:
+		config.DebugLog("Whois queue is full, dropping lookup of %s", ip)
+	}
+}
+
+// worker resolves the queued IPs one after the other, so IPs of a range
+// that was just looked up are answered from the cache.
+func worker() {
+	for ip := range queue {
+		err := resolve(ip)
+		stateLock.Lock()
+		delete(pending, ip)
+		if err != nil {
+			failed[ip] = time.Now()
+		} else {
+			delete(failed, ip)
+		}
+		for k, t := range failed {
+			if time.Since(t) > failedBackoff {
+				delete(failed, k)
+			}
+		}
+		stateLock.Unlock()
+	}
+}
+
+// resolve looks up ip unless a fresh record of its range is cached.
+// While all providers are rate limited it waits for the first to allow
+// another query.
+func resolve(ip string) error {
+	parsed, err := publicIP(ip)
+	if err != nil {
+		return err
+	}
+	settings := config.GetSettings().Integrations.Whois
+	if rec, ok := cached(parsed); ok && time.Since(rec.FetchedAt) <= settings.CacheTTL() {
+		return nil
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+		rec, start, end, err := fetch(ctx, parsed, settings)
+		cancel()
+		if err == nil {
+			store(rec, start, end)
+			config.DebugLog("Whois record of %s (%s) fetched from %s", ip, rec.Range, rec.Source)
+			return nil
+		}
+		var limited *rateLimitError
+		if !errors.As(err, &limited) {
+			log.Printf("⚠️ Whois lookup of %s failed: %v", ip, err)
+			return err
+		}
+		time.Sleep(limited.wait)
+	}
+	return fmt.Errorf("whois lookup of %s is rate limited", ip)
+}
+
+// fetch tries the configured providers in order.
+func fetch(ctx context.Context, ip net.IP, settings config.WhoisSettings) (config.WhoisRecord, net.IP, net.IP, error) {
+	var errs []string
+	var limited *rateLimitError
+	limitedCount := 0
+	for _, provider := range settings.ProviderChain() {
+		var rec config.WhoisRecord
+		var start, end net.IP
+		var err error
+		switch provider {
+		case config.WhoisRDAP:
+			rec, start, end, err = lookupRDAP(ctx, ip, settings.RateLimit())
+		case config.WhoisPort43:
+			rec, start, end, err = lookupPort43(ctx, ip, settings.RateLimit())
+		default:
+			err = fmt.Errorf("unknown provider")
+		}
+		if err == nil {
+			if start == nil || end == nil || !contains(start, end, ip) {
+				// No usable range in the answer: cache the single address.
+				start, end = ip, ip
+				rec.Range = ip.String()
+			}
+			rec.Start, rec.End = start.String(), end.String()
+			rec.FetchedAt = time.Now()
+			if len(rec.Text) > maxTextLen {
+				rec.Text = rec.Text[:maxTextLen] + "\n[...]"
+			}
+			return rec, start, end, nil
+		}
+		var rl *rateLimitError
+		if errors.As(err, &rl) {
+			limitedCount++
+			if limited == nil || rl.wait < limited.wait {
+				limited = rl
+			}
+		}
+		errs = append(errs, provider+": "+err.Error())
+	}
+	if limitedCount > 0 && limitedCount == len(errs) {
+		return config.WhoisRecord{}, nil, nil, limited
+	}
+	return config.WhoisRecord{}, nil, nil, errors.New(strings.Join(errs, "; "))
+}
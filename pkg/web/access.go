@@ -0,0 +1,86 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+var (
+	aclMu     sync.Mutex
+	aclKey    string
+	aclCached config.AccessList
+)
+
+// currentAccessList returns the compiled access settings, re-parsed only
+// when they changed.
+func currentAccessList() config.AccessList {
+	a := config.GetSettings().Auth.Access
+	key := strings.Join(a.AllowedClients, ",") + "|" + strings.Join(a.TrustedProxies, ",")
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	if key != aclKey {
+		// Invalid entries are rejected when saving, see UpdateSettingsHandler.
+		acl, err := a.Compile()
+		if err != nil {
+			config.DebugLog("Invalid access settings: %v", err)
+		}
+		aclKey, aclCached = key, acl
+	}
+	return aclCached
+}
+
+// requestIP returns the client address of the request, honoring the
+// X-Forwarded-For header of trusted proxies.
+func requestIP(c *gin.Context) net.IP {
+	return currentAccessList().ClientIP(c.Request.RemoteAddr, c.Request.Header.Values("X-Forwarded-For"))
+}
+
+// clientAllowed returns the client address of the request and whether acl
+// admits it. Direct connections from localhost are always accepted, so the
+// fail2ban action can deliver ban notifications and the host cannot lock
+// itself out.
+func clientAllowed(c *gin.Context, acl config.AccessList) (net.IP, bool) {
+	forwarded := c.Request.Header.Values("X-Forwarded-For")
+	ip := acl.ClientIP(c.Request.RemoteAddr, forwarded)
+	if ip != nil && ip.IsLoopback() && len(forwarded) == 0 {
+		return ip, true
+	}
+	return ip, acl.Allows(ip)
+}
+
+// accessControl rejects clients outside the allowed networks. The lockout
+// page is meant for visitors from anywhere.
+func accessControl(c *gin.Context) {
+	acl := currentAccessList()
+	if !acl.Enabled() || lockoutRoute(c) {
+		c.Next()
+		return
+	}
+	if ip, ok := clientAllowed(c, acl); !ok {
+		config.DebugLog("Rejected %s %s from %v (access.go)", c.Request.Method, c.Request.URL.Path, ip)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+	c.Next()
+}
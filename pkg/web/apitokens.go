@@ -0,0 +1,132 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// apiTokenKey is the gin context key holding the API token of a request.
+const apiTokenKey = "apiToken"
+
+// authenticateToken handles requests sending an API token as
+// "Authorization: Bearer f2bapi_...", in every authentication mode. It
+// reports whether the request carried one; it has then been continued
+// with the role of the token's scopes, or rejected.
+func authenticateToken(c *gin.Context) bool {
+	secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	secret = strings.TrimSpace(secret)
+	if !ok || !strings.HasPrefix(secret, config.APITokenPrefix) || isIngestRequest(c) {
+		return false
+	}
+	token, found := config.LookupAPIToken(secret)
+	if !found {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API token"})
+		return true
+	}
+	c.Set("user", "token:"+token.Name)
+	c.Set(roleKey, token.Role())
+	c.Set(apiTokenKey, token)
+	switch {
+	case readOnlyRequest(c):
+		if !token.Allows(config.RoleViewer) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the API token lacks the read scope"})
+			return true
+		}
+	default:
+		if token.Role() == config.RoleViewer {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "read-only access"})
+			return true
+		}
+	}
+	c.Next()
+	return true
+}
+
+// requestToken returns the API token the request was authenticated with.
+func requestToken(c *gin.Context) (config.APIToken, bool) {
+	v, ok := c.Get(apiTokenKey)
+	if !ok {
+		return config.APIToken{}, false
+	}
+	t, ok := v.(config.APIToken)
+	return t, ok
+}
+
+type apiTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes"`
+}
+
+// ListAPITokensHandler returns all API tokens, without their secrets.
+func ListAPITokensHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	tokens, next := paginate(page, config.GetAPITokens())
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens, "nextCursor": next})
+}
+
+// AddAPITokenHandler creates an API token. The response carries the
+// secret, which cannot be retrieved later. Tokens cannot create tokens.
+func AddAPITokenHandler(c *gin.Context) {
+	if _, ok := requestToken(c); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot manage API tokens"})
+		return
+	}
+	var req apiTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	t := config.APIToken{Name: req.Name, Scopes: req.Scopes, CreatedBy: currentUser(c)}
+	if err := t.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token: " + err.Error()})
+		return
+	}
+	t, secret, err := config.AddAPIToken(t)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "token.add", Detail: t.Name + " (" + strings.Join(t.Scopes, ", ") + ")"})
+	c.JSON(http.StatusOK, gin.H{"token": t, "secret": secret})
+}
+
+// DeleteAPITokenHandler revokes an API token.
+func DeleteAPITokenHandler(c *gin.Context) {
+	if _, ok := requestToken(c); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot manage API tokens"})
+		return
+	}
+	if err := config.DeleteAPIToken(c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrAPITokenNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "token.revoke", Detail: c.Param("id")})
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
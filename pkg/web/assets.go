@@ -0,0 +1,154 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// immutableCache is sent for fingerprinted URLs, which change with the content.
+	immutableCache = "public, max-age=31536000, immutable"
+	// revalidateCache makes browsers check the ETag before using a cached copy.
+	revalidateCache = "no-cache"
+)
+
+// localeAssets serves the translations, set up by NewHandler.
+var localeAssets *assetDir
+
+// assetDir serves the files of a flat directory. Every file is available
+// under its plain name ("en.json"), revalidated on each use, and under a
+// fingerprinted name ("en.3f2a9c1b7d0e.json") that is cached forever.
+type assetDir struct {
+	fsys  fs.FS
+	mu    sync.Mutex
+	files map[string]assetFile
+}
+
+type assetFile struct {
+	data    []byte
+	hash    string
+	modTime time.Time
+}
+
+func newAssetDir(fsys fs.FS) *assetDir {
+	return &assetDir{fsys: fsys, files: make(map[string]assetFile)}
+}
+
+// load returns a file, re-reading it when it changed on disk.
+func (d *assetDir) load(name string) (assetFile, error) {
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return assetFile{}, fs.ErrNotExist
+	}
+	st, err := fs.Stat(d.fsys, name)
+	if err != nil {
+		return assetFile{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if f, ok := d.files[name]; ok && f.modTime.Equal(st.ModTime()) {
+		return f, nil
+	}
+	data, err := fs.ReadFile(d.fsys, name)
+	if err != nil {
+		return assetFile{}, err
+	}
+	sum := sha256.Sum256(data)
+	f := assetFile{data: data, hash: hex.EncodeToString(sum[:6]), modTime: st.ModTime()}
+	d.files[name] = f
+	return f, nil
+}
+
+// urls maps the plain names with the given extension to their
+// fingerprinted URLs below prefix, keyed by the name without extension.
+func (d *assetDir) urls(prefix, ext string) map[string]string {
+	entries, err := fs.ReadDir(d.fsys, ".")
+	if err != nil {
+		return nil
+	}
+	urls := make(map[string]string)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || path.Ext(name) != ext {
+			continue
+		}
+		if f, err := d.load(name); err == nil {
+			stem := strings.TrimSuffix(name, ext)
+			urls[stem] = prefix + stem + "." + f.hash + ext
+		}
+	}
+	return urls
+}
+
+// resolve returns the file for a plain or fingerprinted name and whether
+// the name carries the current fingerprint.
+func (d *assetDir) resolve(name string) (f assetFile, immutable bool, err error) {
+	if f, err = d.load(name); err == nil {
+		return f, false, nil
+	}
+	// "en.<hash>.json" is "en.json"; an outdated hash (e.g. from a page
+	// rendered before an upgrade) gets the current content, but must not
+	// be cached forever.
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	i := strings.LastIndexByte(stem, '.')
+	if i <= 0 {
+		return f, false, err
+	}
+	if f, err = d.load(stem[:i] + ext); err != nil {
+		return f, false, err
+	}
+	return f, f.hash == stem[i+1:], nil
+}
+
+// serve handles GET requests for the file in the "file" route parameter.
+func (d *assetDir) serve(c *gin.Context) {
+	name := c.Param("file")
+	f, immutable, err := d.resolve(name)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + f.hash + `"`
+	c.Header("ETag", etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if immutable {
+		c.Header("Cache-Control", immutableCache)
+	} else {
+		c.Header("Cache-Control", revalidateCache)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, f.data)
+}
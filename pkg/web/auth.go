@@ -0,0 +1,139 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// roleKey is the gin context key holding the role of the authenticated user.
+const roleKey = "role"
+
+// readOnlyRequest reports whether the request changes nothing: safe
+// methods and the queries of the Grafana datasource, which Grafana POSTs.
+func readOnlyRequest(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return strings.HasPrefix(apiRoute(c), "/api/grafana/")
+}
+
+// authenticate identifies the user in header authentication mode from the
+// headers of the authenticating reverse proxy; password mode is handled
+// by authenticatePassword. Requests that did not pass a
+// trusted proxy are rejected, so clients cannot set the headers themselves.
+// Viewers may only make read-only requests, see readOnlyRequest. Slack requests and ingested events
+// carry their own credentials, which SlackHandler and IngestEventsHandler
+// verify; the lockout page is public. Dashboard sessions are checked by checkSession, API tokens by
+// authenticateToken and client certificates by authenticateClientCert in
+// every mode.
+func authenticate(c *gin.Context) {
+	if authenticateClientCert(c) || authenticateToken(c) {
+		return
+	}
+	auth := config.GetSettings().Auth
+	if auth.PasswordLogin() {
+		authenticatePassword(c, auth)
+		return
+	}
+	if auth.Mode != config.AuthHeader || localCallback(c) || apiRoute(c) == "/api/slack" || isIngestRequest(c) || lockoutRoute(c) {
+		c.Next()
+		return
+	}
+	if !currentAccessList().FromProxy(c.Request.RemoteAddr) {
+		config.DebugLog("Rejected %s %s from %s: not a trusted proxy (auth.go)", c.Request.Method, c.Request.URL.Path, c.Request.RemoteAddr)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	user, role := auth.RoleFromHeaders(c.Request.Header)
+	if user == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	if role == "" {
+		config.DebugLog("Rejected user %s: in none of the mapped groups (auth.go)", user)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user " + user + " has no access"})
+		return
+	}
+	c.Set("user", user)
+	c.Set(roleKey, role)
+	if !checkSession(c, user) {
+		return
+	}
+	if role == config.RoleViewer {
+		switch route := apiRoute(c); {
+		case readOnlyRequest(c):
+		case route == "/api/session", route == "/api/session/keepalive":
+			// Viewers manage their own session.
+		default:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "read-only access"})
+			return
+		}
+	}
+	c.Next()
+}
+
+// localCallback reports whether the request is a ban notification or a
+// metrics scrape made directly from localhost, which bypass the proxy.
+func localCallback(c *gin.Context) bool {
+	if route := apiRoute(c); route != "/api/ban" && route != "/metrics" {
+		return false
+	}
+	if len(c.Request.Header.Values("X-Forwarded-For")) > 0 {
+		return false
+	}
+	ip := requestIP(c)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requestRole returns the role of the request. Without header
+// authentication every user is an admin.
+func requestRole(c *gin.Context) string {
+	if role := c.GetString(roleKey); role != "" {
+		return role
+	}
+	return config.RoleAdmin
+}
+
+// Route middlewares for the roles, see config.RoleAdmin: operatorOnly
+// marks the routes to ban, unban and annotate IPs, adminOnly those changing
+// filters, jails, settings or fail2ban itself. Viewers are limited to safe
+// methods by authenticate already.
+var (
+	operatorOnly = requireRole(config.RoleOperator)
+	adminOnly    = requireRole(config.RoleAdmin)
+)
+
+// requireRole returns a middleware rejecting users below role.
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t, ok := requestToken(c); ok && !t.Allows(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the API token lacks the " + config.ScopeFor(role) + " scope"})
+			return
+		}
+		if !config.HasRole(requestRole(c), role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "requires the " + role + " role"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// BackendStateHandler returns the state of the backend (ok,
+// fail2ban-unreachable, config-error, log-unreadable or read-only) with
+// the details of every active problem, for the status banners.
+func BackendStateHandler(c *gin.Context) {
+	config.DebugLog("BackendStateHandler called (backendstate.go)") // entry point
+	c.JSON(http.StatusOK, fail2ban.GetState())
+}
+
+// errorStatus returns the HTTP status and the backend state reported with
+// err: 503 for errors caused by a degraded backend, 500 otherwise.
+func errorStatus(err error) (int, string) {
+	if state := fail2ban.ErrorState(err); state != fail2ban.StateOK {
+		return http.StatusServiceUnavailable, state
+	}
+	return http.StatusInternalServerError, fail2ban.GetState().State
+}
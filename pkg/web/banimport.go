@@ -0,0 +1,205 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/jobs"
+)
+
+const (
+	banImportBatch = 100    // IPs banned with one fail2ban-client call
+	maxBanImport   = 100000 // entries of one import
+)
+
+// banImportTag marks the notes created from the comments of a blocklist.
+const banImportTag = "blocklist"
+
+// banImportEntry is an IP of an imported blocklist with its comment.
+type banImportEntry struct {
+	IP      string `json:"ip"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// banImport is the preview of a blocklist import into a permanent jail.
+type banImport struct {
+	Jail          string           `json:"jail"`
+	BanTime       string           `json:"bantime"`
+	Bans          []banImportEntry `json:"bans"`
+	Duplicates    int              `json:"duplicates"`
+	AlreadyBanned int              `json:"alreadyBanned"`
+	Invalid       []invalidEntry   `json:"invalid"`
+}
+
+// ImportBansHandler imports a blocklist, one IP per line optionally
+// followed by a comment (CSV with "ip,comment" or plain text), into a
+// jail with a permanent bantime. The IPs are banned in batches by a
+// background job, see the jobs API; if a batch fails, the bans of the
+// import are rolled back. Comments are stored as notes of IPs without
+// one. With ?dryRun=true it only returns the preview.
+func ImportBansHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ImportBansHandler called (banimport.go)") // entry point
+	list, err := listImportText(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := fail2ban.ValidateJailName(list.Jail); err != nil {
+		respondError(c, err)
+		return
+	}
+	if !jailVisible(c, list.Jail) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "jail not found"})
+		return
+	}
+	ctx := c.Request.Context()
+	cfg, err := fail2ban.GetEffectiveConfig(ctx, list.Jail)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	bantime, ok := cfg.Jails[list.Jail]["bantime"]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jail " + list.Jail + " is not running"})
+		return
+	}
+	if n, err := strconv.Atoi(bantime); err != nil || n >= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("jail %s is not permanent (bantime %s), use a jail with bantime = -1", list.Jail, bantime)})
+		return
+	}
+	banned, err := fail2ban.GetBannedIPs(ctx, list.Jail)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	imp, err := planBanImport(list.Text, banned)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	imp.Jail, imp.BanTime = list.Jail, bantime
+	if c.Query("dryRun") == "true" || len(imp.Bans) == 0 {
+		c.JSON(http.StatusOK, gin.H{"preview": imp})
+		return
+	}
+
+	user := currentUser(c)
+	recordAudit(c, config.AuditEntry{Action: "bans.import", Detail: fmt.Sprintf("%d IPs into %s", len(imp.Bans), imp.Jail)})
+	startJob(c, "ban-import", func(p *jobs.Progress) error {
+		return runBanImport(p, imp, user)
+	})
+}
+
+// runBanImport bans the IPs of imp in batches and unbans the ones already
+// banned by the import when a batch fails.
+func runBanImport(p *jobs.Progress, imp banImport, user string) error {
+	ctx := context.Background()
+	p.SetTotal(len(imp.Bans))
+	var done []string
+	for batch := range slices.Chunk(imp.Bans, banImportBatch) {
+		ips := make([]string, len(batch))
+		for i, e := range batch {
+			ips[i] = e.IP
+		}
+		if err := fail2ban.BanIPs(ctx, imp.Jail, ips); err != nil {
+			// A failed batch may be partly applied.
+			done = append(done, ips...)
+			p.SetMessage(fmt.Sprintf("rolling back %d bans", len(done)))
+			for rollback := range slices.Chunk(done, banImportBatch) {
+				if uerr := fail2ban.UnbanIPs(ctx, imp.Jail, rollback); uerr != nil {
+					log.Printf("❌ Failed to roll back the ban import into %s: %v", imp.Jail, uerr)
+					return fmt.Errorf("%w; the rollback failed as well: %v", err, uerr)
+				}
+			}
+			return fmt.Errorf("%w; %d bans of the import were rolled back", err, len(done))
+		}
+		done = append(done, ips...)
+		p.Add(len(batch))
+	}
+
+	notes := make(map[string]string)
+	for _, e := range imp.Bans {
+		if e.Comment != "" {
+			notes[e.IP] = e.Comment
+		}
+	}
+	added, err := config.AddIPNotes(notes, []string{banImportTag}, user)
+	if err != nil {
+		log.Printf("⚠️ Failed to store the comments of the ban import: %v", err)
+	}
+	p.SetMessage(fmt.Sprintf("%d IPs banned in %s, %d comments stored as notes", len(done), imp.Jail, added))
+	log.Printf("✅ Imported %d permanent bans into jail %s", len(done), imp.Jail)
+	return nil
+}
+
+// planBanImport parses a blocklist. Each line holds an IP, optionally
+// followed by a comment after a comma, semicolon, tab or space; empty
+// lines and lines starting with "#" are skipped.
+func planBanImport(text string, banned []string) (banImport, error) {
+	imp := banImport{Bans: []banImportEntry{}, Invalid: []invalidEntry{}}
+	isBanned := make(map[string]bool, len(banned))
+	for _, ip := range banned {
+		isBanned[ip] = true
+	}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		row := strings.TrimSpace(scanner.Text())
+		if row == "" || strings.HasPrefix(row, "#") {
+			continue
+		}
+		value, comment := row, ""
+		if i := strings.IndexAny(row, ",;\t "); i >= 0 {
+			value, comment = row[:i], row[i+1:]
+		}
+		value = strings.Trim(value, `"'`)
+		comment = strings.TrimSpace(strings.TrimLeft(strings.Trim(strings.TrimSpace(comment), `"`), "# "))
+		ip, err := fail2ban.NormalizeIP(value)
+		if err != nil {
+			// A CSV header is no error.
+			if line > 1 || !strings.EqualFold(value, "ip") {
+				imp.Invalid = append(imp.Invalid, invalidEntry{Line: line, Value: value, Error: "not an IP address"})
+			}
+			continue
+		}
+		switch {
+		case seen[ip]:
+			imp.Duplicates++
+		case isBanned[ip]:
+			imp.AlreadyBanned++
+		default:
+			imp.Bans = append(imp.Bans, banImportEntry{IP: ip, Comment: comment})
+		}
+		seen[ip] = true
+		if len(imp.Bans) > maxBanImport {
+			return imp, fmt.Errorf("too many entries, at most %d can be imported at once", maxBanImport)
+		}
+	}
+	return imp, scanner.Err()
+}
@@ -0,0 +1,99 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+const (
+	// banWorkers is the number of ban notifications processed in parallel.
+	banWorkers = 4
+	// banQueueSize is the number of accepted but unprocessed ban notifications.
+	banQueueSize = 1000
+)
+
+// errBanQueueFull is returned when ban notifications arrive faster than
+// they can be processed for a long time.
+var errBanQueueFull = errors.New("ban queue is full")
+
+var (
+	banQueue     = make(chan config.QueuedBan, banQueueSize)
+	banQueueOnce sync.Once
+)
+
+// startBanWorkers starts the workers processing queued ban notifications
+// and requeues the bans left over from the last run.
+func startBanWorkers() {
+	banQueueOnce.Do(func() {
+		for i := 0; i < banWorkers; i++ {
+			go banWorker()
+		}
+		pending, err := config.LoadQueuedBans()
+		if err != nil {
+			log.Printf("❌ Failed to load queued ban notifications: %v", err)
+			return
+		}
+		if len(pending) > 0 {
+			log.Printf("📥 Processing %d ban notifications queued before the restart", len(pending))
+			go func() {
+				for _, b := range pending {
+					banQueue <- b
+				}
+			}()
+		}
+	})
+}
+
+// enqueueBan persists b and hands it to the workers.
+func enqueueBan(b config.QueuedBan) error {
+	startBanWorkers()
+	if err := config.SaveQueuedBan(&b); err != nil {
+		return err
+	}
+	select {
+	case banQueue <- b:
+		return nil
+	default:
+		config.RemoveQueuedBan(b.ID)
+		return errBanQueueFull
+	}
+}
+
+func banWorker() {
+	for b := range banQueue {
+		metrics.ObserveCallback("queue", time.Since(b.ReceivedAt))
+		start := time.Now()
+		err := handleBan(b.ReceivedAt, b.IP, b.Jail, b.Hostname, b.Failures, b.Whois, b.Logs)
+		metrics.ObserveCallback("processing", time.Since(start))
+		if err != nil {
+			log.Printf("❌ Failed to process ban notification for %s in %s: %v", b.IP, b.Jail, err)
+			metrics.CallbackResult(metrics.CallbackError)
+		}
+		// Failed notifications are not retried, the failure is recorded
+		// in the notification log and the metrics.
+		if err := config.RemoveQueuedBan(b.ID); err != nil {
+			log.Printf("⚠️ Failed to remove queued ban notification %s: %v", b.ID, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+)
+
+// apiLoad counts API requests per minute, so the refresh interval handed
+// out to clients can be raised while the server is busy.
+var apiLoad loadCounter
+
+type loadCounter struct {
+	mu       sync.Mutex
+	minute   int64 // unix minute counted by current
+	current  int
+	previous int
+}
+
+func (l *loadCounter) add(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(now)
+	l.current++
+}
+
+// rate returns the requests of the last full minute, or of the running
+// minute if it already exceeds that.
+func (l *loadCounter) rate(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(now)
+	return max(l.previous, l.current)
+}
+
+func (l *loadCounter) roll(now time.Time) {
+	minute := now.Unix() / 60
+	switch {
+	case minute == l.minute:
+		return
+	case minute == l.minute+1:
+		l.previous = l.current
+	default:
+		l.previous = 0
+	}
+	l.minute = minute
+	l.current = 0
+}
+
+// countLoad is the middleware feeding apiLoad.
+func countLoad(c *gin.Context) {
+	apiLoad.add(time.Now())
+	c.Next()
+}
+
+// refreshInterval returns the polling interval in seconds for the current
+// load: the configured interval, scaled up proportionally once the request
+// rate exceeds the busy threshold.
+func refreshInterval() (interval int, busy bool) {
+	r := config.GetSettings().Server.Refresh.Effective()
+	rate := apiLoad.rate(time.Now())
+	if rate <= r.BusyThreshold {
+		return r.Interval, false
+	}
+	return min(r.Interval*rate/r.BusyThreshold, r.MaxInterval), true
+}
+
+// BootstrapHandler returns the values the frontend needs before it starts
+// polling: the refresh interval and the available push channels.
+func BootstrapHandler(c *gin.Context) {
+	interval, busy := refreshInterval()
+	c.JSON(http.StatusOK, gin.H{
+		"refreshInterval": interval,
+		"busy":            busy,
+		"websocket":       false,
+		"eventStream":     true, // bans and unbans on /api/v1/events/stream
+		"update":          integrations.GetUpdateStatus(),
+		"features":        enabledFeatures(),
+		"passwordLogin":   config.GetSettings().Auth.PasswordLogin(),
+		"role":            requestRole(c),
+	})
+}
@@ -0,0 +1,129 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// logoURL is where the uploaded logo is served.
+const logoURL = "/branding/logo"
+
+// brandingView is the branding as used by the template and the API.
+type brandingView struct {
+	Title       string `json:"title"`
+	AccentColor string `json:"accentColor"`
+	LogoURL     string `json:"logoURL,omitempty"`
+}
+
+// logoVersion identifies an uploaded logo in URLs.
+func logoVersion(st os.FileInfo) string {
+	return strconv.FormatInt(st.ModTime().UnixNano(), 36)
+}
+
+func currentBranding(c *gin.Context) brandingView {
+	b := config.GetSettings().Server.Branding
+	view := brandingView{Title: b.Title, AccentColor: b.AccentColor}
+	if path, ok := config.BrandingLogoPath(); ok {
+		// The version parameter makes browsers fetch a replaced logo.
+		if st, err := os.Stat(path); err == nil {
+			view.LogoURL = c.GetString(basePathKey) + logoURL + "?v=" + logoVersion(st)
+		}
+	}
+	return view
+}
+
+// GetBrandingHandler returns the dashboard branding.
+func GetBrandingHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, currentBranding(c))
+}
+
+// UpdateBrandingHandler sets title and accent color.
+func UpdateBrandingHandler(c *gin.Context) {
+	var req config.BrandingSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if _, err := config.UpdateBranding(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, currentBranding(c))
+}
+
+// UploadLogoHandler stores the logo sent as multipart field "logo".
+func UploadLogoHandler(c *gin.Context) {
+	file, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file field \"logo\""})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, config.MaxLogoSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := config.SetBrandingLogo(data); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrInvalidLogo) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, currentBranding(c))
+}
+
+// DeleteLogoHandler removes the uploaded logo.
+func DeleteLogoHandler(c *gin.Context) {
+	if err := config.DeleteBrandingLogo(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, currentBranding(c))
+}
+
+// LogoHandler serves the uploaded logo.
+func LogoHandler(c *gin.Context) {
+	path, ok := config.BrandingLogoPath()
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	// Links from currentBranding carry the version of the current logo.
+	if st, err := os.Stat(path); err == nil && c.Query("v") == logoVersion(st) {
+		c.Header("Cache-Control", immutableCache)
+	} else {
+		c.Header("Cache-Control", revalidateCache)
+	}
+	c.File(path)
+}
@@ -0,0 +1,77 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// authenticateClientCert handles API requests of machines presenting a TLS
+// client certificate of the configured CAs, in every authentication mode.
+// It reports whether it handled the request: continued with the role of
+// the certificate, or rejected. Requests that also carry a session cookie,
+// an API token or Basic auth are left to the other checks, which decide
+// the role; with ClientCertSettings.Required the latter two are rejected
+// without a certificate.
+func authenticateClientCert(c *gin.Context) bool {
+	cc := config.GetSettings().Server.TLS.ClientCerts
+	if !cc.Enabled() || !strings.HasPrefix(apiRoute(c), "/api/") {
+		return false
+	}
+	credentials := c.GetHeader("Authorization") != ""
+	cert := verifiedClientCert(c)
+	if cert == nil {
+		if cc.Required && credentials {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "a client certificate is required"})
+			return true
+		}
+		return false
+	}
+	name, ok := cc.Accepts(cert)
+	if !ok {
+		config.DebugLog("Rejected client certificate %q (clientcerts.go)", cert.Subject.CommonName)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "client certificate " + cert.Subject.CommonName + " is not allowed"})
+		return true
+	}
+	if cookie, _ := c.Cookie(sessionCookie); credentials || cookie != "" {
+		return false
+	}
+	role := cc.CertRole()
+	c.Set("user", "cert:"+name)
+	c.Set(roleKey, role)
+	if role == config.RoleViewer && !readOnlyRequest(c) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "read-only access"})
+		return true
+	}
+	c.Next()
+	return true
+}
+
+// verifiedClientCert returns the client certificate of the request if the
+// TLS handshake verified it against the CAs, or nil.
+func verifiedClientCert(c *gin.Context) *x509.Certificate {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return state.PeerCertificates[0]
+}
@@ -0,0 +1,86 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// auditConsole is the audit log action of console commands.
+const auditConsole = "console"
+
+// recordAudit adds an entry for the current request to the audit log.
+func recordAudit(c *gin.Context, e config.AuditEntry) {
+	e.User = currentUser(c)
+	if ip := requestIP(c); ip != nil {
+		e.ClientIP = ip.String()
+	}
+	if err := config.RecordAudit(e); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+// ConsoleHandler runs a safelisted fail2ban-client command, see
+// fail2ban.ParseConsoleCommand. Every attempt is audited.
+func ConsoleHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ConsoleHandler called (console.go)") // entry point
+	var req struct {
+		Command string `json:"command" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	args, err := fail2ban.ParseConsoleCommand(req.Command)
+	if err != nil {
+		recordAudit(c, config.AuditEntry{Action: auditConsole, Detail: req.Command, Error: "denied: " + err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	command := strings.Join(args, " ")
+	log.Printf("🖥️ %s runs fail2ban-client %s", currentUser(c), command)
+	start := time.Now()
+	output, err := fail2ban.RunConsoleCommand(c.Request.Context(), args)
+	entry := config.AuditEntry{Action: auditConsole, Detail: command, Output: output}
+	resp := gin.H{"command": command, "output": output, "durationMs": time.Since(start).Milliseconds()}
+	if err != nil {
+		entry.Error = err.Error()
+		resp["error"] = err.Error()
+	}
+	recordAudit(c, entry)
+	c.JSON(http.StatusOK, resp)
+}
+
+// AuditLogHandler returns the newest audit log entries, optionally
+// filtered by action.
+func AuditLogHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	entries, next := paginate(page, config.GetAuditLog(c.Query("action"), page.End()))
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "nextCursor": next})
+}
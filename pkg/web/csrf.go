@@ -0,0 +1,112 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// csrfHeader carries the CSRF token of the dashboard session on requests
+// changing something, see checkCSRF.
+const csrfHeader = "X-CSRF-Token"
+
+// serverKey signs the session cookies and derives the CSRF tokens. Like
+// the sessions themselves it only lives as long as the process.
+var serverKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// serverMAC returns the HMAC of msg for purpose, so MACs of one purpose
+// cannot be used for another.
+func serverMAC(purpose, msg string) string {
+	mac := hmac.New(sha256.New, serverKey)
+	mac.Write([]byte(purpose + "\x00" + msg))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// csrfToken returns the CSRF token of the request's dashboard session, or
+// "" without a session. The token is derived from the session, so it needs
+// no storage and changes with every login.
+func csrfToken(c *gin.Context) string {
+	id := c.GetString("session")
+	if id == "" {
+		return ""
+	}
+	return serverMAC("csrf", id)
+}
+
+// checkCSRF rejects requests changing something that another site may
+// have made the browser send:
+//   - requests from a different origin, as reported by the browser in the
+//     Origin and Sec-Fetch-Site headers, which also covers the login form
+//     and header mode requests carrying only the proxy's cookie;
+//   - requests of a dashboard session without its token in X-CSRF-Token.
+//
+// Safe methods, API tokens and Basic auth requests of scripts without a
+// session are not affected.
+func checkCSRF(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	if _, ok := requestToken(c); ok {
+		c.Next()
+		return
+	}
+	if c.GetHeader("Sec-Fetch-Site") == "cross-site" || !sameOrigin(c.Request) {
+		config.DebugLog("Rejected cross-site %s %s from %s (csrf.go)", c.Request.Method, c.Request.URL.Path, c.GetHeader("Origin"))
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "cross-site request rejected"})
+		return
+	}
+	if want := csrfToken(c); want != "" && !hmac.Equal([]byte(c.GetHeader(csrfHeader)), []byte(want)) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing or invalid CSRF token, reload the page"})
+		return
+	}
+	c.Next()
+}
+
+// sameOrigin reports whether the Origin header, if any, names the host the
+// request was sent to, directly or through a proxy. Requests without an
+// Origin header do not come from another site's page and are allowed.
+func sameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := req.Host
+	if fwd := req.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return u.Host == host
+}
@@ -0,0 +1,78 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// DBInfoHandler reports the size and purge age of fail2ban's own database.
+func DBInfoHandler(c *gin.Context) {
+	info, err := fail2ban.GetDBInfo(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// UpdateDBPurgeAgeHandler changes how many days fail2ban keeps bans in its database.
+func UpdateDBPurgeAgeHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("UpdateDBPurgeAgeHandler called (database.go)") // entry point
+	var req struct {
+		PurgeAgeDays int64 `json:"purgeAgeDays" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.PurgeAgeDays < 1 || req.PurgeAgeDays > 3650 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the purge age must be between 1 and 3650 days"})
+		return
+	}
+	if err := fail2ban.SetDBPurgeAge(req.PurgeAgeDays * 86400); err != nil {
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "fail2ban.dbpurgeage", Detail: strconv.FormatInt(req.PurgeAgeDays, 10) + " days"})
+	DBInfoHandler(c)
+}
+
+// MaintainDBHandler purges and vacuums fail2ban's database, which stops
+// fail2ban for the time of the vacuum.
+func MaintainDBHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("MaintainDBHandler called (database.go)") // entry point
+	report, err := fail2ban.MaintainDB()
+	if errors.Is(err, fail2ban.ErrMaintenanceRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "fail2ban.vacuum"})
+	c.JSON(http.StatusOK, gin.H{"message": "Database maintenance finished", "report": report})
+}
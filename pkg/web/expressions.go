@@ -0,0 +1,141 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/expr"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// eventFieldNames lists the fields available in expressions.
+var eventFieldNames = []string{
+	"time", "jail", "ip", "country", "asn", "as_org", "port", "interface",
+	"repeat_count", "tags", "watched", "log_line", "note",
+}
+
+func init() {
+	fail2ban.Events().Subscribe(applyEscalationRules)
+}
+
+// eventFields returns the fields of a ban event that filter expressions and
+// webhook payloads can refer to.
+func eventFields(ev fail2ban.BanEvent) map[string]interface{} {
+	_, watched := config.MatchWatchlist(ev.IP)
+	note, _ := config.GetIPNote(ev.IP)
+	tags := ipTags(ev.IP)
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]interface{}{
+		"time":         ev.Time.Format(time.RFC3339),
+		"jail":         ev.Jail,
+		"ip":           ev.IP,
+		"country":      ev.Country,
+		"asn":          ev.ASN,
+		"as_org":       ev.ASOrg,
+		"port":         ev.Port,
+		"interface":    ev.Interface,
+		"repeat_count": len(fail2ban.Events().ByIP(ev.IP)),
+		"tags":         tags,
+		"watched":      watched,
+		"log_line":     ev.LogLine,
+		"note":         note.Note,
+	}
+}
+
+// sampleEventFields returns the fields of a representative event for tests.
+func sampleEventFields() map[string]interface{} {
+	ev := fail2ban.SampleBanEvents()[0]
+	fields := eventFields(ev)
+	fields["repeat_count"] = 4
+	fields["demo"] = true
+	return fields
+}
+
+// validateExpression checks that src compiles and only refers to known event fields.
+// An empty expression is valid and matches every event.
+func validateExpression(src string) error {
+	if strings.TrimSpace(src) == "" {
+		return nil
+	}
+	_, err := expr.Compile(src, eventFieldNames)
+	return err
+}
+
+// applyEscalationRules bans the IP of a matching event in the rule's target jail.
+func applyEscalationRules(ev fail2ban.BanEvent) {
+	rules := config.GetSettings().Fail2ban.EscalationRules
+	if len(rules) == 0 {
+		return
+	}
+	fields := eventFields(ev)
+	for _, rule := range rules {
+		// Bans in the target jail come back as events, skip them to avoid loops.
+		if rule.Jail == "" || rule.Jail == ev.Jail {
+			continue
+		}
+		matched, err := expr.Match(rule.Expression, fields)
+		if err != nil {
+			config.DebugLog("Skipping escalation rule %s with invalid expression: %v", rule.Name, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		log.Printf("Escalation rule %s matched %s (jail %s), banning in %s", rule.Name, ev.IP, ev.Jail, rule.Jail)
+		if err := fail2ban.BanIP(context.Background(), rule.Jail, ev.IP); err != nil {
+			log.Printf("❌ Escalation rule %s failed: %v", rule.Name, err)
+		}
+	}
+}
+
+// TestExpressionHandler validates an expression and evaluates it against
+// the given fields, or a sample event if none are provided.
+func TestExpressionHandler(c *gin.Context) {
+	var req struct {
+		Expression string                 `json:"expression"`
+		Fields     map[string]interface{} `json:"fields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	fields := req.Fields
+	if fields == nil {
+		fields = sampleEventFields()
+	}
+
+	resp := gin.H{"valid": true, "fields": fields, "knownFields": eventFieldNames}
+	if err := validateExpression(req.Expression); err != nil {
+		resp["valid"] = false
+		resp["error"] = err.Error()
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	// JSON numbers arrive as float64, which the evaluator handles natively.
+	result, _ := expr.Match(req.Expression, fields)
+	resp["result"] = result
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,47 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// enabledFeatures returns the state of all known features, so the
+// frontend can hide the disabled ones.
+func enabledFeatures() map[string]bool {
+	flags := config.GetSettings().Features
+	out := make(map[string]bool)
+	for _, name := range config.FeatureNames() {
+		out[name] = flags.Enabled(name)
+	}
+	return out
+}
+
+// requireFeature returns a middleware rejecting requests while the feature
+// is switched off in the "features" settings section.
+func requireFeature(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.GetSettings().Features.Enabled(name) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "feature " + name + " is disabled"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// ImportFiltersHandler fetches the filters of a Git repository or tarball
+// for preview. Nothing is installed until InstallFiltersHandler is called.
+func ImportFiltersHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ImportFiltersHandler called (filterimport.go)") // entry point
+	var src fail2ban.ImportSource
+	if err := c.ShouldBindJSON(&src); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	imp, err := fail2ban.FetchFilterImport(src)
+	if err != nil {
+		recordAudit(c, config.AuditEntry{Action: "filters.fetch", Detail: src.URL, Error: err.Error()})
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "filters.fetch", Detail: fmt.Sprintf("%s at %s: %d filters", src.URL, imp.Revision, len(imp.Filters))})
+	c.JSON(http.StatusOK, imp)
+}
+
+// GetFilterImportHandler returns a fetched import again.
+func GetFilterImportHandler(c *gin.Context) {
+	imp, err := fail2ban.GetFilterImport(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, imp)
+}
+
+// InstallFiltersHandler installs the selected filters of an import into
+// filter.d and marks fail2ban for reload.
+func InstallFiltersHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("InstallFiltersHandler called (filterimport.go)") // entry point
+	var req struct {
+		Filters []string `json:"filters" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	imp, err := fail2ban.GetFilterImport(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	installed, err := fail2ban.InstallImportedFilters(imp.ID, req.Filters)
+	audit := config.AuditEntry{Action: "filters.install", Detail: fmt.Sprintf("%s at %s: %s", imp.Source, imp.Revision, strings.Join(installed, ", "))}
+	if err != nil {
+		audit.Error = err.Error()
+	}
+	recordAudit(c, audit)
+	if errors.Is(err, fail2ban.ErrImportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if len(installed) > 0 {
+		log.Printf("📥 Installed %d filters from %s", len(installed), imp.Source)
+		recordChange(c, "Import filters %s from %s at %s", strings.Join(installed, ", "), imp.Source, imp.Revision)
+		if merr := config.MarkRestartNeeded(); merr != nil && err == nil {
+			err = merr
+		}
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "restartNeeded": len(installed) > 0})
+}
@@ -0,0 +1,274 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// fleetHost is one host of the fleet, this one or a remote host.
+type fleetHost struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Local  bool     `json:"local,omitempty"`
+	Labels []string `json:"labels"`
+	remote config.RemoteHost
+}
+
+// fleetHosts returns this host and the remote hosts carrying label, all
+// hosts if label is empty.
+func fleetHosts(label string) []fleetHost {
+	var hosts []fleetHost
+	if local := localHostLabels(); label == "" || slices.Contains(local, label) {
+		name, _ := os.Hostname()
+		hosts = append(hosts, fleetHost{ID: localHostID, Name: name, Local: true, Labels: local})
+	}
+	for _, h := range config.GetRemoteHosts() {
+		if h.HasLabel(label) {
+			hosts = append(hosts, fleetHost{ID: h.ID, Name: h.Name, Labels: h.Labels, remote: h})
+		}
+	}
+	return hosts
+}
+
+// fleetLabels returns all labels in use, sorted.
+func fleetLabels() []string {
+	labels := localHostLabels()
+	for _, h := range config.GetRemoteHosts() {
+		labels = append(labels, h.Labels...)
+	}
+	sort.Strings(labels)
+	return slices.Compact(labels)
+}
+
+// forEachHost runs fn for every host in parallel and returns the results
+// in the order of hosts.
+func forEachHost[T any](hosts []fleetHost, fn func(fleetHost) T) []T {
+	results := make([]T, len(hosts))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fn(h)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fleetHostSummary holds the jail counters of one host.
+type fleetHostSummary struct {
+	fleetHost
+	Error  string              `json:"error,omitempty"`
+	Jails  []fleetJail         `json:"jails"`
+	Totals SummaryTotals       `json:"totals"`
+	Health fail2ban.HostHealth `json:"health"`
+}
+
+// fleetJail holds the counters of a jail, on one host or summed over the fleet.
+type fleetJail struct {
+	JailName      string `json:"jailName"`
+	TotalBanned   int    `json:"totalBanned"`
+	NewInLastHour int    `json:"newInLastHour"`
+	Hosts         int    `json:"hosts,omitempty"`
+}
+
+// FleetSummaryHandler returns the bans per host and jail of all hosts
+// (optionally only those with the "label" query parameter) and their sum
+// per jail. Unreachable hosts are listed with their error.
+func FleetSummaryHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	label := c.Query("label")
+	hosts := forEachHost(fleetHosts(label), func(h fleetHost) fleetHostSummary {
+		s := fleetHostSummary{fleetHost: h, Jails: []fleetJail{}, Health: fail2ban.HostHealth{Status: fail2ban.HostOK}}
+		var jails []fail2ban.JailInfo
+		var err error
+		if h.Local {
+			jails, err = cachedJailInfos(ctx)
+		} else {
+			events, _ := fail2ban.RemoteBans(ctx, h.remote)
+			jails, err = fail2ban.RemoteJailInfos(ctx, h.remote, events)
+			s.Health = fail2ban.GetHostHealth(h.ID)
+		}
+		if err != nil {
+			s.Error = err.Error()
+			return s
+		}
+		for _, j := range jails {
+			s.Jails = append(s.Jails, fleetJail{JailName: j.JailName, TotalBanned: j.TotalBanned, NewInLastHour: j.NewInLastHour})
+		}
+		s.Totals = sumJails(jails)
+		return s
+	})
+
+	perJail := map[string]*fleetJail{}
+	var totals SummaryTotals
+	reachable := 0
+	for _, h := range hosts {
+		if h.Error == "" {
+			reachable++
+		}
+		totals.TotalBanned += h.Totals.TotalBanned
+		totals.NewInLastHour += h.Totals.NewInLastHour
+		totals.BannedByFamily.Merge(h.Totals.BannedByFamily)
+		totals.NewInLastHourByFamily.Merge(h.Totals.NewInLastHourByFamily)
+		totals.CurrentlyFailed += h.Totals.CurrentlyFailed
+		totals.TotalFailed += h.Totals.TotalFailed
+		for _, j := range h.Jails {
+			sum := perJail[j.JailName]
+			if sum == nil {
+				sum = &fleetJail{JailName: j.JailName}
+				perJail[j.JailName] = sum
+			}
+			sum.TotalBanned += j.TotalBanned
+			sum.NewInLastHour += j.NewInLastHour
+			sum.Hosts++
+		}
+	}
+	jails := []fleetJail{}
+	for _, j := range perJail {
+		jails = append(jails, *j)
+	}
+	sort.Slice(jails, func(i, k int) bool {
+		if jails[i].TotalBanned != jails[k].TotalBanned {
+			return jails[i].TotalBanned > jails[k].TotalBanned
+		}
+		return jails[i].JailName < jails[k].JailName
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"label":     label,
+		"labels":    fleetLabels(),
+		"hosts":     hosts,
+		"jails":     jails,
+		"totals":    totals,
+		"reachable": reachable,
+	})
+}
+
+// Actions that can be run across the fleet
+const (
+	fleetUnban  = "unban"
+	fleetReload = "reload"
+)
+
+type fleetActionRequest struct {
+	Action string `json:"action" binding:"required"`
+	Label  string `json:"label"` // all hosts if empty
+	IP     string `json:"ip"`    // for unban
+	Jail   string `json:"jail"`  // for unban, all jails if empty
+}
+
+type fleetActionResult struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// FleetActionHandler unbans an IP or reloads fail2ban on all hosts with a
+// label and reports the result of every host.
+func FleetActionHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("FleetActionHandler called (fleet.go)") // entry point
+	var req fleetActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	var run func(fleetHost) error
+	switch req.Action {
+	case fleetUnban:
+		ip, err := fail2ban.NormalizeIP(req.IP)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Jail != "" {
+			if err := fail2ban.ValidateJailName(req.Jail); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		req.IP = ip
+		ctx := c.Request.Context()
+		run = func(h fleetHost) error {
+			switch {
+			case h.Local && req.Jail != "":
+				return fail2ban.UnbanIP(ctx, req.Jail, ip)
+			case h.Local:
+				return fail2ban.UnbanIPAll(ctx, ip)
+			case req.Jail != "":
+				return fail2ban.RemoteUnbanIP(ctx, h.remote, req.Jail, ip)
+			default:
+				return fail2ban.RemoteUnbanAll(ctx, h.remote, ip)
+			}
+		}
+	case fleetReload:
+		if !config.HasRole(requestRole(c), config.RoleAdmin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "requires the " + config.RoleAdmin + " role"})
+			return
+		}
+		run = func(h fleetHost) error {
+			if h.Local {
+				return fail2ban.ReloadFail2ban()
+			}
+			return fail2ban.RemoteReload(h.remote)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown action " + req.Action})
+		return
+	}
+
+	hosts := fleetHosts(req.Label)
+	if len(hosts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no host has the label " + req.Label})
+		return
+	}
+	results := forEachHost(hosts, func(h fleetHost) fleetActionResult {
+		r := fleetActionResult{ID: h.ID, Name: h.Name, OK: true}
+		if err := run(h); err != nil {
+			r.OK, r.Error = false, err.Error()
+		}
+		return r
+	})
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+
+	detail := req.Action
+	if req.Action == fleetUnban {
+		detail += " " + req.IP
+	}
+	if req.Label != "" {
+		detail += " on " + req.Label
+	}
+	recordAudit(c, config.AuditEntry{Action: "fleet." + req.Action, Detail: detail})
+	c.JSON(http.StatusOK, gin.H{"results": results, "ok": len(results) - failed, "failed": failed})
+}
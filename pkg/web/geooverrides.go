@@ -0,0 +1,124 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+type geoOverrideRequest struct {
+	Network string `json:"network" binding:"required"`
+	Country string `json:"country" binding:"required"`
+	ASN     uint   `json:"asn"`
+	ASOrg   string `json:"asOrg"`
+	Comment string `json:"comment"`
+}
+
+func (r geoOverrideRequest) override() config.GeoOverride {
+	return config.GeoOverride{Network: r.Network, Country: r.Country, ASN: r.ASN, ASOrg: r.ASOrg, Comment: r.Comment}
+}
+
+// ListGeoOverridesHandler returns the GeoIP overrides.
+func ListGeoOverridesHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	overrides, next := paginate(page, config.GetGeoOverrides())
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides, "nextCursor": next})
+}
+
+// AddGeoOverrideHandler creates a GeoIP override.
+func AddGeoOverrideHandler(c *gin.Context) {
+	var req geoOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	o, err := config.AddGeoOverride(req.override())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordChange(c, "Add GeoIP override %s (%s)", o.Network, o.Country)
+	c.JSON(http.StatusOK, gin.H{"override": o, "updatedIPs": refreshOverriddenGeo(o)})
+}
+
+// UpdateGeoOverrideHandler changes a GeoIP override.
+func UpdateGeoOverrideHandler(c *gin.Context) {
+	var req geoOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	old, _ := findGeoOverride(c.Param("id"))
+	o, err := config.UpdateGeoOverride(c.Param("id"), req.override())
+	if err != nil {
+		c.JSON(geoOverrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordChange(c, "Update GeoIP override %s (%s)", o.Network, o.Country)
+	c.JSON(http.StatusOK, gin.H{"override": o, "updatedIPs": refreshOverriddenGeo(old, o)})
+}
+
+// DeleteGeoOverrideHandler removes a GeoIP override.
+func DeleteGeoOverrideHandler(c *gin.Context) {
+	old, _ := findGeoOverride(c.Param("id"))
+	if err := config.DeleteGeoOverride(c.Param("id")); err != nil {
+		c.JSON(geoOverrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordChange(c, "Delete GeoIP override %s", old.Network)
+	c.JSON(http.StatusOK, gin.H{"message": "GeoIP override deleted", "updatedIPs": refreshOverriddenGeo(old)})
+}
+
+func findGeoOverride(id string) (config.GeoOverride, bool) {
+	for _, o := range config.GetGeoOverrides() {
+		if o.ID == id {
+			return o, true
+		}
+	}
+	return config.GeoOverride{}, false
+}
+
+// refreshOverriddenGeo looks up the stored bans in the networks of the
+// changed overrides again and returns the number of IPs updated.
+func refreshOverriddenGeo(overrides ...config.GeoOverride) int {
+	var nets []*net.IPNet
+	for _, o := range overrides {
+		if _, n, err := net.ParseCIDR(o.Network); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	if len(nets) == 0 {
+		return 0
+	}
+	return fail2ban.RefreshGeo(nets)
+}
+
+func geoOverrideErrorStatus(err error) int {
+	if errors.Is(err, config.ErrGeoOverrideNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
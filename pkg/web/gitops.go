@@ -0,0 +1,79 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/gitops"
+)
+
+// recordChange adds a configuration change of the request's user to the
+// Git history, see internal/gitops.
+func recordChange(c *gin.Context, format string, args ...any) {
+	gitops.Record(currentUser(c), fmt.Sprintf(format, args...))
+}
+
+// GitOpsStatusHandler returns the repository status and the last commit and push.
+func GitOpsStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gitops.GetStatus())
+}
+
+// GitOpsSyncHandler commits all changes of /etc/fail2ban, including edits
+// made outside the UI, and pushes them.
+func GitOpsSyncHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("GitOpsSyncHandler called (gitops.go)") // entry point
+	if !config.GetSettings().Integrations.GitOps.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "gitops is disabled"})
+		return
+	}
+	gitops.Record(currentUser(c), "Synchronize configuration")
+	if err := gitops.Sync(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "status": gitops.GetStatus()})
+		return
+	}
+	c.JSON(http.StatusOK, gitops.GetStatus())
+}
+
+// GitOpsHistoryHandler returns the last commits, in pages of 50 by default.
+func GitOpsHistoryHandler(c *gin.Context) {
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+	commits, err := gitops.History(page.End())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	commits, next := paginate(page, commits)
+	c.JSON(http.StatusOK, gin.H{"commits": commits, "nextCursor": next})
+}
+
+// GitOpsCommitHandler returns the message and diff of a commit.
+func GitOpsCommitHandler(c *gin.Context) {
+	diff, err := gitops.Show(c.Param("hash"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"hash": c.Param("hash"), "diff": diff})
+}
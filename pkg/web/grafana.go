@@ -0,0 +1,327 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// Metrics of the Grafana datasource. The first ones are time series, the
+// others tables.
+const (
+	grafanaBans          = "bans"
+	grafanaBansByJail    = "bans_by_jail"
+	grafanaBansByCountry = "bans_by_country"
+	grafanaBanEvents     = "ban_events"
+	grafanaJails         = "jails"
+	grafanaCountries     = "countries"
+)
+
+// grafanaMetrics lists the metrics with their label in Grafana's query editor.
+var grafanaMetrics = []struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}{
+	{"Bans", grafanaBans},
+	{"Bans per jail", grafanaBansByJail},
+	{"Bans per country", grafanaBansByCountry},
+	{"Ban events (table)", grafanaBanEvents},
+	{"Jails (table)", grafanaJails},
+	{"Countries (table)", grafanaCountries},
+}
+
+const (
+	// maxGrafanaPoints bounds the intervals of a time series.
+	maxGrafanaPoints = 10000
+	// defaultGrafanaRows is the number of ban events of a table without a limit.
+	defaultGrafanaRows = 1000
+)
+
+// grafanaPayload are the options of a query, set in the payload editor of
+// the JSON datasource or as query parameters, e.g. {"jail": "sshd"}.
+type grafanaPayload struct {
+	Jail  string `json:"jail"`
+	Limit int    `json:"limit"`
+}
+
+// grafanaColumn is a table column of the JSON datasource.
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaTestHandler answers the connection test of the JSON datasource.
+func GrafanaTestHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GrafanaMetricsHandler lists the metrics for the query editor of the JSON
+// datasource (POST /metrics), or only their names for the older
+// SimpleJSON datasource (POST /search).
+func GrafanaMetricsHandler(c *gin.Context) {
+	if apiRoute(c) == "/api/grafana/search" {
+		names := make([]string, len(grafanaMetrics))
+		for i, m := range grafanaMetrics {
+			names[i] = m.Value
+		}
+		c.JSON(http.StatusOK, names)
+		return
+	}
+	c.JSON(http.StatusOK, grafanaMetrics)
+}
+
+// GrafanaQueryHandler answers the queries of a Grafana panel in the format
+// of the JSON datasource: time series as target with [value, unix ms]
+// datapoints, tables as columns and rows. The intervals of a time series
+// follow intervalMs, made longer if there would be more than
+// maxDataPoints of them.
+func GrafanaQueryHandler(c *gin.Context) {
+	var req struct {
+		Range struct {
+			From time.Time `json:"from"`
+			To   time.Time `json:"to"`
+		} `json:"range"`
+		IntervalMs    int64 `json:"intervalMs"`
+		MaxDataPoints int   `json:"maxDataPoints"`
+		Targets       []struct {
+			RefID   string          `json:"refId"`
+			Target  string          `json:"target"`
+			Hide    bool            `json:"hide"`
+			Payload json.RawMessage `json:"payload"`
+		} `json:"targets"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	from, to := req.Range.From, req.Range.To
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "range.from must be before range.to"})
+		return
+	}
+	step := grafanaStep(from, to, time.Duration(req.IntervalMs)*time.Millisecond, req.MaxDataPoints)
+
+	results := []any{}
+	for _, t := range req.Targets {
+		if t.Hide || t.Target == "" {
+			continue
+		}
+		// The payload is an object in current versions of the datasource,
+		// other values are ignored.
+		var payload grafanaPayload
+		_ = json.Unmarshal(t.Payload, &payload)
+		switch t.Target {
+		case grafanaBans, grafanaBansByJail, grafanaBansByCountry:
+			for _, s := range grafanaSeries(c, t.Target, from, to, step, payload) {
+				points := make([][2]int64, len(s.Counts))
+				for i, n := range s.Counts {
+					points[i] = [2]int64{int64(n), from.Add(time.Duration(i) * step).UnixMilli()}
+				}
+				results = append(results, gin.H{"target": s.Name, "refId": t.RefID, "datapoints": points})
+			}
+		case grafanaBanEvents, grafanaJails, grafanaCountries:
+			columns, rows := grafanaTable(c, t.Target, from, to, payload)
+			results = append(results, gin.H{"type": "table", "refId": t.RefID, "columns": columns, "rows": rows})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown metric %q", t.Target)})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GrafanaSeriesHandler returns a time series as a flat list of
+// {"time", "series", "value"} rows for the Infinity datasource, e.g.
+// ?metric=bans_by_jail&from=${__from}&to=${__to}&interval=1h. from and to
+// are unix milliseconds, dates or RFC 3339 timestamps and default to the
+// last 24 hours; interval is a duration and defaults to an hour.
+func GrafanaSeriesHandler(c *gin.Context) {
+	metric := c.DefaultQuery("metric", grafanaBans)
+	switch metric {
+	case grafanaBans, grafanaBansByJail, grafanaBansByCountry:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown time series %q", metric)})
+		return
+	}
+	from, to, ok := grafanaRange(c)
+	if !ok {
+		return
+	}
+	interval, err := time.ParseDuration(c.DefaultQuery("interval", "1h"))
+	if err != nil || interval <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interval"})
+		return
+	}
+	step := grafanaStep(from, to, interval, 0)
+
+	type row struct {
+		Time   time.Time `json:"time"`
+		Series string    `json:"series"`
+		Value  int       `json:"value"`
+	}
+	rows := []row{}
+	for _, s := range grafanaSeries(c, metric, from, to, step, grafanaPayload{Jail: c.Query("jail")}) {
+		for i, n := range s.Counts {
+			rows = append(rows, row{Time: from.Add(time.Duration(i) * step), Series: s.Name, Value: n})
+		}
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+// GrafanaTableHandler returns a table as a list of objects for the
+// Infinity datasource, e.g. ?metric=countries&from=${__from}&to=${__to}.
+// The range is given as for GrafanaSeriesHandler.
+func GrafanaTableHandler(c *gin.Context) {
+	metric := c.DefaultQuery("metric", grafanaBanEvents)
+	switch metric {
+	case grafanaBanEvents, grafanaJails, grafanaCountries:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown table %q", metric)})
+		return
+	}
+	from, to, ok := grafanaRange(c)
+	if !ok {
+		return
+	}
+	payload := grafanaPayload{Jail: c.Query("jail")}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		payload.Limit = n
+	}
+
+	columns, rows := grafanaTable(c, metric, from, to, payload)
+	objects := make([]map[string]any, len(rows))
+	for i, r := range rows {
+		obj := make(map[string]any, len(columns))
+		for j, col := range columns {
+			obj[col.Text] = r[j]
+		}
+		objects[i] = obj
+	}
+	c.JSON(http.StatusOK, objects)
+}
+
+// grafanaSeries counts the bans in [from, to) per step for a time series
+// metric, of the jail of the payload if set.
+func grafanaSeries(c *gin.Context, metric string, from, to time.Time, step time.Duration, p grafanaPayload) []fail2ban.BanSeries {
+	var group func(fail2ban.BanEvent) string
+	switch metric {
+	case grafanaBansByJail:
+		group = func(ev fail2ban.BanEvent) string { return ev.Jail }
+	case grafanaBansByCountry:
+		group = func(ev fail2ban.BanEvent) string { return cmp.Or(ev.Country, "unknown") }
+	}
+	return fail2ban.Events().BanTimeSeries(from, to, step, grafanaVisible(c, p.Jail), group)
+}
+
+// grafanaTable builds a table metric over the bans in [from, to), of the
+// jail of the payload if set. Ban events are limited to the payload's
+// limit, the newest first.
+func grafanaTable(c *gin.Context, metric string, from, to time.Time, p grafanaPayload) ([]grafanaColumn, [][]any) {
+	visible := grafanaVisible(c, p.Jail)
+	rows := [][]any{}
+	switch metric {
+	case grafanaJails:
+		for _, j := range fail2ban.Events().Summarize(from, to, visible, 0).Jails {
+			rows = append(rows, []any{j.Jail, j.Bans, j.UniqueIPs})
+		}
+		return []grafanaColumn{{"jail", "string"}, {"bans", "number"}, {"uniqueIPs", "number"}}, rows
+	case grafanaCountries:
+		sum := fail2ban.Events().Summarize(from, to, visible, maxGrafanaPoints)
+		for _, cs := range sum.TopCountries {
+			rows = append(rows, []any{cs.Country, cs.Bans})
+		}
+		return []grafanaColumn{{"country", "string"}, {"bans", "number"}}, rows
+	}
+	limit := p.Limit
+	if limit <= 0 || limit > maxGrafanaPoints {
+		limit = defaultGrafanaRows
+	}
+	events := fail2ban.Events().Filter(func(ev fail2ban.BanEvent) bool {
+		return !ev.Time.Before(from) && ev.Time.Before(to) && (visible == nil || visible(ev.Jail))
+	}, limit)
+	for _, ev := range events {
+		rows = append(rows, []any{ev.Time.UnixMilli(), ev.Jail, ev.IP, ev.Country})
+	}
+	return []grafanaColumn{{"time", "time"}, {"jail", "string"}, {"ip", "string"}, {"country", "string"}}, rows
+}
+
+// grafanaVisible restricts the bans to jail if not empty and to the jails
+// the request may see; nil if neither applies.
+func grafanaVisible(c *gin.Context, jail string) func(string) bool {
+	if jail == "" && requestTenant(c) == nil {
+		return nil
+	}
+	return func(j string) bool { return (jail == "" || j == jail) && jailVisible(c, j) }
+}
+
+// grafanaStep returns the length of the intervals of a time series over
+// [from, to): interval, but at least a minute and long enough for at most
+// maxPoints (up to maxGrafanaPoints) intervals.
+func grafanaStep(from, to time.Time, interval time.Duration, maxPoints int) time.Duration {
+	if maxPoints <= 0 || maxPoints > maxGrafanaPoints {
+		maxPoints = maxGrafanaPoints
+	}
+	step := max(interval, time.Minute)
+	if least := (to.Sub(from) + time.Duration(maxPoints) - 1) / time.Duration(maxPoints); step < least {
+		step = least.Truncate(time.Second) + time.Second
+	}
+	return step
+}
+
+// grafanaRange parses the from and to query parameters of the Infinity
+// endpoints. It responds with an error if they are invalid.
+func grafanaRange(c *gin.Context) (from, to time.Time, ok bool) {
+	to = time.Now()
+	from = to.Add(-24 * time.Hour)
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			*p.t = time.UnixMilli(ms)
+			continue
+		}
+		t, _, err := parseTimeParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.name + ": " + err.Error()})
+			return from, to, false
+		}
+		*p.t = t
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return from, to, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,137 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+	"github.com/swissmakers/fail2ban-ui/internal/locales"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Settings are the persisted UI and fail2ban settings.
+type Settings = config.AppSettings
+
+// SMTPSettings configure the alert mail server.
+type SMTPSettings = config.SMTPSettings
+
+// Options set the settings file and the other paths the settings are
+// loaded with, see Load.
+type Options = config.Options
+
+// Load loads the settings with opts. Host applications call it before
+// NewHandler to use other paths than those of the environment; only the
+// first call loads the settings.
+func Load(opts Options) error {
+	return config.Load(opts)
+}
+
+// Config configures a Fail2ban UI handler created with NewHandler.
+// The zero value serves the UI at the root path with embedded assets.
+type Config struct {
+	// BasePath is the path the handler is mounted at, e.g. "/security/fail2ban".
+	// The handler expects the full request path, so do not strip the prefix.
+	BasePath string
+	// LogPath is the fail2ban log loaded into the event store on start.
+	// Defaults to /var/log/fail2ban.log.
+	LogPath string
+	// TemplateGlob loads the HTML templates from disk instead of the
+	// embedded copies, e.g. "pkg/web/templates/*".
+	TemplateGlob string
+	// LocalesDir serves the translations from disk instead of the embedded copies.
+	LocalesDir string
+	// DisableBackgroundJobs skips the log backfill and the integration
+	// scheduler, e.g. when the host application starts them itself.
+	DisableBackgroundJobs bool
+	// UserFunc returns the name of the user authenticated by the host
+	// application. It is used for per-user state and tenant scoping.
+	UserFunc func(r *http.Request) string
+}
+
+// basePathKey is the gin context key holding Config.BasePath.
+const basePathKey = "basePath"
+
+// hostAuthentication is set if the host application authenticates the
+// users, see Config.UserFunc.
+var hostAuthentication bool
+
+// NewHandler returns the complete Fail2ban UI, including the dashboard and
+// the API, as an http.Handler that can be mounted into another application:
+//
+//	mux.Handle("/security/fail2ban/", web.NewHandler(web.Config{BasePath: "/security/fail2ban"}))
+//
+// Settings are shared process wide, so only one handler should be created.
+// They are loaded with the options of the environment (F2BUI_SETTINGS_PATH,
+// ...) unless the host application called Load before.
+func NewHandler(cfg Config) http.Handler {
+	if err := config.EnsureLoaded(); err != nil {
+		log.Printf("⚠️ Could not load the settings: %v", err)
+	}
+	router := gin.New()
+	router.Use(gin.Logger(), recoverPanics)
+	if cfg.TemplateGlob != "" {
+		router.LoadHTMLGlob(cfg.TemplateGlob)
+	} else {
+		router.SetHTMLTemplate(template.Must(template.ParseFS(templateFS, "templates/*")))
+	}
+
+	hostAuthentication = cfg.UserFunc != nil
+	basePath := strings.TrimSuffix(cfg.BasePath, "/")
+	base := router.Group(basePath+"/", func(c *gin.Context) {
+		c.Set(basePathKey, basePath)
+		if cfg.UserFunc != nil {
+			if user := cfg.UserFunc(c.Request); user != "" {
+				c.Set("user", user)
+			}
+		}
+		c.Next()
+	}, accessControl, rateLimit, authenticate, checkCSRF)
+	if cfg.LocalesDir != "" {
+		localeAssets = newAssetDir(os.DirFS(cfg.LocalesDir))
+	} else {
+		localeAssets = newAssetDir(locales.FS())
+	}
+	base.GET("/locales/:file", localeAssets.serve)
+
+	if !cfg.DisableBackgroundJobs {
+		logPath := cfg.LogPath
+		if logPath == "" {
+			logPath = fail2ban.DefaultLogPath
+		}
+		// Load the ban history in the background so the API is available right away.
+		fail2ban.StartBackfill(logPath)
+		// Start the scheduler fetching third-party data (cloud ranges, ...).
+		integrations.Start()
+	}
+
+	// Process the ban notifications left in the queue by the last run.
+	startBanWorkers()
+
+	RegisterRoutes(base)
+	return router
+}
@@ -17,91 +17,457 @@
 package web
 
 import (
-	"bytes"
-	"crypto/tls"
+	"context"
 	"errors"
 	"fmt"
-	"io"
+	"html"
 	"log"
-	"net"
 	"net/http"
 	"net/smtp"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/oschwald/maxminddb-golang"
 	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/expr"
 	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+	"github.com/swissmakers/fail2ban-ui/internal/jobs"
+	"github.com/swissmakers/fail2ban-ui/internal/locales"
+	"github.com/swissmakers/fail2ban-ui/internal/mailreply"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+	"github.com/swissmakers/fail2ban-ui/internal/whois"
 )
 
 // SummaryResponse is what we return from /api/summary
 type SummaryResponse struct {
-	Jails    []fail2ban.JailInfo `json:"jails"`
-	LastBans []fail2ban.BanEvent `json:"lastBans"`
+	Jails    []fail2ban.JailInfo     `json:"jails"`
+	LastBans []fail2ban.BanEvent     `json:"lastBans"`
+	Backfill fail2ban.BackfillStatus `json:"backfill"`
+	Demo     bool                    `json:"demo,omitempty"`
+	Totals   SummaryTotals           `json:"totals"`
+	// Countries ranks the countries of the banned IPs, see fail2ban.BannedCountries.
+	Countries []fail2ban.CountryStat `json:"countries"`
+}
+
+// SummaryTotals sums the counters of all visible jails, in total and by
+// address family.
+type SummaryTotals struct {
+	TotalBanned           int                   `json:"totalBanned"`
+	NewInLastHour         int                   `json:"newInLastHour"`
+	BannedByFamily        fail2ban.FamilyCounts `json:"bannedByFamily"`
+	NewInLastHourByFamily fail2ban.FamilyCounts `json:"newInLastHourByFamily"`
+	CurrentlyFailed       int                   `json:"currentlyFailed"`
+	TotalFailed           int                   `json:"totalFailed"`
+}
+
+func sumJails(jails []fail2ban.JailInfo) SummaryTotals {
+	var t SummaryTotals
+	for _, j := range jails {
+		t.TotalBanned += j.TotalBanned
+		t.NewInLastHour += j.NewInLastHour
+		t.BannedByFamily.Merge(j.BannedByFamily)
+		t.NewInLastHourByFamily.Merge(j.NewInLastHourByFamily)
+		t.CurrentlyFailed += j.CurrentlyFailed
+		t.TotalFailed += j.TotalFailed
+	}
+	return t
 }
 
 // SummaryHandler returns a JSON summary of all jails, including
 // number of banned IPs, how many are new in the last hour, etc.
-// and the last 5 overall ban events from the log.
+// and the last 5 overall ban events from the event store.
+// In sample-data mode fake jails and bans flagged as demo are added.
 func SummaryHandler(c *gin.Context) {
-	const logPath = "/var/log/fail2ban.log"
+	sampleData := config.GetSettings().Server.SampleData
+
+	jailInfos, err := cachedJailInfos(c.Request.Context())
+	if err != nil && !sampleData {
+		respondError(c, err)
+		return
+	}
+
+	resp := SummaryResponse{
+		Jails:    jailInfos,
+		LastBans: fail2ban.Events().Latest(5),
+		Backfill: fail2ban.GetBackfillStatus(),
+	}
+	if requestTenant(c) != nil {
+		resp.LastBans = fail2ban.Events().Filter(func(ev fail2ban.BanEvent) bool { return jailVisible(c, ev.Jail) }, 5)
+	}
+	if sampleData {
+		resp.Jails = append(resp.Jails, fail2ban.SampleJailInfos()...)
+		resp.LastBans = append(fail2ban.SampleBanEvents(), resp.LastBans...)
+		resp.Demo = true
+	}
+	resp.Jails = visibleJails(c, resp.Jails)
+	resp.Totals = sumJails(resp.Jails)
+	resp.Countries = fail2ban.BannedCountries(resp.Jails)
+	resp.LastBans = visibleEvents(c, resp.LastBans)
+	if len(resp.LastBans) > 5 {
+		resp.LastBans = resp.LastBans[:5]
+	}
+	markWatched(&resp)
+	tagEvents(resp.LastBans)
+	c.JSON(http.StatusOK, resp)
+}
 
-	jailInfos, err := fail2ban.BuildJailInfos(logPath)
+// JailStatusHandler returns the counters and banned IPs of all jails in
+// one call. Unlike the summary it is never cached and reports jails that
+// could not be queried with their error.
+func JailStatusHandler(c *gin.Context) {
+	snap, err := fail2ban.GetAllJailStatus(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
+	jails := snap.Jails[:0:0]
+	for _, j := range snap.Jails {
+		if jailVisible(c, j.Jail) {
+			jails = append(jails, j)
+		}
+	}
+	snap.Jails = jails
+	c.JSON(http.StatusOK, snap)
+}
 
-	// Parse the log to find last 5 ban events
-	eventsByJail, err := fail2ban.ParseBanLog(logPath)
-	lastBans := make([]fail2ban.BanEvent, 0)
-	if err == nil {
-		// If we can parse logs successfully, let's gather all events
-		var all []fail2ban.BanEvent
-		for _, evs := range eventsByJail {
-			all = append(all, evs...)
+// IPEventsHandler returns all known ban events of a single IP.
+func IPEventsHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	visible := visibleEvents(c, fail2ban.Events().ByIP(ip))
+	events, next := paginate(page, visible)
+	tagEvents(events)
+	resp := gin.H{
+		"ip":         ip,
+		"tags":       ipTags(ip),
+		"events":     events,
+		"nextCursor": next,
+	}
+	if note, ok := config.GetIPNote(ip); ok && (requestTenant(c) == nil || len(visible) > 0) {
+		resp["note"] = note
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RelatedIPsHandler returns the other banned IPs in the same /24 and /16
+// (/64 and /48 for IPv6) and the same AS as the given IP.
+func RelatedIPsHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	related, err := fail2ban.Events().Related(ip, func(ev fail2ban.BanEvent) bool { return jailVisible(c, ev.Jail) })
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, related)
+}
+
+// JailEventsHandler returns the most recent ban events of a jail.
+func JailEventsHandler(c *gin.Context) {
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+	events, next := paginate(page, fail2ban.Events().RecentByJail(jail, page.End()))
+	c.JSON(http.StatusOK, gin.H{
+		"jail":       jail,
+		"events":     events,
+		"nextCursor": next,
+	})
+}
+
+// CountryStatsHandler returns the number of ban events per country, in
+// total and by address family.
+func CountryStatsHandler(c *gin.Context) {
+	var visible func(string) bool
+	if requestTenant(c) != nil {
+		visible = func(jail string) bool { return jailVisible(c, jail) }
+	}
+	byFamily := fail2ban.Events().CountryFamilyCounts(visible)
+	if visible == nil {
+		c.JSON(http.StatusOK, gin.H{"countries": fail2ban.Events().CountryCounts(), "byFamily": byFamily})
+		return
+	}
+	counts := make(map[string]int)
+	for jail, events := range fail2ban.Events().ByJail() {
+		if !jailVisible(c, jail) {
+			continue
 		}
-		// Sort by descending time
-		sortByTimeDesc(all)
-		if len(all) > 5 {
-			lastBans = all[:5]
-		} else {
-			lastBans = all
+		for _, ev := range events {
+			if ev.Country != "" {
+				counts[ev.Country]++
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"countries": counts, "byFamily": byFamily})
+}
+
+// PortStatsHandler counts the bans per targeted port and interface, overall
+// and per jail, so hosts with several listeners see which one is attacked.
+// Bans without a known port or interface are not counted.
+func PortStatsHandler(c *gin.Context) {
+	ports := make(map[string]int)
+	interfaces := make(map[string]int)
+	byJail := make(map[string]map[string]int)
+	for jail, events := range fail2ban.Events().ByJail() {
+		if !jailVisible(c, jail) {
+			continue
+		}
+		for _, ev := range events {
+			if ev.Port != "" {
+				ports[ev.Port]++
+				if byJail[jail] == nil {
+					byJail[jail] = make(map[string]int)
+				}
+				byJail[jail][ev.Port]++
+			}
+			if ev.Interface != "" {
+				interfaces[ev.Interface]++
+			}
 		}
 	}
+	c.JSON(http.StatusOK, gin.H{"ports": ports, "interfaces": interfaces, "byJail": byJail})
+}
 
-	resp := SummaryResponse{
-		Jails:    jailInfos,
-		LastBans: lastBans,
+// HistoricalSummaryHandler summarizes the bans of a past period from the
+// event store, e.g. ?from=2025-07-01&to=2025-07-14. Dates include the whole
+// day of "to", RFC 3339 timestamps are used as they are. Without "from" the
+// last 7 days are summarized.
+func HistoricalSummaryHandler(c *gin.Context) {
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		t, dateOnly, err := parseTimeParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+		to = t
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
 	}
-	c.JSON(http.StatusOK, resp)
+	from := to.AddDate(0, 0, -7)
+	if v := c.Query("from"); v != "" {
+		t, _, err := parseTimeParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top <= 0 || top > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top must be between 1 and 100"})
+		return
+	}
+
+	var visible func(string) bool
+	if requestTenant(c) != nil {
+		visible = func(jail string) bool { return jailVisible(c, jail) }
+	}
+	sum := fail2ban.Events().Summarize(from, to, visible, top)
+	sum.Incidents = visibleIncidents(c, config.IncidentsBetween(from, to))
+	c.JSON(http.StatusOK, sum)
+}
+
+// parseTimeParam parses a date ("2006-01-02") or an RFC 3339 timestamp.
+func parseTimeParam(v string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// IndexStatsHandler exposes the size of the in-memory event indexes for debugging.
+func IndexStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, fail2ban.Events().Stats())
+}
+
+// BackfillStatusHandler reports the progress of the initial ban log backfill.
+func BackfillStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, fail2ban.GetBackfillStatus())
 }
 
 // UnbanIPHandler unbans a given IP in a specific jail.
 func UnbanIPHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("UnbanIPHandler called (handlers.go)") // entry point
-	jail := c.Param("jail")
-	ip := c.Param("ip")
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
 
-	err := fail2ban.UnbanIP(jail, ip)
+	err := fail2ban.UnbanIP(c.Request.Context(), jail, ip)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
-	fmt.Println(ip + " from jail " + jail + " unbanned successfully.")
+	log.Printf("✅ %s from jail %s unbanned successfully", ip, jail)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "IP unbanned successfully",
 	})
 }
 
+// BanIPHandler bans a given IP in a specific jail.
+func BanIPHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("BanIPHandler called (handlers.go)") // entry point
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+
+	if err := fail2ban.BanIP(c.Request.Context(), jail, ip); err != nil {
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "ban", Detail: ip + " in " + jail})
+	c.JSON(http.StatusOK, gin.H{"message": "IP banned successfully"})
+}
+
+// maxBatchUnban limits the IPs of one BatchUnbanHandler request.
+const maxBatchUnban = 500
+
+type unbanItem struct {
+	Jail string `json:"jail"`
+	IP   string `json:"ip"`
+}
+
+type unbanResult struct {
+	Jail  string `json:"jail,omitempty"`
+	IP    string `json:"ip"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchUnbanHandler unbans several IPs in one request, given as (jail, ip)
+// pairs in "items" or as bare IPs in "ips", which are unbanned from every
+// visible jail they are banned in. Each unban is reported on its own, so
+// one failure does not stop the others.
+func BatchUnbanHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("BatchUnbanHandler called (handlers.go)") // entry point
+	var req struct {
+		Items []unbanItem `json:"items"`
+		IPs   []string    `json:"ips"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if len(req.Items)+len(req.IPs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no IPs given"})
+		return
+	}
+	if len(req.Items)+len(req.IPs) > maxBatchUnban {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d IPs per request", maxBatchUnban)})
+		return
+	}
+
+	var results []unbanResult
+	items := req.Items
+	if len(req.IPs) > 0 {
+		jails, err := cachedJailInfos(c.Request.Context())
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		for _, raw := range req.IPs {
+			ip, err := fail2ban.NormalizeIP(raw)
+			if err != nil {
+				results = append(results, unbanResult{IP: raw, Error: err.Error()})
+				continue
+			}
+			found := false
+			for _, j := range jails {
+				if slices.Contains(j.BannedIPs, ip) && jailVisible(c, j.JailName) {
+					items = append(items, unbanItem{Jail: j.JailName, IP: ip})
+					found = true
+				}
+			}
+			if !found {
+				results = append(results, unbanResult{IP: ip, Error: "not banned in any jail"})
+			}
+		}
+	}
+
+	for _, item := range items {
+		r := unbanResult{Jail: item.Jail, IP: item.IP}
+		if fail2ban.ValidateJailName(item.Jail) != nil || !jailVisible(c, item.Jail) {
+			r.Error = "jail not found"
+		} else if err := fail2ban.UnbanIP(c.Request.Context(), item.Jail, item.IP); err != nil {
+			r.Error = err.Error()
+		} else {
+			r.OK = true
+		}
+		results = append(results, r)
+	}
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+	log.Printf("🔓 Batch unban: %d unbanned, %d failed", len(results)-failed, failed)
+	recordAudit(c, config.AuditEntry{Action: "unban.batch", Detail: fmt.Sprintf("%d unbanned, %d failed", len(results)-failed, failed)})
+	c.JSON(http.StatusOK, gin.H{"results": results, "unbanned": len(results) - failed, "failed": failed})
+}
+
+// LogExcerptHandler returns the recent log lines of a jail that mention an IP.
+func LogExcerptHandler(c *gin.Context) {
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	logs, err := fail2ban.LogExcerpt(c.Request.Context(), jail, ip)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jail": jail, "ip": ip, "logs": logs})
+}
+
 // BanNotificationHandler processes incoming ban notifications from Fail2Ban.
 func BanNotificationHandler(c *gin.Context) {
+	start := time.Now()
+	defer func() { metrics.ObserveCallback("total", time.Since(start)) }()
+
 	var request struct {
 		IP       string `json:"ip" binding:"required"`
 		Jail     string `json:"jail" binding:"required"`
@@ -111,23 +477,7 @@ func BanNotificationHandler(c *gin.Context) {
 		Logs     string `json:"logs"`
 	}
 
-	// **DEBUGGING: Log Raw JSON Body**
-	body, _ := io.ReadAll(c.Request.Body)
-	log.Printf("----------------------------------------------------")
-	log.Printf("Request Content-Length: %d", c.Request.ContentLength)
-	log.Printf("Request Headers: %v", c.Request.Header)
-	log.Printf("Request Headers: %v", c.Request.Body)
-
-	log.Printf("----------------------------------------------------")
-
-	config.DebugLog("📩 Incoming Ban Notification: %s\n", string(body))
-
-	// Rebind body so Gin can parse it again (important!)
-	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
-
-	log.Printf("Request Content-Length: %d", c.Request.ContentLength)
-	log.Printf("Request Headers: %v", c.Request.Header)
-	log.Printf("Request Headers: %v", c.Request.Body)
+	config.DebugLog("📩 Incoming Ban Notification (%d bytes)", c.Request.ContentLength)
 
 	// Parse JSON request body
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -139,112 +489,188 @@ func BanNotificationHandler(c *gin.Context) {
 		} else {
 			log.Printf("❌ JSON-Parsing Fehler: %v", err)
 		}
-		log.Printf("Raw JSON: %s", string(body))
+		metrics.CallbackResult(metrics.CallbackInvalid)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
-	// **DEBUGGING: Log Parsed Request**
+	if err := fail2ban.ValidateJailName(request.Jail); err != nil {
+		metrics.CallbackResult(metrics.CallbackInvalid)
+		respondError(c, err)
+		return
+	}
+	ip, err := fail2ban.NormalizeIP(request.IP)
+	if err != nil {
+		metrics.CallbackResult(metrics.CallbackInvalid)
+		respondError(c, err)
+		return
+	}
+	request.IP = ip
+	fail2ban.RecordCallback(request.Jail, request.IP)
+
 	log.Printf("✅ Parsed Ban Request - IP: %s, Jail: %s, Hostname: %s, Failures: %s",
 		request.IP, request.Jail, request.Hostname, request.Failures)
 
-	// Handle the Fail2Ban notification
-	if err := HandleBanNotification(request.IP, request.Jail, request.Hostname, request.Failures, request.Whois, request.Logs); err != nil {
-		log.Printf("❌ Failed to process ban notification: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process ban notification: " + err.Error()})
+	// Queue the notification, so fail2ban's ban action does not wait for
+	// GeoIP lookups and SMTP servers.
+	err = enqueueBan(config.QueuedBan{
+		IP:         request.IP,
+		Jail:       request.Jail,
+		Hostname:   request.Hostname,
+		Failures:   request.Failures,
+		Whois:      request.Whois,
+		Logs:       request.Logs,
+		ReceivedAt: start,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to queue ban notification: %v\n", err)
+		metrics.CallbackResult(metrics.CallbackError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, errBanQueueFull) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": "Failed to queue ban notification: " + err.Error()})
 		return
 	}
 
 	// Respond with success
-	c.JSON(http.StatusOK, gin.H{"message": "Ban notification processed successfully"})
+	metrics.CallbackResult(metrics.CallbackOK)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Ban notification queued"})
 }
 
 // HandleBanNotification processes Fail2Ban notifications, checks geo-location, and sends alerts.
 func HandleBanNotification(ip, jail, hostname, failures, whois, logs string) error {
+	return handleBan(time.Now(), ip, jail, hostname, failures, whois, logs)
+}
+
+// handleBan processes a ban notification received at receivedAt.
+func handleBan(receivedAt time.Time, ip, jail, hostname, failures, whoisText, logs string) error {
 	// Load settings to get alert countries
 	settings := config.GetSettings()
 
-	// Lookup the country for the given IP
-	country, err := lookupCountry(ip)
-	if err != nil {
-		log.Printf("⚠️ GeoIP lookup failed for IP %s: %v", ip, err)
-		return err
-	}
-
-	// Check if country is in alert list
-	if !shouldAlertForCountry(country, settings.AlertCountries) {
-		log.Printf("❌ IP %s belongs to %s, which is NOT in alert countries (%v). No alert sent.", ip, country, settings.AlertCountries)
-		return nil
+	// Lookup the country (and ASN, if available) for the given IP
+	stageStart := time.Now()
+	geo, lookupErr := geoip.Lookup(ip)
+	metrics.ObserveCallback("geoip", time.Since(stageStart))
+	country := geo.Country
+
+	// Collect the log lines ourselves; older action files still send them.
+	if logs == "" {
+		stageStart = time.Now()
+		excerpt, err := fail2ban.LogExcerpt(context.Background(), jail, ip)
+		metrics.ObserveCallback("logs", time.Since(stageStart))
+		if err != nil {
+			log.Printf("⚠️ Failed to collect log lines for IP %s: %v", ip, err)
+		}
+		logs = excerpt
+	} else {
+		logs = fail2ban.TruncateExcerpt(logs)
 	}
 
-	// Send email notification
-	if err := sendBanAlert(ip, jail, hostname, failures, whois, logs, country, settings); err != nil {
-		log.Printf("❌ Failed to send alert email: %v", err)
-		return err
+	// Record the ban so it shows up in the summary without re-reading the log
+	target := fail2ban.TargetFor(jail, logs)
+	ev := fail2ban.BanEvent{
+		Time:      receivedAt,
+		Jail:      jail,
+		IP:        ip,
+		Country:   country,
+		ASN:       geo.ASN,
+		ASOrg:     geo.ASOrg,
+		Port:      target.Port,
+		Interface: target.Interface,
+		LogLine:   fmt.Sprintf("ban notification from %s (%s failures)", hostname, failures),
 	}
-
-	log.Printf("✅ Email alert sent for banned IP %s (%s)", ip, country)
-	return nil
-}
-
-// lookupCountry finds the country ISO code for a given IP using MaxMind GeoLite2 database.
-func lookupCountry(ip string) (string, error) {
-	// Convert the IP string to net.IP
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		return "", fmt.Errorf("invalid IP address: %s", ip)
+	fail2ban.Events().Add(ev)
+
+	// Apply the Tor exit node policy of the jail, if any. It does not
+	// depend on the GeoIP lookup, so it runs even if that failed.
+	if integrations.IsTorExit(ip) {
+		if policy, ok := integrations.TorPolicyFor(jail); ok {
+			if policy.PermanentJail != "" && policy.PermanentJail != jail {
+				if err := fail2ban.BanIP(context.Background(), policy.PermanentJail, ip); err != nil {
+					log.Printf("❌ Failed to permanently ban Tor exit %s: %v", ip, err)
+				}
+			}
+			if policy.SuppressAlert {
+				log.Printf("IP %s is a Tor exit node, alerts are suppressed for jail %s. No alert sent.", ip, jail)
+				recordSkippedAlert(ip, jail, "Tor exit node, alerts suppressed by the jail's Tor policy")
+				return nil
+			}
+		}
 	}
 
-	// Open the GeoIP database
-	db, err := maxminddb.Open("/usr/share/GeoIP/GeoLite2-Country.mmdb")
-	if err != nil {
-		return "", fmt.Errorf("failed to open GeoIP database: %w", err)
+	if lookupErr != nil {
+		log.Printf("⚠️ GeoIP lookup failed for IP %s: %v", ip, lookupErr)
+		recordSkippedAlert(ip, jail, "GeoIP lookup failed: "+lookupErr.Error())
+		return lookupErr
 	}
-	defer db.Close()
 
-	// Define the structure to store the lookup result
-	var record struct {
-		Country struct {
-			ISOCode string `maxminddb:"iso_code"`
-		} `maxminddb:"country"`
-	}
+	// Alert routes send matching bans to their own recipients; other bans
+	// go to destemail if the email policy and alert expression allow.
+	recipients, routes := routeAlert(settings, ev)
+	if len(routes) > 0 {
+		config.DebugLog("Ban of %s matches the alert routes %v", ip, routes)
+	} else {
+		// Check if country is in the alert countries of the email policy
+		if policy := settings.PolicyFor(config.ChannelEmail); !policy.AllowsCountry(country) {
+			log.Printf("❌ IP %s belongs to %s, which is NOT in the email alert countries (%v). No alert sent.", ip, country, policy.Countries)
+			recordSkippedAlert(ip, jail, fmt.Sprintf("country %s is not in the email alert countries %v", country, policy.Countries))
+			return nil
+		}
 
-	// Perform the lookup using net.IP type
-	if err := db.Lookup(parsedIP, &record); err != nil {
-		return "", fmt.Errorf("GeoIP lookup error: %w", err)
+		// Check the optional alert expression
+		if matched, err := expr.Match(settings.Notifications.AlertExpression, eventFields(ev)); err != nil || !matched {
+			log.Printf("❌ Ban of %s does not match the alert expression (%v). No alert sent.", ip, err)
+			reason := "does not match the alert expression"
+			if err != nil {
+				reason = "alert expression failed: " + err.Error()
+			}
+			recordSkippedAlert(ip, jail, reason)
+			return nil
+		}
 	}
 
-	// Return the country code
-	return record.Country.ISOCode, nil
-}
-
-// shouldAlertForCountry checks if an IP’s country is in the allowed alert list.
-func shouldAlertForCountry(country string, alertCountries []string) bool {
-	if len(alertCountries) == 0 || strings.Contains(strings.Join(alertCountries, ","), "ALL") {
-		return true // If "ALL" is selected, alert for all bans
-	}
-	for _, c := range alertCountries {
-		if strings.EqualFold(country, c) {
-			return true
+	// Use the cached whois record; older action files still send whois
+	// output. Uncached IPs are looked up in the background, the alert
+	// does not wait for them.
+	if whoisText == "" || whoisText == "missing whois program" {
+		whoisText = ""
+		if rec, ok, err := whois.Lookup(ip); ok {
+			whoisText = rec.Text
+		} else if err != nil {
+			config.DebugLog("No whois lookup for %s: %v", ip, err)
 		}
 	}
-	return false
-}
 
-func sortByTimeDesc(events []fail2ban.BanEvent) {
-	for i := 0; i < len(events); i++ {
-		for j := i + 1; j < len(events); j++ {
-			if events[j].Time.After(events[i].Time) {
-				events[i], events[j] = events[j], events[i]
-			}
+	// Send email notification
+	stageStart = time.Now()
+	var errs []error
+	for _, r := range recipients {
+		if err := sendBanAlert(r.to, r.lang, ip, jail, hostname, failures, whoisText, logs, country, settings); err != nil {
+			log.Printf("❌ Failed to send alert email to %s: %v", r.to, err)
+			errs = append(errs, err)
+			continue
 		}
+		log.Printf("✅ Email alert sent to %s for banned IP %s (%s)", r.to, ip, country)
 	}
+	metrics.ObserveCallback("email", time.Since(stageStart))
+	return errors.Join(errs...)
 }
 
 // IndexHandler serves the HTML page
 func IndexHandler(c *gin.Context) {
+	// The page embeds the fingerprinted asset URLs, so it must be revalidated.
+	c.Header("Cache-Control", revalidateCache)
+	var localeURLs map[string]string
+	if localeAssets != nil {
+		localeURLs = localeAssets.urls("/locales/", ".json")
+	}
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"timestamp": time.Now().Format(time.RFC1123),
+		"branding":  currentBranding(c),
+		"basePath":  c.GetString(basePathKey),
+		"locales":   localeURLs,
+		"csrfToken": csrfToken(c),
 	})
 }
 
@@ -252,10 +678,13 @@ func IndexHandler(c *gin.Context) {
 func GetJailFilterConfigHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("GetJailFilterConfigHandler called (handlers.go)") // entry point
-	jail := c.Param("jail")
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
 	cfg, err := fail2ban.GetFilterConfig(jail)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -268,7 +697,10 @@ func GetJailFilterConfigHandler(c *gin.Context) {
 func SetJailFilterConfigHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("SetJailFilterConfigHandler called (handlers.go)") // entry point
-	jail := c.Param("jail")
+	jail, ok := jailParam(c)
+	if !ok {
+		return
+	}
 
 	// Parse JSON body (containing the new filter content)
 	var req struct {
@@ -281,7 +713,7 @@ func SetJailFilterConfigHandler(c *gin.Context) {
 
 	// Write the filter config file to /etc/fail2ban/filter.d/<jail>.conf
 	if err := fail2ban.SetFilterConfig(jail, req.Config); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -291,6 +723,7 @@ func SetJailFilterConfigHandler(c *gin.Context) {
 	//		return
 	//	}
 
+	recordChange(c, "Edit filter %s", jail)
 	c.JSON(http.StatusOK, gin.H{"message": "jail config updated"})
 
 	// Return a simple JSON response without forcing a blocking alert
@@ -309,10 +742,10 @@ func ManageJailsHandler(c *gin.Context) {
 	// This helper should parse both files and return []fail2ban.JailInfo.
 	jails, err := fail2ban.GetAllJails()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load jails: " + err.Error()})
+		respondError(c, fmt.Errorf("failed to load jails: %w", err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"jails": jails})
+	c.JSON(http.StatusOK, gin.H{"jails": visibleJails(c, jails)})
 }
 
 // UpdateJailManagementHandler updates the enabled state for each jail.
@@ -326,9 +759,19 @@ func UpdateJailManagementHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
 		return
 	}
+	for jail := range updates {
+		if err := fail2ban.ValidateJailName(jail); err != nil {
+			respondError(c, err)
+			return
+		}
+		if !jailVisible(c, jail) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "jail not found: " + jail})
+			return
+		}
+	}
 	// Update jail configuration file(s) with the new enabled states.
 	if err := fail2ban.UpdateJailEnabledStates(updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update jail settings: " + err.Error()})
+		respondError(c, fmt.Errorf("failed to update jail settings: %w", err))
 		return
 	}
 	// Restart the Fail2ban service.
@@ -340,22 +783,43 @@ func UpdateJailManagementHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	var states []string
+	for jail, enabled := range updates {
+		states = append(states, fmt.Sprintf("%s=%t", jail, enabled))
+	}
+	slices.Sort(states)
+	recordChange(c, "Set enabled jails: %s", strings.Join(states, ", "))
 	c.JSON(http.StatusOK, gin.H{"message": "Jail settings updated successfully"})
 }
 
-// GetSettingsHandler returns the entire AppSettings struct as JSON
+// GetSettingsHandler returns the entire AppSettings struct as JSON, with
+// the secrets blanked
 func GetSettingsHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("GetSettingsHandler called (handlers.go)") // entry point
 	s := config.GetSettings()
+	s.Auth.TOTP = config.TOTPSettings{}
+	config.RedactSecrets(&s)
 	c.JSON(http.StatusOK, s)
 }
 
+// SettingsSchemaHandler describes the settings sections, their fields and
+// the feature flags, for clients rendering the settings form dynamically.
+func SettingsSchemaHandler(c *gin.Context) {
+	flags := config.GetSettings().Features
+	features := []gin.H{}
+	for _, name := range config.FeatureNames() {
+		features = append(features, gin.H{"name": name, "default": config.FeatureDefault(name), "enabled": flags.Enabled(name)})
+	}
+	c.JSON(http.StatusOK, gin.H{"sections": config.SettingsSchema(), "features": features})
+}
+
 // UpdateSettingsHandler updates the AppSettings from a JSON body
 func UpdateSettingsHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("UpdateSettingsHandler called (handlers.go)") // entry point
-	var req config.AppSettings
+	// Bind onto the current settings, so fields the UI does not send are kept.
+	req := config.CopySettings()
 	if err := c.ShouldBindJSON(&req); err != nil {
 		fmt.Println("JSON binding error:", err) // Debug
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -365,33 +829,187 @@ func UpdateSettingsHandler(c *gin.Context) {
 		return
 	}
 	config.DebugLog("JSON binding successful, updating settings (handlers.go)")
+	// Branding has its own endpoints, which validate the logo file.
+	req.Server.Branding = config.GetSettings().Server.Branding
+	req.Fail2ban.ResolvedIgnoreHosts = config.GetSettings().Fail2ban.ResolvedIgnoreHosts
+	// So has the second factor, which is not sent to clients.
+	req.Auth.TOTP = config.GetSettings().Auth.TOTP
+	// Secrets are blanked in GetSettingsHandler; empty ones are kept.
+	config.KeepSecrets(&req, config.GetSettings())
+
+	if label, err := validateSettings(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label, "details": err.Error()})
+		return
+	}
 
-	newSettings, err := config.UpdateSettings(req)
+	oldOverrides := config.GetGeoOverrides()
+	newSettings, err := applySettings(req)
 	if err != nil {
 		fmt.Println("Error updating settings:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	config.DebugLog("Settings updated successfully (handlers.go)")
+	if !slices.Equal(oldOverrides, newSettings.GeoIP.Overrides) {
+		refreshOverriddenGeo(append(oldOverrides, newSettings.GeoIP.Overrides...)...)
+	}
 
+	recordChange(c, "Update settings")
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Settings updated",
-		"restartNeeded": newSettings.RestartNeeded,
+		"restartNeeded": newSettings.Server.RestartNeeded,
 	})
 }
 
+// validateSettings checks settings about to be saved by the request c and
+// normalizes the recipient languages. On failure it returns a short label
+// for the "error" field of the response along with the details.
+func validateSettings(c *gin.Context, req *config.AppSettings) (string, error) {
+	if err := validateExpression(req.Notifications.AlertExpression); err != nil {
+		return "invalid alert expression", err
+	}
+	for i := range req.Notifications.Routes {
+		route := &req.Notifications.Routes[i]
+		if err := route.Validate(); err != nil {
+			return "invalid alert route", err
+		}
+		if err := validateExpression(route.Expression); err != nil {
+			return "invalid alert route " + route.Name, err
+		}
+	}
+	for _, rule := range req.Fail2ban.EscalationRules {
+		if err := validateExpression(rule.Expression); err != nil {
+			return "invalid escalation rule " + rule.Name, err
+		}
+	}
+	acl, err := req.Auth.Access.Compile()
+	if err != nil {
+		return "invalid access settings", err
+	}
+	if ip, ok := clientAllowed(c, acl); !ok {
+		return "invalid access settings", errors.New("your address " + ip.String() + " would not be allowed")
+	}
+	if err := req.Auth.Validate(); err != nil {
+		return "invalid authentication settings", err
+	}
+	if req.Auth.Mode == config.AuthHeader {
+		// Like the access list, refuse settings that would lock out the admin saving them.
+		if _, role := req.Auth.RoleFromHeaders(c.Request.Header); !acl.FromProxy(c.Request.RemoteAddr) || role != config.RoleAdmin {
+			return "invalid authentication settings", errors.New("this request did not come through a trusted proxy as an admin, you would lock yourself out")
+		}
+	}
+	if p := req.Integrations.Metrics.TextfilePath; p != "" && (!filepath.IsAbs(p) || filepath.Ext(p) != ".prom") {
+		return "invalid metrics settings", errors.New("the textfile path must be absolute and end in .prom")
+	}
+	if s := req.Fail2ban.Socket; s != "" && s != "none" && !filepath.IsAbs(s) {
+		return "invalid fail2ban settings", errors.New("the socket path must be absolute")
+	}
+	if req.Fail2ban.Labels, err = config.NormalizeHostLabels(req.Fail2ban.Labels); err != nil {
+		return "invalid fail2ban settings", err
+	}
+	if err := req.Auth.Sessions.Validate(); err != nil {
+		return "invalid session settings", err
+	}
+	if err := req.Auth.SelfProtection.Validate(req.Auth.Mode != config.AuthNone || hostAuthentication); err != nil {
+		return "invalid self-protection settings", err
+	}
+	if err := config.ValidateIgnoreHosts(req.Fail2ban.IgnoreHosts); err != nil {
+		return "invalid ignore hosts", err
+	}
+	if err := req.Server.Refresh.Validate(); err != nil {
+		return "invalid refresh settings", err
+	}
+	if err := req.Integrations.GitOps.Validate(); err != nil {
+		return "invalid gitops settings", err
+	}
+	if err := req.Integrations.Whois.Validate(); err != nil {
+		return "invalid whois settings", err
+	}
+	if err := req.Notifications.Slack.Validate(); err != nil {
+		return "invalid Slack settings", err
+	}
+	if err := req.Notifications.MailReply.Validate(); err != nil {
+		return "invalid mail reply settings", err
+	}
+	if err := req.Notifications.ErrorAlerts.Validate(); err != nil {
+		return "invalid error alert settings", err
+	}
+	if err := req.Features.Validate(); err != nil {
+		return "invalid feature flags", err
+	}
+	if err := req.Fail2ban.LogHealth.Validate(); err != nil {
+		return "invalid log health settings", err
+	}
+	if err := req.Server.Limits.Validate(); err != nil {
+		return "invalid limits", err
+	}
+	if err := req.Server.ValidateListen(); err != nil {
+		return "invalid listen address", err
+	}
+	if err := req.Server.TLS.Validate(); err != nil {
+		return "invalid TLS settings", err
+	}
+	if err := config.ValidateGeoOverrides(req.GeoIP.Overrides); err != nil {
+		return "invalid GeoIP overrides", err
+	}
+	for _, s := range req.Fail2ban.LogSources {
+		if err := s.Validate(); err != nil {
+			return "invalid log source", err
+		}
+	}
+	for _, t := range req.Auth.Tenants {
+		if err := t.Validate(); err != nil {
+			return "invalid tenant", err
+		}
+	}
+	if len(req.Notifications.RecipientLanguages) > 0 {
+		recipientLanguages := make(map[string]string, len(req.Notifications.RecipientLanguages))
+		for recipient, lang := range req.Notifications.RecipientLanguages {
+			if !locales.Has(lang) {
+				return "unknown language " + lang + " for " + recipient, errors.New("available: " + strings.Join(locales.Languages(), ", "))
+			}
+			recipientLanguages[strings.ToLower(recipient)] = lang
+		}
+		req.Notifications.RecipientLanguages = recipientLanguages
+	}
+	return "", nil
+
+}
+
+// applySettings saves validated settings and rewrites ignoreip in
+// jail.local right away when the ignore hosts changed.
+func applySettings(req config.AppSettings) (config.AppSettings, error) {
+	prev := config.GetSettings()
+	newSettings, err := config.UpdateSettings(req)
+	if err != nil {
+		return newSettings, err
+	}
+	if newSettings.Server.RestartNeeded {
+		checkDriftSoon()
+	}
+	if prev.Server.TLS.Enabled() != newSettings.Server.TLS.Enabled() {
+		// The server and the ban action switch to HTTPS together on start.
+		log.Printf("⚠️ TLS settings changed: restart fail2ban-ui to apply them, the ban action is rewritten on start")
+	}
+	if !slices.Equal(prev.Fail2ban.IgnoreHosts, newSettings.Fail2ban.IgnoreHosts) ||
+		(len(newSettings.Fail2ban.ResolvedIgnoreHosts) > 0 && prev.Fail2ban.IgnoreIP != newSettings.Fail2ban.IgnoreIP) {
+		if err := integrations.RunNow("ignore-hosts"); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+	return newSettings, nil
+}
+
 // ListFiltersHandler returns a JSON array of filter names
 // found as *.conf in /etc/fail2ban/filter.d
 func ListFiltersHandler(c *gin.Context) {
 	config.DebugLog("----------------------------")
 	config.DebugLog("ListFiltersHandler called (handlers.go)") // entry point
-	dir := "/etc/fail2ban/filter.d"
+	dir := fail2ban.FilterDir
 
 	files, err := os.ReadDir(dir)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to read filter directory: " + err.Error(),
-		})
+		respondError(c, fmt.Errorf("failed to read filter directory: %w", err))
 		return
 	}
 
@@ -403,7 +1021,51 @@ func ListFiltersHandler(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"filters": filters})
+	metadata, err := fail2ban.ListFilterMeta()
+	if err != nil {
+		log.Printf("⚠️ Could not read filter metadata: %v", err)
+	}
+	c.JSON(http.StatusOK, gin.H{"filters": filters, "metadata": metadata})
+}
+
+// MatchAnyFilterHandler tests raw log lines against all installed filters
+// and returns, for each line, the filters whose failregex matches it.
+func MatchAnyFilterHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("MatchAnyFilterHandler called (handlers.go)") // entry point
+	var req struct {
+		LogLines []string `json:"logLines"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+	var lines []string
+	for _, line := range req.LogLines {
+		// A pasted block may arrive as a single string.
+		for _, l := range strings.Split(line, "\n") {
+			if strings.TrimSpace(l) != "" {
+				lines = append(lines, l)
+			}
+		}
+	}
+	if len(lines) == 0 || len(lines) > fail2ban.MaxMatchLines {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("between 1 and %d log lines are required", fail2ban.MaxMatchLines)})
+		return
+	}
+
+	matches, failures, err := fail2ban.MatchFilters(c.Request.Context(), lines)
+	if err != nil {
+		respondError(c, fmt.Errorf("failed to test the filters: %w", err))
+		return
+	}
+	matched := 0
+	for _, m := range matches {
+		if len(m.Filters) > 0 {
+			matched++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"lines": matches, "matched": matched, "errors": failures})
 }
 
 func TestFilterHandler(c *gin.Context) {
@@ -417,6 +1079,10 @@ func TestFilterHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
 		return
 	}
+	if err := fail2ban.ValidateJailName(req.FilterName); err != nil {
+		respondError(c, err)
+		return
+	}
 
 	// For now, just pretend nothing matches
 	c.JSON(http.StatusOK, gin.H{"matches": []string{}})
@@ -426,26 +1092,23 @@ func TestFilterHandler(c *gin.Context) {
 func ApplyFail2banSettings(jailLocalPath string) error {
 	config.DebugLog("----------------------------")
 	config.DebugLog("ApplyFail2banSettings called (handlers.go)") // entry point
-	s := config.GetSettings()
 
-	// open /etc/fail2ban/jail.local, parse or do a simplistic approach:
-	// TODO: -> maybe we store [DEFAULT] block in memory, replace lines
-	// or do a line-based approach. Example is simplistic:
-
-	newLines := []string{
-		"[DEFAULT]",
-		fmt.Sprintf("bantime.increment = %t", s.BantimeIncrement),
-		fmt.Sprintf("ignoreip = %s", s.IgnoreIP),
-		fmt.Sprintf("bantime = %s", s.Bantime),
-		fmt.Sprintf("findtime = %s", s.Findtime),
-		fmt.Sprintf("maxretry = %d", s.Maxretry),
-		fmt.Sprintf("destemail = %s", s.Destemail),
-		//fmt.Sprintf("sender = %s", s.Sender),
-		"",
+	// Only the managed keys of [DEFAULT] are replaced, the jails and
+	// comments of the file are kept.
+	if err := config.WriteJailLocalDefaults(jailLocalPath, config.GetSettings()); err != nil {
+		return err
 	}
-	content := strings.Join(newLines, "\n")
+	checkDriftSoon()
+	return nil
+}
 
-	return os.WriteFile(jailLocalPath, []byte(content), 0644)
+// checkDriftSoon compares the jail options of the running daemon with the
+// configuration files in the background, so the values that still need a
+// reload are flagged (see DriftHandler).
+func checkDriftSoon() {
+	if err := integrations.RunNow("config-drift"); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
 }
 
 // RestartFail2banHandler reloads the Fail2ban service
@@ -460,16 +1123,16 @@ func RestartFail2banHandler(c *gin.Context) {
 	//	}
 
 	// Attempt to restart the fail2ban service.
-	restartErr := fail2ban.RestartFail2ban()
-	if restartErr != nil {
+	report := fail2ban.RestartWithReport()
+	if !report.Success {
 		// Check if running inside a container.
 		if _, container := os.LookupEnv("CONTAINER"); container {
 			// In a container, the restart command may fail (since fail2ban runs on the host).
 			// Log the error and continue, so we can mark the restart as done.
-			log.Printf("Warning: restart failed inside container (expected behavior): %v", restartErr)
+			log.Printf("Warning: restart failed inside container (expected behavior): %v", report.Error)
 		} else {
 			// On the host, a restart error is not acceptable.
-			c.JSON(http.StatusInternalServerError, gin.H{"error": restartErr.Error()})
+			respondReloadError(c, report)
 			return
 		}
 	}
@@ -479,81 +1142,249 @@ func RestartFail2banHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Fail2ban restarted successfully"})
+	notifyJailCheck(report.JailCheck)
+	c.JSON(http.StatusOK, gin.H{"message": "Fail2ban restarted successfully", "report": report})
 }
 
-// *******************************************************************
-// *                 Unified Email Sending Function :                *
-// *******************************************************************
-func sendEmail(to, subject, body string, settings config.AppSettings) error {
-	// Validate SMTP settings
-	if settings.SMTP.Host == "" || settings.SMTP.Username == "" || settings.SMTP.Password == "" || settings.SMTP.From == "" {
-		return errors.New("SMTP settings are incomplete. Please configure all required fields")
-	}
+// ReloadFail2banHandler reloads the fail2ban configuration and reports the
+// running jails and ban counts before and after the reload.
+func ReloadFail2banHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ReloadFail2banHandler called (handlers.go)") // entry point
 
-	// Format message with **correct HTML headers**
-	message := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n"+
-		"MIME-Version: 1.0\nContent-Type: text/html; charset=\"UTF-8\"\n\n%s",
-		settings.SMTP.From, to, subject, body)
-	msg := []byte(message)
+	report := fail2ban.ReloadWithReport()
+	if !report.Success {
+		respondReloadError(c, report)
+		return
+	}
+	if err := config.MarkRestartDone(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	notifyJailCheck(report.JailCheck)
+	c.JSON(http.StatusOK, gin.H{"message": "Fail2ban reloaded successfully", "report": report})
+}
 
-	// SMTP Connection Config
-	smtpHost := settings.SMTP.Host
-	smtpPort := settings.SMTP.Port
-	auth := LoginAuth(settings.SMTP.Username, settings.SMTP.Password)
-	smtpAddr := net.JoinHostPort(smtpHost, fmt.Sprintf("%d", smtpPort))
-
-	// **Choose Connection Type**
-	switch smtpPort {
-	case 465:
-		// SMTPS (Implicit TLS) - Not supported at the moment.
-		tlsConfig := &tls.Config{ServerName: smtpHost}
-		conn, err := tls.Dial("tcp", smtpAddr, tlsConfig)
-		if err != nil {
-			return fmt.Errorf("failed to connect via TLS: %w", err)
+// EffectiveConfigHandler returns the parameters fail2ban actually uses per
+// jail (queried from the daemon) and the resolved [DEFAULT] section.
+// Use ?jail=<name> to query a single jail.
+func EffectiveConfigHandler(c *gin.Context) {
+	jail := c.Query("jail")
+	if jail != "" && !jailVisible(c, jail) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "jail not found"})
+		return
+	}
+	cfg, err := fail2ban.GetEffectiveConfig(c.Request.Context(), jail)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	for name := range cfg.Jails {
+		if !jailVisible(c, name) {
+			delete(cfg.Jails, name)
 		}
-		defer conn.Close()
+	}
+	c.JSON(http.StatusOK, cfg)
+}
 
-		client, err := smtp.NewClient(conn, smtpHost)
-		if err != nil {
-			return fmt.Errorf("failed to create SMTP client: %w", err)
-		}
-		defer client.Quit()
+// JailCheckHandler compares the enabled jails from the configuration with
+// the jails running in fail2ban. Use ?cached=true to get the last result.
+func JailCheckHandler(c *gin.Context) {
+	var check fail2ban.JailCheck
+	if c.Query("cached") == "true" {
+		check = fail2ban.LastJailCheck()
+	} else {
+		check = fail2ban.CheckJails(c.Request.Context())
+	}
+	check.Configured = visibleNames(c, check.Configured)
+	check.Running = visibleNames(c, check.Running)
+	check.FailedToStart = visibleNames(c, check.FailedToStart)
+	check.Unexpected = visibleNames(c, check.Unexpected)
+	c.JSON(http.StatusOK, gin.H{"ok": check.OK(), "check": check})
+}
 
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("SMTP authentication failed: %w", err)
+// DriftHandler compares the configured jail options with the values of
+// the running daemon. Use ?cached=true to get the last result.
+func DriftHandler(c *gin.Context) {
+	report := fail2ban.LastDrift()
+	if c.Query("cached") != "true" || report.CheckedAt.IsZero() {
+		var err error
+		if report, err = fail2ban.CheckDrift(c.Request.Context()); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	drift := []fail2ban.DriftItem{}
+	for _, d := range report.Drift {
+		if jailVisible(c, d.Jail) {
+			drift = append(drift, d)
 		}
+	}
+	report.Drift, report.InSync = drift, len(drift) == 0
+	c.JSON(http.StatusOK, report)
+}
 
-		return sendSMTPMessage(client, settings.SMTP.From, to, msg)
+// LintHandler returns problems found in the jail configuration. Use
+// ?cached=true to get the result of the last lint pass.
+func LintHandler(c *gin.Context) {
+	var result fail2ban.LintResult
+	if c.Query("cached") == "true" {
+		result = fail2ban.LastLint()
+	} else {
+		result = fail2ban.LintConfig()
+	}
+	c.JSON(http.StatusOK, result)
+}
 
-	case 587:
-		// STARTTLS (Explicit TLS)
-		conn, err := net.Dial("tcp", smtpAddr)
-		if err != nil {
-			return fmt.Errorf("failed to connect to SMTP server: %w", err)
+// notifyJailCheck sends an email if configured jails failed to start.
+func notifyJailCheck(check fail2ban.JailCheck) {
+	if check.Error != "" || len(check.FailedToStart) == 0 {
+		return
+	}
+	log.Printf("⚠️ Jails failed to start after reload: %v", check.FailedToStart)
+	settings := config.GetSettings()
+	go func() {
+		subject := fmt.Sprintf("[Fail2Ban] %d jail(s) failed to start", len(check.FailedToStart))
+		body := fmt.Sprintf("<p>The following jails are enabled in the configuration but not running after the last reload:</p><pre>%s</pre><p>Check the fail2ban log for details.</p>",
+			strings.Join(check.FailedToStart, "\n"))
+		if err := sendEmail(settings.Fail2ban.Destemail, subject, body, settings); err != nil {
+			log.Printf("❌ Failed to send jail check alert: %v", err)
 		}
-		defer conn.Close()
+	}()
+}
 
-		client, err := smtp.NewClient(conn, smtpHost)
-		if err != nil {
-			return fmt.Errorf("failed to create SMTP client: %w", err)
-		}
-		defer client.Quit()
+// jailParam validates the :jail route parameter. On failure it writes a
+// 400 response and returns false.
+func jailParam(c *gin.Context) (string, bool) {
+	jail := c.Param("jail")
+	if err := fail2ban.ValidateJailName(jail); err != nil {
+		respondError(c, err)
+		return "", false
+	}
+	if !jailVisible(c, jail) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "jail not found"})
+		return "", false
+	}
+	return jail, true
+}
 
-		// Start TLS Upgrade
-		tlsConfig := &tls.Config{ServerName: smtpHost}
-		if err := client.StartTLS(tlsConfig); err != nil {
-			return fmt.Errorf("failed to start TLS: %w", err)
-		}
+// ipParam validates the :ip route parameter and returns its canonical form.
+// On failure it writes a 400 response and returns false.
+func ipParam(c *gin.Context) (string, bool) {
+	ip, err := fail2ban.NormalizeIP(c.Param("ip"))
+	if err != nil {
+		respondError(c, err)
+		return "", false
+	}
+	return ip, true
+}
 
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("SMTP authentication failed: %w", err)
-		}
+// respondError writes err as JSON. Invalid user input is answered with
+// 400 and a hint, everything else with 500.
+func respondError(c *gin.Context, err error) {
+	var verr *fail2ban.ValidationError
+	if errors.As(err, &verr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": verr.Error(), "hint": verr.Hint})
+		return
+	}
+	if errors.Is(err, fail2ban.ErrClientBusy) {
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	status, state := errorStatus(err)
+	c.JSON(status, gin.H{"error": err.Error(), "state": state})
+}
+
+// respondReloadError reports a failed reload or restart with its report.
+func respondReloadError(c *gin.Context, report fail2ban.ReloadReport) {
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": report.Error, "report": report, "state": report.State})
+}
+
+// currentUser returns the name of the user issuing the request.
+// Without authentication all requests belong to the "default" user.
+func currentUser(c *gin.Context) string {
+	if user := c.GetString("user"); user != "" {
+		return user
+	}
+	return "default"
+}
+
+// GetTourHandler returns the guided tour steps and the progress of the current user.
+func GetTourHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"steps":      config.TourSteps,
+		"state":      config.GetTourState(currentUser(c)),
+		"sampleData": config.GetSettings().Server.SampleData,
+	})
+}
+
+// CompleteTourStepHandler marks a single tour step as completed for the current user.
+func CompleteTourStepHandler(c *gin.Context) {
+	state, err := config.CompleteTourStep(currentUser(c), c.Param("step"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"state": state})
+}
 
-		return sendSMTPMessage(client, settings.SMTP.From, to, msg)
+// ResetTourHandler restarts the guided tour for the current user.
+func ResetTourHandler(c *gin.Context) {
+	if err := config.ResetTour(currentUser(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tour reset"})
+}
 
-	return errors.New("unsupported SMTP port. Use 587 (STARTTLS) or 465 (SMTPS)")
+// *******************************************************************
+// *                 Unified Email Sending Function :                *
+// *******************************************************************
+func sendEmail(to, subject, body string, settings config.AppSettings) error {
+	return sendEmailContext(context.Background(), to, subject, body, settings)
+}
+
+// sendEmailContext sends an email over a pooled SMTP session. The delivery
+// is aborted when ctx is done, and after smtpTimeout at the latest.
+func sendEmailContext(ctx context.Context, to, subject, body string, settings config.AppSettings) (err error) {
+	started := time.Now()
+	defer func() { recordEmail(ctx, to, subject, started, err) }()
+
+	// Validate SMTP settings
+	if settings.Notifications.SMTP.Host == "" || settings.Notifications.SMTP.Username == "" || settings.Notifications.SMTP.Password == "" || settings.Notifications.SMTP.From == "" {
+		return errors.New("SMTP settings are incomplete. Please configure all required fields")
+	}
+
+	// Alerts that can be answered carry their token as Message-ID, so the
+	// reply references it in In-Reply-To.
+	replyHeaders := ""
+	if token, ok := ctx.Value(replyTokenKey{}).(string); ok && settings.Notifications.MailReply.Address != "" {
+		replyHeaders = fmt.Sprintf("Reply-To: %s\nMessage-ID: <%s@fail2ban-ui>\n", settings.Notifications.MailReply.Address, token)
+	}
+
+	// Format message with **correct HTML headers**
+	message := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n%s"+
+		"MIME-Version: 1.0\nContent-Type: text/html; charset=\"UTF-8\"\n\n%s",
+		settings.Notifications.SMTP.From, to, subject, replyHeaders, body)
+	msg := []byte(message)
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	conn, err := mailPool.get(ctx, settings.Notifications.SMTP)
+	if err != nil {
+		return err
+	}
+	// Abort blocking reads and writes as soon as ctx is done.
+	stop := context.AfterFunc(ctx, func() { conn.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := sendSMTPMessage(conn.client, settings.Notifications.SMTP.From, to, msg); err != nil {
+		conn.client.Close()
+		return err
+	}
+	mailPool.put(conn)
+	return nil
 }
 
 // Helper Function to Send SMTP Message
@@ -571,26 +1402,57 @@ func sendSMTPMessage(client *smtp.Client, from, to string, msg []byte) error {
 	if err != nil {
 		return fmt.Errorf("failed to start data command: %w", err)
 	}
-	defer wc.Close()
-
 	if _, err = wc.Write(msg); err != nil {
+		wc.Close()
 		return fmt.Errorf("failed to write email content: %w", err)
 	}
-
-	// Close connection
-	client.Quit()
+	// Closing the writer ends the DATA command; only then the message is accepted.
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
 	return nil
 }
 
+// alertLanguage returns the language alert emails to recipient are written in:
+// its entry in RecipientLanguages, or the UI language.
+func alertLanguage(settings config.AppSettings, recipient string) string {
+	if lang, ok := settings.Notifications.RecipientLanguages[strings.ToLower(recipient)]; ok && lang != "" {
+		return lang
+	}
+	return settings.Server.Language
+}
+
 // *******************************************************************
 // *                      sendBanAlert Function :                    *
 // *******************************************************************
-func sendBanAlert(ip, jail, hostname, failures, whois, logs, country string, settings config.AppSettings) error {
-	subject := fmt.Sprintf("[Fail2Ban] %s: Banned %s from %s", jail, ip, hostname)
+func sendBanAlert(to, lang, ip, jail, hostname, failures, whoisText, logs, country string, settings config.AppSettings) error {
+	tr := func(key string, args ...string) string { return locales.T(lang, key, args...) }
+	subject := tr("email.ban.subject", "jail", jail, "ip", ip, "hostname", hostname)
+	if whoisText == "" {
+		whoisText = tr("email.ban.whois_pending", "ip", ip)
+	}
+
+	// Show what operators noted about the IP, e.g. "customer VPN egress".
+	noteHTML := ""
+	if note, ok := config.GetIPNote(ip); ok {
+		text := note.Note
+		if len(note.Tags) > 0 {
+			text = strings.TrimSpace(text + " [" + strings.Join(note.Tags, ", ") + "]")
+		}
+		noteHTML = fmt.Sprintf("\n                <p><span class=\"label\">📝 %s</span> %s</p>", tr("email.ban.note"), html.EscapeString(text))
+	}
+
+	// Let the recipient unban the IP by replying "UNBAN", see internal/mailreply.
+	token, replyHTML := "", ""
+	if settings.Notifications.MailReply.Enabled && settings.Notifications.MailReply.Secret != "" {
+		token = mailreply.NewToken(settings.Notifications.MailReply.Secret, ip, jail, settings.Notifications.MailReply.TokenTTL())
+		replyHTML = fmt.Sprintf("\n\n            <p>↩️ %s</p>\n            <p style=\"font-family: monospace; font-size: 11px; color: #888;\">%s</p>",
+			tr("email.ban.reply_unban", "ip", ip, "command", mailreply.CommandUnban), token)
+	}
 
 	// Improved Responsive HTML Email
 	body := fmt.Sprintf(`<!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 <meta charset="UTF-8">
 <meta name="viewport" content="width=device-width, initial-scale=1">
@@ -629,40 +1491,52 @@ func sendBanAlert(ip, jail, hostname, failures, whois, logs, country string, set
         <!-- HEADER -->
         <div class="header">
             <img src="https://swissmakers.ch/wp-content/uploads/2023/09/cyber.png" alt="Swissmakers GmbH" width="150" />
-            <h2>🚨 Security Alert from Fail2Ban-UI</h2>
+            <h2>🚨 %s</h2>
         </div>
 
         <!-- ALERT MESSAGE -->
         <div class="content">
-            <p>A new IP has been banned due to excessive failed login attempts.</p>
+            <p>%s</p>
 
             <div class="details">
-                <p><span class="label">📌 Banned IP:</span> %s</p>
-                <p><span class="label">🛡️ Jail Name:</span> %s</p>
-                <p><span class="label">🏠 Hostname:</span> %s</p>
-                <p><span class="label">🚫 Failed Attempts:</span> %s</p>
-                <p><span class="label">🌍 Country:</span> %s</p>
+                <p><span class="label">📌 %s</span> %s</p>
+                <p><span class="label">🛡️ %s</span> %s</p>
+                <p><span class="label">🏠 %s</span> %s</p>
+                <p><span class="label">🚫 %s</span> %s</p>
+                <p><span class="label">🌍 %s</span> %s</p>%s
             </div>
 
-            <h3>🔍 More Information about Attacker:</h3>
+            <h3>🔍 %s</h3>
             <pre>%s</pre>
 
-            <h3>📄 Server Log Entries:</h3>
-            <pre>%s</pre>
+            <h3>📄 %s</h3>
+            <pre>%s</pre>%s
         </div>
 
         <!-- FOOTER -->
         <div class="footer">
-            <p>This email was generated automatically by Fail2Ban.</p>
-            <p>For security inquiries, contact <a href="mailto:support@swissmakers.ch">support@swissmakers.ch</a></p>
-            <p>&copy; %d Swissmakers GmbH. All rights reserved.</p>
+            <p>%s</p>
+            <p>%s <a href="mailto:support@swissmakers.ch">support@swissmakers.ch</a></p>
+            <p>&copy; %d Swissmakers GmbH. %s</p>
         </div>
     </div>
 </body>
-</html>`, ip, jail, hostname, failures, country, whois, logs, time.Now().Year())
+</html>`, lang, tr("email.ban.heading"), tr("email.ban.intro"),
+		tr("email.ban.ip"), ip,
+		tr("email.ban.jail"), jail,
+		tr("email.ban.hostname"), hostname,
+		tr("email.ban.failures"), failures,
+		tr("email.ban.country"), country, noteHTML,
+		tr("email.ban.whois"), html.EscapeString(whoisText),
+		tr("email.ban.logs"), logs, replyHTML,
+		tr("email.footer.generated"), tr("email.footer.contact"), time.Now().Year(), tr("email.footer.rights"))
 
 	// Send the email
-	return sendEmail(settings.Destemail, subject, body, settings)
+	ctx := withBan(context.Background(), ip, jail)
+	if token != "" {
+		ctx = withReplyToken(ctx, token)
+	}
+	return sendEmailContext(ctx, to, subject, body, settings)
 }
 
 // *******************************************************************
@@ -671,21 +1545,25 @@ func sendBanAlert(ip, jail, hostname, failures, whois, logs, country string, set
 func TestEmailHandler(c *gin.Context) {
 	settings := config.GetSettings()
 
-	err := sendEmail(
-		settings.Destemail,
-		"Test Email from Fail2Ban UI",
-		"This is a test email sent from the Fail2Ban UI to verify SMTP settings.",
-		settings,
-	)
-
-	if err != nil {
-		log.Printf("❌ Test email failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test email: " + err.Error()})
-		return
-	}
-
-	log.Println("✅ Test email sent successfully!")
-	c.JSON(http.StatusOK, gin.H{"message": "Test email sent successfully!"})
+	// Sending may take until smtpTimeout against a dead host, so it runs as
+	// a job; the UI polls /api/jobs/<id> for the result.
+	startJob(c, "test-email", func(p *jobs.Progress) error {
+		p.SetMessage("Sending test email to " + settings.Fail2ban.Destemail)
+		lang := alertLanguage(settings, settings.Fail2ban.Destemail)
+		err := sendEmailContext(context.Background(),
+			settings.Fail2ban.Destemail,
+			locales.T(lang, "email.test.subject"),
+			locales.T(lang, "email.test.body"),
+			settings,
+		)
+		if err != nil {
+			log.Printf("❌ Test email failed: %v", err)
+			return fmt.Errorf("failed to send test email: %w", err)
+		}
+		log.Println("✅ Test email sent successfully!")
+		p.SetMessage("Test email sent successfully!")
+		return nil
+	})
 }
 
 // *******************************************************************
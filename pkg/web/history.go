@@ -0,0 +1,114 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/store"
+)
+
+// HistoryHandler queries the SQLite ban history, newest first. Filters:
+// ip, jail, action (ban, restore, unban), from and to (dates or RFC 3339,
+// "to" includes the whole day). Results are paginated, see parsePage.
+func HistoryHandler(c *gin.Context) {
+	q := store.Query{Jail: c.Query("jail"), Action: c.Query("action")}
+	if v := c.Query("ip"); v != "" {
+		ip, err := fail2ban.NormalizeIP(v)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		q.IP = ip
+	}
+	if q.Jail != "" && !jailVisible(c, q.Jail) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "jail not found"})
+		return
+	}
+	switch q.Action {
+	case "", fail2ban.ActionBan, fail2ban.ActionRestore, fail2ban.ActionUnban:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be ban, restore or unban"})
+		return
+	}
+	if v := c.Query("from"); v != "" {
+		t, _, err := parseTimeParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+		q.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, dateOnly, err := parseTimeParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		q.To = t
+	}
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	// ?offset= predates the cursors and is still accepted.
+	if v := c.Query("offset"); v != "" && c.Query("cursor") == "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		page.Offset = offset
+	}
+	q.Limit, q.Offset = page.Size, page.Offset
+	if requestTenant(c) != nil {
+		q.Jails = func(jail string) bool { return jailVisible(c, jail) }
+	}
+
+	s, err := store.Default()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	events, total, err := s.Find(c.Request.Context(), q)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "total": total, "nextCursor": page.next(total)})
+}
+
+// HistoryStatsHandler reports the size and time range of the ban history.
+func HistoryStatsHandler(c *gin.Context) {
+	s, err := store.Default()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	stats, err := s.Stats(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
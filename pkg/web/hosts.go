@@ -0,0 +1,217 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// localHostID identifies this host in the host list.
+const localHostID = "local"
+
+type hostRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Address    string   `json:"address" binding:"required"`
+	User       string   `json:"user"`
+	PrivateKey string   `json:"privateKey"`
+	KeyFile    string   `json:"keyFile"`
+	HostKeys   string   `json:"hostKeys" binding:"required"`
+	Sudo       bool     `json:"sudo"`
+	LogPath    string   `json:"logPath"`
+	Labels     []string `json:"labels"`
+}
+
+// toHost converts the request. The private key of old, the stored host,
+// is kept unless the request sets another key or a key file, as it is not
+// sent to clients.
+func (r hostRequest) toHost(old config.RemoteHost) (config.RemoteHost, error) {
+	labels, err := config.NormalizeHostLabels(r.Labels)
+	if err != nil {
+		return config.RemoteHost{}, err
+	}
+	h := config.RemoteHost{Name: r.Name, Address: r.Address, User: r.User, PrivateKey: r.PrivateKey, KeyFile: r.KeyFile, HostKeys: r.HostKeys, Sudo: r.Sudo, LogPath: r.LogPath, Labels: labels}
+	if h.PrivateKey == "" && h.KeyFile == "" {
+		h.PrivateKey = old.PrivateKey
+	}
+	return h, h.Validate()
+}
+
+// hostEntry is a host with its connection health, as listed by ListHostsHandler.
+type hostEntry struct {
+	config.RemoteHost
+	Local  bool                `json:"local,omitempty"`
+	Health fail2ban.HostHealth `json:"health"`
+}
+
+// ListHostsHandler lists this host and the remote hosts managed over SSH
+// with the result of their last connection check.
+func ListHostsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	name, _ := os.Hostname()
+	hosts := []hostEntry{{
+		RemoteHost: config.RemoteHost{ID: localHostID, Name: name, Labels: localHostLabels()},
+		Local:      true,
+		Health:     fail2ban.HostHealth{Status: fail2ban.HostOK},
+	}}
+	for _, h := range config.GetRemoteHosts() {
+		hosts = append(hosts, hostEntry{RemoteHost: h.Redacted(), Health: fail2ban.GetHostHealth(h.ID)})
+	}
+	hosts, next := paginate(page, hosts)
+	c.JSON(http.StatusOK, gin.H{"hosts": hosts, "nextCursor": next})
+}
+
+// AddHostHandler registers a remote host and checks its connection.
+func AddHostHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("AddHostHandler called (hosts.go)") // entry point
+	var req hostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	h, err := req.toHost(config.RemoteHost{})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid host: " + err.Error()})
+		return
+	}
+	h, err = config.AddRemoteHost(h)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"host": h.Redacted(), "health": fail2ban.CheckRemoteHost(c.Request.Context(), h)})
+}
+
+// UpdateHostHandler replaces a remote host and checks its connection.
+func UpdateHostHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("UpdateHostHandler called (hosts.go)") // entry point
+	var req hostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	old, ok := hostParam(c)
+	if !ok {
+		return
+	}
+	h, err := req.toHost(old)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid host: " + err.Error()})
+		return
+	}
+	h, err = config.UpdateRemoteHost(c.Param("id"), h)
+	if err != nil {
+		c.JSON(hostErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"host": h.Redacted(), "health": fail2ban.CheckRemoteHost(c.Request.Context(), h)})
+}
+
+// DeleteHostHandler removes a remote host.
+func DeleteHostHandler(c *gin.Context) {
+	if err := config.DeleteRemoteHost(c.Param("id")); err != nil {
+		c.JSON(hostErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Host deleted"})
+}
+
+// CheckHostHandler checks the connection of a remote host right away.
+func CheckHostHandler(c *gin.Context) {
+	h, ok := hostParam(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"health": fail2ban.CheckRemoteHost(c.Request.Context(), h)})
+}
+
+// HostSummaryHandler returns the jails and recent bans of a remote host in
+// the format of SummaryHandler. The bans are read from the end of its
+// fail2ban log.
+func HostSummaryHandler(c *gin.Context) {
+	h, ok := hostParam(c)
+	if !ok {
+		return
+	}
+	events, err := fail2ban.RemoteBans(c.Request.Context(), h)
+	if err != nil {
+		// The jails are still useful without the log.
+		config.DebugLog("Failed to read the fail2ban log of %s: %v", h.Name, err)
+	}
+	jails, err := fail2ban.RemoteJailInfos(c.Request.Context(), h, events)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "health": fail2ban.CheckRemoteHost(c.Request.Context(), h)})
+		return
+	}
+	if len(events) > 5 {
+		events = events[:5]
+	}
+	resp := SummaryResponse{Jails: jails, LastBans: events, Backfill: fail2ban.BackfillStatus{Done: true}}
+	resp.Totals = sumJails(resp.Jails)
+	resp.Countries = fail2ban.BannedCountries(resp.Jails)
+	markWatched(&resp)
+	tagEvents(resp.LastBans)
+	c.JSON(http.StatusOK, resp)
+}
+
+// HostUnbanIPHandler unbans an IP from a jail of a remote host.
+func HostUnbanIPHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("HostUnbanIPHandler called (hosts.go)") // entry point
+	h, ok := hostParam(c)
+	if !ok {
+		return
+	}
+	if err := fail2ban.RemoteUnbanIP(c.Request.Context(), h, c.Param("jail"), c.Param("ip")); err != nil {
+		respondError(c, err)
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "hosts.unban", Detail: h.Name + " " + c.Param("jail") + " " + c.Param("ip")})
+	c.JSON(http.StatusOK, gin.H{"message": "IP unbanned successfully on " + h.Name})
+}
+
+// localHostLabels returns the labels of this host, including the implicit "local".
+func localHostLabels() []string {
+	return append([]string{config.LocalHostLabel}, config.GetSettings().Fail2ban.Labels...)
+}
+
+// hostParam returns the remote host of the ":id" parameter, or responds 404.
+func hostParam(c *gin.Context) (config.RemoteHost, bool) {
+	h, err := config.GetRemoteHost(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return h, false
+	}
+	return h, true
+}
+
+func hostErrorStatus(err error) int {
+	if errors.Is(err, config.ErrHostNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
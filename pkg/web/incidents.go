@@ -0,0 +1,122 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// incidentRequest is the body of create and update requests.
+type incidentRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description"`
+	From        time.Time `json:"from" binding:"required"`
+	To          time.Time `json:"to"`
+	IPs         []string  `json:"ips"`
+	Jails       []string  `json:"jails"`
+}
+
+func (r incidentRequest) incident() config.Incident {
+	return config.Incident{Name: r.Name, Description: r.Description, From: r.From, To: r.To, IPs: r.IPs, Jails: r.Jails}
+}
+
+// incidentVisible reports whether the request may see inc: tenant users
+// see incidents without jails and incidents of at least one of their jails.
+func incidentVisible(c *gin.Context, inc config.Incident) bool {
+	if len(inc.Jails) == 0 {
+		return true
+	}
+	for _, j := range inc.Jails {
+		if jailVisible(c, j) {
+			return true
+		}
+	}
+	return false
+}
+
+func visibleIncidents(c *gin.Context, incidents []config.Incident) []config.Incident {
+	out := []config.Incident{}
+	for _, inc := range incidents {
+		if incidentVisible(c, inc) {
+			out = append(out, inc)
+		}
+	}
+	return out
+}
+
+// ListIncidentsHandler returns all incidents, newest first.
+func ListIncidentsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	incidents, next := paginate(page, visibleIncidents(c, config.GetIncidents()))
+	c.JSON(http.StatusOK, gin.H{"incidents": incidents, "nextCursor": next})
+}
+
+// AddIncidentHandler creates an incident.
+func AddIncidentHandler(c *gin.Context) {
+	var req incidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	inc := req.incident()
+	inc.CreatedBy = currentUser(c)
+	inc, err := config.AddIncident(inc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"incident": inc})
+}
+
+// UpdateIncidentHandler changes an existing incident.
+func UpdateIncidentHandler(c *gin.Context) {
+	var req incidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	inc, err := config.UpdateIncident(c.Param("id"), req.incident())
+	if err != nil {
+		c.JSON(incidentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"incident": inc})
+}
+
+// DeleteIncidentHandler removes an incident.
+func DeleteIncidentHandler(c *gin.Context) {
+	if err := config.DeleteIncident(c.Param("id")); err != nil {
+		c.JSON(incidentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Incident deleted"})
+}
+
+func incidentErrorStatus(err error) int {
+	if errors.Is(err, config.ErrIncidentNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,266 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+const (
+	maxIngestBatch  = 100  // events per request
+	maxIngestEvents = 1000 // events kept for GET /ingest/events
+)
+
+// ingestedEvent is a security event posted by an external tool, see
+// config.IngestToken.
+type ingestedEvent struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"` // name of the token
+	IP      string    `json:"ip"`
+	Type    string    `json:"type,omitempty"` // e.g. "login_failed"
+	Message string    `json:"message,omitempty"`
+	// Banned is the jail the event got the IP banned in.
+	Banned string `json:"banned,omitempty"`
+}
+
+type ingestRequest struct {
+	IP      string     `json:"ip"`
+	Type    string     `json:"type"`
+	Message string     `json:"message"`
+	Time    *time.Time `json:"time"`
+	// Events posts several events at once instead of the fields above.
+	Events []ingestRequest `json:"events"`
+}
+
+var (
+	ingested     []ingestedEvent
+	ingestHits   = make(map[string][]time.Time) // token ID + IP -> recent events
+	ingestedLock sync.Mutex
+)
+
+// isIngestRequest reports whether c posts events with an ingest token,
+// which replaces the authentication of the UI.
+func isIngestRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodPost && apiRoute(c) == "/api/events"
+}
+
+// IngestEventsHandler accepts security events of non-fail2ban sources. The
+// request is authenticated by an ingest token in the Authorization header
+// ("Bearer <token>"); when the token has a jail, IPs reaching its threshold
+// are banned there.
+func IngestEventsHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("IngestEventsHandler called (ingest.go)") // entry point
+	secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	token, found := config.LookupIngestToken(strings.TrimSpace(secret))
+	if !ok || !found {
+		log.Printf("❌ Rejected event ingestion from %s: invalid token", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid ingest token"})
+		return
+	}
+	var req ingestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	events := req.Events
+	if len(events) == 0 {
+		events = []ingestRequest{req}
+	}
+	if len(events) > maxIngestBatch {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("at most %d events per request", maxIngestBatch)})
+		return
+	}
+	for i, ev := range events {
+		if net.ParseIP(ev.IP) == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("event %d: invalid IP %q", i, ev.IP)})
+			return
+		}
+	}
+
+	now := time.Now()
+	banned := []string{}
+	for _, ev := range events {
+		e := ingestedEvent{Time: now, Source: token.Name, IP: ev.IP, Type: ev.Type, Message: ev.Message}
+		if ev.Time != nil && !ev.Time.IsZero() && ev.Time.Before(now) {
+			e.Time = *ev.Time
+		}
+		// Watched IPs raise a hit even if the event bans nothing.
+		checkWatchlist(watchKindEvent, fail2ban.BanEvent{Time: e.Time, Jail: token.Jail, IP: e.IP, LogLine: strings.TrimSpace(fmt.Sprintf("%s: %s %s", token.Name, e.Type, e.Message))})
+		if recordIngested(token, e) {
+			log.Printf("🚨 %s reached %d events from source %s, banning in %s", e.IP, token.BanThreshold(), token.Name, token.Jail)
+			if err := fail2ban.BanIP(c.Request.Context(), token.Jail, e.IP); err != nil {
+				log.Printf("❌ Failed to ban %s in %s for source %s: %v", e.IP, token.Jail, token.Name, err)
+				continue
+			}
+			banned = append(banned, e.IP)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"accepted": len(events), "banned": banned})
+}
+
+// recordIngested stores e and reports whether its IP reached the ban
+// threshold of token. The count starts over after a ban.
+func recordIngested(token config.IngestToken, e ingestedEvent) bool {
+	ingestedLock.Lock()
+	defer ingestedLock.Unlock()
+
+	ban := false
+	if token.Jail != "" {
+		key := token.ID + "|" + e.IP
+		since := time.Now().Add(-token.Window())
+		hits := ingestHits[key][:0]
+		for _, t := range ingestHits[key] {
+			if t.After(since) {
+				hits = append(hits, t)
+			}
+		}
+		if e.Time.After(since) {
+			hits = append(hits, e.Time)
+		}
+		if ban = len(hits) >= token.BanThreshold(); ban {
+			e.Banned = token.Jail
+			hits = nil
+		}
+		if len(hits) == 0 {
+			delete(ingestHits, key)
+		} else {
+			ingestHits[key] = hits
+		}
+	}
+
+	ingested = append(ingested, e)
+	if len(ingested) > maxIngestEvents {
+		ingested = ingested[len(ingested)-maxIngestEvents:]
+	}
+	return ban
+}
+
+// ListIngestedEventsHandler returns the latest ingested events, newest
+// first, optionally only those of ?source= or ?ip=.
+func ListIngestedEventsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	source, ip := c.Query("source"), c.Query("ip")
+
+	ingestedLock.Lock()
+	events := []ingestedEvent{}
+	for i := len(ingested) - 1; i >= 0 && len(events) < page.End(); i-- {
+		e := ingested[i]
+		if (source == "" || e.Source == source) && (ip == "" || e.IP == ip) {
+			events = append(events, e)
+		}
+	}
+	ingestedLock.Unlock()
+	events, next := paginate(page, events)
+	c.JSON(http.StatusOK, gin.H{"events": events, "nextCursor": next})
+}
+
+type ingestTokenRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Jail          string `json:"jail"`
+	Threshold     int    `json:"threshold"`
+	WindowMinutes int    `json:"windowMinutes"`
+	Enabled       bool   `json:"enabled"`
+}
+
+func (r ingestTokenRequest) toToken() (config.IngestToken, error) {
+	t := config.IngestToken{Name: r.Name, Jail: r.Jail, Threshold: r.Threshold, WindowMinutes: r.WindowMinutes, Enabled: r.Enabled}
+	return t, t.Validate()
+}
+
+// ListIngestTokensHandler returns all ingest tokens, without their secrets.
+func ListIngestTokensHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	tokens, next := paginate(page, config.GetIngestTokens())
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens, "nextCursor": next})
+}
+
+// AddIngestTokenHandler creates an ingest token. The response carries the
+// secret, which cannot be retrieved later.
+func AddIngestTokenHandler(c *gin.Context) {
+	var req ingestTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	t, err := req.toToken()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token: " + err.Error()})
+		return
+	}
+	t, secret, err := config.AddIngestToken(t)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "ingest.token.add", Detail: t.Name})
+	c.JSON(http.StatusOK, gin.H{"token": t, "secret": secret})
+}
+
+// UpdateIngestTokenHandler replaces the settings of an ingest token.
+func UpdateIngestTokenHandler(c *gin.Context) {
+	var req ingestTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	t, err := req.toToken()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token: " + err.Error()})
+		return
+	}
+	t, err = config.UpdateIngestToken(c.Param("id"), t)
+	if err != nil {
+		c.JSON(ingestTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": t})
+}
+
+// DeleteIngestTokenHandler removes an ingest token.
+func DeleteIngestTokenHandler(c *gin.Context) {
+	if err := config.DeleteIngestToken(c.Param("id")); err != nil {
+		c.JSON(ingestTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "ingest.token.delete", Detail: c.Param("id")})
+	c.JSON(http.StatusOK, gin.H{"message": "Ingest token deleted"})
+}
+
+func ingestTokenErrorStatus(err error) int {
+	if errors.Is(err, config.ErrIngestTokenNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,142 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/geoip"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+)
+
+// tagEvents attaches the integration tags (cloud provider, ...) and the
+// operator notes to each event.
+func tagEvents(events []fail2ban.BanEvent) {
+	incidents := config.GetIncidents()
+	for i := range events {
+		events[i].Tags = ipTags(events[i].IP)
+		if n, ok := config.GetIPNote(events[i].IP); ok {
+			events[i].Note = n.Note
+		}
+		for _, inc := range incidents {
+			if inc.Matches(events[i].IP, events[i].Jail, events[i].Time) {
+				events[i].Incidents = append(events[i].Incidents, inc.ID)
+			}
+		}
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ListEventsHandler returns the newest ban events, optionally filtered
+// by ?jail=, ?ip= and ?tag= (e.g. tag=cloud:aws), in pages of 100 by default.
+func ListEventsHandler(c *gin.Context) {
+	jail := c.Query("jail")
+	ip := c.Query("ip")
+	tag := c.Query("tag")
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	var err error
+	if jail != "" {
+		if err := fail2ban.ValidateJailName(jail); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	if ip != "" {
+		if ip, err = fail2ban.NormalizeIP(ip); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+
+	events := fail2ban.Events().Filter(func(ev fail2ban.BanEvent) bool {
+		if (jail != "" && ev.Jail != jail) || !jailVisible(c, ev.Jail) {
+			return false
+		}
+		if ip != "" && ev.IP != ip {
+			return false
+		}
+		return tag == "" || hasTag(integrations.Tags(ev.IP), tag)
+	}, page.End())
+	events, next := paginate(page, events)
+	tagEvents(events)
+	c.JSON(http.StatusOK, gin.H{"events": events, "nextCursor": next})
+}
+
+// TagStatsHandler returns the number of ban events and distinct IPs per tag.
+func TagStatsHandler(c *gin.Context) {
+	type tagStat struct {
+		Events int `json:"events"`
+		IPs    int `json:"ips"`
+	}
+	stats := make(map[string]*tagStat)
+	ipCounts := fail2ban.Events().IPCounts()
+	if requestTenant(c) != nil {
+		ipCounts = make(map[string]int)
+		for jail, events := range fail2ban.Events().ByJail() {
+			if jailVisible(c, jail) {
+				for _, ev := range events {
+					ipCounts[ev.IP]++
+				}
+			}
+		}
+	}
+	for ip, count := range ipCounts {
+		for _, tag := range ipTags(ip) {
+			st, ok := stats[tag]
+			if !ok {
+				st = &tagStat{}
+				stats[tag] = st
+			}
+			st.Events += count
+			st.IPs++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": stats})
+}
+
+// IntegrationsStatusHandler returns the state of all scheduled integration jobs.
+func IntegrationsStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": integrations.Status()})
+}
+
+// GeoIPStatusHandler reports the GeoIP databases in use and their detected format.
+func GeoIPStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, geoip.GetStatus())
+}
+
+// RunIntegrationHandler triggers an immediate run of an integration job.
+func RunIntegrationHandler(c *gin.Context) {
+	if err := integrations.RunNow(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Integration job triggered"})
+}
@@ -0,0 +1,105 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+)
+
+// ipTags returns the integration tags of ip followed by the tags operators
+// attached to it.
+func ipTags(ip string) []string {
+	tags := integrations.Tags(ip)
+	if n, ok := config.GetIPNote(ip); ok {
+		for _, t := range n.Tags {
+			if !hasTag(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// ListIPNotesHandler returns all operator notes.
+func ListIPNotesHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	notes, next := paginate(page, config.GetIPNotes())
+	c.JSON(http.StatusOK, gin.H{"notes": notes, "nextCursor": next})
+}
+
+// GetIPNoteHandler returns the note of an IP. Tenant users only see notes
+// of IPs banned in their jails.
+func GetIPNoteHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	note, found := config.GetIPNote(ip)
+	if !found || (requestTenant(c) != nil && len(visibleEvents(c, fail2ban.Events().ByIP(ip))) == 0) {
+		c.JSON(http.StatusNotFound, gin.H{"error": config.ErrIPNoteNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"note": note})
+}
+
+// SetIPNoteHandler attaches a note and tags to an IP.
+func SetIPNoteHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Note string   `json:"note"`
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	note, err := config.SetIPNote(ip, req.Note, req.Tags, currentUser(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"note": note})
+}
+
+// DeleteIPNoteHandler removes the note of an IP.
+func DeleteIPNoteHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	if err := config.DeleteIPNote(ip); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrIPNoteNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
+}
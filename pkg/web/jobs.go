@@ -0,0 +1,69 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/jobs"
+)
+
+// ListJobsHandler returns all background jobs, newest first.
+func ListJobsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	list, next := paginate(page, jobs.List())
+	c.JSON(http.StatusOK, gin.H{"jobs": list, "nextCursor": next})
+}
+
+// GetJobHandler returns the status of a single background job.
+func GetJobHandler(c *gin.Context) {
+	st, ok := jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// StartGeoEnrichHandler starts a job resolving country/ASN for stored events that lack them.
+func StartGeoEnrichHandler(c *gin.Context) {
+	startJob(c, "geoip-enrich", func(p *jobs.Progress) error {
+		resolved, err := fail2ban.EnrichGeo(p.SetTotal, p.Add)
+		if err != nil {
+			return err
+		}
+		p.SetMessage(fmt.Sprintf("%d IPs resolved", resolved))
+		return nil
+	})
+}
+
+// startJob starts a background job and responds with its status.
+func startJob(c *gin.Context, kind string, fn func(p *jobs.Progress) error) {
+	st, err := jobs.Start(kind, fn)
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "job": st})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": st})
+}
@@ -0,0 +1,77 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+// bodyLimits holds the routes with their own body limit, all other API
+// routes use LimitSettings.MaxBody.
+var bodyLimits = map[string]func(config.LimitSettings) int64{
+	"/api/ban": config.LimitSettings.BanMaxBody,
+}
+
+// limitBody rejects request bodies above the configured limit with 413.
+// Accepted bodies are read up front, so handlers never see a body larger
+// than the limit, even if the client did not send a Content-Length.
+func limitBody(c *gin.Context) {
+	limits := config.GetSettings().Server.Limits
+	limit := limits.MaxBody()
+	if routeLimit, ok := bodyLimits[apiRoute(c)]; ok {
+		limit = routeLimit(limits)
+	}
+
+	if c.Request.ContentLength > limit {
+		rejectBody(c, limit)
+		return
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		c.Next()
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body: " + err.Error()})
+		return
+	}
+	if int64(len(body)) > limit {
+		rejectBody(c, limit)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Next()
+}
+
+func rejectBody(c *gin.Context, limit int64) {
+	if apiRoute(c) == "/api/ban" {
+		// A dropped ban callback means a missing alert.
+		metrics.CallbackResult(metrics.CallbackInvalid)
+	}
+	log.Printf("⚠️ Rejected %s %s from %s: request body exceeds %d KB", c.Request.Method, c.Request.URL.Path, requestIP(c), limit>>10)
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": fmt.Sprintf("request body too large, the limit is %d KB", limit>>10),
+	})
+}
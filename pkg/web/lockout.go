@@ -0,0 +1,170 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// lockoutRoute reports whether the request is for the lockout self-service
+// page, which visitors use without an account while the lockout feature
+// is enabled. It bypasses the allowed networks and authentication.
+func lockoutRoute(c *gin.Context) bool {
+	route := apiRoute(c)
+	if route != "/lockout" && !strings.HasPrefix(route, "/lockout/") {
+		return false
+	}
+	return config.GetSettings().Features.Enabled(config.FeatureLockout)
+}
+
+// lockoutStatus is what the lockout page shows a visitor about their address.
+type lockoutStatus struct {
+	IP     string   `json:"ip"`
+	Banned bool     `json:"banned"`
+	Jails  []string `json:"jails"`
+	// PendingSince is the time of the visitor's pending unban request.
+	PendingSince *time.Time `json:"pendingSince,omitempty"`
+	// Decision is the outcome of the visitor's last request.
+	Decision *lockoutDecision `json:"decision,omitempty"`
+}
+
+// lockoutDecision is the part of a decided request shown to its requester.
+type lockoutDecision struct {
+	Status    string    `json:"status"`
+	DecidedAt time.Time `json:"decidedAt"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// currentLockout looks up the jails banning the address of the request.
+// It uses the cached jail infos, so visitors reloading the page do not run
+// fail2ban-client.
+func currentLockout(ctx context.Context, c *gin.Context) (lockoutStatus, error) {
+	ip := requestIP(c)
+	if ip == nil {
+		return lockoutStatus{}, errors.New("cannot determine your IP address")
+	}
+	st := lockoutStatus{IP: ip.String(), Jails: []string{}}
+	infos, err := cachedJailInfos(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, j := range infos {
+		if slices.Contains(j.BannedIPs, st.IP) {
+			st.Jails = append(st.Jails, j.JailName)
+		}
+	}
+	st.Banned = len(st.Jails) > 0
+	if r, ok := config.LatestUnbanRequest(st.IP); ok {
+		if r.Status == config.UnbanPending {
+			st.PendingSince = &r.CreatedAt
+		} else {
+			st.Decision = &lockoutDecision{Status: r.Status, DecidedAt: r.DecidedAt, Note: r.Note}
+		}
+	}
+	return st, nil
+}
+
+// LockoutPageHandler renders the lockout self-service page.
+func LockoutPageHandler(c *gin.Context) {
+	st, err := currentLockout(c.Request.Context(), c)
+	c.Header("Cache-Control", "no-store")
+	c.HTML(http.StatusOK, "lockout.html", gin.H{
+		"branding": currentBranding(c),
+		"basePath": c.GetString(basePathKey),
+		"status":   st,
+		"failed":   err != nil,
+		"result":   c.Query("result"),
+	})
+}
+
+// LockoutStatusHandler reports whether the visitor's address is banned and
+// by which jails.
+func LockoutStatusHandler(c *gin.Context) {
+	st, err := currentLockout(c.Request.Context(), c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, st)
+}
+
+// LockoutRequestHandler queues an unban request of the visitor's address
+// for approval. It accepts the form of the lockout page, answered with a
+// redirect back to it, and JSON.
+func LockoutRequestHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("LockoutRequestHandler called (lockout.go)") // entry point
+	var req struct {
+		Message string `json:"message" form:"message"`
+		Contact string `json:"contact" form:"contact"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		lockoutResult(c, http.StatusBadRequest, "invalid", "Invalid request: "+err.Error())
+		return
+	}
+	st, err := currentLockout(c.Request.Context(), c)
+	if err != nil {
+		lockoutResult(c, http.StatusServiceUnavailable, "failed", err.Error())
+		return
+	}
+	if !st.Banned {
+		lockoutResult(c, http.StatusConflict, "notbanned", "your IP address is not banned")
+		return
+	}
+	r, err := config.AddUnbanRequest(config.UnbanRequest{IP: st.IP, Jails: st.Jails, Message: req.Message, Contact: req.Contact})
+	switch {
+	case errors.Is(err, config.ErrUnbanRequestPending):
+		lockoutResult(c, http.StatusConflict, "pending", err.Error())
+		return
+	case errors.Is(err, config.ErrUnbanQueueFull):
+		lockoutResult(c, http.StatusServiceUnavailable, "full", err.Error())
+		return
+	case err != nil:
+		lockoutResult(c, http.StatusBadRequest, "invalid", err.Error())
+		return
+	}
+	log.Printf("📨 Unban request %s of %s (jails %s) queued for approval", r.ID, r.IP, strings.Join(r.Jails, ", "))
+	// Visitors have no user name, see recordAudit.
+	if err := config.RecordAudit(config.AuditEntry{User: "visitor", ClientIP: r.IP, Action: "unban-request", Detail: "submit " + r.ID + " of " + r.IP}); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+	lockoutResult(c, http.StatusOK, "sent", "")
+}
+
+// lockoutResult answers a lockout request: JSON requests get status and
+// the error message, the form is redirected back to the page with result.
+func lockoutResult(c *gin.Context, status int, result, message string) {
+	if c.ContentType() != "application/json" {
+		c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/lockout?result="+result)
+		return
+	}
+	if message != "" {
+		c.JSON(status, gin.H{"error": message, "result": result})
+		return
+	}
+	c.JSON(status, gin.H{"message": "Unban request submitted", "result": result})
+}
@@ -0,0 +1,98 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/webhooks"
+)
+
+func init() {
+	fail2ban.OnLogProblems(warnLogProblems)
+}
+
+// warnLogProblems sends an email and a webhook warning for log files that
+// turned missing, unreadable or stale, unless logHealth.silent is set.
+func warnLogProblems(files []fail2ban.LogFileHealth) {
+	for _, f := range files {
+		log.Printf("⚠️ Log file %s of jail %s is %s %s", f.Path, f.Jail, f.Status, f.Error)
+	}
+	settings := config.GetSettings()
+	if settings.Fail2ban.LogHealth.Silent {
+		return
+	}
+
+	lines := make([]string, len(files))
+	items := make([]string, len(files))
+	for i, f := range files {
+		lines[i] = logProblemText(f, settings)
+		items[i] = "<li>" + html.EscapeString(lines[i]) + "</li>"
+	}
+	webhooks.DispatchWarning("log_health", strings.Join(lines, "\n"), map[string]interface{}{"files": files})
+
+	subject := fmt.Sprintf("[Fail2Ban] WARNING: %d log file(s) of your jails need attention", len(files))
+	body := fmt.Sprintf(`<p>Fail2ban cannot ban attackers of a jail whose log file is not written or not readable.</p>
+<ul>%s</ul>
+<p>Check the <code>logpath</code> of the jails and the logging of the services.</p>`, strings.Join(items, ""))
+	go func() {
+		if err := sendEmailContext(context.Background(), settings.Fail2ban.Destemail, subject, body, settings); err != nil {
+			log.Printf("❌ Failed to send log health warning: %v", err)
+		}
+	}()
+}
+
+// logProblemText describes the problem of a log file in one line.
+func logProblemText(f fail2ban.LogFileHealth, settings config.AppSettings) string {
+	switch f.Status {
+	case fail2ban.LogFileMissing:
+		return fmt.Sprintf("Log file %s of jail %s does not exist", f.Path, f.Jail)
+	case fail2ban.LogFileUnreadable:
+		return fmt.Sprintf("Log file %s of jail %s cannot be read: %s", f.Path, f.Jail, f.Error)
+	default:
+		return fmt.Sprintf("Log file %s of jail %s has no new lines since %s (more than %d hours)",
+			f.Path, f.Jail, f.ModTime.Format("2006-01-02 15:04"), int(settings.Fail2ban.LogHealth.StaleAfter().Hours()))
+	}
+}
+
+// LogHealthHandler returns the result of the last log health check,
+// limited to the jails visible to the request.
+func LogHealthHandler(c *gin.Context) {
+	report := fail2ban.GetLogHealth()
+	files := report.Files[:0:0]
+	for _, f := range report.Files {
+		if jailVisible(c, f.Jail) {
+			files = append(files, f)
+		}
+	}
+	report.Files = files
+	problems := 0
+	for _, f := range files {
+		if f.Status != fail2ban.LogFileOK {
+			problems++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report, "problems": problems, "staleHours": int(config.GetSettings().Fail2ban.LogHealth.StaleAfter().Hours())})
+}
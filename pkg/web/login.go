@@ -0,0 +1,265 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// auditLogin is the audit log action of password logins.
+const auditLogin = "login"
+
+const (
+	maxLoginFailures   = 5                // per client address within loginFailureWindow
+	loginFailureWindow = 15 * time.Minute // before the failures are forgotten
+)
+
+type loginFailure struct {
+	count int
+	first time.Time
+}
+
+var (
+	loginFailures     = make(map[string]*loginFailure) // by client address
+	loginFailuresLock sync.Mutex
+)
+
+// loginBlocked reports how long ip has to wait after too many failed logins.
+func loginBlocked(ip string) time.Duration {
+	loginFailuresLock.Lock()
+	defer loginFailuresLock.Unlock()
+	f, ok := loginFailures[ip]
+	if !ok {
+		return 0
+	}
+	if wait := loginFailureWindow - time.Since(f.first); wait > 0 && f.count >= maxLoginFailures {
+		return wait
+	} else if wait <= 0 {
+		delete(loginFailures, ip)
+	}
+	return 0
+}
+
+// recordLogin counts a failed login of ip, or forgets its failures.
+func recordLogin(ip string, ok bool) {
+	loginFailuresLock.Lock()
+	defer loginFailuresLock.Unlock()
+	if ok {
+		delete(loginFailures, ip)
+		return
+	}
+	f, found := loginFailures[ip]
+	if !found || time.Since(f.first) > loginFailureWindow {
+		f = &loginFailure{first: time.Now()}
+		loginFailures[ip] = f
+	}
+	f.count++
+}
+
+// verifyLogin checks the credentials of a login or a Basic auth request,
+// applying the failure limit. It returns 0 for valid credentials and the
+// response status otherwise.
+func verifyLogin(c *gin.Context, user, password string) int {
+	return verifyLoginCode(c, user, password, nil)
+}
+
+// verifyLoginCode is verifyLogin also checking the second factor with
+// secondFactor unless it is nil. Wrong codes count as failed logins and
+// are reported with 403.
+func verifyLoginCode(c *gin.Context, user, password string, secondFactor func() bool) int {
+	ip := ""
+	if addr := requestIP(c); addr != nil {
+		ip = addr.String()
+	}
+	if wait := loginBlocked(ip); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		return http.StatusTooManyRequests
+	}
+	ok := config.GetSettings().Auth.CheckPassword(user, password)
+	if !ok {
+		recordLogin(ip, false)
+		c.Set("user", user)
+		recordAudit(c, config.AuditEntry{Action: auditLogin, Error: "wrong user name or password"})
+		return http.StatusUnauthorized
+	}
+	if secondFactor != nil && !secondFactor() {
+		recordLogin(ip, false)
+		c.Set("user", user)
+		recordAudit(c, config.AuditEntry{Action: auditLogin, Error: "wrong authentication code"})
+		return http.StatusForbidden
+	}
+	recordLogin(ip, true)
+	return 0
+}
+
+// checkLogin is verifyLogin for API requests. On failure it writes the response.
+func checkLogin(c *gin.Context, user, password string) bool {
+	switch verifyLogin(c, user, password) {
+	case 0:
+		return true
+	case http.StatusTooManyRequests:
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many failed logins, try again later"})
+	default:
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "wrong user name or password"})
+	}
+	return false
+}
+
+// authenticatePassword protects the UI and the API in password mode. The
+// dashboard uses the session started by LoginHandler, scripts send the
+// credentials with HTTP Basic auth.
+func authenticatePassword(c *gin.Context, auth config.AuthSettings) {
+	switch route := apiRoute(c); {
+	case localCallback(c), route == "/api/slack", isIngestRequest(c), route == "/login", route == logoURL, strings.HasPrefix(route, "/locales/"), lockoutRoute(c):
+		c.Next()
+		return
+	}
+	if user, password, ok := c.Request.BasicAuth(); ok {
+		if auth.TOTP.Enabled() {
+			// Basic auth cannot carry the second factor.
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "two-factor authentication is enabled, use an API token"})
+			return
+		}
+		if !checkLogin(c, user, password) {
+			return
+		}
+		c.Set("user", user)
+		c.Set(roleKey, config.RoleAdmin)
+		c.Next()
+		return
+	}
+
+	token, err := sessionToken(c)
+	if token == "" && err == nil {
+		loginRequired(c, nil)
+		return
+	}
+	var s *uiSession
+	if err == nil {
+		s, err = useSession(token, "", auth.Sessions, c.GetHeader(passiveHeader) == "")
+	}
+	if err != nil {
+		clearSessionCookie(c)
+		loginRequired(c, err)
+		return
+	}
+	c.Set("user", s.User)
+	c.Set(roleKey, config.RoleAdmin)
+	c.Set("session", s.ID)
+	c.Next()
+}
+
+// loginRequired sends the dashboard to the login page and rejects API
+// requests, telling the dashboard why its session ended if it had one.
+func loginRequired(c *gin.Context, err *sessionError) {
+	if apiRoute(c) == "/" {
+		c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/login")
+		c.Abort()
+		return
+	}
+	if err != nil {
+		abortSession(c, err)
+		return
+	}
+	c.Header("WWW-Authenticate", `Basic realm="Fail2ban UI"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+}
+
+// LoginPageHandler serves the login form of password mode.
+func LoginPageHandler(c *gin.Context) {
+	if !config.GetSettings().Auth.PasswordLogin() {
+		c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.HTML(http.StatusOK, "login.html", gin.H{
+		"branding": currentBranding(c),
+		"basePath": c.GetString(basePathKey),
+		"error":    c.Query("error"),
+		"totp":     config.GetSettings().Auth.TOTP.Enabled(),
+	})
+}
+
+// LoginHandler checks the admin credentials posted by the login form and
+// starts a dashboard session.
+func LoginHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("LoginHandler called (login.go)") // entry point
+	settings := config.GetSettings().Auth
+	if !settings.PasswordLogin() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "password authentication is not enabled"})
+		return
+	}
+	user, password := c.PostForm("username"), c.PostForm("password")
+	loginPage := c.GetString(basePathKey) + "/login"
+	var secondFactor func() bool
+	if settings.TOTP.Enabled() {
+		secondFactor = func() bool { return config.VerifySecondFactor(c.PostForm("code")) }
+	}
+	if status := verifyLoginCode(c, user, password, secondFactor); status != 0 {
+		c.Redirect(http.StatusSeeOther, loginPage+"?error="+strconv.Itoa(status))
+		return
+	}
+
+	ip := ""
+	if addr := requestIP(c); addr != nil {
+		ip = addr.String()
+	}
+	s, err := startSession(user, ip, settings.Sessions)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, loginPage+"?error="+err.code)
+		return
+	}
+	setSessionCookie(c, s, settings.Sessions)
+	c.Set("user", user)
+	recordAudit(c, config.AuditEntry{Action: auditLogin, Detail: s.ID})
+	c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/")
+}
+
+// SetPasswordHandler sets the admin password of password mode. Once a
+// password is set, the current one is required. With "enable" the UI
+// switches to password mode right away.
+func SetPasswordHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("SetPasswordHandler called (login.go)") // entry point
+	var req struct {
+		CurrentPassword string `json:"currentPassword"`
+		Password        string `json:"password" binding:"required"`
+		Enable          bool   `json:"enable"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	auth := config.GetSettings().Auth
+	if auth.AdminPasswordHash() != "" && !checkLogin(c, auth.Admin(), req.CurrentPassword) {
+		return
+	}
+	if err := config.SetAdminPassword(req.Password, req.Enable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "auth.password"})
+	c.JSON(http.StatusOK, gin.H{"message": "Password set", "passwordLogin": config.GetSettings().Auth.PasswordLogin()})
+}
@@ -0,0 +1,131 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"golang.org/x/net/websocket"
+)
+
+// maxTailBacklog limits the lines sent when a log tail starts.
+const maxTailBacklog = 500
+
+// LogTailHandler streams new lines of the fail2ban log over a WebSocket as
+// JSON messages {"path", "line"}. ?jail= keeps the lines of a jail, ?ip=
+// those mentioning an IP, and ?source=jail follows the jail's own log
+// files instead. ?backlog=n sends the last n matching lines first.
+func LogTailHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("LogTailHandler called (logtail.go)") // entry point
+	opts := fail2ban.TailOptions{Jail: c.Query("jail")}
+	if opts.Jail != "" {
+		if err := fail2ban.ValidateJailName(opts.Jail); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	if ip := c.Query("ip"); ip != "" {
+		normalized, err := fail2ban.NormalizeIP(ip)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		opts.IP = normalized
+	}
+	if b := c.Query("backlog"); b != "" {
+		n, err := strconv.Atoi(b)
+		if err != nil || n < 0 || n > maxTailBacklog {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("backlog must be between 0 and %d", maxTailBacklog)})
+			return
+		}
+		opts.Backlog = n
+	}
+
+	var paths []string
+	switch c.DefaultQuery("source", "fail2ban") {
+	case "fail2ban":
+		logPath := fail2ban.GetBackfillStatus().LogPath
+		if logPath == "" {
+			logPath = fail2ban.DefaultLogPath
+		}
+		paths = []string{logPath}
+	case "jail":
+		if opts.Jail == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "source=jail requires a jail"})
+			return
+		}
+		var err error
+		if paths, err = fail2ban.JailLogPaths(c.Request.Context(), opts.Jail); err != nil {
+			respondError(c, err)
+			return
+		}
+		if len(paths) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "jail " + opts.Jail + " has no log files, it probably reads the journal"})
+			return
+		}
+		// The jail's own logs do not carry the jail name.
+		opts.Jail = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source must be fail2ban or jail"})
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: checkWebSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			ctx, cancel := context.WithCancel(c.Request.Context())
+			defer cancel()
+			// The client sends nothing; a failed read means it went away.
+			go func() {
+				var discard string
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				cancel()
+			}()
+			err := fail2ban.TailLogs(ctx, paths, opts, func(l fail2ban.TailLine) error {
+				return websocket.JSON.Send(ws, l)
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Log tail of %v ended: %v", paths, err)
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkWebSocketOrigin rejects WebSocket connections opened by pages of
+// other sites, which browsers would send with the user's cookies.
+func checkWebSocketOrigin(cfg *websocket.Config, req *http.Request) error {
+	if !sameOrigin(req) {
+		return errors.New("cross-origin WebSocket connections are not allowed")
+	}
+	if origin := req.Header.Get("Origin"); origin != "" {
+		cfg.Origin, _ = url.Parse(origin)
+	}
+	return nil
+}
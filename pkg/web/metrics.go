@@ -0,0 +1,51 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+// MetricsHandler serves the jail statistics in the Prometheus text format.
+func MetricsHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := metrics.Write(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("❌ Failed to write metrics: %v", err)
+	}
+}
+
+// observeAPI is the middleware recording the latency of API requests,
+// labelled with the route pattern so the series stay bounded.
+func observeAPI(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	if route := c.FullPath(); route != "" {
+		metrics.ObserveAPI(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// CallbackStatsHandler returns how many ban callbacks arrived, how long
+// they took and how many bans in the log had no callback.
+func CallbackStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, metrics.GetCallbackStats())
+}
@@ -0,0 +1,169 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/expr"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+// banRef identifies the ban an email is about, for the notification log.
+type banRef struct {
+	ip, jail string
+}
+
+type banRefKey struct{}
+
+// withBan attaches the ban an email is about to ctx.
+func withBan(ctx context.Context, ip, jail string) context.Context {
+	return context.WithValue(ctx, banRefKey{}, banRef{ip: ip, jail: jail})
+}
+
+type replyTokenKey struct{}
+
+// withReplyToken attaches the unban token of an alert to ctx, see
+// internal/mailreply.
+func withReplyToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, replyTokenKey{}, token)
+}
+
+// recordEmail logs an email delivery attempt.
+func recordEmail(ctx context.Context, to, subject string, started time.Time, sendErr error) {
+	ref, _ := ctx.Value(banRefKey{}).(banRef)
+	rec := config.NotificationRecord{
+		Channel:   config.ChannelEmail,
+		Recipient: to,
+		Subject:   subject,
+		IP:        ref.ip,
+		Jail:      ref.jail,
+		Status:    config.NotificationSent,
+		StartedAt: started,
+	}
+	if sendErr != nil {
+		rec.Status = config.NotificationFailed
+		rec.Error = sendErr.Error()
+	}
+	metrics.Notification(config.ChannelEmail, sendErr)
+	if err := config.RecordNotification(rec); err != nil {
+		log.Printf("Failed to record email delivery: %v", err)
+	}
+}
+
+// alertRecipient is an address a ban alert is sent to, in its language.
+type alertRecipient struct {
+	to, lang string
+}
+
+// routeAlert evaluates the alert routes for ev and returns the recipients
+// and the names of the matching routes. Without a match it returns
+// destemail, which is subject to the email policy.
+func routeAlert(settings config.AppSettings, ev fail2ban.BanEvent) ([]alertRecipient, []string) {
+	var recipients []alertRecipient
+	var routes []string
+	seen := make(map[string]bool)
+	fields := eventFields(ev)
+	for _, route := range settings.Notifications.Routes {
+		if !(config.NotificationPolicy{Countries: route.Countries}).AllowsCountry(ev.Country) {
+			continue
+		}
+		if matched, err := expr.Match(route.Expression, fields); err != nil {
+			log.Printf("⚠️ Alert route %s failed: %v", route.Name, err)
+			continue
+		} else if !matched {
+			continue
+		}
+		routes = append(routes, route.Name)
+		for _, to := range route.Recipients {
+			if seen[strings.ToLower(to)] {
+				continue
+			}
+			seen[strings.ToLower(to)] = true
+			lang := route.Language
+			if lang == "" {
+				lang = alertLanguage(settings, to)
+			}
+			recipients = append(recipients, alertRecipient{to: to, lang: lang})
+		}
+		if !route.Continue {
+			break
+		}
+	}
+	if len(routes) == 0 {
+		to := settings.Fail2ban.Destemail
+		recipients = []alertRecipient{{to: to, lang: alertLanguage(settings, to)}}
+	}
+	return recipients, routes
+}
+
+// recordSkippedAlert logs why no alert email was sent for a ban.
+func recordSkippedAlert(ip, jail, reason string) {
+	rec := config.NotificationRecord{
+		Channel:   config.ChannelEmail,
+		Recipient: config.GetSettings().Fail2ban.Destemail,
+		IP:        ip,
+		Jail:      jail,
+		Status:    config.NotificationSkipped,
+		Error:     reason,
+	}
+	if err := config.RecordNotification(rec); err != nil {
+		log.Printf("Failed to record skipped alert: %v", err)
+	}
+}
+
+// NotificationLogHandler returns the notification log, newest first.
+// Filters: ?channel=email|webhook, ?status=sent|failed|skipped, ?ip=, ?jail=,
+// ?since=<RFC 3339 time>. Pages hold 100 records by default.
+func NotificationLogHandler(c *gin.Context) {
+	f := config.NotificationFilter{
+		Channel: c.Query("channel"),
+		Status:  c.Query("status"),
+		Jail:    c.Query("jail"),
+	}
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	f.Limit = page.End()
+	var err error
+	if ip := c.Query("ip"); ip != "" {
+		if f.IP, err = fail2ban.NormalizeIP(ip); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	if since := c.Query("since"); since != "" {
+		if f.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected RFC 3339 like 2025-01-31T12:00:00Z"})
+			return
+		}
+	}
+	if requestTenant(c) != nil {
+		// Tenant users only see alerts about their own jails.
+		f.JailAllowed = func(jail string) bool { return jailVisible(c, jail) }
+	}
+	records, next := paginate(page, config.GetNotificationLog(f))
+	c.JSON(http.StatusOK, gin.H{"notifications": records, "nextCursor": next})
+}
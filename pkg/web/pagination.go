@@ -0,0 +1,106 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List endpoints return one page of their items at a time: ?pageSize=
+// sets its size, capped at maxPageSize, and the response's "nextCursor"
+// is passed as ?cursor= to get the next page. It is empty on the last one.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	cursorPrefix    = "o"
+)
+
+// pageRequest is the page requested by ?pageSize= and ?cursor=.
+type pageRequest struct {
+	Size   int
+	Offset int // position of the first item, from the cursor
+}
+
+// parsePage reads the pagination parameters; on invalid values it writes
+// the error response and returns false. ?limit= is still accepted for the
+// page size since older clients sent it, before pagination was added.
+func parsePage(c *gin.Context, defaultSize int) (pageRequest, bool) {
+	p := pageRequest{Size: defaultSize}
+	size := c.Query("pageSize")
+	if size == "" {
+		size = c.Query("limit")
+	}
+	if size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageSize"})
+			return p, false
+		}
+		p.Size = min(n, maxPageSize)
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		offset, ok := decodeCursor(cursor)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return p, false
+		}
+		p.Offset = offset
+	}
+	return p, true
+}
+
+// End returns how many items of the full list are needed for the page and
+// to tell whether another one follows, for lists loaded up to a count.
+func (p pageRequest) End() int {
+	return p.Offset + p.Size + 1
+}
+
+// next returns the cursor of the page following p if the list has total
+// items, or "" if p is the last page.
+func (p pageRequest) next(total int) string {
+	if p.Offset+p.Size >= total {
+		return ""
+	}
+	return encodeCursor(p.Offset + p.Size)
+}
+
+// paginate returns the items of page p and the cursor of the next page.
+func paginate[T any](p pageRequest, items []T) ([]T, string) {
+	start := min(p.Offset, len(items))
+	end := min(start+p.Size, len(items))
+	// A fresh slice, so JSON encodes an empty page as [] rather than null.
+	return append(make([]T, 0, end-start), items[start:end]...), p.next(len(items))
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	v, ok := strings.CutPrefix(string(b), cursorPrefix)
+	offset, err := strconv.Atoi(v)
+	return offset, ok && err == nil && offset >= 0
+}
@@ -0,0 +1,85 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// ListProfilesHandler returns all settings profiles and the active one.
+func ListProfilesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"profiles": config.GetProfiles(),
+		"active":   config.GetSettings().Fail2ban.ActiveProfile,
+	})
+}
+
+// SaveProfileHandler creates or replaces a settings profile.
+func SaveProfileHandler(c *gin.Context) {
+	var p config.SettingsProfile
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := validateExpression(p.AlertExpression); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert expression", "details": err.Error()})
+		return
+	}
+	if err := config.SaveProfile(p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile saved"})
+}
+
+// DeleteProfileHandler removes a settings profile.
+func DeleteProfileHandler(c *gin.Context) {
+	if err := config.DeleteProfile(c.Param("name")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, config.ErrProfileNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile deleted"})
+}
+
+// ActivateProfileHandler switches to a settings profile. The jail.local
+// changes are staged and take effect with the next reload.
+func ActivateProfileHandler(c *gin.Context) {
+	settings, err := config.ActivateProfile(c.Param("name"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrProfileNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	recordChange(c, "Activate profile %s", settings.Fail2ban.ActiveProfile)
+	checkDriftSoon()
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Profile activated",
+		"active":        settings.Fail2ban.ActiveProfile,
+		"restartNeeded": settings.Server.RestartNeeded,
+	})
+}
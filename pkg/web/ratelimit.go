@@ -0,0 +1,138 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// maxBuckets bounds the number of tracked client addresses; beyond it the
+// buckets that refilled completely are dropped.
+const maxBuckets = 10000
+
+// bucket is the token bucket of one client address.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter holds a token bucket per client address. Each request takes
+// a token; tokens refill at perMinute up to burst.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+var (
+	requestLimiter = &rateLimiter{buckets: make(map[string]*bucket)}
+	loginLimiter   = &rateLimiter{buckets: make(map[string]*bucket)}
+	lockoutLimiter = &rateLimiter{buckets: make(map[string]*bucket)}
+)
+
+// take takes a token of key. When the bucket is empty it returns how long
+// until the next token is available.
+func (l *rateLimiter) take(key string, perMinute, burst int, now time.Time) (time.Duration, bool) {
+	rate := float64(perMinute) / 60 // tokens per second
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.pruneLocked(rate, float64(burst), now)
+		}
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// pruneLocked drops the buckets that are full again. The caller holds l.mu.
+func (l *rateLimiter) pruneLocked(rate, burst float64, now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimit limits the requests of each client address with a token
+// bucket, see config.LimitSettings.RequestRate, and the posts of the login
+// form and the unban requests of the lockout page with stricter ones, so
+// the UI is no easy brute-force or spam target.
+// Ban notifications and ingested events are not limited: dropping them
+// would lose bans during the very attacks they report.
+func rateLimit(c *gin.Context) {
+	route := apiRoute(c)
+	if route == "/api/ban" || isIngestRequest(c) {
+		c.Next()
+		return
+	}
+	ip := requestIP(c)
+	if ip == nil {
+		c.Next()
+		return
+	}
+	limits := config.GetSettings().Server.Limits
+	now := time.Now()
+	if c.Request.Method == http.MethodPost && (route == "/login" || route == "/lockout/request") {
+		limiter := loginLimiter
+		if route != "/login" {
+			limiter = lockoutLimiter
+		}
+		perMinute, burst := limits.LoginRate()
+		if wait, ok := limiter.take(ip.String(), perMinute, burst, now); !ok {
+			rejectRate(c, wait)
+			return
+		}
+	}
+	perMinute, burst := limits.RequestRate()
+	if wait, ok := requestLimiter.take(ip.String(), perMinute, burst, now); !ok {
+		rejectRate(c, wait)
+		return
+	}
+	c.Next()
+}
+
+// rejectRate answers a request over the rate limit with 429.
+func rejectRate(c *gin.Context, wait time.Duration) {
+	config.DebugLog("Rate limited %s %s from %s (ratelimit.go)", c.Request.Method, c.Request.URL.Path, c.Request.RemoteAddr)
+	c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	switch route := apiRoute(c); {
+	case route == "/login":
+		c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/login?error=429")
+		c.Abort()
+		return
+	case route == "/lockout/request" && c.ContentType() != "application/json":
+		c.Redirect(http.StatusSeeOther, c.GetString(basePathKey)+"/lockout?result=429")
+		c.Abort()
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, slow down"})
+}
@@ -0,0 +1,159 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/metrics"
+)
+
+// errorAlertCooldown is the minimum time between two alerts of the same
+// kind, so a broken deployment does not flood the admin's inbox.
+const errorAlertCooldown = time.Hour
+
+// serverError is a response with a 5xx status, kept for the error alert.
+type serverError struct {
+	at     time.Time
+	method string
+	route  string
+	status int
+}
+
+var errorWatch struct {
+	sync.Mutex
+	recent     []serverError        // within the alert window
+	lastAlerts map[string]time.Time // by alert kind
+}
+
+// recoverPanics replaces gin.Recovery: a panicking handler is logged with
+// its stack trace, counted in fail2ban_ui_panics_total and answered with
+// 500, and the admin is alerted if error alerts are enabled. Responses
+// with server errors are watched for spikes.
+func recoverPanics(c *gin.Context) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			watchErrors(c)
+			return
+		}
+		if err, ok := rec.(error); ok {
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+				// The client went away, nothing is broken.
+				c.Abort()
+				return
+			}
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		stack := debug.Stack()
+		log.Printf("💥 Panic in %s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, rec, stack)
+		metrics.Panic(route)
+		if c.Writer.Written() {
+			c.Abort()
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal error, see the server log"})
+		}
+		alertAdmin("panic "+route,
+			fmt.Sprintf("[Fail2Ban UI] Panic in %s %s", c.Request.Method, route),
+			fmt.Sprintf("<p>A request handler of Fail2ban UI panicked and was recovered.</p><p><b>Request:</b> %s %s<br><b>Time:</b> %s</p><pre>%s\n\n%s</pre>",
+				c.Request.Method, html.EscapeString(c.Request.URL.Path), time.Now().Format(time.RFC1123),
+				html.EscapeString(fmt.Sprint(rec)), html.EscapeString(string(stack))))
+	}()
+	c.Next()
+}
+
+// watchErrors records responses with server errors and alerts the admin
+// once their number within the configured window reaches the threshold.
+func watchErrors(c *gin.Context) {
+	status := c.Writer.Status()
+	if status < http.StatusInternalServerError {
+		return
+	}
+	settings := config.GetSettings().Notifications.ErrorAlerts
+	now := time.Now()
+
+	errorWatch.Lock()
+	recent := errorWatch.recent[:0]
+	for _, e := range errorWatch.recent {
+		if now.Sub(e.at) <= settings.Window() {
+			recent = append(recent, e)
+		}
+	}
+	recent = append(recent, serverError{at: now, method: c.Request.Method, route: c.FullPath(), status: status})
+	if len(recent) > settings.Limit() {
+		recent = recent[len(recent)-settings.Limit():]
+	}
+	errorWatch.recent = recent
+	spike := len(recent) >= settings.Limit()
+	var lines []string
+	if spike {
+		for _, e := range recent[max(0, len(recent)-10):] {
+			lines = append(lines, fmt.Sprintf("%s %d %s %s", e.at.Format(time.TimeOnly), e.status, e.method, html.EscapeString(e.route)))
+		}
+	}
+	errorWatch.Unlock()
+
+	if spike {
+		alertAdmin("errors",
+			fmt.Sprintf("[Fail2Ban UI] %d server errors in %s", len(recent), settings.Window()),
+			fmt.Sprintf("<p>Fail2ban UI answered %d requests with server errors within %s. The backend may be broken, bans could be missed.</p><p>Latest:</p><pre>%s</pre>",
+				len(recent), settings.Window(), strings.Join(lines, "\n")))
+	}
+}
+
+// alertAdmin emails the admin address (destemail) in the background if
+// error alerts are enabled and no alert of kind was sent within
+// errorAlertCooldown.
+func alertAdmin(kind, subject, body string) {
+	settings := config.GetSettings()
+	if !settings.Notifications.ErrorAlerts.Enabled || settings.Fail2ban.Destemail == "" {
+		return
+	}
+	errorWatch.Lock()
+	if errorWatch.lastAlerts == nil {
+		errorWatch.lastAlerts = make(map[string]time.Time)
+	}
+	if time.Since(errorWatch.lastAlerts[kind]) < errorAlertCooldown {
+		errorWatch.Unlock()
+		return
+	}
+	errorWatch.lastAlerts[kind] = time.Now()
+	errorWatch.Unlock()
+
+	go func() {
+		if err := sendEmail(settings.Fail2ban.Destemail, subject, body, settings); err != nil {
+			log.Printf("❌ Failed to send the error alert to %s: %v", settings.Fail2ban.Destemail, err)
+		}
+	}()
+}
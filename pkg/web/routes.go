@@ -18,43 +18,261 @@ package web
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
 )
 
-// RegisterRoutes sets up the routes for the Fail2ban UI.
-func RegisterRoutes(r *gin.Engine) {
+// RegisterRoutes sets up the routes for the Fail2ban UI on r, which may be
+// a route group when the UI is mounted below a base path.
+func RegisterRoutes(r gin.IRouter) {
 
 	// Render the dashboard
 	r.GET("/", IndexHandler)
+	r.GET(logoURL, LogoHandler)
+	r.GET("/metrics", requireFeature(config.FeatureMetrics), MetricsHandler)
+	r.GET("/login", LoginPageHandler)
+	r.POST("/login", LoginHandler)
 
-	api := r.Group("/api")
+	// Public lockout self-service page for banned visitors, see lockout.go
+	r.GET("/lockout", requireFeature(config.FeatureLockout), LockoutPageHandler)
+	r.GET("/lockout/status", requireFeature(config.FeatureLockout), LockoutStatusHandler)
+	r.POST("/lockout/request", requireFeature(config.FeatureLockout), LockoutRequestHandler)
+
+	// The API is served under /api/v1 and, deprecated, under /api; see versions.go.
+	r.GET("/api/versions", APIVersionsHandler)
+	for _, v := range apiVersions {
+		registerAPI(r.Group(v.Path, versionHeaders(v)))
+	}
+}
+
+// registerAPI sets up the routes of the v1 API on api.
+func registerAPI(api *gin.RouterGroup) {
+	// Requests of tenant users only see the jails of their tenant;
+	// providerOnly marks endpoints affecting the whole instance.
+	// operatorOnly and adminOnly mark the changes limited to these roles.
+	api.Use(observeAPI, countLoad, limitBody, tenantContext, trackAdmin)
 	{
+		api.GET("/bootstrap", BootstrapHandler)
+		api.GET("/self", SelfProtectionHandler)
 		api.GET("/summary", SummaryHandler)
-		api.POST("/jails/:jail/unban/:ip", UnbanIPHandler)
+		api.GET("/summary/historical", HistoricalSummaryHandler)
+		api.GET("/tenant", CurrentTenantHandler)
+
+		// Dashboard sessions in header authentication mode, see sessions.go
+		api.GET("/session", GetSessionHandler)
+		api.POST("/session/keepalive", KeepAliveHandler)
+		api.DELETE("/session", LogoutHandler)
+		api.GET("/sessions", providerOnly, ListSessionsHandler)
+		api.DELETE("/sessions/:id", providerOnly, adminOnly, RevokeSessionHandler)
+		api.PUT("/auth/password", providerOnly, adminOnly, SetPasswordHandler)
+		api.GET("/auth/totp", providerOnly, adminOnly, TOTPStatusHandler)
+		api.POST("/auth/totp/enroll", providerOnly, adminOnly, EnrollTOTPHandler)
+		api.POST("/auth/totp/confirm", providerOnly, adminOnly, ConfirmTOTPHandler)
+		api.POST("/auth/totp/recovery-codes", providerOnly, adminOnly, RecoveryCodesHandler)
+		api.DELETE("/auth/totp", providerOnly, adminOnly, DisableTOTPHandler)
+
+		// API tokens for automation, see apitokens.go
+		api.GET("/tokens", providerOnly, adminOnly, ListAPITokensHandler)
+		api.POST("/tokens", providerOnly, adminOnly, AddAPITokenHandler)
+		api.DELETE("/tokens/:id", providerOnly, adminOnly, DeleteAPITokenHandler)
+
+		// Ban event store lookups
+		api.GET("/events", ListEventsHandler)
+		api.GET("/events/backfill", providerOnly, BackfillStatusHandler)
+		api.GET("/events/index", providerOnly, IndexStatsHandler)
+		api.GET("/events/ip/:ip", IPEventsHandler)
+		api.GET("/events/ip/:ip/related", RelatedIPsHandler)
+		api.GET("/whois/:ip", requireFeature(config.FeatureWhois), WhoisHandler)
+		api.GET("/events/jail/:jail", JailEventsHandler)
+		api.GET("/events/stream", EventStreamHandler)
+
+		// Events of non-fail2ban sources, authenticated by ingest tokens
+		api.POST("/events", IngestEventsHandler)
+		api.GET("/ingest/events", providerOnly, ListIngestedEventsHandler)
+		api.GET("/ingest/tokens", providerOnly, ListIngestTokensHandler)
+		api.POST("/ingest/tokens", providerOnly, adminOnly, AddIngestTokenHandler)
+		api.PUT("/ingest/tokens/:id", providerOnly, adminOnly, UpdateIngestTokenHandler)
+		api.DELETE("/ingest/tokens/:id", providerOnly, adminOnly, DeleteIngestTokenHandler)
+
+		// SQLite ban history, kept beyond the log rotation
+		api.GET("/history", requireFeature(config.FeatureHistory), HistoryHandler)
+		api.GET("/history/stats", providerOnly, requireFeature(config.FeatureHistory), HistoryStatsHandler)
+		api.GET("/stats/countries", CountryStatsHandler)
+		api.GET("/stats/ports", PortStatsHandler)
+		api.GET("/stats/tags", TagStatsHandler)
+
+		// Operator notes and tags on IPs
+		api.GET("/notes", providerOnly, ListIPNotesHandler)
+		api.GET("/notes/:ip", GetIPNoteHandler)
+		api.PUT("/notes/:ip", providerOnly, operatorOnly, SetIPNoteHandler)
+		api.DELETE("/notes/:ip", providerOnly, operatorOnly, DeleteIPNoteHandler)
+
+		// Incidents annotating the ban timeline
+		api.GET("/incidents", ListIncidentsHandler)
+		api.POST("/incidents", providerOnly, operatorOnly, AddIncidentHandler)
+		api.PUT("/incidents/:id", providerOnly, operatorOnly, UpdateIncidentHandler)
+		api.DELETE("/incidents/:id", providerOnly, operatorOnly, DeleteIncidentHandler)
+
+		// Expression language used by alerts, webhooks and escalation rules
+		api.POST("/expressions/test", providerOnly, TestExpressionHandler)
+
+		// Additional hosts managed over SSH, see hosts.go
+		api.GET("/hosts", providerOnly, ListHostsHandler)
+		api.POST("/hosts", providerOnly, adminOnly, AddHostHandler)
+		api.PUT("/hosts/:id", providerOnly, adminOnly, UpdateHostHandler)
+		api.DELETE("/hosts/:id", providerOnly, adminOnly, DeleteHostHandler)
+		api.POST("/hosts/:id/check", providerOnly, CheckHostHandler)
+		api.GET("/hosts/:id/summary", providerOnly, HostSummaryHandler)
+		api.POST("/hosts/:id/jails/:jail/unban/:ip", providerOnly, operatorOnly, HostUnbanIPHandler)
+		api.GET("/fleet/summary", providerOnly, FleetSummaryHandler)
+		api.POST("/fleet/actions", providerOnly, operatorOnly, FleetActionHandler)
+
+		// Outbound webhooks
+		api.GET("/webhooks", providerOnly, ListWebhooksHandler)
+		api.POST("/webhooks", providerOnly, adminOnly, AddWebhookHandler)
+		api.PUT("/webhooks/:id", providerOnly, adminOnly, UpdateWebhookHandler)
+		api.DELETE("/webhooks/:id", providerOnly, adminOnly, DeleteWebhookHandler)
+		api.POST("/webhooks/:id/test", providerOnly, adminOnly, TestWebhookHandler)
+
+		// Log of all alert deliveries (email, webhooks)
+		api.GET("/notifications/log", NotificationLogHandler)
+		api.GET("/callbacks", providerOnly, CallbackStatsHandler)
+		api.GET("/log-health", requireFeature(config.FeatureLogHealth), LogHealthHandler)
+		api.GET("/state", BackendStateHandler)
+		api.GET("/logs/tail", providerOnly, LogTailHandler)
+
+		// Dashboard branding (white-labeling)
+		api.GET("/branding", GetBrandingHandler)
+		api.PUT("/branding", providerOnly, adminOnly, UpdateBrandingHandler)
+		api.POST("/branding/logo", providerOnly, adminOnly, UploadLogoHandler)
+		api.DELETE("/branding/logo", providerOnly, adminOnly, DeleteLogoHandler)
+
+		// Background jobs
+		api.GET("/jobs", providerOnly, ListJobsHandler)
+		api.GET("/jobs/:id", providerOnly, GetJobHandler)
+		api.POST("/jobs/geoip-enrich", providerOnly, adminOnly, StartGeoEnrichHandler)
+
+		// Third-party integrations (cloud ranges, ...)
+		api.GET("/integrations", providerOnly, IntegrationsStatusHandler)
+		api.POST("/integrations/:name/run", providerOnly, adminOnly, RunIntegrationHandler)
+		api.GET("/geoip", providerOnly, GeoIPStatusHandler)
+		api.GET("/geoip/overrides", providerOnly, ListGeoOverridesHandler)
+		api.POST("/geoip/overrides", providerOnly, adminOnly, AddGeoOverrideHandler)
+		api.PUT("/geoip/overrides/:id", providerOnly, adminOnly, UpdateGeoOverrideHandler)
+		api.DELETE("/geoip/overrides/:id", providerOnly, adminOnly, DeleteGeoOverrideHandler)
+
+		// Watchlist of IPs/CIDRs of special interest
+		api.GET("/watchlist", providerOnly, ListWatchlistHandler)
+		api.POST("/watchlist", providerOnly, operatorOnly, AddWatchlistHandler)
+		api.PUT("/watchlist/:id", providerOnly, operatorOnly, UpdateWatchlistHandler)
+		api.DELETE("/watchlist/:id", providerOnly, operatorOnly, DeleteWatchlistHandler)
+		api.GET("/watchlist/hits", providerOnly, WatchHitsHandler)
+		api.POST("/jails/:jail/ban/:ip", operatorOnly, BanIPHandler)
+		api.POST("/jails/:jail/unban/:ip", operatorOnly, UnbanIPHandler)
+		api.POST("/unban", operatorOnly, BatchUnbanHandler)
+		api.POST("/bans/import", providerOnly, operatorOnly, ImportBansHandler)
+
+		// Approval queue of the unban requests of the lockout page
+		api.GET("/requests", providerOnly, ListUnbanRequestsHandler)
+		api.POST("/requests/:id/approve", providerOnly, operatorOnly, ApproveUnbanRequestHandler)
+		api.POST("/requests/:id/deny", providerOnly, operatorOnly, DenyUnbanRequestHandler)
+		api.GET("/jails/:jail/logs/:ip", LogExcerptHandler)
 
 		// Routes for jail-filter management (TODO: rename API-call)
 		api.GET("/jails/:jail/config", GetJailFilterConfigHandler)
-		api.POST("/jails/:jail/config", SetJailFilterConfigHandler)
+		api.POST("/jails/:jail/config", providerOnly, adminOnly, SetJailFilterConfigHandler)
 
 		// Routes for jail management
 		api.GET("/jails/manage", ManageJailsHandler)
-		api.POST("/jails/manage", UpdateJailManagementHandler)
+		api.POST("/jails/manage", adminOnly, UpdateJailManagementHandler)
+		api.GET("/jails/check", JailCheckHandler)
+		api.GET("/jails/status", JailStatusHandler)
+		api.GET("/lint", providerOnly, LintHandler)
+
+		// Read-only browser of /etc/fail2ban
+		api.GET("/config/files", providerOnly, ListConfigFilesHandler)
+		api.GET("/config/files/*path", providerOnly, GetConfigFileHandler)
+
+		// Git history of /etc/fail2ban
+		api.GET("/gitops", providerOnly, GitOpsStatusHandler)
+		api.POST("/gitops/sync", providerOnly, adminOnly, GitOpsSyncHandler)
+		api.GET("/gitops/commits", providerOnly, GitOpsHistoryHandler)
+		api.GET("/gitops/commits/:hash", providerOnly, GitOpsCommitHandler)
+
+		// Declarative configuration for configuration management tools
+		api.POST("/state/plan", providerOnly, adminOnly, PlanStateHandler)
+		api.PUT("/state", providerOnly, adminOnly, ApplyStateHandler)
 
 		// Settings endpoints
-		api.GET("/settings", GetSettingsHandler)
-		api.POST("/settings", UpdateSettingsHandler)
-		api.POST("/settings/test-email", TestEmailHandler)
+		api.GET("/settings", providerOnly, GetSettingsHandler)
+		api.GET("/settings/schema", providerOnly, SettingsSchemaHandler)
+		api.POST("/settings", providerOnly, adminOnly, UpdateSettingsHandler)
+		api.POST("/settings/test-email", providerOnly, adminOnly, TestEmailHandler)
+		api.POST("/whitelist/import", providerOnly, adminOnly, ImportWhitelistHandler)
+
+		// Settings profiles
+		api.GET("/profiles", providerOnly, ListProfilesHandler)
+		api.POST("/profiles", providerOnly, adminOnly, SaveProfileHandler)
+		api.DELETE("/profiles/:name", providerOnly, adminOnly, DeleteProfileHandler)
+		api.POST("/profiles/:name/activate", providerOnly, adminOnly, ActivateProfileHandler)
+
+		// Guided tour for first-time users
+		api.GET("/tour", GetTourHandler)
+		api.POST("/tour/steps/:step", CompleteTourStepHandler)
+		api.DELETE("/tour", ResetTourHandler)
 
 		// Filter debugger endpoints
-		api.GET("/filters", ListFiltersHandler)
-		api.POST("/filters/test", TestFilterHandler)
+		api.GET("/filters", providerOnly, ListFiltersHandler)
+		api.POST("/filters/test", providerOnly, TestFilterHandler)
+		api.POST("/filters/match-any", providerOnly, MatchAnyFilterHandler)
+
+		// Import of filter collections from Git repositories or tarballs
+		api.POST("/filters/import", providerOnly, adminOnly, ImportFiltersHandler)
+		api.GET("/filters/import/:id", providerOnly, GetFilterImportHandler)
+		api.POST("/filters/import/:id/install", providerOnly, adminOnly, InstallFiltersHandler)
 
 		// TODO: create or generate new filters
 		// api.POST("/filters/generate", GenerateFilterHandler)
 
+		// Restricted fail2ban-client console and the audit log of its commands
+		api.POST("/console", providerOnly, adminOnly, requireFeature(config.FeatureConsole), ConsoleHandler)
+		api.GET("/audit", providerOnly, AuditLogHandler)
+
+		// Self-update
+		api.GET("/updates", providerOnly, UpdateStatusHandler)
+		api.POST("/updates/check", providerOnly, adminOnly, CheckUpdateHandler)
+		api.POST("/updates/download", providerOnly, adminOnly, DownloadUpdateHandler)
+		api.POST("/updates/apply", providerOnly, adminOnly, ApplyUpdateHandler)
+
 		// Restart endpoint
-		api.POST("/fail2ban/restart", RestartFail2banHandler)
+		api.POST("/fail2ban/restart", providerOnly, adminOnly, RestartFail2banHandler)
+		api.POST("/fail2ban/reload", providerOnly, adminOnly, ReloadFail2banHandler)
+		api.GET("/fail2ban/effective-config", EffectiveConfigHandler)
+		api.GET("/fail2ban/drift", DriftHandler)
+
+		// fail2ban's own database, see database.go
+		api.GET("/fail2ban/db", providerOnly, DBInfoHandler)
+		api.PUT("/fail2ban/db/purgeage", providerOnly, adminOnly, UpdateDBPurgeAgeHandler)
+		api.POST("/fail2ban/db/maintenance", providerOnly, adminOnly, MaintainDBHandler)
+
+		// Log level and target of the fail2ban server, see serverlogging.go
+		api.GET("/fail2ban/logging", providerOnly, Fail2banLoggingHandler)
+		api.PUT("/fail2ban/logging", providerOnly, adminOnly, UpdateFail2banLoggingHandler)
+
+		// Replay of past failures and bans against proposed settings, see simulate.go
+		api.POST("/simulate", providerOnly, SimulateHandler)
+
+		// Grafana JSON and Infinity datasources, see grafana.go
+		api.GET("/grafana", GrafanaTestHandler)
+		api.POST("/grafana/metrics", GrafanaMetricsHandler)
+		api.POST("/grafana/search", GrafanaMetricsHandler)
+		api.POST("/grafana/query", GrafanaQueryHandler)
+		api.GET("/grafana/series", GrafanaSeriesHandler)
+		api.GET("/grafana/table", GrafanaTableHandler)
 
 		// Handle Fail2Ban notifications
-		api.POST("/ban", BanNotificationHandler)
+		api.POST("/ban", providerOnly, BanNotificationHandler)
+
+		// Slack buttons and slash command, signed by Slack
+		api.POST("/slack", providerOnly, requireFeature(config.FeatureSlack), SlackHandler)
 	}
 }
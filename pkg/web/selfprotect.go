@@ -0,0 +1,215 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// adminSession is the address of a UI user, protected from bans while it
+// is active (see config.SelfProtectionSettings).
+type adminSession struct {
+	IP       string    `json:"ip"`
+	User     string    `json:"user"`
+	LastSeen time.Time `json:"lastSeen"`
+	// Whitelisted lists the jails the IP was added to the ignore list of.
+	Whitelisted []string `json:"whitelisted,omitempty"`
+
+	whitelisting bool
+}
+
+var (
+	adminSessions     = make(map[string]*adminSession)
+	adminSessionsLock sync.Mutex
+	sessionSweeper    sync.Once
+)
+
+func init() {
+	fail2ban.Events().Subscribe(checkSelfBan)
+	// A reload drops the ignore list entries added at runtime.
+	fail2ban.OnReload(func() {
+		adminSessionsLock.Lock()
+		defer adminSessionsLock.Unlock()
+		for _, s := range adminSessions {
+			s.Whitelisted = nil
+		}
+	})
+}
+
+// trackAdmin records the addresses of authenticated UI users. Ban
+// notifications from the fail2ban action, Slack's requests, ingested
+// events, API token requests, local connections and requests without a
+// user, e.g. without authentication, are not tracked.
+func trackAdmin(c *gin.Context) {
+	mode := config.GetSettings().Auth.SelfProtection.Mode
+	_, token := requestToken(c)
+	if route := apiRoute(c); mode != config.SelfProtectionOff && c.GetString("user") != "" && route != "/api/ban" && route != "/api/slack" && !isIngestRequest(c) && !token {
+		if ip := requestIP(c); ip != nil && !ip.IsLoopback() {
+			touchSession(ip.String(), currentUser(c), mode)
+		}
+	}
+	c.Next()
+}
+
+func touchSession(ip, user, mode string) {
+	sessionSweeper.Do(func() { go sweepSessions() })
+
+	adminSessionsLock.Lock()
+	defer adminSessionsLock.Unlock()
+	s, ok := adminSessions[ip]
+	if !ok {
+		log.Printf("🛡️ Admin %s is connected from %s", user, ip)
+		s = &adminSession{IP: ip}
+		adminSessions[ip] = s
+	}
+	s.User = user
+	s.LastSeen = time.Now()
+	if mode == config.SelfProtectionWhitelist && s.Whitelisted == nil && !s.whitelisting {
+		s.whitelisting = true
+		go whitelistSession(ip)
+	}
+}
+
+// whitelistSession adds ip to the ignore list of all running jails.
+func whitelistSession(ip string) {
+	jails, err := fail2ban.GetJails(context.Background())
+	if err != nil {
+		log.Printf("❌ Failed to whitelist admin IP %s: %v", ip, err)
+	}
+	added := []string{}
+	for _, jail := range jails {
+		if err := fail2ban.AddIgnoreIP(context.Background(), jail, ip); err != nil {
+			log.Printf("❌ Failed to whitelist admin IP %s in jail %s: %v", ip, jail, err)
+			continue
+		}
+		added = append(added, jail)
+	}
+	config.DebugLog("Whitelisted admin IP %s in jails %v (selfprotect.go)", ip, added)
+
+	adminSessionsLock.Lock()
+	defer adminSessionsLock.Unlock()
+	if s, ok := adminSessions[ip]; ok {
+		s.Whitelisted = added
+		s.whitelisting = false
+		return
+	}
+	// The session expired in the meantime.
+	go unwhitelist(ip, added)
+}
+
+// unwhitelist removes ip from the ignore lists it was added to.
+func unwhitelist(ip string, jails []string) {
+	for _, jail := range jails {
+		if err := fail2ban.DelIgnoreIP(context.Background(), jail, ip); err != nil {
+			log.Printf("❌ Failed to remove admin IP %s from the ignore list of jail %s: %v", ip, jail, err)
+		}
+	}
+}
+
+// sweepSessions ends inactive sessions, and all whitelisting once the
+// mode is no longer "whitelist".
+func sweepSessions() {
+	for range time.Tick(time.Minute) {
+		p := config.GetSettings().Auth.SelfProtection
+		adminSessionsLock.Lock()
+		for ip, s := range adminSessions {
+			expired := time.Since(s.LastSeen) > p.Session() || p.Mode == config.SelfProtectionOff
+			if len(s.Whitelisted) > 0 && (expired || p.Mode != config.SelfProtectionWhitelist) {
+				go unwhitelist(ip, s.Whitelisted)
+				s.Whitelisted = nil
+			}
+			if expired {
+				delete(adminSessions, ip)
+			}
+		}
+		adminSessionsLock.Unlock()
+	}
+}
+
+// activeSession returns the session of ip if it has not expired.
+func activeSession(ip string) (adminSession, bool) {
+	adminSessionsLock.Lock()
+	defer adminSessionsLock.Unlock()
+	s, ok := adminSessions[ip]
+	if !ok || time.Since(s.LastSeen) > config.GetSettings().Auth.SelfProtection.Session() {
+		return adminSession{}, false
+	}
+	return *s, true
+}
+
+// checkSelfBan reacts to bans of admin addresses: in "whitelist" mode the
+// IP is unbanned right away, in "warn" mode an alert is sent.
+func checkSelfBan(ev fail2ban.BanEvent) {
+	mode := config.GetSettings().Auth.SelfProtection.Mode
+	if mode == config.SelfProtectionOff {
+		return
+	}
+	s, ok := activeSession(ev.IP)
+	if !ok {
+		return
+	}
+	log.Printf("⚠️ IP %s of admin %s was banned in jail %s", ev.IP, s.User, ev.Jail)
+
+	go func() {
+		if mode == config.SelfProtectionWhitelist {
+			if err := fail2ban.UnbanIP(context.Background(), ev.Jail, ev.IP); err != nil {
+				log.Printf("❌ Failed to unban admin IP %s: %v", ev.IP, err)
+			}
+			if err := fail2ban.AddIgnoreIP(context.Background(), ev.Jail, ev.IP); err != nil {
+				log.Printf("❌ Failed to whitelist admin IP %s in jail %s: %v", ev.IP, ev.Jail, err)
+			}
+			return
+		}
+		if err := sendSelfBanAlert(s, ev, config.GetSettings()); err != nil {
+			log.Printf("❌ Failed to send self-ban alert: %v", err)
+		}
+	}()
+}
+
+// sendSelfBanAlert warns that a UI user banned themselves.
+func sendSelfBanAlert(s adminSession, ev fail2ban.BanEvent, settings config.AppSettings) error {
+	subject := fmt.Sprintf("[Fail2Ban] WARNING: admin IP %s banned in %s", ev.IP, ev.Jail)
+	body := fmt.Sprintf(`<p>The IP of a Fail2ban UI user was banned.</p>
+<p><b>IP:</b> %s<br><b>User:</b> %s<br><b>Last request:</b> %s<br><b>Jail:</b> %s<br><b>Time:</b> %s</p>
+<p>Unban it with <code>fail2ban-client set %s unbanip %s</code>.</p>`,
+		html.EscapeString(ev.IP), html.EscapeString(s.User), s.LastSeen.Format(time.RFC1123),
+		html.EscapeString(ev.Jail), ev.Time.Format(time.RFC1123), html.EscapeString(ev.Jail), html.EscapeString(ev.IP))
+	return sendEmailContext(withBan(context.Background(), ev.IP, ev.Jail), settings.Fail2ban.Destemail, subject, body, settings)
+}
+
+// SelfProtectionHandler reports whether the address of the caller is protected.
+func SelfProtectionHandler(c *gin.Context) {
+	resp := gin.H{"mode": config.GetSettings().Auth.SelfProtection.Mode}
+	if ip := requestIP(c); ip != nil {
+		resp["ip"] = ip.String()
+		if s, ok := activeSession(ip.String()); ok {
+			resp["session"] = s
+			resp["expiresAt"] = s.LastSeen.Add(config.GetSettings().Auth.SelfProtection.Session())
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,79 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// Fail2banLoggingHandler reports the log level and target of the fail2ban
+// server, the configured ones and a pending revert.
+func Fail2banLoggingHandler(c *gin.Context) {
+	info, err := fail2ban.GetLogging(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"logging": info, "levels": fail2ban.LogLevels})
+}
+
+// UpdateFail2banLoggingHandler changes the log level or target of the
+// fail2ban server, persistently or with revertAfterMinutes only for that
+// long, e.g. DEBUG while a filter is investigated.
+func UpdateFail2banLoggingHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("UpdateFail2banLoggingHandler called (serverlogging.go)") // entry point
+	var req struct {
+		Level              string `json:"level"`
+		Target             string `json:"target"`
+		RevertAfterMinutes int    `json:"revertAfterMinutes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Level == "" && req.Target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level or target is required"})
+		return
+	}
+	revertAfter := time.Duration(req.RevertAfterMinutes) * time.Minute
+	if req.RevertAfterMinutes < 0 || revertAfter > fail2ban.MaxLoggingRevert {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("revertAfterMinutes must be between 0 and %d", int(fail2ban.MaxLoggingRevert.Minutes()))})
+		return
+	}
+	if err := fail2ban.ValidateLogging(req.Level, req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := fail2ban.SetLogging(c.Request.Context(), req.Level, req.Target, revertAfter); err != nil {
+		respondError(c, err)
+		return
+	}
+	detail := strings.TrimSpace(strings.ToUpper(req.Level) + " " + req.Target)
+	if revertAfter > 0 {
+		detail += fmt.Sprintf(" for %d minutes", req.RevertAfterMinutes)
+	}
+	recordAudit(c, config.AuditEntry{Action: "fail2ban.logging", Detail: detail})
+	Fail2banLoggingHandler(c)
+}
@@ -0,0 +1,423 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// sessionCookie holds the token of the UI session, see checkSession.
+const sessionCookie = "fail2ban_ui_session"
+
+// passiveHeader marks requests made by the dashboard on its own, such as
+// the periodic refresh. They do not count as user activity.
+const passiveHeader = "X-Session-Passive"
+
+// endedHeader tells the dashboard why its session ended.
+const endedHeader = "X-Session-Ended"
+
+// uiSession is a login of a user to the dashboard. The token is the
+// cookie value; ID identifies the session in the API.
+type uiSession struct {
+	ID       string    `json:"id"`
+	User     string    `json:"user"`
+	IP       string    `json:"ip"`
+	Created  time.Time `json:"created"`
+	LastSeen time.Time `json:"lastSeen"`
+
+	token string
+}
+
+// sessionError is the reason a session could not be used or started.
+type sessionError struct {
+	status int
+	code   string
+	msg    string
+}
+
+func (e *sessionError) Error() string { return e.msg }
+
+var (
+	errSessionExpired   = &sessionError{http.StatusUnauthorized, "expired", "session expired"}
+	errSessionIdle      = &sessionError{http.StatusUnauthorized, "idle", "session ended after inactivity"}
+	errSessionReplaced  = &sessionError{http.StatusUnauthorized, "replaced", "session ended by a newer login of the same user"}
+	errSessionRevoked   = &sessionError{http.StatusUnauthorized, "revoked", "session ended by an administrator"}
+	errSessionLoggedOut = &sessionError{http.StatusUnauthorized, "loggedOut", "logged out"}
+	errSessionUnknown   = &sessionError{http.StatusUnauthorized, "unknown", "unknown session"}
+	errSessionLimit     = &sessionError{http.StatusConflict, "limit", "the maximum number of concurrent sessions is reached, log out elsewhere first"}
+)
+
+// endedSession remembers why a session ended, so its next request gets a
+// meaningful error.
+type endedSession struct {
+	err *sessionError
+	at  time.Time
+}
+
+// endedRetention is how long the reason of an ended session is kept.
+const endedRetention = 24 * time.Hour
+
+var (
+	uiSessions     = make(map[string]*uiSession) // by token
+	endedSessions  = make(map[string]endedSession)
+	uiSessionsLock sync.Mutex
+)
+
+// checkSession applies the session settings to a request of user in
+// header authentication mode. Loading the dashboard starts a session if
+// the request has none; API requests with an ended session are rejected,
+// API requests without a session cookie (scripts) pass.
+func checkSession(c *gin.Context, user string) bool {
+	settings := config.GetSettings().Auth.Sessions
+	pageLoad := apiRoute(c) == "/"
+	if token, err := sessionToken(c); token != "" || err != nil {
+		var s *uiSession
+		if err == nil {
+			s, err = useSession(token, user, settings, c.GetHeader(passiveHeader) == "")
+		}
+		if err == nil {
+			c.Set("session", s.ID)
+			return true
+		}
+		clearSessionCookie(c)
+		if !pageLoad {
+			abortSession(c, err)
+			return false
+		}
+	}
+	if !pageLoad {
+		return true
+	}
+	ip := ""
+	if addr := requestIP(c); addr != nil {
+		ip = addr.String()
+	}
+	s, err := startSession(user, ip, settings)
+	if err != nil {
+		config.DebugLog("Refused login of %s: %v (sessions.go)", user, err)
+		c.Header(endedHeader, err.code)
+		c.String(err.status, err.Error())
+		c.Abort()
+		return false
+	}
+	setSessionCookie(c, s, settings)
+	return true
+}
+
+// setSessionCookie hands the token of the new session s to the browser,
+// signed together with the end of the session, see sessionToken.
+func setSessionCookie(c *gin.Context, s *uiSession, settings config.SessionSettings) {
+	expires := s.Created.Add(settings.Lifetime())
+	value := signSession(s.token, expires)
+	c.SetSameSite(sameSiteMode(settings))
+	c.SetCookie(sessionCookie, value, int(time.Until(expires).Seconds()), cookiePath(c), "", secureCookie(c, settings), true)
+	c.Set("session", s.ID)
+}
+
+// signSession returns the cookie value for token: the token, the end of
+// the session in Unix seconds and an HMAC of both.
+func signSession(token string, expires time.Time) string {
+	payload := token + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + serverMAC("session", payload)
+}
+
+// sessionToken returns the session token of the request's cookie, or ""
+// without a cookie. Cookies with a wrong signature are rejected before
+// the session is looked up, expired ones even after a restart.
+func sessionToken(c *gin.Context) (string, *sessionError) {
+	value, _ := c.Cookie(sessionCookie)
+	if value == "" {
+		return "", nil
+	}
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 || !hmac.Equal([]byte(value[i+1:]), []byte(serverMAC("session", value[:i]))) {
+		return "", errSessionUnknown
+	}
+	token, expires, ok := strings.Cut(value[:i], ".")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if !ok || err != nil {
+		return "", errSessionUnknown
+	}
+	if time.Now().Unix() > unix {
+		return "", errSessionExpired
+	}
+	return token, nil
+}
+
+// abortSession rejects an API request whose session could not be used.
+func abortSession(c *gin.Context, err *sessionError) {
+	c.Header(endedHeader, err.code)
+	c.AbortWithStatusJSON(err.status, gin.H{"error": err.Error(), "session": err.code})
+}
+
+func clearSessionCookie(c *gin.Context) {
+	settings := config.GetSettings().Auth.Sessions
+	c.SetSameSite(sameSiteMode(settings))
+	c.SetCookie(sessionCookie, "", -1, cookiePath(c), "", secureCookie(c, settings), true)
+}
+
+// sameSiteMode returns the configured SameSite attribute of the session cookie.
+func sameSiteMode(settings config.SessionSettings) http.SameSite {
+	switch settings.SameSite {
+	case config.SameSiteLax:
+		return http.SameSiteLaxMode
+	case config.SameSiteNone:
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteStrictMode
+}
+
+// secureCookie reports whether the session cookie is marked secure.
+func secureCookie(c *gin.Context, settings config.SessionSettings) bool {
+	switch settings.SecureCookie {
+	case config.SecureAlways:
+		return true
+	case config.SecureNever:
+		return false
+	}
+	return secureRequest(c)
+}
+
+func cookiePath(c *gin.Context) string {
+	return c.GetString(basePathKey) + "/"
+}
+
+// secureRequest reports whether the browser connected over HTTPS,
+// directly or through a proxy.
+func secureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+// startSession starts a session of user, applying the concurrent login policy.
+func startSession(user, ip string, settings config.SessionSettings) (*uiSession, *sessionError) {
+	uiSessionsLock.Lock()
+	defer uiSessionsLock.Unlock()
+	pruneSessions(settings)
+
+	var active []*uiSession
+	for _, s := range uiSessions {
+		if s.User == user {
+			active = append(active, s)
+		}
+	}
+	if len(active) >= settings.Max() {
+		switch settings.ConcurrentLogins {
+		case config.ConcurrentDeny:
+			return nil, errSessionLimit
+		case config.ConcurrentKickOldest:
+			sort.Slice(active, func(i, j int) bool { return active[i].Created.Before(active[j].Created) })
+			for _, s := range active[:len(active)-settings.Max()+1] {
+				log.Printf("🔑 Ending the oldest session of %s from %s for a new login from %s", user, s.IP, ip)
+				endSessionLocked(s, errSessionReplaced)
+			}
+		}
+	}
+
+	now := time.Now()
+	s := &uiSession{ID: randomHex(8), User: user, IP: ip, Created: now, LastSeen: now, token: randomHex(32)}
+	uiSessions[s.token] = s
+	config.DebugLog("Started session %s of %s from %s (sessions.go)", s.ID, user, ip)
+	return s, nil
+}
+
+// useSession returns the session of token if it is still valid for user,
+// or for any user if user is empty, and records the activity unless the
+// request is passive.
+func useSession(token, user string, settings config.SessionSettings, active bool) (*uiSession, *sessionError) {
+	uiSessionsLock.Lock()
+	defer uiSessionsLock.Unlock()
+	pruneSessions(settings)
+
+	s, ok := uiSessions[token]
+	if !ok {
+		if ended, ok := endedSessions[token]; ok {
+			return nil, ended.err
+		}
+		return nil, errSessionUnknown
+	}
+	if user != "" && s.User != user {
+		// The proxy now authenticates someone else in this browser.
+		endSessionLocked(s, errSessionLoggedOut)
+		return nil, errSessionUnknown
+	}
+	if active {
+		s.LastSeen = time.Now()
+	}
+	return s, nil
+}
+
+// pruneSessions ends the sessions past their lifetime or idle timeout and
+// forgets old reasons. The caller holds uiSessionsLock.
+func pruneSessions(settings config.SessionSettings) {
+	now := time.Now()
+	for _, s := range uiSessions {
+		switch {
+		case now.Sub(s.Created) > settings.Lifetime():
+			endSessionLocked(s, errSessionExpired)
+		case now.Sub(s.LastSeen) > settings.Idle():
+			endSessionLocked(s, errSessionIdle)
+		}
+	}
+	for token, ended := range endedSessions {
+		if now.Sub(ended.at) > endedRetention {
+			delete(endedSessions, token)
+		}
+	}
+}
+
+// endSessionLocked ends s for reason. The caller holds uiSessionsLock.
+func endSessionLocked(s *uiSession, reason *sessionError) {
+	delete(uiSessions, s.token)
+	endedSessions[s.token] = endedSession{err: reason, at: time.Now()}
+	config.DebugLog("Ended session %s of %s: %s (sessions.go)", s.ID, s.User, reason.code)
+}
+
+// sessionByID returns the active session with the public id.
+// The caller holds uiSessionsLock.
+func sessionByID(id string) *uiSession {
+	for _, s := range uiSessions {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// sessionInfo describes s with the times it ends.
+func sessionInfo(s uiSession, settings config.SessionSettings) gin.H {
+	return gin.H{
+		"id":            s.ID,
+		"user":          s.User,
+		"ip":            s.IP,
+		"created":       s.Created,
+		"lastSeen":      s.LastSeen,
+		"expiresAt":     s.Created.Add(settings.Lifetime()),
+		"idleExpiresAt": s.LastSeen.Add(settings.Idle()),
+	}
+}
+
+// currentSession returns a copy of the session of the request.
+func currentSession(c *gin.Context) (uiSession, bool) {
+	id := c.GetString("session")
+	if id == "" {
+		return uiSession{}, false
+	}
+	uiSessionsLock.Lock()
+	defer uiSessionsLock.Unlock()
+	if s := sessionByID(id); s != nil {
+		return *s, true
+	}
+	return uiSession{}, false
+}
+
+// GetSessionHandler returns the session of the request, when it ends and
+// its CSRF token.
+// Passive requests do not extend it, so the dashboard can poll this to
+// warn before the idle timeout.
+func GetSessionHandler(c *gin.Context) {
+	s, ok := currentSession(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no session"})
+		return
+	}
+	info := sessionInfo(s, config.GetSettings().Auth.Sessions)
+	info["csrfToken"] = csrfToken(c)
+	c.JSON(http.StatusOK, info)
+}
+
+// KeepAliveHandler extends the session of a user active on the dashboard.
+// The activity itself was recorded by checkSession.
+func KeepAliveHandler(c *gin.Context) {
+	GetSessionHandler(c)
+}
+
+// LogoutHandler ends the session of the request.
+func LogoutHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("LogoutHandler called (sessions.go)") // entry point
+	if id := c.GetString("session"); id != "" {
+		uiSessionsLock.Lock()
+		if s := sessionByID(id); s != nil {
+			endSessionLocked(s, errSessionLoggedOut)
+		}
+		uiSessionsLock.Unlock()
+	}
+	clearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// ListSessionsHandler lists the active sessions of all users.
+func ListSessionsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	settings := config.GetSettings().Auth.Sessions
+	uiSessionsLock.Lock()
+	pruneSessions(settings)
+	list := make([]gin.H, 0, len(uiSessions))
+	for _, s := range uiSessions {
+		list = append(list, sessionInfo(*s, settings))
+	}
+	uiSessionsLock.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i]["created"].(time.Time).Before(list[j]["created"].(time.Time))
+	})
+	list, next := paginate(page, list)
+	c.JSON(http.StatusOK, gin.H{"sessions": list, "current": c.GetString("session"), "nextCursor": next})
+}
+
+// RevokeSessionHandler ends the session with the given id.
+func RevokeSessionHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("RevokeSessionHandler called (sessions.go)") // entry point
+	id := c.Param("id")
+	uiSessionsLock.Lock()
+	s := sessionByID(id)
+	var user string
+	if s != nil {
+		user = s.User
+		endSessionLocked(s, errSessionRevoked)
+	}
+	uiSessionsLock.Unlock()
+	if s == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session " + id + " not found"})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "sessions.revoke", Detail: user + " " + id})
+	c.JSON(http.StatusOK, gin.H{"message": "Session of " + user + " ended"})
+}
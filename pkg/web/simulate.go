@@ -0,0 +1,141 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// defaultSimulationDays is the period a simulation replays without "days".
+const defaultSimulationDays = 7
+
+// AlertSimulation counts the stored bans a notification channel alerts on
+// with its current and the proposed policy.
+type AlertSimulation struct {
+	Current  int `json:"current"`
+	Proposed int `json:"proposed"`
+}
+
+// SimulateHandler replays the last days of logged failures and stored bans
+// against proposed jail settings and notification policies, e.g.
+//
+//	{"days": 14, "jail": "sshd", "maxretry": 5, "findtime": "1h",
+//	 "policies": {"email": {"countries": ["CH", "DE"]}}}
+//
+// and reports how many bans and alerts they would have caused compared to
+// the current settings. Nothing is changed.
+func SimulateHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("SimulateHandler called (simulate.go)") // entry point
+	var req struct {
+		Days     int    `json:"days"`
+		Jail     string `json:"jail"`
+		Maxretry int    `json:"maxretry"`
+		Findtime string `json:"findtime"`
+		Bantime  string `json:"bantime"`
+		// Policies of the notification channels, see config.NotificationPolicy.
+		Policies map[string]config.NotificationPolicy `json:"policies"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = defaultSimulationDays
+	}
+	if req.Days < 0 || req.Days > fail2ban.MaxSimulationDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", fail2ban.MaxSimulationDays)})
+		return
+	}
+	req.Jail = strings.TrimSpace(req.Jail)
+	if req.Jail != "" {
+		if err := fail2ban.ValidateJailName(req.Jail); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	params := fail2ban.SimulationParams{Maxretry: req.Maxretry, Findtime: strings.TrimSpace(req.Findtime), Bantime: strings.TrimSpace(req.Bantime)}
+	if err := params.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for channel := range req.Policies {
+		if channel != config.ChannelEmail && channel != config.ChannelWebhook {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown notification channel %q", channel)})
+			return
+		}
+	}
+
+	logPath := fail2ban.GetBackfillStatus().LogPath
+	if logPath == "" {
+		logPath = fail2ban.DefaultLogPath
+	}
+	sources := config.GetSettings().Fail2ban.LogSources
+	if len(sources) == 0 {
+		sources = []config.LogSource{{Type: config.LogSourceFile, Path: logPath}}
+	}
+	since := time.Now().AddDate(0, 0, -req.Days)
+	sim, err := fail2ban.Simulate(c.Request.Context(), sources, since, req.Jail, nil, params)
+	if errors.Is(err, fail2ban.ErrSimulationRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"simulation": sim, "alerts": simulateAlerts(since, req.Jail, req.Policies)})
+}
+
+// simulateAlerts counts the stored bans since the given time each channel
+// alerts on with its current policy and the proposed one. Channels without
+// a proposed policy keep their current one.
+func simulateAlerts(since time.Time, jail string, proposed map[string]config.NotificationPolicy) map[string]AlertSimulation {
+	settings := config.GetSettings()
+	channels := []string{config.ChannelEmail, config.ChannelWebhook}
+	alerts := make(map[string]AlertSimulation, len(channels))
+	bans := fail2ban.Events().Filter(func(ev fail2ban.BanEvent) bool {
+		return !ev.Demo && !ev.Time.Before(since) && (jail == "" || ev.Jail == jail)
+	}, math.MaxInt)
+	for _, channel := range channels {
+		current := settings.PolicyFor(channel)
+		policy, ok := proposed[channel]
+		if !ok {
+			policy = current
+		}
+		var a AlertSimulation
+		for _, ev := range bans {
+			if current.AllowsCountry(ev.Country) {
+				a.Current++
+			}
+			if policy.AllowsCountry(ev.Country) {
+				a.Proposed++
+			}
+		}
+		alerts[channel] = a
+	}
+	return alerts
+}
@@ -0,0 +1,277 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/webhooks"
+)
+
+// slackMaxSkew is how old a Slack request may be, against replays.
+const slackMaxSkew = 5 * time.Minute
+
+var slackClient = &http.Client{Timeout: 10 * time.Second}
+
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+// SlackHandler answers the buttons of Slack alerts and the slash command
+// "/fail2ban unban <ip> [jail]" or "/fail2ban whitelist <ip>". Requests are
+// authenticated by Slack's signature, not by the proxy headers.
+func SlackHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("SlackHandler called (slack.go)") // entry point
+	settings := config.GetSettings().Notifications.Slack
+	if !settings.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Slack integration is not configured"})
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := verifySlackSignature(settings.SigningSecret, c.Request.Header, body); err != nil {
+		log.Printf("❌ Rejected Slack request from %s: %v", c.ClientIP(), err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form data"})
+		return
+	}
+
+	// Slash command: the answer is posted by Slack itself.
+	if form.Get("command") != "" {
+		text, ephemeral := slackCommand(c, settings, form.Get("user_id"), form.Get("user_name"), form.Get("text"))
+		c.JSON(http.StatusOK, gin.H{"response_type": slackResponseType(ephemeral), "text": text})
+		return
+	}
+
+	// Button: Slack only wants an acknowledgement, the answer goes to response_url.
+	var in slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &in); err != nil || in.Type != "block_actions" || len(in.Actions) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported Slack request"})
+		return
+	}
+	var target webhooks.SlackTarget
+	if err := json.Unmarshal([]byte(in.Actions[0].Value), &target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid button value"})
+		return
+	}
+	text, ephemeral := slackAction(c, settings, in.User.ID, in.User.Username, in.Actions[0].ActionID, target)
+	c.Status(http.StatusOK)
+	go respondSlack(in.ResponseURL, text, ephemeral)
+}
+
+// verifySlackSignature checks the X-Slack-Signature of body, see
+// https://api.slack.com/authentication/verifying-requests-from-slack.
+func verifySlackSignature(secret string, h http.Header, body []byte) error {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing request timestamp")
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > slackMaxSkew || d < -slackMaxSkew {
+		return errors.New("request timestamp is too far off")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", ts)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(h.Get("X-Slack-Signature"))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// slackCommand runs the text of a slash command and returns the answer,
+// and whether only the user should see it.
+func slackCommand(c *gin.Context, settings config.SlackSettings, userID, userName, text string) (string, bool) {
+	args := strings.Fields(text)
+	usage := "Usage: /fail2ban unban <ip> [jail] | /fail2ban whitelist <ip>"
+	if len(args) < 2 || len(args) > 3 || (args[0] == webhooks.SlackActionWhitelist && len(args) > 2) {
+		return usage, true
+	}
+	target := webhooks.SlackTarget{IP: args[1]}
+	if len(args) == 3 {
+		target.Jail = args[2]
+	}
+	switch args[0] {
+	case webhooks.SlackActionUnban, webhooks.SlackActionWhitelist:
+		return slackAction(c, settings, userID, userName, args[0], target)
+	}
+	return usage, true
+}
+
+// slackAction unbans or whitelists the target on behalf of a Slack user
+// and returns the answer, and whether only the user should see it.
+func slackAction(c *gin.Context, settings config.SlackSettings, userID, userName, action string, target webhooks.SlackTarget) (string, bool) {
+	if !settings.Allows(userID) {
+		log.Printf("❌ Slack user %s (%s) is not allowed to %s %s", userName, userID, action, target.IP)
+		return "You are not allowed to " + action + " IPs.", true
+	}
+	c.Set("user", "slack:"+userName)
+	ip, err := fail2ban.NormalizeIP(target.IP)
+	if err != nil {
+		return err.Error(), true
+	}
+	if target.Jail != "" {
+		if err := fail2ban.ValidateJailName(target.Jail); err != nil {
+			return err.Error(), true
+		}
+	}
+
+	var jails []string
+	switch action {
+	case webhooks.SlackActionUnban:
+		jails, err = unbanFromJails(c.Request.Context(), ip, target.Jail)
+	case webhooks.SlackActionWhitelist:
+		jails, err = whitelistIP(c, ip)
+	default:
+		return "Unknown action " + action, true
+	}
+	entry := config.AuditEntry{Action: "slack " + action, Detail: strings.TrimSpace(ip + " " + target.Jail)}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	recordAudit(c, entry)
+	if err != nil {
+		log.Printf("❌ Slack %s of %s by %s failed: %v", action, ip, userName, err)
+		return fmt.Sprintf("Failed to %s %s: %v", action, ip, err), false
+	}
+	log.Printf("✅ Slack user %s: %s %s", userName, action, ip)
+
+	answer := fmt.Sprintf("@%s whitelisted %s", userName, ip)
+	if action == webhooks.SlackActionUnban {
+		answer = fmt.Sprintf("@%s unbanned %s", userName, ip)
+	}
+	if len(jails) > 0 {
+		answer += " (unbanned in " + strings.Join(jails, ", ") + ")"
+	} else if action == webhooks.SlackActionUnban {
+		answer = fmt.Sprintf("%s is not banned", ip)
+	}
+	return answer, false
+}
+
+// unbanFromJails unbans ip from jail, or from every jail banning it if
+// jail is empty, and returns the jails it was unbanned from.
+func unbanFromJails(ctx context.Context, ip, jail string) ([]string, error) {
+	if jail != "" {
+		if err := fail2ban.UnbanIP(ctx, jail, ip); err != nil {
+			return nil, err
+		}
+		return []string{jail}, nil
+	}
+	jails, err := fail2ban.GetJails(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var unbanned []string
+	for _, j := range jails {
+		banned, err := fail2ban.GetBannedIPs(ctx, j)
+		if err != nil || !slices.Contains(banned, ip) {
+			continue
+		}
+		if err := fail2ban.UnbanIP(ctx, j, ip); err != nil {
+			return unbanned, err
+		}
+		unbanned = append(unbanned, j)
+	}
+	return unbanned, nil
+}
+
+// whitelistIP adds ip to ignoreip, also in the running jails so no
+// restart is needed, and unbans it everywhere.
+func whitelistIP(c *gin.Context, ip string) ([]string, error) {
+	req := config.CopySettings()
+	if !slices.Contains(strings.FieldsFunc(req.Fail2ban.IgnoreIP, isIgnoreIPSeparator), ip) {
+		req.Fail2ban.IgnoreIP = strings.TrimSpace(req.Fail2ban.IgnoreIP + " " + ip)
+		if _, err := applySettings(req); err != nil {
+			return nil, err
+		}
+		recordChange(c, "Whitelist %s", ip)
+	}
+	jails, err := fail2ban.GetJails(c.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, jail := range jails {
+		if err := fail2ban.AddIgnoreIP(c.Request.Context(), jail, ip); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+	return unbanFromJails(c.Request.Context(), ip, "")
+}
+
+func isIgnoreIPSeparator(r rune) bool {
+	return r == ' ' || r == ',' || r == '\t' || r == '\n'
+}
+
+// respondSlack posts the answer to a button press to the response URL of
+// the interaction. Only Slack's own hosts are contacted.
+func respondSlack(responseURL, text string, ephemeral bool) {
+	u, err := url.Parse(responseURL)
+	if err != nil || u.Scheme != "https" || u.Host != "hooks.slack.com" {
+		log.Printf("⚠️ Ignoring Slack response URL %q", responseURL)
+		return
+	}
+	body, _ := json.Marshal(gin.H{"text": text, "response_type": slackResponseType(ephemeral), "replace_original": false})
+	resp, err := slackClient.Post(u.String(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("⚠️ Failed to answer Slack: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+func slackResponseType(ephemeral bool) string {
+	if ephemeral {
+		return "ephemeral"
+	}
+	return "in_channel"
+}
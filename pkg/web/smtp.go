@@ -0,0 +1,185 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+const (
+	// smtpTimeout bounds a whole delivery, including dialing and authentication.
+	smtpTimeout = 30 * time.Second
+	// smtpIdleTimeout closes pooled connections that were not reused.
+	smtpIdleTimeout = 60 * time.Second
+	// smtpMaxIdle is the number of idle connections kept per server and account.
+	smtpMaxIdle = 2
+)
+
+// smtpConn is an authenticated SMTP session that can be reused for several messages.
+type smtpConn struct {
+	key    string
+	conn   net.Conn
+	client *smtp.Client
+	timer  *time.Timer
+}
+
+// smtpPool keeps authenticated SMTP sessions open for a while, so bursts of
+// alerts do not dial and authenticate once per message.
+type smtpPool struct {
+	mu   sync.Mutex
+	idle map[string][]*smtpConn
+}
+
+var mailPool = &smtpPool{idle: make(map[string][]*smtpConn)}
+
+// smtpKey identifies sessions that may be shared. Changed settings never
+// reuse a session of the old configuration.
+func smtpKey(s config.SMTPSettings) string {
+	return fmt.Sprintf("%s|%d|%s|%s", s.Host, s.Port, s.Username, s.Password)
+}
+
+// get returns an idle session for s that is still alive, or dials a new one.
+// The session's deadline is set from ctx.
+func (p *smtpPool) get(ctx context.Context, s config.SMTPSettings) (*smtpConn, error) {
+	key := smtpKey(s)
+	for {
+		c := p.pop(key)
+		if c == nil {
+			break
+		}
+		setDeadline(ctx, c.conn)
+		// RSET both checks the session and clears any state left from the last message.
+		if err := c.client.Reset(); err == nil {
+			return c, nil
+		}
+		c.close()
+	}
+	return dialSMTP(ctx, key, s)
+}
+
+func (p *smtpPool) pop(key string) *smtpConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := p.idle[key]
+	if len(list) == 0 {
+		return nil
+	}
+	c := list[len(list)-1]
+	p.idle[key] = list[:len(list)-1]
+	c.timer.Stop()
+	return c
+}
+
+// put returns a healthy session to the pool.
+func (p *smtpPool) put(c *smtpConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle[c.key]) >= smtpMaxIdle {
+		go c.close()
+		return
+	}
+	c.conn.SetDeadline(time.Time{})
+	c.timer = time.AfterFunc(smtpIdleTimeout, func() { p.expire(c) })
+	p.idle[c.key] = append(p.idle[c.key], c)
+}
+
+// expire closes an idle session unless it was taken from the pool meanwhile.
+func (p *smtpPool) expire(c *smtpConn) {
+	p.mu.Lock()
+	list := p.idle[c.key]
+	found := false
+	for i, other := range list {
+		if other == c {
+			p.idle[c.key] = append(list[:i], list[i+1:]...)
+			found = true
+			break
+		}
+	}
+	p.mu.Unlock()
+	if found {
+		c.close()
+	}
+}
+
+// close ends the session politely, bounded by a short deadline.
+func (c *smtpConn) close() {
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+// setDeadline applies the deadline of ctx, or smtpTimeout, to conn.
+func setDeadline(ctx context.Context, conn net.Conn) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+}
+
+// dialSMTP connects, upgrades to TLS and authenticates.
+// Port 465 uses implicit TLS, port 587 STARTTLS.
+func dialSMTP(ctx context.Context, key string, s config.SMTPSettings) (*smtpConn, error) {
+	if s.Port != 465 && s.Port != 587 {
+		return nil, errors.New("unsupported SMTP port. Use 587 (STARTTLS) or 465 (SMTPS)")
+	}
+	addr := net.JoinHostPort(s.Host, fmt.Sprintf("%d", s.Port))
+	tlsConfig := &tls.Config{ServerName: s.Host}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	setDeadline(ctx, conn)
+
+	if s.Port == 465 {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect via TLS: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	if s.Port == 587 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if err := client.Auth(LoginAuth(s.Username, s.Password)); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+	return &smtpConn{key: key, conn: conn, client: client}, nil
+}
@@ -0,0 +1,321 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// DesiredState is the declarative configuration accepted by PUT /api/state,
+// so configuration management tools (Ansible, ...) can drive the UI
+// idempotently. Omitted parts are left as they are.
+type DesiredState struct {
+	// Settings are merged onto the current settings, like POST /api/settings.
+	Settings json.RawMessage `json:"settings,omitempty"`
+	// Jails maps jail names to their enabled state.
+	Jails map[string]bool `json:"jails,omitempty"`
+	// Filters maps filter names to the content of filter.d/<name>.conf.
+	Filters map[string]string `json:"filters,omitempty"`
+	// IgnoreIPs and IgnoreHosts replace the whitelist of the [DEFAULT] section.
+	IgnoreIPs   []string `json:"ignoreIPs,omitempty"`
+	IgnoreHosts []string `json:"ignoreHosts,omitempty"`
+}
+
+// StateChange is a step of the plan reconciling the current with the desired state.
+type StateChange struct {
+	Kind   string `json:"kind"`   // "setting", "filter" or "jail"
+	Name   string `json:"name"`   // settings key, filter or jail name
+	Action string `json:"action"` // "create", "update", "enable" or "disable"
+	From   any    `json:"from,omitempty"`
+	To     any    `json:"to,omitempty"`
+}
+
+// statePlan is the result of planState, ready to be applied.
+type statePlan struct {
+	Changes  []StateChange
+	settings *config.AppSettings // nil if unchanged
+	filters  map[string]string
+	jails    map[string]bool
+}
+
+// PlanStateHandler returns the changes PUT /api/state would make.
+func PlanStateHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("PlanStateHandler called (state.go)") // entry point
+	var desired DesiredState
+	if !bindState(c, &desired) {
+		return
+	}
+	plan, ok := planStateOrRespond(c, desired)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": plan.Changes, "applied": false})
+}
+
+// ApplyStateHandler reconciles the configuration with the desired state.
+// With ?dryRun=true it only returns the plan.
+func ApplyStateHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ApplyStateHandler called (state.go)") // entry point
+	if c.Query("dryRun") == "true" {
+		PlanStateHandler(c)
+		return
+	}
+	var desired DesiredState
+	if !bindState(c, &desired) {
+		return
+	}
+	plan, ok := planStateOrRespond(c, desired)
+	if !ok {
+		return
+	}
+	if len(plan.Changes) == 0 {
+		c.JSON(http.StatusOK, gin.H{"changes": plan.Changes, "applied": true, "restartNeeded": config.GetSettings().Server.RestartNeeded})
+		return
+	}
+
+	err := applyStatePlan(plan)
+	audit := config.AuditEntry{Action: "state.apply", Detail: fmt.Sprintf("%d changes", len(plan.Changes))}
+	if err != nil {
+		audit.Error = err.Error()
+	}
+	recordAudit(c, audit)
+	recordChange(c, "Apply desired state (%d changes)", len(plan.Changes))
+	if err != nil {
+		status, state := errorStatus(err)
+		c.JSON(status, gin.H{"error": err.Error(), "changes": plan.Changes, "state": state})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": plan.Changes, "applied": true, "restartNeeded": config.GetSettings().Server.RestartNeeded})
+}
+
+// bindState decodes the desired state, rejecting unknown fields so typos in
+// a playbook do not go unnoticed.
+func bindState(c *gin.Context, desired *DesiredState) bool {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(desired); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+func planStateOrRespond(c *gin.Context, desired DesiredState) (statePlan, bool) {
+	plan, label, err := planState(c, desired)
+	if err == nil {
+		return plan, true
+	}
+	if label != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label, "details": err.Error()})
+	} else {
+		respondError(c, err)
+	}
+	return statePlan{}, false
+}
+
+// planState compares the desired with the current state. Invalid settings
+// are reported with a label like in UpdateSettingsHandler.
+func planState(c *gin.Context, desired DesiredState) (plan statePlan, label string, err error) {
+	plan.Changes = []StateChange{}
+
+	if desired.Settings != nil || desired.IgnoreIPs != nil || desired.IgnoreHosts != nil {
+		current := config.GetSettings()
+		next := config.CopySettings()
+		if desired.Settings != nil {
+			dec := json.NewDecoder(bytes.NewReader(desired.Settings))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&next); err != nil {
+				return plan, "invalid settings", err
+			}
+		}
+		next.Server.Branding = current.Server.Branding
+		next.Fail2ban.ResolvedIgnoreHosts = current.Fail2ban.ResolvedIgnoreHosts
+		next.Auth.TOTP = current.Auth.TOTP
+		config.KeepSecrets(&next, current)
+		if desired.IgnoreIPs != nil {
+			for _, ip := range desired.IgnoreIPs {
+				if ip == "" || strings.ContainsAny(ip, " \t\n") {
+					return plan, "invalid ignore IPs", fmt.Errorf("invalid entry %q", ip)
+				}
+			}
+			next.Fail2ban.IgnoreIP = strings.Join(desired.IgnoreIPs, " ")
+		}
+		if desired.IgnoreHosts != nil {
+			next.Fail2ban.IgnoreHosts = desired.IgnoreHosts
+		}
+		if label, err := validateSettings(c, &next); err != nil {
+			return plan, label, err
+		}
+		changes, err := settingsChanges(current, next)
+		if err != nil {
+			return plan, "", err
+		}
+		if len(changes) > 0 {
+			plan.Changes = append(plan.Changes, changes...)
+			plan.settings = &next
+		}
+	}
+
+	plan.filters = make(map[string]string)
+	for _, name := range sortedKeys(desired.Filters) {
+		if err := fail2ban.ValidateJailName(name); err != nil {
+			return plan, "", err
+		}
+		content := desired.Filters[name]
+		current, err := fail2ban.GetFilterConfig(name)
+		switch {
+		case err != nil:
+			plan.Changes = append(plan.Changes, StateChange{Kind: "filter", Name: name, Action: "create"})
+		case current != content:
+			plan.Changes = append(plan.Changes, StateChange{Kind: "filter", Name: name, Action: "update"})
+		default:
+			continue
+		}
+		plan.filters[name] = content
+	}
+
+	plan.jails = make(map[string]bool)
+	if len(desired.Jails) > 0 {
+		jails, err := fail2ban.GetAllJails()
+		if err != nil {
+			return plan, "", err
+		}
+		enabled := make(map[string]bool, len(jails))
+		for _, j := range jails {
+			enabled[j.JailName] = j.Enabled
+		}
+		for _, name := range sortedKeys(desired.Jails) {
+			if err := fail2ban.ValidateJailName(name); err != nil {
+				return plan, "", err
+			}
+			want := desired.Jails[name]
+			action := "disable"
+			if want {
+				action = "enable"
+			}
+			current, exists := enabled[name]
+			switch {
+			case !exists:
+				plan.Changes = append(plan.Changes, StateChange{Kind: "jail", Name: name, Action: "create", To: want})
+			case current != want:
+				plan.Changes = append(plan.Changes, StateChange{Kind: "jail", Name: name, Action: action, From: current, To: want})
+			default:
+				continue
+			}
+			plan.jails[name] = want
+		}
+	}
+	return plan, "", nil
+}
+
+// applyStatePlan makes the planned changes. Filters are written before the
+// jails are enabled, so a new jail never references a missing filter.
+func applyStatePlan(plan statePlan) error {
+	if plan.settings != nil {
+		if _, err := applySettings(*plan.settings); err != nil {
+			return fmt.Errorf("failed to save settings: %w", err)
+		}
+	}
+	for _, name := range sortedKeys(plan.filters) {
+		if err := fail2ban.SetFilterConfig(name, plan.filters[name]); err != nil {
+			return err
+		}
+	}
+	if len(plan.jails) > 0 {
+		if err := fail2ban.UpdateJailEnabledStates(plan.jails); err != nil {
+			return fmt.Errorf("failed to update jails: %w", err)
+		}
+	}
+	if len(plan.filters) > 0 || len(plan.jails) > 0 {
+		return config.MarkRestartNeeded()
+	}
+	return nil
+}
+
+// settingsChanges lists the settings that differ, by section and key, e.g.
+// "notifications.smtp". Read-only values (runtime state and values with
+// their own endpoints) are not reported, values holding secrets not shown.
+func settingsChanges(current, next config.AppSettings) ([]StateChange, error) {
+	a, err := settingsMap(current)
+	if err != nil {
+		return nil, err
+	}
+	b, err := settingsMap(next)
+	if err != nil {
+		return nil, err
+	}
+	readOnly := config.SettingsPaths(config.SettingReadOnly)
+	secrets := config.SettingsPaths(config.SettingSecret)
+	var changes []StateChange
+	for _, key := range sortedKeys(b) {
+		if slices.Contains(readOnly, key) || reflect.DeepEqual(a[key], b[key]) {
+			continue
+		}
+		change := StateChange{Kind: "setting", Name: key, Action: "update"}
+		if !slices.ContainsFunc(secrets, func(p string) bool { return p == key || strings.HasPrefix(p, key+".") }) {
+			change.From, change.To = a[key], b[key]
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// settingsMap returns the settings keyed by section and key. The feature
+// flags are compared as a whole.
+func settingsMap(s config.AppSettings) (map[string]any, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]any
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	for name, section := range sections {
+		fields, ok := section.(map[string]any)
+		if !ok || name == "features" {
+			m[name] = section
+			continue
+		}
+		for key, value := range fields {
+			m[name+"."+key] = value
+		}
+	}
+	return m, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// streamEvent is a ban or unban pushed to the clients of the event stream.
+type streamEvent struct {
+	Type  string             `json:"type"` // "ban" or "unban"
+	Jail  string             `json:"jail"` // empty for unbans from all jails
+	IP    string             `json:"ip"`
+	Time  time.Time          `json:"time"`
+	Event *fail2ban.BanEvent `json:"event,omitempty"`
+}
+
+const (
+	streamBuffer    = 64               // events queued per client before it is considered too slow
+	streamKeepAlive = 25 * time.Second // comment sent to keep proxies from closing idle streams
+)
+
+var (
+	streamLock    sync.Mutex
+	streamClients = make(map[chan streamEvent]struct{})
+	streamClosed  = make(chan struct{})
+	streamOnce    sync.Once
+)
+
+func init() {
+	fail2ban.Events().Subscribe(func(ev fail2ban.BanEvent) {
+		publishStreamEvent(streamEvent{Type: "ban", Jail: ev.Jail, IP: ev.IP, Time: ev.Time, Event: &ev})
+	})
+	fail2ban.OnUnban(func(jail, ip string) {
+		publishStreamEvent(streamEvent{Type: "unban", Jail: jail, IP: ip, Time: time.Now()})
+	})
+}
+
+// publishStreamEvent passes ev to all connected clients. Clients whose
+// queue is full miss the event instead of blocking the publisher.
+func publishStreamEvent(ev streamEvent) {
+	streamLock.Lock()
+	defer streamLock.Unlock()
+	for ch := range streamClients {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// CloseStreams ends all event streams, so a graceful shutdown does not wait for them.
+func CloseStreams() {
+	streamOnce.Do(func() { close(streamClosed) })
+}
+
+// EventStreamHandler pushes new ban and unban events as Server-Sent Events.
+// Unbans made outside the UI, e.g. expiring bans, are not included.
+func EventStreamHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("EventStreamHandler called (stream.go)") // entry point
+	ch := make(chan streamEvent, streamBuffer)
+	streamLock.Lock()
+	streamClients[ch] = struct{}{}
+	streamLock.Unlock()
+	defer func() {
+		streamLock.Lock()
+		delete(streamClients, ch)
+		streamLock.Unlock()
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // disable buffering in nginx
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-streamClosed:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+		case ev := <-ch:
+			// A tenant only sees events of its jails; an unban from all
+			// jails names none and could concern any IP of other tenants.
+			if ev.Jail == "" && requestTenant(c) != nil || !jailVisible(c, ev.Jail) {
+				continue
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				log.Printf("❌ Failed to encode stream event: %v", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,93 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// summaryMaxAge bounds how long cached jail infos are served even when
+// nothing changed, so the "new in the last hour" counters keep rolling.
+const summaryMaxAge = time.Minute
+
+// summaryKey identifies the state the cached jail infos were built from.
+type summaryKey struct {
+	logModTime time.Time
+	logSize    int64
+	storeAt    time.Time
+	changedAt  time.Time
+}
+
+var (
+	summaryMu     sync.Mutex
+	summaryCached summaryKey
+	summaryInfos  []fail2ban.JailInfo
+	summaryErr    error
+	summaryAt     time.Time
+)
+
+// currentSummaryKey stats the fail2ban log and collects the times the
+// event store and the daemon last changed.
+func currentSummaryKey() summaryKey {
+	logPath := fail2ban.GetBackfillStatus().LogPath
+	if logPath == "" {
+		logPath = fail2ban.DefaultLogPath
+	}
+	key := summaryKey{
+		storeAt:   fail2ban.Events().Stats().UpdatedAt,
+		changedAt: fail2ban.ChangedAt(),
+	}
+	if fi, err := os.Stat(logPath); err == nil {
+		key.logModTime, key.logSize = fi.ModTime(), fi.Size()
+	}
+	return key
+}
+
+// cachedJailInfos returns the result of fail2ban.BuildJailInfos, reusing
+// the last result while the fail2ban log, the event store and the daemon
+// are unchanged. Dashboards polling every few seconds then no longer run
+// fail2ban-client for every jail on each request. Callers get their own
+// copy and may modify it.
+func cachedJailInfos(ctx context.Context) ([]fail2ban.JailInfo, error) {
+	key := currentSummaryKey()
+	summaryMu.Lock()
+	defer summaryMu.Unlock()
+	if summaryAt.IsZero() || key != summaryCached || time.Since(summaryAt) > summaryMaxAge {
+		infos, err := fail2ban.BuildJailInfos(ctx)
+		if errors.Is(err, fail2ban.ErrClientBusy) || ctx.Err() != nil {
+			// Retry on the next request instead of serving the error for a minute.
+			return nil, err
+		}
+		summaryInfos, summaryErr = infos, err
+		summaryCached, summaryAt = key, time.Now()
+	} else {
+		config.DebugLog("Serving cached jail infos from %s", summaryAt.Format(time.RFC3339))
+	}
+	if summaryErr != nil {
+		return nil, summaryErr
+	}
+	infos := make([]fail2ban.JailInfo, len(summaryInfos))
+	copy(infos, summaryInfos)
+	return infos, nil
+}
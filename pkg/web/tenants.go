@@ -0,0 +1,114 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// tenantKey is the gin context key holding the config.Tenant of the request.
+const tenantKey = "tenant"
+
+// tenantContext resolves the tenant of the current user for all API handlers.
+func tenantContext(c *gin.Context) {
+	if t, ok := config.TenantForUser(currentUser(c)); ok {
+		c.Set(tenantKey, t)
+	}
+	c.Next()
+}
+
+// requestTenant returns the tenant of the request, or nil for operators.
+func requestTenant(c *gin.Context) *config.Tenant {
+	if v, ok := c.Get(tenantKey); ok {
+		t := v.(config.Tenant)
+		return &t
+	}
+	return nil
+}
+
+// providerOnly rejects tenant users from endpoints affecting the whole instance,
+// such as settings, daemon restarts or webhooks. Viewers are rejected as
+// well, since these endpoints expose secrets like the SMTP password.
+func providerOnly(c *gin.Context) {
+	if t := requestTenant(c); t != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available for users of tenant " + t.Name})
+		return
+	}
+	if requestRole(c) == config.RoleViewer {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available for read-only users"})
+		return
+	}
+	c.Next()
+}
+
+// jailVisible reports whether the request may see jail.
+func jailVisible(c *gin.Context, jail string) bool {
+	t := requestTenant(c)
+	return t == nil || t.AllowsJail(jail)
+}
+
+// visibleEvents drops events of jails the request may not see.
+func visibleEvents(c *gin.Context, events []fail2ban.BanEvent) []fail2ban.BanEvent {
+	if requestTenant(c) == nil {
+		return events
+	}
+	out := events[:0:0]
+	for _, ev := range events {
+		if jailVisible(c, ev.Jail) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// visibleJails drops jails the request may not see.
+func visibleJails(c *gin.Context, jails []fail2ban.JailInfo) []fail2ban.JailInfo {
+	if requestTenant(c) == nil {
+		return jails
+	}
+	out := jails[:0:0]
+	for _, j := range jails {
+		if jailVisible(c, j.JailName) {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+// visibleNames drops jail names the request may not see.
+func visibleNames(c *gin.Context, jails []string) []string {
+	if requestTenant(c) == nil {
+		return jails
+	}
+	out := jails[:0:0]
+	for _, j := range jails {
+		if jailVisible(c, j) {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+// CurrentTenantHandler returns the user, role and tenant of the request.
+// "tenant" is null for operators with access to all jails.
+func CurrentTenantHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"user": currentUser(c), "role": requestRole(c), "tenant": requestTenant(c)})
+}
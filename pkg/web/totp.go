@@ -0,0 +1,175 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// totpQRSize is the size of the enrollment QR code in pixels.
+const totpQRSize = 256
+
+// tokenRequest rejects requests made with an API token, which must not
+// change the login of the admin.
+func tokenRequest(c *gin.Context) bool {
+	if _, ok := requestToken(c); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot manage two-factor authentication"})
+		return true
+	}
+	return false
+}
+
+// TOTPStatusHandler reports whether the admin login requires a second
+// factor and how many recovery codes are left.
+func TOTPStatusHandler(c *gin.Context) {
+	totp := config.GetSettings().Auth.TOTP
+	c.JSON(http.StatusOK, gin.H{"enabled": totp.Enabled(), "recoveryCodesLeft": len(totp.RecoveryCodes)})
+}
+
+// EnrollTOTPHandler starts the enrollment of an authenticator app for the
+// admin login of password mode. It returns the secret, the otpauth:// URL
+// and the URL as QR code image; the second factor is enabled by
+// ConfirmTOTPHandler.
+func EnrollTOTPHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("EnrollTOTPHandler called (totp.go)") // entry point
+	if tokenRequest(c) {
+		return
+	}
+	auth := config.GetSettings().Auth
+	if auth.AdminPasswordHash() == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication needs an admin password, set it first"})
+		return
+	}
+	secret, url, err := config.BeginTOTPEnrollment(auth.Admin())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	png, err := qrcode.Encode(url, qrcode.Medium, totpQRSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render the QR code: " + err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"secret": secret,
+		"url":    url,
+		"qr":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// ConfirmTOTPHandler enables the second factor once the enrolled app
+// produced a valid code, and returns the recovery codes. They are only
+// shown this once.
+func ConfirmTOTPHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ConfirmTOTPHandler called (totp.go)") // entry point
+	if tokenRequest(c) {
+		return
+	}
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	codes, err := config.ConfirmTOTP(req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "auth.totp.enable"})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recoveryCodes": codes})
+}
+
+// RecoveryCodesHandler replaces the recovery codes, given a valid code of
+// the authenticator app or a recovery code.
+func RecoveryCodesHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("RecoveryCodesHandler called (totp.go)") // entry point
+	if tokenRequest(c) {
+		return
+	}
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !config.VerifySecondFactor(req.Code) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "wrong authentication code"})
+		return
+	}
+	codes, err := config.RegenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "auth.totp.recovery"})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// DisableTOTPHandler turns the second factor off. It requires the admin
+// password and a valid authentication code.
+func DisableTOTPHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("DisableTOTPHandler called (totp.go)") // entry point
+	if tokenRequest(c) {
+		return
+	}
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	auth := config.GetSettings().Auth
+	if !auth.TOTP.Enabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+	switch verifyLoginCode(c, auth.Admin(), req.Password, func() bool { return config.VerifySecondFactor(req.Code) }) {
+	case 0:
+	case http.StatusTooManyRequests:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed logins, try again later"})
+		return
+	case http.StatusForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "wrong authentication code"})
+		return
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "wrong user name or password"})
+		return
+	}
+	if err := config.DisableTOTP(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, config.AuditEntry{Action: "auth.totp.disable"})
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
@@ -0,0 +1,209 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/mail"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// maxRequestHistory is the number of ban events listed with each request.
+const maxRequestHistory = 20
+
+// unbanRequestView is an unban request with what an operator needs to
+// decide it: the latest bans of the IP and its earlier requests.
+type unbanRequestView struct {
+	config.UnbanRequest
+	History          []fail2ban.BanEvent   `json:"history"`
+	PreviousRequests []config.UnbanRequest `json:"previousRequests"`
+}
+
+// ListUnbanRequestsHandler returns the unban requests of the lockout page,
+// by default the pending ones; ?status=approved, denied or all lists others.
+func ListUnbanRequestsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	status := c.DefaultQuery("status", config.UnbanPending)
+	switch status {
+	case config.UnbanPending, config.UnbanApproved, config.UnbanDenied, "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved, denied or all"})
+		return
+	}
+	all := config.GetUnbanRequests()
+	reqs := all
+	if status != "all" {
+		reqs = slices.DeleteFunc(slices.Clone(all), func(r config.UnbanRequest) bool { return r.Status != status })
+	}
+	reqs, next := paginate(page, reqs)
+	out := make([]unbanRequestView, 0, len(reqs))
+	for _, r := range reqs {
+		out = append(out, unbanRequestView{UnbanRequest: r, History: banHistory(r.IP), PreviousRequests: previousRequests(all, r)})
+	}
+	c.JSON(http.StatusOK, gin.H{"requests": out, "nextCursor": next})
+}
+
+// banHistory returns the latest ban events of ip, newest first.
+func banHistory(ip string) []fail2ban.BanEvent {
+	events := fail2ban.Events().ByIP(ip)
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.After(events[j].Time) })
+	if len(events) > maxRequestHistory {
+		events = events[:maxRequestHistory]
+	}
+	return events
+}
+
+// previousRequests returns the requests of r's IP submitted before r.
+func previousRequests(all []config.UnbanRequest, r config.UnbanRequest) []config.UnbanRequest {
+	out := []config.UnbanRequest{}
+	for _, old := range all {
+		if old.IP == r.IP && old.CreatedAt.Before(r.CreatedAt) {
+			out = append(out, old)
+		}
+	}
+	return out
+}
+
+// decisionRequest is the optional body of the approve and deny endpoints.
+type decisionRequest struct {
+	Note string `json:"note"` // sent to the requester
+}
+
+// ApproveUnbanRequestHandler unbans the address of a pending request from
+// all jails, marks the request approved and notifies the requester.
+func ApproveUnbanRequestHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ApproveUnbanRequestHandler called (unbanrequests.go)") // entry point
+	r, note, ok := pendingUnbanRequest(c)
+	if !ok {
+		return
+	}
+	if err := fail2ban.UnbanIPAll(c.Request.Context(), r.IP); err != nil {
+		recordAudit(c, config.AuditEntry{Action: "unban-request", Detail: "approve " + r.ID + " of " + r.IP, Error: err.Error()})
+		respondError(c, err)
+		return
+	}
+	decideUnbanRequest(c, r, config.UnbanApproved, note)
+}
+
+// DenyUnbanRequestHandler marks a pending request denied and notifies the
+// requester; the address stays banned.
+func DenyUnbanRequestHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("DenyUnbanRequestHandler called (unbanrequests.go)") // entry point
+	r, note, ok := pendingUnbanRequest(c)
+	if !ok {
+		return
+	}
+	decideUnbanRequest(c, r, config.UnbanDenied, note)
+}
+
+// pendingUnbanRequest looks up the pending request named by the id
+// parameter and reads the note of the decision.
+func pendingUnbanRequest(c *gin.Context) (config.UnbanRequest, string, bool) {
+	var req decisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return config.UnbanRequest{}, "", false
+		}
+	}
+	id := c.Param("id")
+	for _, r := range config.GetUnbanRequests() {
+		if r.ID != id {
+			continue
+		}
+		if r.Status != config.UnbanPending {
+			c.JSON(http.StatusConflict, gin.H{"error": config.ErrUnbanRequestDecided.Error()})
+			return r, "", false
+		}
+		return r, req.Note, true
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": config.ErrUnbanRequestNotFound.Error()})
+	return config.UnbanRequest{}, "", false
+}
+
+// decideUnbanRequest records the decision on r, audits it, notifies the
+// requester and answers with the request.
+func decideUnbanRequest(c *gin.Context, r config.UnbanRequest, status, note string) {
+	action := "approve"
+	if status == config.UnbanDenied {
+		action = "deny"
+	}
+	r, err := config.DecideUnbanRequest(r.ID, status, currentUser(c), note)
+	if err != nil {
+		recordAudit(c, config.AuditEntry{Action: "unban-request", Detail: action + " " + r.ID + " of " + r.IP, Error: err.Error()})
+		switch {
+		case errors.Is(err, config.ErrUnbanRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, config.ErrUnbanRequestDecided):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	entry := config.AuditEntry{Action: "unban-request", Detail: action + " " + r.ID + " of " + r.IP}
+	if err := notifyRequester(r); err != nil {
+		r.NotifyError = err.Error()
+		entry.Error = "requester not notified: " + err.Error()
+		if err := config.SetUnbanNotifyError(r.ID, r.NotifyError); err != nil {
+			log.Printf("⚠️ Failed to save unban request %s: %v", r.ID, err)
+		}
+	}
+	recordAudit(c, entry)
+	c.JSON(http.StatusOK, gin.H{"request": r})
+}
+
+// notifyRequester emails the decision on r to its contact address. The
+// lockout page shows the decision as well, so requests without an email
+// address are not an error.
+func notifyRequester(r config.UnbanRequest) error {
+	if r.Contact == "" {
+		return nil
+	}
+	addr, err := mail.ParseAddress(r.Contact)
+	if err != nil {
+		return nil
+	}
+	settings := config.GetSettings()
+	title := settings.Server.Branding.Title
+	if title == "" {
+		title = "Fail2ban UI"
+	}
+	subject := fmt.Sprintf("[%s] Your unban request for %s was %s", title, r.IP, r.Status)
+	body := fmt.Sprintf("<p>Your request to unban the IP address <b>%s</b> was <b>%s</b>.</p>", html.EscapeString(r.IP), r.Status)
+	if r.Status == config.UnbanApproved {
+		body += "<p>The address is no longer banned.</p>"
+	}
+	if r.Note != "" {
+		body += "<p>" + html.EscapeString(r.Note) + "</p>"
+	}
+	return sendEmail(addr.Address, subject, body, settings)
+}
@@ -0,0 +1,85 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/integrations"
+	"github.com/swissmakers/fail2ban-ui/internal/jobs"
+)
+
+// UpdateStatusHandler returns the result of the last update check.
+func UpdateStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, integrations.GetUpdateStatus())
+}
+
+// CheckUpdateHandler checks GitHub for a new release right away.
+func CheckUpdateHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("CheckUpdateHandler called (updates.go)")
+	if err := integrations.CheckForUpdate(); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Update check failed: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, integrations.GetUpdateStatus())
+}
+
+// DownloadUpdateHandler starts a job downloading and verifying the new release.
+func DownloadUpdateHandler(c *gin.Context) {
+	// The audit entry is recorded now, the context is not valid once the job runs.
+	recordAudit(c, config.AuditEntry{Action: "update-download", Detail: integrations.GetUpdateStatus().Latest})
+	startJob(c, "update-download", func(p *jobs.Progress) error {
+		path, err := integrations.DownloadUpdate()
+		if err != nil {
+			return err
+		}
+		p.SetMessage("verified and saved as " + path)
+		return nil
+	})
+}
+
+// ApplyUpdateHandler installs the downloaded release and exits, so the
+// service manager restarts fail2ban-ui with the new binary.
+func ApplyUpdateHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ApplyUpdateHandler called (updates.go)")
+	st := integrations.GetUpdateStatus()
+	err := integrations.ApplyUpdate()
+	entry := config.AuditEntry{Action: "update-apply", Detail: st.Latest}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	recordAudit(c, entry)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Update installed, restarting"})
+
+	go func() {
+		// Give the response a moment to reach the client.
+		time.Sleep(time.Second)
+		log.Printf("🔄 Restarting to run %s", st.Latest)
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,113 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersion describes a version of the REST API. Breaking changes (error
+// format, pagination, ...) go into a new version, while the old ones keep
+// working until their sunset.
+type apiVersion struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Status  string `json:"status"` // "current" or "deprecated"
+	// Deprecated and Sunset are announced in the Deprecation (RFC 9745) and
+	// Sunset (RFC 8594) headers of deprecated versions.
+	Deprecated *time.Time `json:"deprecated,omitempty"`
+	Sunset     *time.Time `json:"sunset,omitempty"`
+	Successor  string     `json:"successor,omitempty"`
+}
+
+const currentAPIVersion = "v1"
+
+// apiVersions lists the served API versions. The unversioned /api routes
+// are the v1 routes from before versioning; they stay until the sunset so
+// existing ban actions and scripts keep working.
+var apiVersions = []apiVersion{
+	{Version: "v1", Path: "/api/v1", Status: "current"},
+	{
+		Version:    "unversioned",
+		Path:       "/api",
+		Status:     "deprecated",
+		Deprecated: date(2026, time.October, 18),
+		Sunset:     date(2027, time.October, 18),
+		Successor:  "v1",
+	},
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+// versionHeaders returns the middleware marking the responses of version v.
+func versionHeaders(v apiVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		if v.Status == "deprecated" {
+			h.Set("Deprecation", fmt.Sprintf("@%d", v.Deprecated.Unix()))
+			if v.Sunset != nil {
+				h.Set("Sunset", v.Sunset.Format(http.TimeFormat))
+			}
+			if successor := findAPIVersion(v.Successor); successor != nil {
+				base := c.GetString(basePathKey)
+				rest := strings.TrimPrefix(strings.TrimPrefix(c.Request.URL.Path, base), v.Path)
+				h.Add("Link", fmt.Sprintf("<%s%s%s>; rel=\"successor-version\"", base, successor.Path, rest))
+			}
+			h.Set("API-Version", v.Successor)
+		} else {
+			h.Set("API-Version", v.Version)
+		}
+		c.Next()
+	}
+}
+
+func findAPIVersion(name string) *apiVersion {
+	for i := range apiVersions {
+		if apiVersions[i].Version == name {
+			return &apiVersions[i]
+		}
+	}
+	return nil
+}
+
+var versionPrefix = regexp.MustCompile(`^/api/v[0-9]+(/|$)`)
+
+// apiRoute returns the matched route without base path and API version,
+// e.g. "/api/ban" for both /api/ban and /api/v1/ban, so middlewares can
+// treat all versions of a route alike.
+func apiRoute(c *gin.Context) string {
+	route := strings.TrimPrefix(c.FullPath(), c.GetString(basePathKey))
+	if loc := versionPrefix.FindStringIndex(route); loc != nil {
+		route = "/api/" + route[loc[1]:]
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route
+}
+
+// APIVersionsHandler lists the API versions for clients discovering the API.
+func APIVersionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"current": currentAPIVersion, "versions": apiVersions})
+}
@@ -0,0 +1,220 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+)
+
+// WatchHit is a high-priority event raised when a watched IP is banned,
+// fails in a jail or is reported by an ingest source.
+type WatchHit struct {
+	Entry config.WatchEntry `json:"entry"`
+	Kind  string            `json:"kind"` // see the watchKind constants
+	Event fail2ban.BanEvent `json:"event"`
+	Time  time.Time         `json:"time"`
+}
+
+// Kinds of watchlist hits.
+const (
+	watchKindBan     = "ban"     // banned in a jail
+	watchKindFailure = "failure" // found in the fail2ban log
+	watchKindEvent   = "event"   // posted to the ingest API
+)
+
+const (
+	// maxWatchHits is the number of watchlist hits kept in memory.
+	maxWatchHits = 200
+	// watchAlertInterval limits the alerts of failures and ingested events
+	// to one per IP and kind, as they usually come in bursts. Bans are
+	// always alerted.
+	watchAlertInterval = time.Hour
+)
+
+var (
+	watchHits     []WatchHit
+	watchAlerted  = make(map[string]time.Time) // kind|ip -> last alert
+	watchHitsLock sync.RWMutex
+)
+
+func init() {
+	fail2ban.Events().Subscribe(func(ev fail2ban.BanEvent) { checkWatchlist(watchKindBan, ev) })
+	fail2ban.OnFailure(func(ev fail2ban.BanEvent) { checkWatchlist(watchKindFailure, ev) })
+}
+
+// checkWatchlist records a hit and sends a high-priority alert if the IP
+// of ev is watched.
+func checkWatchlist(kind string, ev fail2ban.BanEvent) {
+	entry, ok := config.MatchWatchlist(ev.IP)
+	if !ok {
+		return
+	}
+	log.Printf("🚩 Watched IP %s (%s): %s in jail %s", ev.IP, entry.Value, kind, ev.Jail)
+
+	now := time.Now()
+	watchHitsLock.Lock()
+	watchHits = append(watchHits, WatchHit{Entry: entry, Kind: kind, Event: ev, Time: now})
+	if len(watchHits) > maxWatchHits {
+		watchHits = watchHits[len(watchHits)-maxWatchHits:]
+	}
+	key := kind + "|" + ev.IP
+	alert := kind == watchKindBan || now.Sub(watchAlerted[key]) >= watchAlertInterval
+	if alert && kind != watchKindBan {
+		watchAlerted[key] = now
+		for k, t := range watchAlerted {
+			if now.Sub(t) >= watchAlertInterval {
+				delete(watchAlerted, k)
+			}
+		}
+	}
+	watchHitsLock.Unlock()
+	if !alert {
+		return
+	}
+
+	go func() {
+		if err := sendWatchlistAlert(entry, kind, ev, config.GetSettings()); err != nil {
+			log.Printf("❌ Failed to send watchlist alert: %v", err)
+		}
+	}()
+}
+
+// sendWatchlistAlert notifies about a watched IP regardless of the alert country filter.
+func sendWatchlistAlert(entry config.WatchEntry, kind string, ev fail2ban.BanEvent, settings config.AppSettings) error {
+	var subject, intro string
+	switch kind {
+	case watchKindFailure:
+		subject = fmt.Sprintf("[Fail2Ban] HIGH PRIORITY: watched IP %s failed in %s", ev.IP, ev.Jail)
+		intro = "A watched IP was found in the fail2ban log."
+	case watchKindEvent:
+		subject = fmt.Sprintf("[Fail2Ban] HIGH PRIORITY: watched IP %s reported by an ingest source", ev.IP)
+		intro = "A watched IP was reported by an ingest source."
+	default:
+		subject = fmt.Sprintf("[Fail2Ban] HIGH PRIORITY: watched IP %s banned in %s", ev.IP, ev.Jail)
+		intro = "A watched IP was banned."
+	}
+	body := fmt.Sprintf(`<p>%s</p>
+<p><b>IP:</b> %s<br><b>Watchlist entry:</b> %s<br><b>Note:</b> %s<br><b>Jail:</b> %s<br><b>Country:</b> %s<br><b>Time:</b> %s</p>`,
+		intro, html.EscapeString(ev.IP), html.EscapeString(entry.Value), html.EscapeString(entry.Note), html.EscapeString(ev.Jail), html.EscapeString(ev.Country), ev.Time.Format(time.RFC1123))
+	if kind != watchKindBan && ev.LogLine != "" {
+		body += fmt.Sprintf("\n<pre>%s</pre>", html.EscapeString(ev.LogLine))
+	}
+	return sendEmailContext(withBan(context.Background(), ev.IP, ev.Jail), settings.Fail2ban.Destemail, subject, body, settings)
+}
+
+// markWatched flags watched IPs in the summary response.
+func markWatched(resp *SummaryResponse) {
+	for i := range resp.Jails {
+		for _, ip := range resp.Jails[i].BannedIPs {
+			if _, ok := config.MatchWatchlist(ip); ok {
+				resp.Jails[i].WatchedIPs = append(resp.Jails[i].WatchedIPs, ip)
+			}
+		}
+	}
+	for i := range resp.LastBans {
+		_, resp.LastBans[i].Watched = config.MatchWatchlist(resp.LastBans[i].IP)
+	}
+}
+
+// ListWatchlistHandler returns all watchlist entries.
+func ListWatchlistHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	entries, next := paginate(page, config.GetWatchlist())
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "nextCursor": next})
+}
+
+// AddWatchlistHandler adds an IP or CIDR to the watchlist.
+func AddWatchlistHandler(c *gin.Context) {
+	var req struct {
+		Value string `json:"value" binding:"required"`
+		Note  string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	entry, err := config.AddWatchEntry(req.Value, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// UpdateWatchlistHandler changes an existing watchlist entry.
+func UpdateWatchlistHandler(c *gin.Context) {
+	var req struct {
+		Value string `json:"value" binding:"required"`
+		Note  string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	entry, err := config.UpdateWatchEntry(c.Param("id"), req.Value, req.Note)
+	if err != nil {
+		c.JSON(watchlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// DeleteWatchlistHandler removes a watchlist entry.
+func DeleteWatchlistHandler(c *gin.Context) {
+	if err := config.DeleteWatchEntry(c.Param("id")); err != nil {
+		c.JSON(watchlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Watchlist entry deleted"})
+}
+
+// WatchHitsHandler returns the most recent watchlist hits, newest first.
+func WatchHitsHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	watchHitsLock.RLock()
+	hits := make([]WatchHit, len(watchHits))
+	for i, h := range watchHits {
+		hits[len(watchHits)-1-i] = h
+	}
+	watchHitsLock.RUnlock()
+	hits, next := paginate(page, hits)
+	c.JSON(http.StatusOK, gin.H{"hits": hits, "nextCursor": next})
+}
+
+func watchlistErrorStatus(err error) int {
+	if errors.Is(err, config.ErrWatchEntryNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
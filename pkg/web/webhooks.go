@@ -0,0 +1,152 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+	"github.com/swissmakers/fail2ban-ui/internal/expr"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/webhooks"
+)
+
+func init() {
+	fail2ban.Events().Subscribe(func(ev fail2ban.BanEvent) {
+		webhooks.Dispatch(eventFields(ev))
+	})
+}
+
+type webhookRequest struct {
+	Name    string `json:"name" binding:"required"`
+	URL     string `json:"url" binding:"required,url"`
+	Filter  string `json:"filter"`
+	Secret  string `json:"secret"`
+	Format  string `json:"format"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (r webhookRequest) toWebhook() (config.Webhook, error) {
+	if err := validateExpression(r.Filter); err != nil {
+		return config.Webhook{}, fmt.Errorf("invalid filter: %w", err)
+	}
+	switch r.Format {
+	case "json":
+		r.Format = config.WebhookFormatJSON
+	case config.WebhookFormatJSON, config.WebhookFormatSlack:
+	default:
+		return config.Webhook{}, fmt.Errorf("unknown format %q, use \"json\" or %q", r.Format, config.WebhookFormatSlack)
+	}
+	return config.Webhook{Name: r.Name, URL: r.URL, Filter: r.Filter, Secret: r.Secret, Format: r.Format, Enabled: r.Enabled}, nil
+}
+
+// ListWebhooksHandler returns all webhooks with their last delivery result.
+func ListWebhooksHandler(c *gin.Context) {
+	page, ok := parsePage(c, defaultPageSize)
+	if !ok {
+		return
+	}
+	var list []config.Webhook
+	for _, w := range config.GetWebhooks() {
+		list = append(list, w.Redacted())
+	}
+	list, next := paginate(page, list)
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks":   list,
+		"deliveries": webhooks.LastDeliveries(),
+		"nextCursor": next,
+	})
+}
+
+// AddWebhookHandler registers a new webhook.
+func AddWebhookHandler(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	w, err := req.toWebhook()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook: " + err.Error()})
+		return
+	}
+	w, err = config.AddWebhook(w)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": w.Redacted()})
+}
+
+// UpdateWebhookHandler replaces an existing webhook.
+func UpdateWebhookHandler(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	w, err := req.toWebhook()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook: " + err.Error()})
+		return
+	}
+	w, err = config.UpdateWebhook(c.Param("id"), w)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": w.Redacted()})
+}
+
+// DeleteWebhookHandler removes a webhook.
+func DeleteWebhookHandler(c *gin.Context) {
+	if err := config.DeleteWebhook(c.Param("id")); err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// TestWebhookHandler sends a sample event to a webhook and reports whether
+// its filter would have matched it.
+func TestWebhookHandler(c *gin.Context) {
+	id := c.Param("id")
+	for _, w := range config.GetWebhooks() {
+		if w.ID != id {
+			continue
+		}
+		fields := sampleEventFields()
+		matched, _ := expr.Match(w.Filter, fields)
+		c.JSON(http.StatusOK, gin.H{
+			"delivery":      webhooks.Send(w, fields),
+			"filterMatch":   matched,
+			"samplePayload": fields,
+		})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": config.ErrWebhookNotFound.Error()})
+}
+
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, config.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,253 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/config"
+)
+
+// maxWhitelistImport limits the entries of one whitelist import.
+const maxWhitelistImport = 5000
+
+// listImportRequest is the JSON form of a whitelist or ban import; the
+// list can also be uploaded as multipart field "file" or sent as
+// text/plain, with the jail as form field or query parameter.
+type listImportRequest struct {
+	Text string `json:"text"`
+	Jail string `json:"jail"` // ban imports only
+}
+
+// invalidEntry is an entry of the imported list that is no IP or CIDR.
+type invalidEntry struct {
+	Line  int    `json:"line"`
+	Value string `json:"value"`
+	Error string `json:"error"`
+}
+
+// overlapEntry is an imported entry overlapping other whitelist entries:
+// it is either covered by one of them or covers some of them.
+type overlapEntry struct {
+	Entry string   `json:"entry"`
+	With  []string `json:"with"`
+}
+
+// whitelistImport is the preview, and after applying the result, of an
+// import. Entries already present or covered are not added; entries
+// covering existing ones are added and listed in Covers.
+type whitelistImport struct {
+	Added      []string       `json:"added"`
+	Duplicates []string       `json:"duplicates"`
+	Covered    []overlapEntry `json:"covered"`
+	Covers     []overlapEntry `json:"covers"`
+	Invalid    []invalidEntry `json:"invalid"`
+	IgnoreIP   string         `json:"ignoreip"` // the resulting value
+	Applied    bool           `json:"applied"`
+}
+
+// ImportWhitelistHandler adds a list of IPs and CIDRs to the whitelist
+// (ignoreip) at once. Entries are separated by whitespace, commas or
+// newlines, "#" starts a comment. With ?dryRun=true it only returns the
+// preview.
+func ImportWhitelistHandler(c *gin.Context) {
+	config.DebugLog("----------------------------")
+	config.DebugLog("ImportWhitelistHandler called (whitelist.go)") // entry point
+	list, err := listImportText(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req := config.CopySettings()
+	imp, err := planWhitelistImport(req.Fail2ban.IgnoreIP, list.Text)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("dryRun") == "true" || len(imp.Added) == 0 {
+		c.JSON(http.StatusOK, imp)
+		return
+	}
+
+	req.Fail2ban.IgnoreIP = imp.IgnoreIP
+	if label, err := validateSettings(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label, "details": err.Error()})
+		return
+	}
+	if _, err := applySettings(req); err != nil {
+		respondError(c, err)
+		return
+	}
+	imp.Applied = true
+	recordAudit(c, config.AuditEntry{Action: "whitelist.import", Detail: fmt.Sprintf("%d entries added", len(imp.Added))})
+	recordChange(c, "Import %d whitelist entries", len(imp.Added))
+	c.JSON(http.StatusOK, imp)
+}
+
+// listImportText returns the list sent as file, JSON or plain text.
+func listImportText(c *gin.Context) (listImportRequest, error) {
+	req := listImportRequest{Jail: c.Query("jail")}
+	switch c.ContentType() {
+	case "multipart/form-data":
+		file, err := c.FormFile("file")
+		if err != nil {
+			return req, fmt.Errorf("missing file field \"file\"")
+		}
+		f, err := file.Open()
+		if err != nil {
+			return req, err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		req.Text = string(data)
+		if jail := c.PostForm("jail"); jail != "" {
+			req.Jail = jail
+		}
+		return req, err
+	case "text/plain":
+		data, err := io.ReadAll(c.Request.Body)
+		req.Text = string(data)
+		return req, err
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %v", err)
+	}
+	return req, nil
+}
+
+// planWhitelistImport validates, deduplicates and checks the entries of
+// text against the current ignoreip value and returns the preview.
+func planWhitelistImport(current, text string) (whitelistImport, error) {
+	imp := whitelistImport{
+		Added:      []string{},
+		Duplicates: []string{},
+		Covered:    []overlapEntry{},
+		Covers:     []overlapEntry{},
+		Invalid:    []invalidEntry{},
+	}
+	existing := strings.FieldsFunc(current, isIgnoreIPSeparator)
+	// Existing entries that are no IP or CIDR, e.g. host names, are kept
+	// but cannot overlap.
+	prefixes := make(map[string]netip.Prefix, len(existing))
+	for _, e := range existing {
+		if p, err := parseWhitelistEntry(e); err == nil {
+			prefixes[e] = p
+		}
+	}
+
+	type candidate struct {
+		entry  string
+		prefix netip.Prefix
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, e := range existing {
+		if p, ok := prefixes[e]; ok {
+			seen[whitelistString(p)] = true
+		} else {
+			seen[e] = true
+		}
+	}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		content, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, value := range strings.FieldsFunc(content, isIgnoreIPSeparator) {
+			p, err := parseWhitelistEntry(value)
+			if err != nil {
+				imp.Invalid = append(imp.Invalid, invalidEntry{Line: line, Value: value, Error: err.Error()})
+				continue
+			}
+			entry := whitelistString(p)
+			if seen[entry] {
+				imp.Duplicates = append(imp.Duplicates, entry)
+				continue
+			}
+			seen[entry] = true
+			candidates = append(candidates, candidate{entry, p})
+			if len(candidates) > maxWhitelistImport {
+				return imp, fmt.Errorf("too many entries, at most %d can be imported at once", maxWhitelistImport)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return imp, err
+	}
+
+	// An entry is skipped if an existing or another imported, larger
+	// entry already covers it.
+	for _, cand := range candidates {
+		var by []string
+		for _, e := range existing {
+			if p, ok := prefixes[e]; ok && p.Bits() <= cand.prefix.Bits() && p.Contains(cand.prefix.Addr()) {
+				by = append(by, e)
+			}
+		}
+		for _, other := range candidates {
+			if other.entry != cand.entry && other.prefix.Bits() < cand.prefix.Bits() && other.prefix.Contains(cand.prefix.Addr()) {
+				by = append(by, other.entry)
+			}
+		}
+		if len(by) > 0 {
+			imp.Covered = append(imp.Covered, overlapEntry{Entry: cand.entry, With: by})
+			continue
+		}
+		var covers []string
+		for _, e := range existing {
+			if p, ok := prefixes[e]; ok && cand.prefix.Bits() < p.Bits() && cand.prefix.Contains(p.Addr()) {
+				covers = append(covers, e)
+			}
+		}
+		if len(covers) > 0 {
+			imp.Covers = append(imp.Covers, overlapEntry{Entry: cand.entry, With: covers})
+		}
+		imp.Added = append(imp.Added, cand.entry)
+	}
+	imp.IgnoreIP = strings.Join(append(existing, imp.Added...), " ")
+	return imp, nil
+}
+
+// parseWhitelistEntry parses an IP or CIDR.
+func parseWhitelistEntry(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		p, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR")
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("not an IP or CIDR")
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// whitelistString formats p like fail2ban expects it: single addresses
+// without prefix length, networks in their canonical form.
+func whitelistString(p netip.Prefix) string {
+	if p.IsSingleIP() {
+		return p.Addr().String()
+	}
+	return p.String()
+}
@@ -0,0 +1,56 @@
+// Fail2ban UI - A Swiss made, management interface for Fail2ban.
+//
+// Copyright (C) 2025 Swissmakers GmbH (https://swissmakers.ch)
+//
+// Licensed under the GNU General Public License, Version 3 (GPL-3.0)
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.gnu.org/licenses/gpl-3.0.en.html
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swissmakers/fail2ban-ui/internal/fail2ban"
+	"github.com/swissmakers/fail2ban-ui/internal/whois"
+)
+
+// WhoisHandler returns the cached whois record of an IP. Uncached or
+// expired records are looked up in the background; the client polls
+// until "pending" is false. Tenants only see IPs banned in their jails.
+func WhoisHandler(c *gin.Context) {
+	ip, ok := ipParam(c)
+	if !ok {
+		return
+	}
+	if requestTenant(c) != nil && len(visibleEvents(c, fail2ban.Events().ByIP(ip))) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "IP not found"})
+		return
+	}
+	rec, found, err := whois.Lookup(ip)
+	if errors.Is(err, whois.ErrNotPublic) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := gin.H{"ip": ip, "pending": whois.Pending(ip)}
+	if !found {
+		c.JSON(http.StatusAccepted, resp)
+		return
+	}
+	resp["record"] = rec
+	c.JSON(http.StatusOK, resp)
+}